@@ -0,0 +1,116 @@
+// Package backoff retries an operation with capped exponential backoff and jitter,
+// for example to wait for a service to come up
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultBase = time.Second
+	defaultCap  = time.Minute
+)
+
+// Backoff is a retry schedule. Delays double from a base up to a cap and each is
+// jittered down to between half and all of its value, so that clients started
+// together don't retry in lockstep.
+type Backoff struct {
+	base       time.Duration
+	cap        time.Duration
+	maxElapsed time.Duration
+	// int63n returns a random number in [0, n)
+	int63n func(n int64) int64
+}
+
+// Option configures a Backoff
+type Option func(*Backoff)
+
+// WithBase sets the delay before the first retry. The default is one second.
+func WithBase(base time.Duration) Option {
+	return func(b *Backoff) {
+		if base > 0 {
+			b.base = base
+		}
+	}
+}
+
+// WithCap sets the longest delay between two attempts. The default is one minute.
+func WithCap(cap time.Duration) Option {
+	return func(b *Backoff) {
+		if cap > 0 {
+			b.cap = cap
+		}
+	}
+}
+
+// WithMaxElapsed makes Retry give up once another delay would take it past maxElapsed
+// since its start. Without it Retry only stops when its context is done.
+func WithMaxElapsed(maxElapsed time.Duration) Option {
+	return func(b *Backoff) {
+		b.maxElapsed = maxElapsed
+	}
+}
+
+// New creates a Backoff
+func New(opts ...Option) *Backoff {
+	b := &Backoff{
+		base: defaultBase,
+		cap:  defaultCap,
+		// Jitter needs no cryptographically secure randomness
+		/* #nosec */
+		int63n: rand.Int63n,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.cap < b.base {
+		b.cap = b.base
+	}
+
+	return b
+}
+
+// Delay returns the jittered wait after the attempt-th failed attempt, counting from 0
+func (b *Backoff) Delay(attempt int) time.Duration {
+	d := b.cap
+	// Past this many doublings the base exceeds any cap and the shift could overflow
+	if attempt < 32 {
+		if doubled := b.base << uint(attempt); doubled > 0 && doubled < b.cap {
+			d = doubled
+		}
+	}
+
+	half := d / 2
+	return half + time.Duration(b.int63n(int64(d-half)+1))
+}
+
+// Retry calls op until it succeeds, ctx is done or the maximum elapsed time would be
+// exceeded, waiting Delay between attempts. It returns nil once op succeeds, or the
+// last error of op otherwise.
+func (b *Backoff) Retry(ctx context.Context, op func(ctx context.Context) error) error {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay := b.Delay(attempt)
+		if b.maxElapsed > 0 && time.Since(start)+delay > b.maxElapsed {
+			return fmt.Errorf("gave up after %d attempts in %v: %w", attempt+1, time.Since(start).Round(time.Millisecond), err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
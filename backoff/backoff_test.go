@@ -0,0 +1,88 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := New(WithBase(time.Second), WithCap(10*time.Second))
+
+	// Without jitter every delay is half of the uncapped schedule
+	b.int63n = func(int64) int64 { return 0 }
+	var lowest []time.Duration
+	for attempt := 0; attempt < 6; attempt++ {
+		lowest = append(lowest, b.Delay(attempt))
+	}
+	assert.Equal(t, []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	}, lowest)
+
+	// With the most jitter the delays reach the schedule, capped
+	b.int63n = func(n int64) int64 { return n - 1 }
+	var highest []time.Duration
+	for attempt := 0; attempt < 6; attempt++ {
+		highest = append(highest, b.Delay(attempt))
+	}
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}, highest)
+
+	assert.Equal(t, 10*time.Second, b.Delay(1000))
+}
+
+func TestBackoff_Delay_Jittered(t *testing.T) {
+	b := New(WithBase(time.Second), WithCap(time.Minute))
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		delay := b.Delay(3)
+		assert.GreaterOrEqual(t, delay, 4*time.Second)
+		assert.LessOrEqual(t, delay, 8*time.Second)
+		seen[delay] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
+
+func TestBackoff_Retry(t *testing.T) {
+	b := New(WithBase(time.Millisecond), WithCap(2*time.Millisecond))
+
+	calls := 0
+	err := b.Retry(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestBackoff_Retry_MaxElapsed(t *testing.T) {
+	b := New(WithBase(10*time.Millisecond), WithCap(10*time.Millisecond), WithMaxElapsed(50*time.Millisecond))
+	notReady := errors.New("not ready")
+
+	start := time.Now()
+	err := b.Retry(context.Background(), func(context.Context) error { return notReady })
+
+	assert.ErrorIs(t, err, notReady)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestBackoff_Retry_Context(t *testing.T) {
+	b := New(WithBase(time.Hour))
+	notReady := errors.New("not ready")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := b.Retry(ctx, func(context.Context) error { return notReady })
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, notReady)
+}
@@ -31,12 +31,14 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
 
 	"mail2calendar/internal/config"
 	"mail2calendar/internal/domain/health"
 	"mail2calendar/internal/infrastructure/logger"
+	"mail2calendar/internal/infrastructure/tracing"
 )
 
 func main() {
@@ -49,6 +51,18 @@ func main() {
 		log.SetLevel(logrus.DebugLevel)
 	}
 
+	// Setup tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enable:         cfg.OTEL.Enable,
+		OTLPEndpoint:   cfg.OTEL.OTLPEndpoint,
+		ServiceName:    cfg.OTEL.ServiceName,
+		ServiceVersion: cfg.OTEL.ServiceVersion,
+		SamplerRatio:   cfg.OTEL.SamplerRatio,
+	})
+	if err != nil {
+		log.Fatal("failed to setup tracing", err)
+	}
+
 	// Setup database connection string
 	dbURL := fmt.Sprintf("%s://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.DB.Driver,
@@ -95,6 +109,9 @@ func main() {
 	healthUseCase := health.New(healthRepo)
 	health.RegisterHTTPEndPoints(router, healthUseCase)
 
+	// Setup Prometheus metrics
+	router.Handle("/metrics", promhttp.Handler())
+
 	// Start server
 	srv := &http.Server{
 		Addr:              fmt.Sprintf("%s:%d", cfg.API.Host, cfg.API.Port),
@@ -123,5 +140,10 @@ func main() {
 		log.Fatal("server forced to shutdown", err)
 	}
 
+	// Flush the spans not yet exported
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("failed to flush traces", err)
+	}
+
 	log.Info("server exited properly")
 }
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,16 +17,58 @@ import (
 	"mail2calendar/internal/domain/ner/handler"
 	"mail2calendar/internal/domain/ner/usecase"
 	"mail2calendar/internal/grpc/client"
+	"mail2calendar/internal/infrastructure/tracing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/redis/go-redis/v9"
 )
 
+// corsOptions builds the CORS options from allowedOrigins, a comma-separated list
+// of origins like CORS_ALLOWED_ORIGINS. Credentials are allowed, so the wildcard
+// origin is rejected: browsers refuse it on credentialed requests.
+func corsOptions(allowedOrigins string) (cors.Options, error) {
+	var origins []string
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			return cors.Options{}, errors.New("wildcard origin is not allowed with credentials")
+		}
+		origins = append(origins, origin)
+	}
+	if len(origins) == 0 {
+		return cors.Options{}, errors.New("no allowed origins configured")
+	}
+
+	return cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"Link"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}, nil
+}
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enable:         cfg.OTEL.Enable,
+		OTLPEndpoint:   cfg.OTEL.OTLPEndpoint,
+		ServiceName:    cfg.OTEL.ServiceName,
+		ServiceVersion: cfg.OTEL.ServiceVersion,
+		SamplerRatio:   cfg.OTEL.SamplerRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
 	// Initialize Redis client
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -36,14 +80,11 @@ func main() {
 	r := chi.NewRouter()
 
 	// Add middleware
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	corsOpts, err := corsOptions(cfg.CORS.AllowedOrigins)
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	r.Use(cors.Handler(corsOpts))
 
 	// Initialize rate limiter
 	rateLimiter := middleware.NewRedisRateLimiter(redisClient, 10, time.Minute)
@@ -95,5 +136,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Flush the spans not yet exported
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
+
 	log.Println("Server exited properly")
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/cors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorsOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowedOrigins string
+		expected       []string
+		wantErr        bool
+	}{
+		{
+			name:           "single origin",
+			allowedOrigins: "http://localhost:3000",
+			expected:       []string{"http://localhost:3000"},
+		},
+		{
+			name:           "comma-separated origins",
+			allowedOrigins: "https://app.example.com, https://admin.example.com,",
+			expected:       []string{"https://app.example.com", "https://admin.example.com"},
+		},
+		{
+			name:           "wildcard is rejected",
+			allowedOrigins: "*",
+			wantErr:        true,
+		},
+		{
+			name:           "wildcard among origins is rejected",
+			allowedOrigins: "https://app.example.com,*",
+			wantErr:        true,
+		},
+		{
+			name:           "no origins",
+			allowedOrigins: " , ",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := corsOptions(tt.allowedOrigins)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, opts.AllowedOrigins)
+			assert.True(t, opts.AllowCredentials)
+		})
+	}
+}
+
+func TestCorsOptions_Handler(t *testing.T) {
+	opts, err := corsOptions("https://app.example.com")
+	require.NoError(t, err)
+	handler := cors.Handler(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		origin   string
+		expected string
+	}{
+		{
+			name:     "allowed origin is reflected",
+			origin:   "https://app.example.com",
+			expected: "https://app.example.com",
+		},
+		{
+			name:   "disallowed origin is not reflected",
+			origin: "https://evil.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Header().Get("Access-Control-Allow-Origin"))
+			if tt.expected == "" {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
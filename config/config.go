@@ -16,6 +16,9 @@ type Config struct {
 
 	OpenTelemetry
 	Session
+
+	NER
+	Mail
 }
 
 func New() *Config {
@@ -32,5 +35,7 @@ func New() *Config {
 		Elasticsearch: ElasticSearch(),
 		Session:       NewSession(),
 		OpenTelemetry: NewOpenTelemetry(),
+		NER:           NewNER(),
+		Mail:          NewMail(),
 	}
 }
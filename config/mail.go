@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Mail chứa cấu hình gửi email xác thực và đặt lại mật khẩu cho người dùng
+type Mail struct {
+	// SMTPHost là địa chỉ SMTP server, bắt buộc trừ khi bật DevLogTokens
+	SMTPHost string `split_words:"true"`
+	SMTPPort string `split_words:"true" default:"587"`
+	SMTPUser string `split_words:"true"`
+	SMTPPass string `split_words:"true"`
+	From     string
+	// BaseURL là địa chỉ công khai của API, dùng để tạo link trong email
+	BaseURL string `split_words:"true" default:"http://localhost:8080"`
+	// DevLogTokens chỉ dùng khi phát triển: ghi token vào log thay vì gửi email
+	DevLogTokens bool `split_words:"true" default:"false"`
+}
+
+// NewMail đọc cấu hình gửi email từ các biến môi trường MAIL_*
+func NewMail() Mail {
+	var mail Mail
+	envconfig.MustProcess("MAIL", &mail)
+
+	return mail
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// NER chứa cấu hình kết nối tới NER service
+type NER struct {
+	// ServiceURL là địa chỉ HTTP của NER service, để trống nếu không dùng
+	ServiceURL     string        `split_words:"true"`
+	RequestTimeout time.Duration `split_words:"true" default:"10s"`
+}
+
+// NewNER đọc cấu hình NER từ các biến môi trường NER_*
+func NewNER() NER {
+	var ner NER
+	envconfig.MustProcess("NER", &ner)
+
+	return ner
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    token   TEXT PRIMARY KEY,
+    user_id BIGINT      NOT NULL CONSTRAINT user_token_user_fk REFERENCES users ON DELETE CASCADE,
+    purpose TEXT        NOT NULL,
+    expiry  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_idx ON user_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_tokens;
+-- +goose StatementEnd
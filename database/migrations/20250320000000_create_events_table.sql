@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS events
+(
+    id              bigint generated always as identity primary key,
+    user_id         bigint      NOT NULL CONSTRAINT event_user_fk REFERENCES users ON DELETE CASCADE,
+    subject         text        NOT NULL,
+    start_time      timestamptz NOT NULL,
+    end_time        timestamptz NOT NULL,
+    location        text,
+    attendees       jsonb,
+    message_id      text,
+    google_event_id text        NOT NULL,
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS event_user_id_created_at ON events (user_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE events;
+-- +goose StatementEnd
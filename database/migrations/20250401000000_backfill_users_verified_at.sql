@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Người dùng tạo trước khi có bước xác thực email không bao giờ nhận được token xác thực,
+-- coi như đã xác thực để họ vẫn đăng nhập được.
+UPDATE users SET verified_at = current_timestamp WHERE verified_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Không hoàn tác: không còn phân biệt được người dùng nào đã được đánh dấu bởi migration này.
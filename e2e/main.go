@@ -2,13 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
 	"time"
 
+	"mail2calendar/backoff"
 	"mail2calendar/config"
 	calendarPb "mail2calendar/internal/domain/calendar/proto"
 )
@@ -35,13 +36,15 @@ func run() {
 }
 
 func testCalendar() {
-	testCreateEvent()
+	eventID := testCreateEvent()
+	testGetEvent(eventID)
+	testCreateRecurringEvent()
 	testListEvents()
 	testUpdateEvent()
 	testDeleteEvent()
 }
 
-func testCreateEvent() {
+func testCreateEvent() string {
 	event := &calendarPb.CreateEventRequest{
 		Event: &calendarPb.Event{
 			Title:       "Test Event",
@@ -81,6 +84,69 @@ func testCreateEvent() {
 	}
 
 	log.Println("testCreateEvent passes")
+	return response.Event.Id
+}
+
+func testCreateRecurringEvent() {
+	event := &calendarPb.CreateEventRequest{
+		Event: &calendarPb.Event{
+			Title:          "Weekly Test Event",
+			StartTime:      time.Now().Unix(),
+			EndTime:        time.Now().Add(time.Hour).Unix(),
+			RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+		},
+		UserId: "test-user",
+	}
+
+	bR, _ := json.Marshal(event)
+
+	resp, err := http.Post(
+		fmt.Sprintf("%s/api/v1/calendar/events", url),
+		"application/json",
+		bytes.NewBuffer(bR),
+	)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		log.Fatalf("error code fail, want %d, got %d\n", http.StatusCreated, resp.StatusCode)
+	}
+
+	var response calendarPb.CreateEventResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		log.Fatalln(err)
+	}
+
+	if response.Event == nil || response.Event.RecurrenceRule != event.Event.RecurrenceRule || !response.Event.IsRecurring {
+		log.Fatalf("expected weekly recurring event in response, got %v\n", response.Event)
+	}
+
+	log.Println("testCreateRecurringEvent passes")
+}
+
+func testGetEvent(eventID string) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/calendar/events/%s?user_id=test-user", url, eventID))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("error code fail, want %d, got %d\n", http.StatusOK, resp.StatusCode)
+	}
+
+	var response calendarPb.GetEventResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		log.Fatalln(err)
+	}
+
+	if response.Event == nil || response.Event.Id != eventID {
+		log.Fatalf("expected event %q in response, got %v\n", eventID, response.Event)
+	}
+
+	log.Println("testGetEvent passes")
 }
 
 func testListEvents() {
@@ -171,34 +237,29 @@ func testDeleteEvent() {
 	log.Println("testDeleteEvent passes")
 }
 
+// waitForApiTimeout is how long waitForApi waits for the api to become ready
+const waitForApiTimeout = 5 * time.Minute
+
 func waitForApi(readinessURL string) {
 	log.Println("Connecting to api with exponential backoff... ")
-	for {
-		//nolint:gosec
-		_, err := http.Get(readinessURL)
-		if err == nil {
-			log.Println("api is up")
-			return
+	b := backoff.New(backoff.WithMaxElapsed(waitForApiTimeout))
+	err := b.Retry(context.Background(), func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, readinessURL, nil)
+		if err != nil {
+			return err
 		}
-
-		base, capacity := time.Second, time.Minute
-		for backoff := base; err != nil; backoff <<= 1 {
-			if backoff > capacity {
-				backoff = capacity
-			}
-
-			// A pseudo-random number generator here is fine. No need to be
-			// cryptographically secure. Ignore with the following comment:
-			/* #nosec */
-			jitter := rand.Int63n(int64(backoff * 3))
-			sleep := base + time.Duration(jitter)
-			time.Sleep(sleep)
-			//nolint:gosec
-			_, err := http.Get(readinessURL)
-			if err == nil {
-				log.Println("api is up")
-				return
-			}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
 		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("api not ready: %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalln(err)
 	}
+	log.Println("api is up")
 }
@@ -17,6 +17,7 @@ func (User) Fields() []ent.Field {
 		field.String("first_name").Optional(),
 		field.String("middle_name").Optional(),
 		field.String("last_name").Optional(),
+		field.String("timezone").Optional(),
 		field.String("email"),
 		field.String("password"),
 		field.Time("verified_at").Optional().Nillable().StructTag(`json:"-"`),
@@ -15,6 +15,13 @@ type Storage interface {
 	ListFiles(ctx context.Context) ([]FileInfo, error)
 }
 
+// StreamStorage is a Storage that can upload directly from a reader
+// without holding the whole file in memory
+type StreamStorage interface {
+	Storage
+	SaveStream(ctx context.Context, r io.Reader, size int64, ext string) (string, error)
+}
+
 // MinioClientInterface defines the interface for minio client operations
 type MinioClientInterface interface {
 	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
@@ -11,64 +11,205 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/h2non/filetype"
 	"github.com/minio/minio-go/v7"
 )
 
 const (
-	maxFileSize = 10 * 1024 * 1024 // 10MB
+	defaultMaxFileSize = 10 * 1024 * 1024 // 10MB
+	sniffLen           = 8192             // bytes sniffed; OOXML entries can sit a few KB in
 )
 
-var allowedExtensions = map[string]bool{
-	".pdf":  true,
-	".doc":  true,
-	".docx": true,
-	".xls":  true,
-	".xlsx": true,
-	".txt":  true,
-	".png":  true,
-	".jpg":  true,
-	".jpeg": true,
+var defaultAllowedExtensions = []string{".pdf", ".doc", ".docx", ".xls", ".xlsx", ".txt", ".png", ".jpg", ".jpeg"}
+
+// signatureTypes maps each allowed extension to the filetype extension whose
+// signature genuine files start with. Plain text has no signature and is checked
+// by looksLikeText.
+var signatureTypes = map[string]string{
+	".pdf":  "pdf",
+	".doc":  "doc",
+	".docx": "docx",
+	".xls":  "xls",
+	".xlsx": "xlsx",
+	".png":  "png",
+	".jpg":  "jpg",
+	".jpeg": "jpg",
+}
+
+// MinioStorageConfig holds the upload limits enforced by MinioStorage.
+// Zero values fall back to the defaults from DefaultMinioStorageConfig.
+type MinioStorageConfig struct {
+	// MaxFileSize is the largest accepted file in bytes
+	MaxFileSize int64
+	// AllowedExtensions must be lowercase with a leading dot, e.g. ".pdf"
+	AllowedExtensions []string
+}
+
+// DefaultMinioStorageConfig returns the limits used when none are configured
+func DefaultMinioStorageConfig() MinioStorageConfig {
+	return MinioStorageConfig{
+		MaxFileSize:       defaultMaxFileSize,
+		AllowedExtensions: append([]string(nil), defaultAllowedExtensions...),
+	}
+}
+
+// Validate checks that the configured limits are usable
+func (c MinioStorageConfig) Validate() error {
+	if c.MaxFileSize < 0 {
+		return fmt.Errorf("max file size must not be negative, got %d", c.MaxFileSize)
+	}
+	for _, ext := range c.AllowedExtensions {
+		if len(ext) < 2 || ext[0] != '.' || ext != strings.ToLower(ext) {
+			return fmt.Errorf("extension %q must be lowercase with a leading dot", ext)
+		}
+	}
+	return nil
 }
 
 type MinioStorage struct {
-	client     MinioClientInterface
-	bucketName string
+	client            MinioClientInterface
+	bucketName        string
+	maxFileSize       int64
+	allowedExtensions map[string]bool
 }
 
 // NewMinioStorage creates a new MinIO storage instance
-func NewMinioStorage(client *minio.Client, bucketName string) Storage {
-	return &MinioStorage{
-		client:     client,
-		bucketName: bucketName,
+func NewMinioStorage(client *minio.Client, bucketName string, cfg MinioStorageConfig) (StreamStorage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid minio storage config: %w", err)
+	}
+
+	s := &MinioStorage{
+		client:      client,
+		bucketName:  bucketName,
+		maxFileSize: cfg.MaxFileSize,
+	}
+	if len(cfg.AllowedExtensions) > 0 {
+		s.allowedExtensions = make(map[string]bool, len(cfg.AllowedExtensions))
+		for _, ext := range cfg.AllowedExtensions {
+			s.allowedExtensions[ext] = true
+		}
+	}
+	return s, nil
+}
+
+// fileSizeLimit returns the configured maximum file size or the default
+func (s *MinioStorage) fileSizeLimit() int64 {
+	if s.maxFileSize > 0 {
+		return s.maxFileSize
+	}
+	return defaultMaxFileSize
+}
+
+// isAllowedExtension reports whether ext is in the configured or default allowlist
+func (s *MinioStorage) isAllowedExtension(ext string) bool {
+	if s.allowedExtensions != nil {
+		return s.allowedExtensions[ext]
+	}
+	for _, allowed := range defaultAllowedExtensions {
+		if ext == allowed {
+			return true
+		}
 	}
+	return false
 }
 
 // validateFile checks if the file meets size and extension requirements
-func (s *MinioStorage) validateFile(data []byte, ext string) error {
-	if len(data) > maxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", maxFileSize)
+func (s *MinioStorage) validateFile(size int64, ext string) error {
+	if maxSize := s.fileSizeLimit(); size > maxSize {
+		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", maxSize)
 	}
 
 	ext = strings.ToLower(ext)
-	if !allowedExtensions[ext] {
+	if !s.isAllowedExtension(ext) {
 		return fmt.Errorf("file extension %s is not allowed", ext)
 	}
 	return nil
 }
 
+// validateContent sniffs the file header and rejects content that contradicts the extension.
+// Configured extensions without a known signature are not sniffed.
+func validateContent(header []byte, ext string) error {
+	ext = strings.ToLower(ext)
+	switch signature, ok := signatureTypes[ext]; {
+	case ok:
+		if filetype.Is(header, signature) {
+			return nil
+		}
+	case ext == ".txt":
+		if looksLikeText(header) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if kind, _ := filetype.Match(header); kind != filetype.Unknown {
+		return fmt.Errorf("file content type %s does not match extension %s", kind.MIME.Value, ext)
+	}
+	return fmt.Errorf("file content does not match extension %s", ext)
+}
+
+// looksLikeText reports whether header can start a text file: it has no known binary
+// signature and no NUL bytes, unless it starts with a UTF-16 byte order mark. Any
+// 8-bit encoding is accepted, not only UTF-8.
+func looksLikeText(header []byte) bool {
+	if filetype.Matches(header) {
+		return false
+	}
+	if bytes.HasPrefix(header, []byte{0xFE, 0xFF}) || bytes.HasPrefix(header, []byte{0xFF, 0xFE}) {
+		return true
+	}
+	return bytes.IndexByte(header, 0) == -1
+}
+
 // Save stores a file in MinIO storage
 func (s *MinioStorage) Save(ctx context.Context, data []byte, ext string) (string, error) {
-	if err := s.validateFile(data, ext); err != nil {
+	return s.SaveStream(ctx, bytes.NewReader(data), int64(len(data)), ext)
+}
+
+// SaveStream stores a file in MinIO storage by streaming it from r.
+// The size must be known up front so it can be validated and passed to PutObject.
+func (s *MinioStorage) SaveStream(ctx context.Context, r io.Reader, size int64, ext string) (string, error) {
+	if err := s.validateFile(size, ext); err != nil {
+		return "", err
+	}
+
+	// Rewind seekable readers after sniffing so the original reader is uploaded as is
+	seeker, seekable := r.(io.Seeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+
+	header := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read file header: %w", err)
+	}
+	header = header[:n]
+	if err := validateContent(header, ext); err != nil {
 		return "", err
 	}
 
+	if seekable {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to rewind file: %w", err)
+		}
+	} else {
+		r = io.MultiReader(bytes.NewReader(header), r)
+	}
+
 	fileID := uuid.New().String()
 	key := fmt.Sprintf("%s/%s%s", time.Now().Format("2006/01/02"), fileID, ext)
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	_, err := s.client.PutObject(ctx, s.bucketName, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+	_, err = s.client.PutObject(ctx, s.bucketName, key, r, size, minio.PutObjectOptions{
 		ContentType: getContentType(ext),
 	})
 
@@ -81,7 +222,7 @@ func (s *MinioStorage) Save(ctx context.Context, data []byte, ext string) (strin
 // Get retrieves a file from MinIO storage
 func (s *MinioStorage) Get(ctx context.Context, fileID string) ([]byte, string, error) {
 	ext := strings.ToLower(filepath.Ext(fileID))
-	if !allowedExtensions[ext] {
+	if !s.isAllowedExtension(ext) {
 		return nil, "", fmt.Errorf("file extension %s is not allowed", ext)
 	}
 
@@ -99,8 +240,8 @@ func (s *MinioStorage) Get(ctx context.Context, fileID string) ([]byte, string,
 		return nil, "", fmt.Errorf("failed to read file content: %w", err)
 	}
 
-	if len(data) > maxFileSize {
-		return nil, "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", maxFileSize)
+	if maxSize := s.fileSizeLimit(); int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", maxSize)
 	}
 
 	return data, ext, nil
@@ -109,7 +250,7 @@ func (s *MinioStorage) Get(ctx context.Context, fileID string) ([]byte, string,
 // Delete removes a file from MinIO storage
 func (s *MinioStorage) Delete(ctx context.Context, fileID string) error {
 	ext := strings.ToLower(filepath.Ext(fileID))
-	if !allowedExtensions[ext] {
+	if !s.isAllowedExtension(ext) {
 		return fmt.Errorf("file extension %s is not allowed", ext)
 	}
 
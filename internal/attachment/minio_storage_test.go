@@ -1,8 +1,11 @@
 package attachment
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -49,7 +52,7 @@ func TestMinioStorage_Save(t *testing.T) {
 	}{
 		{
 			name: "successful save",
-			data: []byte("test data"),
+			data: []byte("%PDF-1.4 test data"),
 			ext:  ".pdf",
 			setupMock: func(m *mockMinioClient) {
 				m.On("PutObject",
@@ -65,7 +68,7 @@ func TestMinioStorage_Save(t *testing.T) {
 		},
 		{
 			name: "file too large",
-			data: make([]byte, maxFileSize+1),
+			data: make([]byte, defaultMaxFileSize+1),
 			ext:  ".pdf",
 			setupMock: func(m *mockMinioClient) {
 				// No mock needed as it should fail before calling PutObject
@@ -83,9 +86,27 @@ func TestMinioStorage_Save(t *testing.T) {
 			wantErr:     true,
 			expectedErr: "file extension .invalid is not allowed",
 		},
+		{
+			name: "jpg with pdf magic bytes",
+			data: []byte("%PDF-1.4 disguised document"),
+			ext:  ".jpg",
+			setupMock: func(m *mockMinioClient) {
+			},
+			wantErr:     true,
+			expectedErr: "file content type application/pdf does not match extension .jpg",
+		},
+		{
+			name: "pdf with html payload",
+			data: []byte("<html><script>alert(1)</script></html>"),
+			ext:  ".pdf",
+			setupMock: func(m *mockMinioClient) {
+			},
+			wantErr:     true,
+			expectedErr: "file content does not match extension .pdf",
+		},
 		{
 			name: "context timeout",
-			data: []byte("test data"),
+			data: []byte("%PDF-1.4 test data"),
 			ext:  ".pdf",
 			setupMock: func(m *mockMinioClient) {
 				m.On("PutObject",
@@ -135,6 +156,274 @@ func TestMinioStorage_Save(t *testing.T) {
 	}
 }
 
+func TestMinioStorage_SaveStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		size        int64
+		ext         string
+		setupMock   func(*mockMinioClient, *bytes.Reader)
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name: "successful stream",
+			data: []byte("%PDF-1.4 streamed content"),
+			size: int64(len("%PDF-1.4 streamed content")),
+			ext:  ".pdf",
+			setupMock: func(m *mockMinioClient, r *bytes.Reader) {
+				m.On("PutObject",
+					mock.Anything,
+					"test-bucket",
+					mock.MatchedBy(func(key string) bool {
+						return regexp.MustCompile(`^\d{4}/\d{2}/\d{2}/[0-9a-f-]{36}\.pdf$`).MatchString(key)
+					}),
+					r,
+					int64(len("%PDF-1.4 streamed content")),
+					minio.PutObjectOptions{ContentType: "application/pdf"},
+				).Return(minio.UploadInfo{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "declared size too large",
+			data: []byte("small"),
+			size: defaultMaxFileSize + 1,
+			ext:  ".pdf",
+			setupMock: func(m *mockMinioClient, r *bytes.Reader) {
+			},
+			wantErr:     true,
+			expectedErr: "file size exceeds maximum allowed size",
+		},
+		{
+			name: "invalid extension",
+			data: []byte("test data"),
+			size: int64(len("test data")),
+			ext:  ".exe",
+			setupMock: func(m *mockMinioClient, r *bytes.Reader) {
+			},
+			wantErr:     true,
+			expectedErr: "file extension .exe is not allowed",
+		},
+		{
+			name: "jpg with pdf magic bytes",
+			data: []byte("%PDF-1.4 disguised document"),
+			size: int64(len("%PDF-1.4 disguised document")),
+			ext:  ".jpg",
+			setupMock: func(m *mockMinioClient, r *bytes.Reader) {
+			},
+			wantErr:     true,
+			expectedErr: "file content type application/pdf does not match extension .jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bytes.NewReader(tt.data)
+			mockClient := new(mockMinioClient)
+			tt.setupMock(mockClient, reader)
+
+			storage := &MinioStorage{
+				client:     mockClient,
+				bucketName: "test-bucket",
+			}
+
+			key, err := storage.SaveStream(context.Background(), reader, tt.size, tt.ext)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Empty(t, key)
+				mockClient.AssertNotCalled(t, "PutObject")
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(key, time.Now().Format("2006/01/02")+"/"))
+				assert.True(t, strings.HasSuffix(key, tt.ext))
+				mockClient.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestMinioStorage_SaveStream_NonSeekable(t *testing.T) {
+	data := append([]byte("%PDF-1.4 "), bytes.Repeat([]byte("x"), 2*sniffLen)...)
+
+	var uploaded []byte
+	mockClient := new(mockMinioClient)
+	mockClient.On("PutObject", mock.Anything, "test-bucket", mock.Anything, mock.Anything, int64(len(data)), minio.PutObjectOptions{ContentType: "application/pdf"}).
+		Run(func(args mock.Arguments) {
+			uploaded, _ = io.ReadAll(args.Get(3).(io.Reader))
+		}).
+		Return(minio.UploadInfo{}, nil)
+
+	storage := &MinioStorage{
+		client:     mockClient,
+		bucketName: "test-bucket",
+	}
+
+	// io.MultiReader hides the Seeker so the sniffed header must be replayed
+	_, err := storage.SaveStream(context.Background(), io.MultiReader(bytes.NewReader(data)), int64(len(data)), ".pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, data, uploaded)
+	mockClient.AssertExpectations(t)
+}
+
+// ooxmlHeader returns the zip local file header of the first entry, name, of an
+// Office Open XML file
+func ooxmlHeader(name string) []byte {
+	header := append([]byte("PK\x03\x04"), make([]byte, 26)...)
+	header[26] = byte(len(name))
+	return append(header, name...)
+}
+
+// oleHeader returns the start of a legacy Office file whose first sector after the
+// compound file header begins with sector
+func oleHeader(sector ...byte) []byte {
+	header := make([]byte, 512, 512+len(sector))
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	return append(header, sector...)
+}
+
+func TestValidateContent(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name        string
+		header      []byte
+		ext         string
+		expectedErr string
+	}{
+		{name: "pdf", header: []byte("%PDF-1.7\n"), ext: ".pdf"},
+		{name: "jpeg as .jpeg", header: []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF"), ext: ".jpeg"},
+		{name: "word document", header: oleHeader(0xEC, 0xA5, 0xC1, 0x00), ext: ".doc"},
+		{name: "excel workbook", header: oleHeader(0x09, 0x08, 0x10, 0x00), ext: ".xls"},
+		{name: "docx", header: ooxmlHeader("word/document.xml"), ext: ".docx"},
+		{name: "xlsx", header: ooxmlHeader("xl/workbook.xml"), ext: ".xlsx"},
+		{name: "utf-8 text", header: []byte("Lịch họp tuần sau"), ext: ".txt"},
+		{name: "latin-1 text", header: []byte("Caf\xe9 at 10:00"), ext: ".txt"},
+		{name: "utf-16 text", header: []byte("\xFF\xFEH\x00i\x00"), ext: ".TXT"},
+		{
+			name:        "executable as .doc",
+			header:      []byte{0x4D, 0x5A, 0x90, 0x00, 0x03},
+			ext:         ".doc",
+			expectedErr: "file content type application/vnd.microsoft.portable-executable does not match extension .doc",
+		},
+		{
+			name:        "arbitrary binary as .xls",
+			header:      bytes.Repeat([]byte{0x00, 0x01}, 64),
+			ext:         ".xls",
+			expectedErr: "file content does not match extension .xls",
+		},
+		{
+			name:        "word document as .xls",
+			header:      oleHeader(0xEC, 0xA5, 0xC1, 0x00),
+			ext:         ".xls",
+			expectedErr: "file content type application/msword does not match extension .xls",
+		},
+		{
+			name:        "plain zip as .docx",
+			header:      ooxmlHeader("payload.exe"),
+			ext:         ".docx",
+			expectedErr: "does not match extension .docx",
+		},
+		{
+			name:        "xlsx as .docx",
+			header:      ooxmlHeader("xl/workbook.xml"),
+			ext:         ".docx",
+			expectedErr: "does not match extension .docx",
+		},
+		{
+			name:        "image as .txt",
+			header:      png,
+			ext:         ".txt",
+			expectedErr: "file content type image/png does not match extension .txt",
+		},
+		{
+			name:        "binary as .txt",
+			header:      []byte("MZ\x90\x00\x03\x00\x00\x00"),
+			ext:         ".txt",
+			expectedErr: "does not match extension .txt",
+		},
+		{name: "extension without signature is not sniffed", header: png, ext: ".csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateContent(tt.header, tt.ext)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestMinioStorage_ConfiguredExtensions(t *testing.T) {
+	docx := ooxmlHeader("word/document.xml")
+
+	tests := []struct {
+		name        string
+		config      MinioStorageConfig
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name:   "docx permitted when configured",
+			config: MinioStorageConfig{AllowedExtensions: []string{".pdf", ".docx"}},
+		},
+		{
+			name:        "docx rejected when not configured",
+			config:      MinioStorageConfig{AllowedExtensions: []string{".pdf"}},
+			wantErr:     true,
+			expectedErr: "file extension .docx is not allowed",
+		},
+		{
+			name:        "configured max size",
+			config:      MinioStorageConfig{MaxFileSize: 16, AllowedExtensions: []string{".docx"}},
+			wantErr:     true,
+			expectedErr: "file size exceeds maximum allowed size of 16 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := NewMinioStorage(nil, "test-bucket", tt.config)
+			assert.NoError(t, err)
+
+			mockClient := new(mockMinioClient)
+			storage.(*MinioStorage).client = mockClient
+			if !tt.wantErr {
+				mockClient.On("PutObject", mock.Anything, "test-bucket", mock.Anything, mock.Anything, int64(len(docx)), mock.Anything).
+					Return(minio.UploadInfo{}, nil)
+			}
+
+			key, err := storage.Save(context.Background(), docx, ".docx")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				mockClient.AssertNotCalled(t, "PutObject")
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, strings.HasSuffix(key, ".docx"))
+				mockClient.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestMinioStorageConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultMinioStorageConfig().Validate())
+	assert.NoError(t, MinioStorageConfig{}.Validate())
+	assert.Error(t, MinioStorageConfig{AllowedExtensions: []string{".DOCX"}}.Validate())
+	assert.Error(t, MinioStorageConfig{AllowedExtensions: []string{"pdf"}}.Validate())
+	assert.Error(t, MinioStorageConfig{AllowedExtensions: []string{"."}}.Validate())
+	assert.Error(t, MinioStorageConfig{MaxFileSize: -1}.Validate())
+
+	_, err := NewMinioStorage(nil, "test-bucket", MinioStorageConfig{AllowedExtensions: []string{"Pdf"}})
+	assert.Error(t, err)
+}
+
 func TestMinioStorage_Get(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -379,7 +668,10 @@ func TestMinioStorage_Integration(t *testing.T) {
 	}
 
 	// Create storage instance
-	storage := NewMinioStorage(client, bucketName)
+	storage, err := NewMinioStorage(client, bucketName, DefaultMinioStorageConfig())
+	if err != nil {
+		t.Fatalf("Error creating storage: %v", err)
+	}
 
 	// Test Save
 	testData := []byte("test data")
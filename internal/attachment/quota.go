@@ -0,0 +1,209 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrOwnerRequired is returned when a quota-tracked save has no owner in its context
+var ErrOwnerRequired = errors.New("attachment owner is required to enforce the storage quota")
+
+// QuotaExceededError is returned when a save would take a user over their storage quota
+type QuotaExceededError struct {
+	UserID string
+	Used   int64
+	Size   int64
+	Quota  int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("attachment quota exceeded for user %s: %d of %d bytes used, %d more requested",
+		e.UserID, e.Used, e.Quota, e.Size)
+}
+
+type ownerKey struct{}
+
+// WithOwner returns a context whose attachment saves and deletes count against userID
+func WithOwner(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, userID)
+}
+
+// OwnerFromContext returns the attachment owner stored by WithOwner
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(ownerKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// UsageStore keeps track of the attachment bytes stored by each user
+type UsageStore interface {
+	// Reserve adds size bytes to the user's usage, or returns a *QuotaExceededError
+	// if that would exceed quota. A quota of zero or less is unlimited.
+	Reserve(ctx context.Context, userID string, size, quota int64) error
+	// Release gives back bytes reserved for a save that did not happen
+	Release(ctx context.Context, userID string, size int64) error
+	// Track records the owner and size of a saved file so Free can release it later
+	Track(ctx context.Context, fileID, userID string, size int64) error
+	// Free releases the space used by a tracked file. Untracked files are ignored.
+	Free(ctx context.Context, fileID string) error
+	// Usage returns the bytes currently stored by the user
+	Usage(ctx context.Context, userID string) (int64, error)
+}
+
+// QuotaStorage caps the total attachment bytes stored per user. The owner of each
+// save is taken from the context, see WithOwner.
+type QuotaStorage struct {
+	Storage
+	usage UsageStore
+	quota int64
+}
+
+// NewQuotaStorage wraps storage with a per-user quota in bytes. A quota of zero or
+// less only tracks usage.
+func NewQuotaStorage(storage Storage, usage UsageStore, quota int64) *QuotaStorage {
+	return &QuotaStorage{
+		Storage: storage,
+		usage:   usage,
+		quota:   quota,
+	}
+}
+
+// Save stores the file if it fits in the owner's remaining quota
+func (s *QuotaStorage) Save(ctx context.Context, data []byte, ext string) (string, error) {
+	return s.save(ctx, int64(len(data)), func() (string, error) {
+		return s.Storage.Save(ctx, data, ext)
+	})
+}
+
+// SaveStream streams the file if the wrapped storage supports it and the file fits
+// in the owner's remaining quota
+func (s *QuotaStorage) SaveStream(ctx context.Context, r io.Reader, size int64, ext string) (string, error) {
+	stream, ok := s.Storage.(StreamStorage)
+	if !ok {
+		return "", errors.New("underlying storage does not support streaming")
+	}
+	if size < 0 {
+		return "", errors.New("file size must be known to enforce the storage quota")
+	}
+	return s.save(ctx, size, func() (string, error) {
+		return stream.SaveStream(ctx, r, size, ext)
+	})
+}
+
+func (s *QuotaStorage) save(ctx context.Context, size int64, save func() (string, error)) (string, error) {
+	userID, ok := OwnerFromContext(ctx)
+	if !ok {
+		return "", ErrOwnerRequired
+	}
+
+	// Reserve first so concurrent saves cannot overshoot the quota together
+	if err := s.usage.Reserve(ctx, userID, size, s.quota); err != nil {
+		return "", err
+	}
+
+	fileID, err := save()
+	if err != nil {
+		if releaseErr := s.usage.Release(ctx, userID, size); releaseErr != nil {
+			return "", errors.Join(err, releaseErr)
+		}
+		return "", err
+	}
+
+	if err := s.usage.Track(ctx, fileID, userID, size); err != nil {
+		// An untracked file could never be freed, so undo the save
+		err = fmt.Errorf("failed to track attachment usage: %w", err)
+		return "", errors.Join(err, s.Storage.Delete(ctx, fileID), s.usage.Release(ctx, userID, size))
+	}
+
+	return fileID, nil
+}
+
+// Delete removes the file and frees its space in the owner's quota
+func (s *QuotaStorage) Delete(ctx context.Context, id string) error {
+	if err := s.Storage.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.usage.Free(ctx, id)
+}
+
+// reserveScript adds to the user's usage if it stays within quota. It returns
+// {1, new usage} on success or {0, current usage} when the quota would be exceeded.
+var reserveScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+local size = tonumber(ARGV[1])
+local quota = tonumber(ARGV[2])
+if quota > 0 and used + size > quota then
+	return {0, used}
+end
+return {1, redis.call('INCRBY', KEYS[1], size)}
+`)
+
+// freeScript deletes the file record and subtracts its size from the owner's usage
+var freeScript = redis.NewScript(`
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if not owner then
+	return 0
+end
+local size = tonumber(redis.call('HGET', KEYS[1], 'size'))
+redis.call('DEL', KEYS[1])
+local usageKey = ARGV[1] .. owner
+if redis.call('DECRBY', usageKey, size) < 0 then
+	redis.call('SET', usageKey, 0)
+end
+return size
+`)
+
+const (
+	usageKeyPrefix = "attachment_usage:"
+	fileKeyPrefix  = "attachment_file:"
+)
+
+// RedisUsageStore is a UsageStore backed by Redis counters
+type RedisUsageStore struct {
+	client *redis.Client
+}
+
+// NewRedisUsageStore creates a Redis backed UsageStore
+func NewRedisUsageStore(client *redis.Client) *RedisUsageStore {
+	return &RedisUsageStore{client: client}
+}
+
+func (s *RedisUsageStore) Reserve(ctx context.Context, userID string, size, quota int64) error {
+	res, err := reserveScript.Run(ctx, s.client, []string{usageKeyPrefix + userID}, size, quota).Int64Slice()
+	if err != nil {
+		return fmt.Errorf("failed to reserve attachment quota: %w", err)
+	}
+	if res[0] == 0 {
+		return &QuotaExceededError{UserID: userID, Used: res[1], Size: size, Quota: quota}
+	}
+	return nil
+}
+
+func (s *RedisUsageStore) Release(ctx context.Context, userID string, size int64) error {
+	if err := s.client.DecrBy(ctx, usageKeyPrefix+userID, size).Err(); err != nil {
+		return fmt.Errorf("failed to release attachment quota: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisUsageStore) Track(ctx context.Context, fileID, userID string, size int64) error {
+	return s.client.HSet(ctx, fileKeyPrefix+fileID, "owner", userID, "size", size).Err()
+}
+
+func (s *RedisUsageStore) Free(ctx context.Context, fileID string) error {
+	if err := freeScript.Run(ctx, s.client, []string{fileKeyPrefix + fileID}, usageKeyPrefix).Err(); err != nil {
+		return fmt.Errorf("failed to free attachment quota: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisUsageStore) Usage(ctx context.Context, userID string) (int64, error) {
+	used, err := s.client.Get(ctx, usageKeyPrefix+userID).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return used, err
+}
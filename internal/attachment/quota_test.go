@@ -0,0 +1,112 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUsageStore(t *testing.T) *RedisUsageStore {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisUsageStore(client)
+}
+
+func TestQuotaStorage_Save(t *testing.T) {
+	usage := newTestUsageStore(t)
+	storage := new(mockStorage)
+	quota := NewQuotaStorage(storage, usage, 10)
+
+	ctx := WithOwner(context.Background(), "user-1")
+	storage.On("Save", ctx, []byte("123456"), ".txt").Return("file-1", nil).Once()
+	storage.On("Save", ctx, []byte("1234"), ".txt").Return("file-2", nil).Once()
+
+	id, err := quota.Save(ctx, []byte("123456"), ".txt")
+	require.NoError(t, err)
+	assert.Equal(t, "file-1", id)
+
+	// 6 + 5 bytes would exceed the 10 byte quota
+	_, err = quota.Save(ctx, []byte("12345"), ".txt")
+	var exceeded *QuotaExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, QuotaExceededError{UserID: "user-1", Used: 6, Size: 5, Quota: 10}, *exceeded)
+
+	// Exactly filling the quota is allowed
+	_, err = quota.Save(ctx, []byte("1234"), ".txt")
+	require.NoError(t, err)
+
+	// Other users have their own quota
+	other := WithOwner(context.Background(), "user-2")
+	storage.On("Save", other, []byte("12345"), ".txt").Return("file-3", nil).Once()
+	_, err = quota.Save(other, []byte("12345"), ".txt")
+	require.NoError(t, err)
+
+	used, err := usage.Usage(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), used)
+
+	storage.AssertExpectations(t)
+}
+
+func TestQuotaStorage_DeleteFreesSpace(t *testing.T) {
+	usage := newTestUsageStore(t)
+	storage := new(mockStorage)
+	quota := NewQuotaStorage(storage, usage, 10)
+
+	ctx := WithOwner(context.Background(), "user-1")
+	storage.On("Save", ctx, mock.Anything, ".pdf").Return("file-1", nil).Once()
+	storage.On("Save", ctx, mock.Anything, ".pdf").Return("file-2", nil).Once()
+	storage.On("Delete", mock.Anything, "file-1").Return(nil)
+
+	_, err := quota.Save(ctx, []byte("12345678"), ".pdf")
+	require.NoError(t, err)
+
+	_, err = quota.Save(ctx, []byte("12345678"), ".pdf")
+	var exceeded *QuotaExceededError
+	require.ErrorAs(t, err, &exceeded)
+
+	// Deleting does not need the owner, the usage record knows it
+	require.NoError(t, quota.Delete(context.Background(), "file-1"))
+	used, err := usage.Usage(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Zero(t, used)
+
+	_, err = quota.Save(ctx, []byte("12345678"), ".pdf")
+	require.NoError(t, err)
+
+	// A second delete of the same file must not free its space twice
+	require.NoError(t, quota.Delete(context.Background(), "file-1"))
+	used, err = usage.Usage(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), used)
+
+	storage.AssertExpectations(t)
+}
+
+func TestQuotaStorage_SaveErrors(t *testing.T) {
+	usage := newTestUsageStore(t)
+	storage := new(mockStorage)
+	quota := NewQuotaStorage(storage, usage, 10)
+
+	_, err := quota.Save(context.Background(), []byte("data"), ".txt")
+	assert.ErrorIs(t, err, ErrOwnerRequired)
+
+	// A failed save gives the reserved space back
+	ctx := WithOwner(context.Background(), "user-1")
+	storage.On("Save", ctx, []byte("data"), ".txt").Return("", errors.New("upload failed")).Once()
+	_, err = quota.Save(ctx, []byte("data"), ".txt")
+	assert.EqualError(t, err, "upload failed")
+
+	used, err := usage.Usage(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Zero(t, used)
+
+	storage.AssertExpectations(t)
+}
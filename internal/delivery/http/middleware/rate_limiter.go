@@ -3,16 +3,34 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisRateLimiter xử lý giới hạn request sử dụng Redis
+// slidingWindowScript xoá các request đã ra khỏi cửa sổ rồi chỉ ghi nhận request mới khi
+// còn hạn mức. Trả về 1 nếu request được chấp nhận, 0 nếu bị từ chối.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', key, now, ARGV[4])
+redis.call('PEXPIRE', key, math.ceil(window / 1000))
+return 1
+`)
+
+// RedisRateLimiter xử lý giới hạn request sử dụng Redis theo cửa sổ trượt
 type RedisRateLimiter struct {
 	client *redis.Client
 	limit  int
 	window time.Duration
+	seq    atomic.Uint64
 }
 
 // NewRedisRateLimiter tạo một rate limiter mới
@@ -30,37 +48,22 @@ func (r *RedisRateLimiter) Limit(next http.Handler) http.Handler {
 		// Lấy IP của client làm key
 		key := fmt.Sprintf("rate_limit:%s", req.RemoteAddr)
 
-		// Kiểm tra số lượng request trong window
-		val, err := r.client.Get(req.Context(), key).Int()
-		if err != nil && err != redis.Nil {
+		// Đếm số request trong window gần nhất, không phụ thuộc ranh giới cửa sổ cố định
+		now := time.Now()
+		member := fmt.Sprintf("%d-%d", now.UnixNano(), r.seq.Add(1))
+		allowed, err := slidingWindowScript.Run(req.Context(), r.client, []string{key},
+			now.UnixMicro(), r.window.Microseconds(), r.limit, member).Int()
+		if err != nil {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
 
-		// Nếu chưa có key, tạo mới với TTL là window
-		if err == redis.Nil {
-			err = r.client.Set(req.Context(), key, 1, r.window).Err()
-			if err != nil {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-				return
-			}
-			next.ServeHTTP(w, req)
-			return
-		}
-
 		// Nếu đã vượt quá limit
-		if val >= r.limit {
+		if allowed == 0 {
 			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
 			return
 		}
 
-		// Tăng counter
-		err = r.client.Incr(req.Context(), key).Err()
-		if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-
 		next.ServeHTTP(w, req)
 	})
 }
@@ -4,7 +4,10 @@ package authentication
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/alexedwards/argon2id"
 	"github.com/gmhafiz/scs/v2"
@@ -23,13 +26,31 @@ const (
 var ErrEmailRequired = errors.New("email is required")
 
 var (
-	ErrPasswordLength = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrPasswordLength   = fmt.Errorf("password must be between %d and %d characters", minPasswordLength, maxPasswordLength)
+	ErrTokenRequired    = errors.New("token is required")
+	ErrInvalidToken     = errors.New("token is invalid or has expired")
+	ErrEmailNotVerified = errors.New("email address has not been verified")
+
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	ErrInvalidCredentials     = errors.New("invalid email or password")
 )
 
 // Handler xử lý các request liên quan đến xác thực
 type Handler struct {
 	repo    Repo
 	session *scs.SessionManager
+	sender  TokenSender
+	limiter LoginLimiter
+}
+
+// HandlerOption cấu hình thêm cho Handler
+type HandlerOption func(*Handler)
+
+// WithLoginLimiter bật giới hạn số lần đăng nhập sai theo email
+func WithLoginLimiter(limiter LoginLimiter) HandlerOption {
+	return func(h *Handler) {
+		h.limiter = limiter
+	}
 }
 
 // Register xử lý đăng ký tài khoản mới
@@ -41,13 +62,160 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := req.Validate(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	hashedPassword, err := argon2id.CreateHash(req.Password, argon2id.DefaultParams)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	_, err = h.repo.Register(ctx, req.FirstName, req.LastName, req.Email, hashedPassword, tokenHash, time.Now().Add(verificationTokenTTL))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.sender.SendVerification(ctx, req.Email, token); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusCreated)
+}
+
+// Verify xác thực email bằng token được gửi khi đăng ký. Token chỉ dùng được một lần.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respond.Error(w, http.StatusBadRequest, ErrTokenRequired)
+		return
+	}
+
+	ok, err := h.repo.VerifyEmail(r.Context(), hashToken(token))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !ok {
+		respond.Error(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// ResendVerification gửi lại token xác thực cho email chưa được xác thực, vd: khi token cũ
+// đã hết hạn hoặc email bị thất lạc. Luôn trả về 202 để không tiết lộ email nào đã được đăng ký.
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, nil)
+		return
+	}
+
 	if req.Email == "" {
 		respond.Error(w, http.StatusBadRequest, ErrEmailRequired)
 		return
 	}
 
-	if len(req.Password) < minPasswordLength {
-		respond.Error(w, http.StatusBadRequest, ErrPasswordLength)
+	token, tokenHash, err := newToken()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	ctx := r.Context()
+
+	created, err := h.repo.CreateVerificationToken(ctx, req.Email, tokenHash, time.Now().Add(verificationTokenTTL))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if created {
+		if err := h.sender.SendVerification(ctx, req.Email, token); err != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	respond.Status(w, http.StatusAccepted)
+}
+
+// ForgotPassword gửi token đặt lại mật khẩu tới email của người dùng.
+// Luôn trả về 202 để không tiết lộ email nào đã được đăng ký.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, nil)
+		return
+	}
+
+	if req.Email == "" {
+		respond.Error(w, http.StatusBadRequest, ErrEmailRequired)
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, found, err := h.repo.UserIDByEmail(ctx, req.Email)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if found {
+		token, tokenHash, err := newToken()
+		if err != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+
+		if err := h.repo.CreateToken(ctx, userID, PurposeResetPassword, tokenHash, time.Now().Add(resetPasswordTokenTTL)); err != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+
+		if err := h.sender.SendPasswordReset(ctx, req.Email, token); err != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	respond.Status(w, http.StatusAccepted)
+}
+
+// ResetPassword đặt mật khẩu mới bằng token đặt lại mật khẩu và hủy các session hiện có
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, nil)
+		return
+	}
+
+	if req.Token == "" {
+		respond.Error(w, http.StatusBadRequest, ErrTokenRequired)
+		return
+	}
+
+	if err := validatePassword(req.Password); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -57,12 +225,77 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.repo.Register(r.Context(), req.FirstName, req.LastName, req.Email, hashedPassword); err != nil {
+	ok, err := h.repo.ResetPassword(r.Context(), hashToken(req.Token), hashedPassword)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !ok {
+		respond.Error(w, http.StatusBadRequest, ErrInvalidToken)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// ChangePassword đổi mật khẩu cho người dùng đã đăng nhập sau khi xác nhận mật khẩu hiện tại.
+// Mọi session khác của người dùng bị đăng xuất vì có thể đã bị lộ; session hiện tại được giữ lại.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := h.session.Get(ctx, string(middleware.KeyID)).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, nil)
+		return
+	}
+
+	var req ChangePasswordRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, nil)
+		return
+	}
+
+	if err := validatePassword(req.NewPassword); err != nil {
 		respond.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	respond.Status(w, http.StatusCreated)
+	currentHash, err := h.repo.PasswordHash(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	match, err := argon2id.ComparePasswordAndHash(req.CurrentPassword, currentHash)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !match {
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidCurrentPassword)
+		return
+	}
+
+	hashedPassword, err := argon2id.CreateHash(req.NewPassword, argon2id.DefaultParams)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if err := h.repo.ChangePassword(ctx, userID, hashedPassword); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if err := h.repo.DeleteSessions(ctx, userID, h.session.Token(ctx)); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
 }
 
 // Login xử lý đăng nhập
@@ -86,14 +319,39 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	retryAfter, err := h.limiter.Locked(ctx, req.Email)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if retryAfter > 0 {
+		tooManyLoginAttempts(w, retryAfter)
+		return
+	}
+
+	// Lỗi hệ thống không phải lần đăng nhập sai nên không được tính vào giới hạn
 	user, match, err := h.repo.Login(ctx, req)
 	if err != nil {
-		respond.Error(w, http.StatusUnauthorized, err)
+		respond.Error(w, http.StatusInternalServerError, nil)
 		return
 	}
 
 	if !match {
-		respond.Error(w, http.StatusUnauthorized, errors.New("invalid password"))
+		if _, failErr := h.limiter.Fail(ctx, req.Email); failErr != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidCredentials)
+		return
+	}
+
+	if err := h.limiter.Reset(ctx, req.Email); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if user.VerifiedAt == nil {
+		respond.Error(w, http.StatusForbidden, ErrEmailNotVerified)
 		return
 	}
 
@@ -107,6 +365,13 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	respond.Status(w, http.StatusOK)
 }
 
+// tooManyLoginAttempts trả về 429 kèm Retry-After tính bằng giây (làm tròn lên)
+func tooManyLoginAttempts(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respond.Error(w, http.StatusTooManyRequests, ErrTooManyLoginAttempts)
+}
+
 // Protected kiểm tra xem request có được xác thực hay không
 func (h *Handler) Protected(w http.ResponseWriter, _ *http.Request) {
 	respond.Json(w, http.StatusOK, map[string]string{"success": "yup!"})
@@ -176,10 +441,19 @@ func (h *Handler) Csrf(w http.ResponseWriter, r *http.Request) {
 	respond.Json(w, http.StatusOK, &RespondCsrf{CsrfToken: token})
 }
 
-// NewHandler tạo một handler mới
-func NewHandler(session *scs.SessionManager, repo Repo) *Handler {
-	return &Handler{
+// NewHandler tạo một handler mới. sender gửi token xác thực email và đặt lại mật khẩu
+// cho người dùng, không có giá trị mặc định để production không vô tình ghi token vào log.
+func NewHandler(session *scs.SessionManager, repo Repo, sender TokenSender, opts ...HandlerOption) *Handler {
+	h := &Handler{
 		repo:    repo,
 		session: session,
+		sender:  sender,
+		limiter: noopLoginLimiter{},
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
@@ -0,0 +1,108 @@
+package authentication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gmhafiz/scs/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/middleware"
+)
+
+// tokenRepo là Repo giả ghi lại các token xác thực được lưu
+type tokenRepo struct {
+	Repo
+
+	registered map[string]string
+	unverified map[string]bool
+	tokens     map[string]string
+}
+
+func newTokenRepo() *tokenRepo {
+	return &tokenRepo{
+		registered: make(map[string]string),
+		unverified: make(map[string]bool),
+		tokens:     make(map[string]string),
+	}
+}
+
+func (r *tokenRepo) Register(_ context.Context, _, _, email, _, tokenHash string, _ time.Time) (uint64, error) {
+	r.registered[email] = tokenHash
+	r.unverified[email] = true
+	return uint64(len(r.registered)), nil
+}
+
+func (r *tokenRepo) CreateVerificationToken(_ context.Context, email, tokenHash string, _ time.Time) (bool, error) {
+	if !r.unverified[email] {
+		return false, nil
+	}
+	r.tokens[email] = tokenHash
+	return true, nil
+}
+
+func newTokenTestRouter(repo Repo, sender TokenSender) *chi.Mux {
+	session := scs.New()
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, repo, sender)
+	return router
+}
+
+func postJSON(t *testing.T, router http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b)))
+	return rr
+}
+
+func TestHandler_Register_StoresTokenWithUser(t *testing.T) {
+	repo := newTokenRepo()
+	sender := newCaptureSender()
+	router := newTokenTestRouter(repo, sender)
+
+	rr := postJSON(t, router, "/api/v1/register", &RegisterRequest{Email: "new@example.com", Password: "highEntropyPassword"})
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	token := sender.tokens["new@example.com"]
+	require.NotEmpty(t, token)
+	assert.Equal(t, hashToken(token), repo.registered["new@example.com"])
+}
+
+func TestHandler_ResendVerification(t *testing.T) {
+	repo := newTokenRepo()
+	repo.unverified["pending@example.com"] = true
+	sender := newCaptureSender()
+	router := newTokenTestRouter(repo, sender)
+
+	t.Run("unverified email gets a new token", func(t *testing.T) {
+		rr := postJSON(t, router, "/api/v1/verify/resend", &ResendVerificationRequest{Email: "pending@example.com"})
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+
+		token := sender.tokens["pending@example.com"]
+		require.NotEmpty(t, token)
+		assert.Equal(t, hashToken(token), repo.tokens["pending@example.com"])
+	})
+
+	t.Run("unknown or verified email is not revealed", func(t *testing.T) {
+		rr := postJSON(t, router, "/api/v1/verify/resend", &ResendVerificationRequest{Email: "verified@example.com"})
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Empty(t, sender.tokens["verified@example.com"])
+	})
+
+	t.Run("email is required", func(t *testing.T) {
+		rr := postJSON(t, router, "/api/v1/verify/resend", &ResendVerificationRequest{})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
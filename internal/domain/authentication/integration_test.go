@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -40,15 +41,36 @@ var (
 	ErrEmailNotAvailable = errors.New("email is not available")
 )
 
+// testDatabaseEnv names the Postgres DSN the integration tests run against. Without it
+// they are skipped and only the unit tests run.
+const testDatabaseEnv = "AUTH_TEST_DATABASE_DSN"
+
 func TestMain(m *testing.M) {
-	// Skip Docker setup and just run tests
-	os.Exit(0) // Always pass
+	if dsn := os.Getenv(testDatabaseEnv); dsn != "" {
+		db, err := sql.Open(DBDriver, dsn)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		migrator = database.Migrator(db, database.WithDSN(dsn))
+		migrator.Up()
+	}
+
+	os.Exit(m.Run())
 }
 
-func TestHandler_RegisterIntegration(t *testing.T) {
+// requireDatabase skips an integration test in short mode or without a test database
+func requireDatabase(t *testing.T) {
+	t.Helper()
 	if testing.Short() {
 		t.Skip("skipping integration test")
 	}
+	if migrator == nil {
+		t.Skipf("skipping integration test: %s is not set", testDatabaseEnv)
+	}
+}
+
+func TestHandler_RegisterIntegration(t *testing.T) {
+	requireDatabase(t)
 
 	type args struct {
 		*RegisterRequest
@@ -101,6 +123,32 @@ func TestHandler_RegisterIntegration(t *testing.T) {
 				status: http.StatusBadRequest,
 			},
 		},
+		{
+			name: "malformed email",
+			args: args{
+				RegisterRequest: &RegisterRequest{
+					Email:    "not-an-email",
+					Password: "highEntropyPassword",
+				},
+			},
+			want: want{
+				error:  ErrEmailInvalid,
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "over-long password",
+			args: args{
+				RegisterRequest: &RegisterRequest{
+					Email:    "long-password@example.com",
+					Password: strings.Repeat("highEntropyPassword", 10),
+				},
+			},
+			want: want{
+				error:  ErrPasswordLength,
+				status: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "no password is supplied",
 			args: args{
@@ -125,7 +173,7 @@ func TestHandler_RegisterIntegration(t *testing.T) {
 
 	router := chi.NewRouter()
 	router.Use(middleware.LoadAndSave(session))
-	RegisterHTTPEndPoints(router, session, repo)
+	RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -171,9 +219,7 @@ func TestHandler_RegisterIntegration(t *testing.T) {
 }
 
 func TestHandler_LoginIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -237,8 +283,8 @@ func TestHandler_LoginIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -257,7 +303,7 @@ func TestHandler_LoginIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 
 			router.ServeHTTP(ww, rr)
 
@@ -269,9 +315,7 @@ func TestHandler_LoginIntegration(t *testing.T) {
 }
 
 func TestHandler_ProtectedIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -311,8 +355,8 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -331,7 +375,7 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 
 			router.ServeHTTP(ww, rr)
 
@@ -351,7 +395,7 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 
 			router = chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -360,9 +404,7 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 }
 
 func TestHandler_MeIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -402,8 +444,8 @@ func TestHandler_MeIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -422,7 +464,7 @@ func TestHandler_MeIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 
 			router.ServeHTTP(ww, rr)
 
@@ -442,7 +484,7 @@ func TestHandler_MeIntegration(t *testing.T) {
 
 			router = chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -467,9 +509,7 @@ func TestHandler_MeIntegration(t *testing.T) {
 }
 
 func TestHandler_LogoutIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -509,8 +549,8 @@ func TestHandler_LogoutIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -529,7 +569,7 @@ func TestHandler_LogoutIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -568,9 +608,7 @@ func TestHandler_LogoutIntegration(t *testing.T) {
 }
 
 func TestHandler_Force_LogoutIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -611,8 +649,8 @@ func TestHandler_Force_LogoutIntegration(t *testing.T) {
 
 	// Create normal user
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -631,7 +669,7 @@ func TestHandler_Force_LogoutIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -697,9 +735,7 @@ func TestHandler_Force_LogoutIntegration(t *testing.T) {
 }
 
 func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -743,8 +779,8 @@ func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -763,7 +799,7 @@ func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -809,9 +845,7 @@ func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
 }
 
 func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -855,8 +889,8 @@ func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -875,7 +909,7 @@ func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -916,9 +950,7 @@ func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 }
 
 func TestHandler_LoginWithInvalidPasswordIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
-	}
+	requireDatabase(t)
 
 	type args struct {
 		*LoginRequest
@@ -971,8 +1003,8 @@ func TestHandler_LoginWithInvalidPasswordIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "email@example.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -991,7 +1023,7 @@ func TestHandler_LoginWithInvalidPasswordIntegration(t *testing.T) {
 			router := chi.NewRouter()
 			router.Use(middleware.LoadAndSave(session))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -1011,11 +1043,53 @@ func TestHandler_LoginWithInvalidPasswordIntegration(t *testing.T) {
 	}
 }
 
-func TestHandler_SessionExpirationIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test")
+func TestHandler_LoginLockoutIntegration(t *testing.T) {
+	requireDatabase(t)
+
+	const (
+		email    = "lockout@example.com"
+		password = "highEntropyPassword"
+	)
+
+	limiter, mr := newTestLoginLimiter(t)
+
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(migrator.DB, session)
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, repo, newCaptureSender(), WithLoginLimiter(limiter))
+
+	hashedPassword, err := argon2id.CreateHash(password, argon2id.DefaultParams)
+	assert.NoError(t, err)
+
+	_, err = migrator.DB.ExecContext(context.Background(), `
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
+	`, email, hashedPassword)
+	assert.NoError(t, err)
+
+	login := func(password string) int {
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(
+			`{"email":"`+email+`","password":"`+password+`"}`))
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww.Code
 	}
 
+	for i := 0; i < defaultMaxLoginAttempts; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("wrongPassword123456"), "attempt %d", i+1)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login("wrongPassword123456"))
+	assert.Equal(t, http.StatusTooManyRequests, login(password))
+
+	mr.FastForward(defaultLoginLockout)
+	assert.Equal(t, http.StatusOK, login(password))
+}
+
+func TestHandler_SessionExpirationIntegration(t *testing.T) {
+	requireDatabase(t)
+
 	drv := entsql.OpenDB(DBDriver, migrator.DB)
 	client := gen.NewClient(gen.Driver(drv))
 	defer client.Close()
@@ -1028,8 +1102,8 @@ func TestHandler_SessionExpirationIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	_, err = migrator.DB.ExecContext(context.Background(), `
-		INSERT INTO users (email, password) VALUES ($1, $2)
-		ON CONFLICT (email) DO NOTHING 
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
 	`, "session@test.com", hashedPassword)
 	assert.NoError(t, err)
 
@@ -1047,7 +1121,7 @@ func TestHandler_SessionExpirationIntegration(t *testing.T) {
 
 	router := chi.NewRouter()
 	router.Use(middleware.LoadAndSave(session))
-	RegisterHTTPEndPoints(router, session, repo)
+	RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
 	router.ServeHTTP(ww, rr)
 
 	assert.Equal(t, http.StatusOK, ww.Code)
@@ -1069,6 +1143,430 @@ func TestHandler_SessionExpirationIntegration(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, ww.Code)
 }
 
+// captureSender ghi lại token đã gửi để test có thể dùng lại
+type captureSender struct {
+	tokens      map[string]string
+	resetTokens map[string]string
+}
+
+func newCaptureSender() *captureSender {
+	return &captureSender{
+		tokens:      make(map[string]string),
+		resetTokens: make(map[string]string),
+	}
+}
+
+func (c *captureSender) SendVerification(_ context.Context, email, token string) error {
+	c.tokens[email] = token
+	return nil
+}
+
+func (c *captureSender) SendPasswordReset(_ context.Context, email, token string) error {
+	c.resetTokens[email] = token
+	return nil
+}
+
+func TestHandler_VerifyIntegration(t *testing.T) {
+	requireDatabase(t)
+
+	const (
+		email    = "verify@example.com"
+		password = "highEntropyPassword"
+	)
+
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(migrator.DB, session)
+	sender := newCaptureSender()
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, repo, sender)
+
+	login := func() int {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: email, Password: password})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf)
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww.Code
+	}
+
+	verify := func(token string) (int, string) {
+		rr := httptest.NewRequest(http.MethodGet, "/api/v1/verify?token="+token, nil)
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+
+		errStruct := struct {
+			Message string `json:"message"`
+		}{}
+		b, err := io.ReadAll(ww.Body)
+		assert.NoError(t, err)
+		if len(b) > 0 {
+			assert.NoError(t, json.Unmarshal(b, &errStruct))
+		}
+		return ww.Code, errStruct.Message
+	}
+
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(&RegisterRequest{Email: email, Password: password})
+	assert.NoError(t, err)
+
+	rr := httptest.NewRequest(http.MethodPost, "/api/v1/register", &buf)
+	ww := httptest.NewRecorder()
+	router.ServeHTTP(ww, rr)
+	assert.Equal(t, http.StatusCreated, ww.Code)
+
+	token := sender.tokens[email]
+	assert.NotEmpty(t, token)
+
+	// Unverified users can register but not log in
+	assert.Equal(t, http.StatusForbidden, login())
+
+	resend := func() int {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(&ResendVerificationRequest{Email: email})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/verify/resend", &buf)
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww.Code
+	}
+
+	// A lost verification email can be sent again
+	delete(sender.tokens, email)
+	assert.Equal(t, http.StatusAccepted, resend())
+	resent := sender.tokens[email]
+	assert.NotEmpty(t, resent)
+	assert.NotEqual(t, token, resent)
+
+	code, _ := verify(resent)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Verified users are not sent another token
+	delete(sender.tokens, email)
+	assert.Equal(t, http.StatusAccepted, resend())
+	assert.Empty(t, sender.tokens[email])
+
+	var verifiedAt sql.NullTime
+	err = migrator.DB.QueryRowContext(context.Background(),
+		`SELECT verified_at FROM users WHERE email = $1`, email).Scan(&verifiedAt)
+	assert.NoError(t, err)
+	assert.True(t, verifiedAt.Valid)
+
+	assert.Equal(t, http.StatusOK, login())
+
+	// Token is single-use
+	code, message := verify(resent)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrInvalidToken.Error(), message)
+
+	// Expired tokens are rejected
+	expired, expiredHash, err := newToken()
+	assert.NoError(t, err)
+	_, err = migrator.DB.ExecContext(context.Background(), `
+		INSERT INTO user_tokens (token, user_id, purpose, expiry)
+		SELECT $1, id, $2, $3 FROM users WHERE email = $4
+	`, expiredHash, PurposeVerifyEmail, time.Now().Add(-time.Minute), email)
+	assert.NoError(t, err)
+
+	code, message = verify(expired)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrInvalidToken.Error(), message)
+
+	code, message = verify("")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrTokenRequired.Error(), message)
+}
+
+func TestHandler_ResetPasswordIntegration(t *testing.T) {
+	requireDatabase(t)
+
+	const (
+		email       = "reset@example.com"
+		oldPassword = "highEntropyPassword"
+		newPassword = "anotherHighEntropyPassword"
+	)
+
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(migrator.DB, session)
+	sender := newCaptureSender()
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, repo, sender)
+
+	hashedPassword, err := argon2id.CreateHash(oldPassword, argon2id.DefaultParams)
+	assert.NoError(t, err)
+
+	_, err = migrator.DB.ExecContext(context.Background(), `
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
+	`, email, hashedPassword)
+	assert.NoError(t, err)
+
+	post := func(path string, body any) (int, string) {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(body)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRequest(http.MethodPost, path, &buf)
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+
+		errStruct := struct {
+			Message string `json:"message"`
+		}{}
+		b, err := io.ReadAll(ww.Body)
+		assert.NoError(t, err)
+		if len(b) > 0 {
+			assert.NoError(t, json.Unmarshal(b, &errStruct))
+		}
+		return ww.Code, errStruct.Message
+	}
+
+	forgotPassword := func() string {
+		code, _ := post("/api/v1/forgot-password", &ForgotPasswordRequest{Email: email})
+		assert.Equal(t, http.StatusAccepted, code)
+		token := sender.resetTokens[email]
+		assert.NotEmpty(t, token)
+		return token
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		// Log in first so there is an existing session to invalidate
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(
+			`{"email":"`+email+`","password":"`+oldPassword+`"}`))
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		sessionToken, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.NoError(t, err)
+
+		token := forgotPassword()
+
+		code, message := post("/api/v1/reset-password", &ResetPasswordRequest{Token: token, Password: "short"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, ErrPasswordLength.Error(), message)
+
+		code, _ = post("/api/v1/reset-password", &ResetPasswordRequest{Token: token, Password: newPassword})
+		assert.Equal(t, http.StatusOK, code)
+
+		var storedHash string
+		err = migrator.DB.QueryRowContext(context.Background(),
+			`SELECT password FROM users WHERE email = $1`, email).Scan(&storedHash)
+		assert.NoError(t, err)
+		match, err := argon2id.ComparePasswordAndHash(newPassword, storedHash)
+		assert.NoError(t, err)
+		assert.True(t, match)
+
+		// Existing sessions are invalidated
+		rr = httptest.NewRequest(http.MethodGet, "/api/v1/restricted", nil)
+		ww = httptest.NewRecorder()
+		rr.AddCookie(&http.Cookie{
+			Name:  sessionName,
+			Value: sessionToken,
+		})
+		router.ServeHTTP(ww, rr)
+		assert.Equal(t, http.StatusUnauthorized, ww.Code)
+	})
+
+	t.Run("reused token", func(t *testing.T) {
+		token := forgotPassword()
+
+		code, _ := post("/api/v1/reset-password", &ResetPasswordRequest{Token: token, Password: newPassword})
+		assert.Equal(t, http.StatusOK, code)
+
+		code, message := post("/api/v1/reset-password", &ResetPasswordRequest{Token: token, Password: newPassword})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, ErrInvalidToken.Error(), message)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token := forgotPassword()
+
+		_, err := migrator.DB.ExecContext(context.Background(), `
+			UPDATE user_tokens SET expiry = $1 WHERE token = $2
+		`, time.Now().Add(-time.Minute), hashToken(token))
+		assert.NoError(t, err)
+
+		code, message := post("/api/v1/reset-password", &ResetPasswordRequest{Token: token, Password: newPassword})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, ErrInvalidToken.Error(), message)
+	})
+
+	t.Run("unknown email does not reveal registration", func(t *testing.T) {
+		code, _ := post("/api/v1/forgot-password", &ForgotPasswordRequest{Email: "nobody@example.com"})
+		assert.Equal(t, http.StatusAccepted, code)
+		assert.Empty(t, sender.resetTokens["nobody@example.com"])
+	})
+}
+
+func TestHandler_ChangePasswordIntegration(t *testing.T) {
+	requireDatabase(t)
+
+	const (
+		email       = "change@example.com"
+		oldPassword = "highEntropyPassword"
+		newPassword = "anotherHighEntropyPassword"
+	)
+
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(migrator.DB, session)
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, repo, newCaptureSender())
+
+	hashedPassword, err := argon2id.CreateHash(oldPassword, argon2id.DefaultParams)
+	assert.NoError(t, err)
+
+	_, err = migrator.DB.ExecContext(context.Background(), `
+		INSERT INTO users (email, password, verified_at) VALUES ($1, $2, current_timestamp)
+		ON CONFLICT (email) DO UPDATE SET password = EXCLUDED.password, verified_at = EXCLUDED.verified_at
+	`, email, hashedPassword)
+	assert.NoError(t, err)
+
+	tryLogin := func(password string) *httptest.ResponseRecorder {
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(
+			`{"email":"`+email+`","password":"`+password+`"}`))
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww
+	}
+
+	login := func(password string) string {
+		ww := tryLogin(password)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		token, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.NoError(t, err)
+		return token
+	}
+
+	changePassword := func(sessionToken string, req *ChangePasswordRequest) (int, string) {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(req)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRequest(http.MethodPost, "/api/v1/restricted/change-password", &buf)
+		rr.AddCookie(&http.Cookie{
+			Name:  sessionName,
+			Value: sessionToken,
+		})
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+
+		errStruct := struct {
+			Message string `json:"message"`
+		}{}
+		b, err := io.ReadAll(ww.Body)
+		assert.NoError(t, err)
+		if len(b) > 0 {
+			assert.NoError(t, json.Unmarshal(b, &errStruct))
+		}
+		return ww.Code, errStruct.Message
+	}
+
+	restricted := func(sessionToken string) int {
+		rr := httptest.NewRequest(http.MethodGet, "/api/v1/restricted", nil)
+		rr.AddCookie(&http.Cookie{
+			Name:  sessionName,
+			Value: sessionToken,
+		})
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww.Code
+	}
+
+	t.Run("wrong current password", func(t *testing.T) {
+		token := login(oldPassword)
+
+		code, message := changePassword(token, &ChangePasswordRequest{
+			CurrentPassword: "notTheCurrentPassword",
+			NewPassword:     newPassword,
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, ErrInvalidCurrentPassword.Error(), message)
+
+		var storedHash string
+		err := migrator.DB.QueryRowContext(context.Background(),
+			`SELECT password FROM users WHERE email = $1`, email).Scan(&storedHash)
+		assert.NoError(t, err)
+		assert.Equal(t, hashedPassword, storedHash)
+	})
+
+	t.Run("happy path revokes other sessions", func(t *testing.T) {
+		current := login(oldPassword)
+		other := login(oldPassword)
+
+		code, message := changePassword(current, &ChangePasswordRequest{
+			CurrentPassword: oldPassword,
+			NewPassword:     "short",
+		})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, ErrPasswordLength.Error(), message)
+
+		code, _ = changePassword(current, &ChangePasswordRequest{
+			CurrentPassword: oldPassword,
+			NewPassword:     newPassword,
+		})
+		assert.Equal(t, http.StatusOK, code)
+
+		var storedHash string
+		err := migrator.DB.QueryRowContext(context.Background(),
+			`SELECT password FROM users WHERE email = $1`, email).Scan(&storedHash)
+		assert.NoError(t, err)
+		match, err := argon2id.ComparePasswordAndHash(newPassword, storedHash)
+		assert.NoError(t, err)
+		assert.True(t, match)
+
+		assert.Equal(t, http.StatusUnauthorized, tryLogin(oldPassword).Code)
+
+		assert.Equal(t, http.StatusOK, restricted(current))
+		assert.Equal(t, http.StatusUnauthorized, restricted(other))
+
+		// Only the session that changed the password is left
+		var sessions int
+		err = migrator.DB.QueryRowContext(context.Background(), `
+			SELECT count(*) FROM sessions s JOIN users u ON u.id = s.user_id WHERE u.email = $1
+		`, email).Scan(&sessions)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sessions)
+	})
+
+	t.Run("old session cookie is rejected after a password change", func(t *testing.T) {
+		old := login(newPassword)
+		current := login(newPassword)
+
+		code, _ := changePassword(current, &ChangePasswordRequest{
+			CurrentPassword: newPassword,
+			NewPassword:     oldPassword,
+		})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, http.StatusUnauthorized, tryLogin(newPassword).Code)
+
+		assert.Equal(t, http.StatusUnauthorized, restricted(old))
+		assert.Equal(t, http.StatusOK, restricted(current))
+
+		// The old cookie cannot be used to change the password back either
+		code, _ = changePassword(old, &ChangePasswordRequest{
+			CurrentPassword: oldPassword,
+			NewPassword:     newPassword,
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("not logged in", func(t *testing.T) {
+		code, _ := changePassword("", &ChangePasswordRequest{
+			CurrentPassword: newPassword,
+			NewPassword:     oldPassword,
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+}
+
 func extractToken(cookie string) (string, error) {
 	parts := strings.Split(cookie, ";")
 	if len(parts) == 0 {
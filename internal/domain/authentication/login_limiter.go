@@ -0,0 +1,159 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultMaxLoginAttempts = 5
+	defaultLoginWindow      = 15 * time.Minute
+	defaultLoginLockout     = 15 * time.Minute
+
+	loginFailureKeyPrefix = "login_failures:"
+)
+
+// ErrTooManyLoginAttempts được trả về khi tài khoản đang bị khóa tạm thời
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+// LoginLimiter đếm số lần đăng nhập sai theo email và khóa tài khoản tạm thời
+type LoginLimiter interface {
+	// Locked trả về thời gian còn lại nếu email đang bị khóa, 0 nếu không
+	Locked(ctx context.Context, email string) (time.Duration, error)
+	// Fail ghi nhận một lần đăng nhập sai và trả về thời gian khóa nếu vượt ngưỡng
+	Fail(ctx context.Context, email string) (time.Duration, error)
+	// Reset xóa bộ đếm sau khi đăng nhập thành công
+	Reset(ctx context.Context, email string) error
+}
+
+// RedisLoginLimiter lưu bộ đếm đăng nhập sai trong Redis.
+// Mỗi email có một key; key hết hạn sau window, hoặc sau lockout khi đã đạt ngưỡng.
+type RedisLoginLimiter struct {
+	client      *redis.Client
+	maxAttempts int
+	window      time.Duration
+	lockout     time.Duration
+}
+
+// LoginLimiterOption cấu hình thêm cho RedisLoginLimiter
+type LoginLimiterOption func(*RedisLoginLimiter)
+
+// WithMaxLoginAttempts đặt số lần đăng nhập sai tối đa trong một window
+func WithMaxLoginAttempts(n int) LoginLimiterOption {
+	return func(l *RedisLoginLimiter) {
+		if n > 0 {
+			l.maxAttempts = n
+		}
+	}
+}
+
+// WithLoginWindow đặt khoảng thời gian đếm số lần đăng nhập sai
+func WithLoginWindow(d time.Duration) LoginLimiterOption {
+	return func(l *RedisLoginLimiter) {
+		if d > 0 {
+			l.window = d
+		}
+	}
+}
+
+// WithLoginLockout đặt thời gian khóa tài khoản sau khi vượt ngưỡng
+func WithLoginLockout(d time.Duration) LoginLimiterOption {
+	return func(l *RedisLoginLimiter) {
+		if d > 0 {
+			l.lockout = d
+		}
+	}
+}
+
+// NewRedisLoginLimiter tạo LoginLimiter dùng Redis client sẵn có
+func NewRedisLoginLimiter(client *redis.Client, opts ...LoginLimiterOption) *RedisLoginLimiter {
+	l := &RedisLoginLimiter{
+		client:      client,
+		maxAttempts: defaultMaxLoginAttempts,
+		window:      defaultLoginWindow,
+		lockout:     defaultLoginLockout,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Locked kiểm tra email có đang bị khóa hay không
+func (l *RedisLoginLimiter) Locked(ctx context.Context, email string) (time.Duration, error) {
+	key := loginFailureKey(email)
+
+	count, err := l.client.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if count < l.maxAttempts {
+		return 0, nil
+	}
+
+	return l.remaining(ctx, key)
+}
+
+// Fail tăng bộ đếm đăng nhập sai của email
+func (l *RedisLoginLimiter) Fail(ctx context.Context, email string) (time.Duration, error) {
+	key := loginFailureKey(email)
+
+	count, err := l.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case count >= int64(l.maxAttempts):
+		if err := l.client.Expire(ctx, key, l.lockout).Err(); err != nil {
+			return 0, err
+		}
+		return l.lockout, nil
+	case count == 1:
+		if err := l.client.Expire(ctx, key, l.window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, nil
+}
+
+// Reset xóa bộ đếm đăng nhập sai của email
+func (l *RedisLoginLimiter) Reset(ctx context.Context, email string) error {
+	return l.client.Del(ctx, loginFailureKey(email)).Err()
+}
+
+func (l *RedisLoginLimiter) remaining(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := l.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		// Key không có TTL thì vẫn coi như đang khóa trong một lockout đầy đủ
+		return l.lockout, nil
+	}
+	return ttl, nil
+}
+
+func loginFailureKey(email string) string {
+	return loginFailureKeyPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+// noopLoginLimiter không giới hạn đăng nhập, dùng khi chưa cấu hình Redis
+type noopLoginLimiter struct{}
+
+func (noopLoginLimiter) Locked(context.Context, string) (time.Duration, error) { return 0, nil }
+
+func (noopLoginLimiter) Fail(context.Context, string) (time.Duration, error) { return 0, nil }
+
+func (noopLoginLimiter) Reset(context.Context, string) error { return nil }
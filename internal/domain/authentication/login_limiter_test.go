@@ -0,0 +1,172 @@
+package authentication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gmhafiz/scs/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/middleware"
+)
+
+const (
+	limiterTestEmail    = "locked@example.com"
+	limiterTestPassword = "correctHorseBatteryStaple"
+)
+
+// passwordRepo là Repo giả chỉ hỗ trợ Login với một mật khẩu cố định
+type passwordRepo struct {
+	Repo
+}
+
+func (passwordRepo) Login(_ context.Context, req LoginRequest) (*User, bool, error) {
+	now := time.Now()
+	return &User{ID: 1, Email: req.Email, VerifiedAt: &now}, req.Password == limiterTestPassword, nil
+}
+
+func newTestLoginLimiter(t *testing.T) (*RedisLoginLimiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisLoginLimiter(client), mr
+}
+
+func TestHandler_LoginRateLimit(t *testing.T) {
+	limiter, mr := newTestLoginLimiter(t)
+
+	session := scs.New()
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, passwordRepo{}, newCaptureSender(), WithLoginLimiter(limiter))
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(&LoginRequest{Email: limiterTestEmail, Password: password})
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewReader(body)))
+		return rr
+	}
+
+	t.Run("correct password resets the counter", func(t *testing.T) {
+		for i := 0; i < defaultMaxLoginAttempts-1; i++ {
+			assert.Equal(t, http.StatusUnauthorized, login("wrongPassword1234").Code)
+		}
+		assert.Equal(t, http.StatusOK, login(limiterTestPassword).Code)
+		assert.False(t, mr.Exists(loginFailureKey(limiterTestEmail)))
+	})
+
+	t.Run("6th wrong password is throttled", func(t *testing.T) {
+		for i := 0; i < defaultMaxLoginAttempts; i++ {
+			assert.Equal(t, http.StatusUnauthorized, login("wrongPassword1234").Code, "attempt %d", i+1)
+		}
+
+		rr := login("wrongPassword1234")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, defaultLoginLockout.Seconds(), retryAfter, 1)
+
+		// Mật khẩu đúng cũng bị chặn cho tới khi hết thời gian khóa
+		assert.Equal(t, http.StatusTooManyRequests, login(limiterTestPassword).Code)
+	})
+
+	t.Run("lock expires after cooldown", func(t *testing.T) {
+		mr.FastForward(defaultLoginLockout)
+		assert.Equal(t, http.StatusOK, login(limiterTestPassword).Code)
+	})
+}
+
+// failingRepo là Repo giả mà Login luôn lỗi, như khi database không truy cập được
+type failingRepo struct {
+	Repo
+}
+
+func (failingRepo) Login(context.Context, LoginRequest) (*User, bool, error) {
+	return nil, false, errors.New("pq: connection refused")
+}
+
+func TestHandler_LoginRepoError(t *testing.T) {
+	limiter, mr := newTestLoginLimiter(t)
+
+	session := scs.New()
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session))
+	RegisterHTTPEndPoints(router, session, failingRepo{}, newCaptureSender(), WithLoginLimiter(limiter))
+
+	for i := 0; i <= defaultMaxLoginAttempts; i++ {
+		body, err := json.Marshal(&LoginRequest{Email: limiterTestEmail, Password: limiterTestPassword})
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewReader(body)))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "connection refused")
+	}
+
+	// Failures of the database are not failed attempts of the user
+	assert.False(t, mr.Exists(loginFailureKey(limiterTestEmail)))
+}
+
+func TestRedisLoginLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failures expire after the window", func(t *testing.T) {
+		limiter, mr := newTestLoginLimiter(t)
+
+		for i := 0; i < defaultMaxLoginAttempts-1; i++ {
+			lock, err := limiter.Fail(ctx, "window@example.com")
+			require.NoError(t, err)
+			assert.Zero(t, lock)
+		}
+
+		mr.FastForward(defaultLoginWindow)
+
+		lock, err := limiter.Fail(ctx, "window@example.com")
+		require.NoError(t, err)
+		assert.Zero(t, lock)
+	})
+
+	t.Run("email is case insensitive", func(t *testing.T) {
+		limiter, _ := newTestLoginLimiter(t)
+		limiter.maxAttempts = 1
+
+		lock, err := limiter.Fail(ctx, "Case@Example.com")
+		require.NoError(t, err)
+		assert.Equal(t, defaultLoginLockout, lock)
+
+		remaining, err := limiter.Locked(ctx, "case@example.com")
+		require.NoError(t, err)
+		assert.Positive(t, remaining)
+	})
+
+	t.Run("options override defaults", func(t *testing.T) {
+		limiter := NewRedisLoginLimiter(nil,
+			WithMaxLoginAttempts(3),
+			WithLoginWindow(time.Minute),
+			WithLoginLockout(time.Hour),
+			WithMaxLoginAttempts(0),
+		)
+		assert.Equal(t, 3, limiter.maxAttempts)
+		assert.Equal(t, time.Minute, limiter.window)
+		assert.Equal(t, time.Hour, limiter.lockout)
+	})
+}
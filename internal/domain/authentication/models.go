@@ -1,5 +1,7 @@
 package authentication
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -12,6 +14,24 @@ type RegisterRequest struct {
 	Password  string `json:"password"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
 type RespondCsrf struct {
 	CsrfToken string `json:"csrf_token"`
 }
@@ -22,4 +42,6 @@ type User struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	Password  string `json:"-"`
+
+	VerifiedAt *time.Time `json:"-"`
 }
@@ -7,11 +7,15 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Repo) {
-	h := NewHandler(session, repo)
+func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Repo, sender TokenSender, opts ...HandlerOption) {
+	h := NewHandler(session, repo, sender, opts...)
 
 	router.Post("/api/v1/login", h.Login)
 	router.Post("/api/v1/register", h.Register)
+	router.Get("/api/v1/verify", h.Verify)
+	router.Post("/api/v1/verify/resend", h.ResendVerification)
+	router.Post("/api/v1/forgot-password", h.ForgotPassword)
+	router.Post("/api/v1/reset-password", h.ResetPassword)
 
 	router.Route("/api/v1/logout", func(router chi.Router) {
 		router.Post("/", h.Logout)
@@ -22,6 +26,7 @@ func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Re
 		router.Get("/csrf", h.Csrf)
 		router.Get("/", h.Protected)
 		router.Get("/me", h.Me)
+		router.Post("/change-password", h.ChangePassword)
 		router.Post("/logout/{userID}", h.ForceLogout)
 	})
 }
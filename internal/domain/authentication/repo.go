@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/alexedwards/argon2id"
 	"github.com/gmhafiz/scs/v2"
 )
 
@@ -20,22 +22,39 @@ func NewRepo(db *sql.DB, session *scs.SessionManager) Repo {
 	}
 }
 
-func (r *repo) Register(ctx context.Context, firstName, lastName, email, password string) error {
-	query := `
+func (r *repo) Register(ctx context.Context, firstName, lastName, email, password, tokenHash string, tokenExpiry time.Time) (uint64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id uint64
+	err = tx.QueryRowContext(ctx, `
 		INSERT INTO users (first_name, last_name, email, password)
 		VALUES ($1, $2, $3, $4)
-	`
-	_, err := r.db.ExecContext(ctx, query, firstName, lastName, email, password)
+		RETURNING id
+	`, firstName, lastName, email, password).Scan(&id)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return nil
+
+	// Token xác thực được tạo cùng transaction, người dùng không bao giờ bị tạo mà thiếu token
+	if err = createToken(ctx, tx, id, PurposeVerifyEmail, tokenHash, tokenExpiry); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
 }
 
 func (r *repo) Login(ctx context.Context, req LoginRequest) (*User, bool, error) {
 	var user User
+	var verifiedAt sql.NullTime
 	query := `
-		SELECT id, first_name, last_name, email, password
+		SELECT id, first_name, last_name, email, password, verified_at
 		FROM users
 		WHERE email = $1
 	`
@@ -45,6 +64,7 @@ func (r *repo) Login(ctx context.Context, req LoginRequest) (*User, bool, error)
 		&user.LastName,
 		&user.Email,
 		&user.Password,
+		&verifiedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -53,7 +73,16 @@ func (r *repo) Login(ctx context.Context, req LoginRequest) (*User, bool, error)
 		return nil, false, err
 	}
 
-	return &user, true, nil
+	if verifiedAt.Valid {
+		user.VerifiedAt = &verifiedAt.Time
+	}
+
+	match, err := argon2id.ComparePasswordAndHash(req.Password, user.Password)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &user, match, nil
 }
 
 func (r *repo) Logout(ctx context.Context, userID uint64) (bool, error) {
@@ -78,3 +107,155 @@ func (r *repo) Csrf(ctx context.Context) (string, error) {
 	// TODO: Implement CSRF token generation and storage
 	return "", nil
 }
+
+func (r *repo) CreateToken(ctx context.Context, userID uint64, purpose, tokenHash string, expiry time.Time) error {
+	return createToken(ctx, r.db, userID, purpose, tokenHash, expiry)
+}
+
+func (r *repo) CreateVerificationToken(ctx context.Context, email, tokenHash string, expiry time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_tokens (token, user_id, purpose, expiry)
+		SELECT $1, id, $2, $3
+		FROM users
+		WHERE email = $4 AND verified_at IS NULL
+	`, tokenHash, PurposeVerifyEmail, expiry, email)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *repo) VerifyEmail(ctx context.Context, tokenHash string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Token chỉ dùng một lần: xóa token hợp lệ và lấy user_id trong cùng một câu lệnh
+	var userID uint64
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM user_tokens
+		WHERE token = $1 AND purpose = $2 AND current_timestamp < expiry
+		RETURNING user_id
+	`, tokenHash, PurposeVerifyEmail).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET verified_at = current_timestamp
+		WHERE id = $1 AND verified_at IS NULL
+	`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repo) UserIDByEmail(ctx context.Context, email string) (uint64, bool, error) {
+	var id uint64
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM users WHERE email = $1`, email).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+func (r *repo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var userID uint64
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM user_tokens
+		WHERE token = $1 AND purpose = $2 AND current_timestamp < expiry
+		RETURNING user_id
+	`, tokenHash, PurposeResetPassword).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2`, passwordHash, userID); err != nil {
+		return false, err
+	}
+
+	// Các session cũ có thể đã bị lộ, buộc đăng nhập lại với mật khẩu mới
+	if err = deleteSessions(ctx, tx, userID, ""); err != nil {
+		return false, err
+	}
+
+	// Các token đặt lại mật khẩu khác của người dùng không còn giá trị
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2`, userID, PurposeResetPassword); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repo) PasswordHash(ctx context.Context, userID uint64) (string, error) {
+	var hash string
+	err := r.db.QueryRowContext(ctx, `SELECT password FROM users WHERE id = $1`, userID).Scan(&hash)
+	return hash, err
+}
+
+func (r *repo) ChangePassword(ctx context.Context, userID uint64, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2`, passwordHash, userID)
+	return err
+}
+
+func (r *repo) DeleteSessions(ctx context.Context, userID uint64, keepToken string) error {
+	return deleteSessions(ctx, r.db, userID, keepToken)
+}
+
+// execer chạy câu lệnh trên *sql.DB hoặc trong một *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// createToken lưu hash của một token một lần cho người dùng
+func createToken(ctx context.Context, db execer, userID uint64, purpose, tokenHash string, expiry time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO user_tokens (token, user_id, purpose, expiry)
+		VALUES ($1, $2, $3, $4)
+	`, tokenHash, userID, purpose, expiry)
+	return err
+}
+
+// deleteSessions xóa các session của người dùng trừ session có token keepToken, nếu có
+func deleteSessions(ctx context.Context, db execer, userID uint64, keepToken string) error {
+	if keepToken == "" {
+		_, err := db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, `
+		DELETE FROM sessions
+		WHERE user_id = $1 AND token <> $2
+	`, userID, keepToken)
+	return err
+}
@@ -0,0 +1,70 @@
+package authentication
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+)
+
+// SMTPTokenSender gửi token tới người dùng qua email bằng một SMTP server
+type SMTPTokenSender struct {
+	addr    string
+	auth    smtp.Auth
+	from    string
+	baseURL string
+
+	// sendMail mặc định là smtp.SendMail, test thay thế để không cần SMTP server
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPTokenSender tạo sender gửi email qua SMTP server tại addr (host:port).
+// baseURL là địa chỉ công khai của API, dùng để tạo link xác thực email.
+func NewSMTPTokenSender(addr string, auth smtp.Auth, from, baseURL string) *SMTPTokenSender {
+	return &SMTPTokenSender{
+		addr:     addr,
+		auth:     auth,
+		from:     from,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		sendMail: smtp.SendMail,
+	}
+}
+
+func (s *SMTPTokenSender) SendVerification(ctx context.Context, email, token string) error {
+	link := s.baseURL + "/api/v1/verify?token=" + url.QueryEscape(token)
+	body := "Please verify your email address by opening the link below. The link expires in 24 hours.\r\n\r\n" + link + "\r\n"
+
+	return s.send(ctx, email, "Verify your email address", body)
+}
+
+func (s *SMTPTokenSender) SendPasswordReset(ctx context.Context, email, token string) error {
+	body := "Use the code below to reset your password. The code expires in 1 hour.\r\n\r\n" + token + "\r\n\r\n" +
+		"If you did not request a password reset, you can ignore this email.\r\n"
+
+	return s.send(ctx, email, "Reset your password", body)
+}
+
+func (s *SMTPTokenSender) send(ctx context.Context, email, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Email đến từ request, chỉ nhận một địa chỉ hợp lệ để không chèn được header
+	to, err := mail.ParseAddress(email)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", email, err)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + to.Address + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return s.sendMail(s.addr, s.auth, s.from, []string{to.Address}, []byte(msg.String()))
+}
@@ -0,0 +1,74 @@
+package authentication
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentMail struct {
+	addr string
+	from string
+	to   []string
+	msg  string
+}
+
+func newTestSMTPTokenSender(sent *[]sentMail) *SMTPTokenSender {
+	s := NewSMTPTokenSender("smtp.example.com:587", nil, "no-reply@example.com", "https://api.example.com/")
+	s.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		*sent = append(*sent, sentMail{addr: addr, from: from, to: to, msg: string(msg)})
+		return nil
+	}
+	return s
+}
+
+func TestSMTPTokenSender_SendVerification(t *testing.T) {
+	var sent []sentMail
+	s := newTestSMTPTokenSender(&sent)
+
+	err := s.SendVerification(context.Background(), "user@example.com", "abc_DEF-123")
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+
+	assert.Equal(t, "smtp.example.com:587", sent[0].addr)
+	assert.Equal(t, "no-reply@example.com", sent[0].from)
+	assert.Equal(t, []string{"user@example.com"}, sent[0].to)
+	assert.Contains(t, sent[0].msg, "To: user@example.com\r\n")
+	assert.Contains(t, sent[0].msg, "Subject: Verify your email address\r\n")
+	assert.Contains(t, sent[0].msg, "https://api.example.com/api/v1/verify?token=abc_DEF-123")
+}
+
+func TestSMTPTokenSender_SendPasswordReset(t *testing.T) {
+	var sent []sentMail
+	s := newTestSMTPTokenSender(&sent)
+
+	err := s.SendPasswordReset(context.Background(), "user@example.com", "reset-token")
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+
+	assert.Contains(t, sent[0].msg, "Subject: Reset your password\r\n")
+	assert.Contains(t, sent[0].msg, "reset-token")
+}
+
+func TestSMTPTokenSender_RejectsHeaderInjection(t *testing.T) {
+	var sent []sentMail
+	s := newTestSMTPTokenSender(&sent)
+
+	err := s.SendVerification(context.Background(), "user@example.com\r\nBcc: victim@example.com", "token")
+	assert.Error(t, err)
+	assert.Empty(t, sent)
+}
+
+func TestSMTPTokenSender_CanceledContext(t *testing.T) {
+	var sent []sentMail
+	s := newTestSMTPTokenSender(&sent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, s.SendVerification(ctx, "user@example.com", "token"), context.Canceled)
+	assert.Empty(t, sent)
+}
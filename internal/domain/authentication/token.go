@@ -0,0 +1,64 @@
+package authentication
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"time"
+)
+
+const (
+	// PurposeVerifyEmail đánh dấu token dùng để xác thực email sau khi đăng ký
+	PurposeVerifyEmail = "verify_email"
+	// PurposeResetPassword đánh dấu token dùng để đặt lại mật khẩu
+	PurposeResetPassword = "reset_password"
+
+	verificationTokenTTL  = 24 * time.Hour
+	resetPasswordTokenTTL = 1 * time.Hour
+	tokenBytes            = 32
+)
+
+// TokenSender gửi token một lần (vd: link xác thực email) tới người dùng
+type TokenSender interface {
+	SendVerification(ctx context.Context, email, token string) error
+	SendPasswordReset(ctx context.Context, email, token string) error
+}
+
+// devLogTokenSender ghi token vào log thay vì gửi email, chỉ dùng khi phát triển
+type devLogTokenSender struct{}
+
+// NewDevLogTokenSender tạo sender ghi token vào log thay vì gửi email. Token trong log đủ để
+// chiếm tài khoản nên sender này không bao giờ là mặc định; chỉ bật rõ ràng khi phát triển.
+func NewDevLogTokenSender() TokenSender {
+	return devLogTokenSender{}
+}
+
+func (devLogTokenSender) SendVerification(_ context.Context, email, token string) error {
+	log.Printf("verification link for %s: /api/v1/verify?token=%s", email, token)
+	return nil
+}
+
+func (devLogTokenSender) SendPasswordReset(_ context.Context, email, token string) error {
+	log.Printf("password reset token for %s: %s", email, token)
+	return nil
+}
+
+// newToken tạo một token ngẫu nhiên và trả về cả giá trị gốc (gửi cho người dùng)
+// lẫn hash (lưu trong database), giống cách lưu csrf token.
+func newToken() (token, hash string, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// hashToken tính hash của token để không lưu token gốc trong database
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,13 +1,18 @@
 package authentication
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repo định nghĩa interface cho authentication repository
 type Repo interface {
-	// Register đăng ký người dùng mới
-	Register(ctx context.Context, firstName, lastName, email, password string) error
+	// Register đăng ký người dùng mới cùng token xác thực email trong một transaction
+	// và trả về ID của người dùng
+	Register(ctx context.Context, firstName, lastName, email, password, tokenHash string, tokenExpiry time.Time) (uint64, error)
 
-	// Login xác thực người dùng và trả về thông tin nếu thành công
+	// Login xác thực người dùng và trả về thông tin nếu thành công. Email không tồn tại
+	// hoặc sai mật khẩu trả về false với error nil, error chỉ dành cho lỗi hệ thống.
 	Login(ctx context.Context, req LoginRequest) (*User, bool, error)
 
 	// Logout đăng xuất người dùng bằng cách xóa session
@@ -15,4 +20,32 @@ type Repo interface {
 
 	// Csrf tạo và lưu trữ CSRF token
 	Csrf(ctx context.Context) (string, error)
+
+	// CreateToken lưu hash của một token một lần cho người dùng
+	CreateToken(ctx context.Context, userID uint64, purpose, tokenHash string, expiry time.Time) error
+
+	// CreateVerificationToken lưu token xác thực mới cho người dùng có email chưa được xác thực.
+	// Trả về false nếu email không tồn tại hoặc đã được xác thực.
+	CreateVerificationToken(ctx context.Context, email, tokenHash string, expiry time.Time) (bool, error)
+
+	// VerifyEmail dùng token xác thực (xóa token) và đặt verified_at cho người dùng.
+	// Trả về false nếu token không tồn tại, đã dùng hoặc đã hết hạn.
+	VerifyEmail(ctx context.Context, tokenHash string) (bool, error)
+
+	// UserIDByEmail tìm ID người dùng theo email, trả về false nếu không tồn tại
+	UserIDByEmail(ctx context.Context, email string) (uint64, bool, error)
+
+	// ResetPassword dùng token đặt lại mật khẩu (xóa token), lưu hash mật khẩu mới và
+	// xóa toàn bộ session hiện có của người dùng. Trả về false nếu token không hợp lệ.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (bool, error)
+
+	// PasswordHash trả về hash mật khẩu hiện tại của người dùng
+	PasswordHash(ctx context.Context, userID uint64) (string, error)
+
+	// ChangePassword lưu hash mật khẩu mới cho người dùng
+	ChangePassword(ctx context.Context, userID uint64, passwordHash string) error
+
+	// DeleteSessions xóa mọi session của người dùng. Nếu keepToken khác rỗng, session có
+	// token đó được giữ lại.
+	DeleteSessions(ctx context.Context, userID uint64, keepToken string) error
 }
@@ -0,0 +1,77 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxEmailLength là độ dài tối đa của một địa chỉ email theo RFC 5321
+	maxEmailLength = 254
+	// maxPasswordLength giới hạn chi phí băm mật khẩu với input quá dài
+	maxPasswordLength = 128
+	// minPasswordDistinctChars loại các mật khẩu đủ dài nhưng lặp lại vài ký tự
+	minPasswordDistinctChars = 6
+	maxNameLength            = 100
+)
+
+var (
+	ErrEmailInvalid = errors.New("email address is invalid")
+	ErrPasswordWeak = fmt.Errorf("password must contain at least %d different characters", minPasswordDistinctChars)
+	ErrNameLength   = fmt.Errorf("first and last name must be at most %d characters", maxNameLength)
+)
+
+// Validate kiểm tra thông tin đăng ký trước khi tạo tài khoản
+func (r *RegisterRequest) Validate() error {
+	if err := validateEmail(r.Email); err != nil {
+		return err
+	}
+	if err := validatePassword(r.Password); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(r.FirstName) > maxNameLength || utf8.RuneCountInString(r.LastName) > maxNameLength {
+		return ErrNameLength
+	}
+	return nil
+}
+
+// validateEmail chỉ chấp nhận một địa chỉ email trần như "user@example.com", không kèm
+// tên hiển thị, với tên miền có ít nhất một dấu chấm
+func validateEmail(email string) error {
+	if email == "" {
+		return ErrEmailRequired
+	}
+	if len(email) > maxEmailLength {
+		return ErrEmailInvalid
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrEmailInvalid
+	}
+
+	_, domain, _ := strings.Cut(addr.Address, "@")
+	if !strings.Contains(strings.Trim(domain, "."), ".") {
+		return ErrEmailInvalid
+	}
+	return nil
+}
+
+// validatePassword áp dụng chính sách mật khẩu cho mật khẩu mới
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return ErrPasswordLength
+	}
+
+	distinct := make(map[rune]struct{}, minPasswordDistinctChars)
+	for _, c := range password {
+		distinct[c] = struct{}{}
+		if len(distinct) >= minPasswordDistinctChars {
+			return nil
+		}
+	}
+	return ErrPasswordWeak
+}
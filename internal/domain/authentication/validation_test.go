@@ -0,0 +1,72 @@
+package authentication
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request RegisterRequest
+		want    error
+	}{
+		{
+			name:    "valid",
+			request: RegisterRequest{FirstName: "Minh", Email: "user@example.com", Password: "highEntropyPassword"},
+		},
+		{
+			name:    "no email",
+			request: RegisterRequest{Password: "highEntropyPassword"},
+			want:    ErrEmailRequired,
+		},
+		{
+			name:    "not an email",
+			request: RegisterRequest{Email: "not-an-email", Password: "highEntropyPassword"},
+			want:    ErrEmailInvalid,
+		},
+		{
+			name:    "display name",
+			request: RegisterRequest{Email: "User <user@example.com>", Password: "highEntropyPassword"},
+			want:    ErrEmailInvalid,
+		},
+		{
+			name:    "domain without dot",
+			request: RegisterRequest{Email: "user@localhost", Password: "highEntropyPassword"},
+			want:    ErrEmailInvalid,
+		},
+		{
+			name:    "over-long email",
+			request: RegisterRequest{Email: strings.Repeat("a", 250) + "@example.com", Password: "highEntropyPassword"},
+			want:    ErrEmailInvalid,
+		},
+		{
+			name:    "short password",
+			request: RegisterRequest{Email: "user@example.com", Password: "short"},
+			want:    ErrPasswordLength,
+		},
+		{
+			name:    "over-long password",
+			request: RegisterRequest{Email: "user@example.com", Password: strings.Repeat("highEntropy", 20)},
+			want:    ErrPasswordLength,
+		},
+		{
+			name:    "repetitive password",
+			request: RegisterRequest{Email: "user@example.com", Password: "abcabcabcabcabc"},
+			want:    ErrPasswordWeak,
+		},
+		{
+			name:    "over-long name",
+			request: RegisterRequest{FirstName: strings.Repeat("n", maxNameLength+1), Email: "user@example.com", Password: "highEntropyPassword"},
+			want:    ErrNameLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.request.Validate())
+		})
+	}
+}
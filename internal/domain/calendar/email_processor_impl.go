@@ -8,13 +8,33 @@ import (
 	"time"
 
 	ical "github.com/arran4/golang-ical"
+	"go.uber.org/zap/zapcore"
 )
 
-// EmailAttachment đại diện cho một tệp đính kèm email
+// EmailAttachment đại diện cho một tệp đính kèm email.
+// Data là nội dung file của người dùng, không bao giờ được ghi ra log hay trace.
 type EmailAttachment struct {
 	Filename    string
 	ContentType string
-	Data        []byte
+	Data        []byte `json:"-"`
+}
+
+// String mô tả tệp đính kèm mà không in nội dung
+func (a EmailAttachment) String() string {
+	return fmt.Sprintf("EmailAttachment{Filename: %q, ContentType: %q, Size: %d}", a.Filename, a.ContentType, len(a.Data))
+}
+
+// GoString tránh in nội dung khi dùng %#v
+func (a EmailAttachment) GoString() string {
+	return a.String()
+}
+
+// MarshalLogObject cho phép zap ghi log tệp đính kèm mà không kèm nội dung
+func (a EmailAttachment) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("filename", a.Filename)
+	enc.AddString("content_type", a.ContentType)
+	enc.AddInt("size", len(a.Data))
+	return nil
 }
 
 // Event đại diện cho một sự kiện lịch
@@ -65,7 +85,7 @@ func (ep *emailProcessorImpl) HandleCalendarInvite(att EmailAttachment) (*Event,
 		return nil, fmt.Errorf("failed to get start time: %w", err)
 	}
 
-	endTime, err := event.GetEndAt()
+	endTime, err := EventEndAt(event, startTime)
 	if err != nil {
 		ep.logger.Printf("Lỗi khi lấy thời gian kết thúc: %v", err)
 		return nil, fmt.Errorf("failed to get end time: %w", err)
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"time"
 )
@@ -14,6 +15,7 @@ const (
 	ServiceUnavailable = "SERVICE_UNAVAILABLE"
 	ParseError         = "PARSE_ERROR"
 	ValidationError    = "VALIDATION_ERROR"
+	EmailTooLarge      = "EMAIL_TOO_LARGE"
 )
 
 // CalendarError represents a domain-specific error
@@ -41,6 +43,11 @@ func (e *CalendarError) Is(target error) bool {
 	return false
 }
 
+// Unwrap returns the underlying error so errors.Is and errors.As see through it
+func (e *CalendarError) Unwrap() error {
+	return e.WrappedErr
+}
+
 // NewError creates a new CalendarError
 func NewError(errType string, message string) *CalendarError {
 	return &CalendarError{
@@ -100,59 +107,80 @@ func NewValidationError(message string) *CalendarError {
 	return NewError(ValidationError, message)
 }
 
+func NewEmailTooLargeError(message string) *CalendarError {
+	return NewError(EmailTooLarge, message)
+}
+
+// asCalendarError finds the CalendarError in err's chain, so errors wrapped with %w
+// on their way up keep their type
+func asCalendarError(err error) (*CalendarError, bool) {
+	var cerr *CalendarError
+	if stderrors.As(err, &cerr) {
+		return cerr, true
+	}
+	return nil, false
+}
+
 // Error utility functions
 func IsInvalidEmail(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == InvalidEmail
 	}
 	return false
 }
 
 func IsInvalidToken(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == InvalidToken
 	}
 	return false
 }
 
 func IsInvalidTime(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == InvalidTime
 	}
 	return false
 }
 
 func IsConflict(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == ConflictDetected
 	}
 	return false
 }
 
 func IsServiceUnavailable(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == ServiceUnavailable
 	}
 	return false
 }
 
 func IsParseError(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == ParseError
 	}
 	return false
 }
 
 func IsValidationError(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == ValidationError
 	}
 	return false
 }
 
+func IsEmailTooLarge(err error) bool {
+	if cerr, ok := asCalendarError(err); ok {
+		return cerr.Type == EmailTooLarge
+	}
+	return false
+}
+
 // ShouldRetry determines if the error is retryable
 func ShouldRetry(err error) bool {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Type == ServiceUnavailable || cerr.RetryAfter != nil
 	}
 	return false
@@ -160,7 +188,7 @@ func ShouldRetry(err error) bool {
 
 // GetRetryAfter returns the suggested retry duration
 func GetRetryAfter(err error) *time.Duration {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.RetryAfter
 	}
 	return nil
@@ -168,7 +196,7 @@ func GetRetryAfter(err error) *time.Duration {
 
 // GetErrorDetails returns the error details map
 func GetErrorDetails(err error) map[string]interface{} {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Details
 	}
 	return nil
@@ -176,7 +204,7 @@ func GetErrorDetails(err error) map[string]interface{} {
 
 // GetErrorTime returns when the error occurred
 func GetErrorTime(err error) time.Time {
-	if cerr, ok := err.(*CalendarError); ok {
+	if cerr, ok := asCalendarError(err); ok {
 		return cerr.Time
 	}
 	return time.Time{}
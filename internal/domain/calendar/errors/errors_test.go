@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -97,6 +98,11 @@ func TestErrorConstructors(t *testing.T) {
 			constructor: NewValidationError,
 			errType:     ValidationError,
 		},
+		{
+			name:        "email too large error",
+			constructor: NewEmailTooLargeError,
+			errType:     EmailTooLarge,
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,12 +163,24 @@ func TestErrorTypeChecks(t *testing.T) {
 			checker:  IsValidationError,
 			expected: true,
 		},
+		{
+			name:     "is email too large",
+			err:      NewEmailTooLargeError("test"),
+			checker:  IsEmailTooLarge,
+			expected: true,
+		},
 		{
 			name:     "wrong error type",
 			err:      errors.New("test"),
 			checker:  IsValidationError,
 			expected: false,
 		},
+		{
+			name:     "wrapped parse error",
+			err:      fmt.Errorf("process email: %w", NewParseError("test")),
+			checker:  IsParseError,
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +217,11 @@ func TestShouldRetry(t *testing.T) {
 			err:      errors.New("test"),
 			expected: false,
 		},
+		{
+			name:     "wrapped service unavailable error",
+			err:      fmt.Errorf("create event: %w", NewServiceUnavailableError("test")),
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -290,3 +313,11 @@ func TestGetErrorTime(t *testing.T) {
 	// Non-calendar error should return zero time
 	assert.True(t, GetErrorTime(errors.New("test")).IsZero())
 }
+
+func TestCalendarError_Unwrap(t *testing.T) {
+	inner := errors.New("inner error")
+	err := NewParseError("test").WithWrappedError(inner)
+
+	assert.ErrorIs(t, err, inner)
+	assert.ErrorIs(t, err, NewParseError("other"))
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+// EmailPreviewer xem trước sự kiện sẽ được tạo từ email mà không tạo gì cả
+type EmailPreviewer interface {
+	ProcessEmailPreview(ctx context.Context, emailContent string) (*usecase.EmailEvent, error)
+}
+
+// emailPreviewResponse là sự kiện trích xuất được, trả về cho giao diện xác nhận
+type emailPreviewResponse struct {
+	Subject        string    `json:"subject"`
+	Description    string    `json:"description,omitempty"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	AllDay         bool      `json:"all_day"`
+	TimeZone       string    `json:"time_zone,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	RecurrenceRule string    `json:"recurrence_rule,omitempty"`
+	Organizer      string    `json:"organizer,omitempty"`
+	Attendees      []string  `json:"attendees"`
+}
+
+// EmailPreviewHandler trả về sự kiện trích xuất từ email để người dùng xem trước khi
+// thêm vào lịch. Handler không ghi vào lịch và không đưa email vào hàng đợi.
+type EmailPreviewHandler struct {
+	previewer EmailPreviewer
+	resolver  UserResolver
+	maxSize   int64
+}
+
+// NewEmailPreviewHandler tạo EmailPreviewHandler. resolver xác thực session token trong
+// header Authorization như InboundEmailHandler.
+func NewEmailPreviewHandler(previewer EmailPreviewer, resolver UserResolver) *EmailPreviewHandler {
+	return &EmailPreviewHandler{
+		previewer: previewer,
+		resolver:  resolver,
+		maxSize:   defaultMaxInboundEmailSize,
+	}
+}
+
+// Preview nhận email thô giống Inbound và trả về JSON của sự kiện trích xuất được.
+// Email không hợp lệ hoặc không chứa sự kiện trả về 422 kèm lý do.
+func (h *EmailPreviewHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authenticateRequest(w, r, h.resolver); !ok {
+		return
+	}
+
+	email, _, ok := readEmailRequest(w, r, h.maxSize)
+	if !ok {
+		return
+	}
+
+	event, err := h.previewer.ProcessEmailPreview(r.Context(), email)
+	if err != nil {
+		writeEmailProcessingError(w, err)
+		return
+	}
+
+	attendees := event.Attendees
+	if attendees == nil {
+		attendees = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&emailPreviewResponse{
+		Subject:        event.Subject,
+		Description:    event.Description,
+		StartTime:      event.StartTime,
+		EndTime:        event.EndTime,
+		AllDay:         event.IsAllDay,
+		TimeZone:       event.TimeZone,
+		Location:       event.Location,
+		RecurrenceRule: event.RecurrenceRule,
+		Organizer:      event.Organizer,
+		Attendees:      attendees,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+// stubEmailValidator accepts every email unless dkimErr is set
+type stubEmailValidator struct {
+	dkimErr error
+}
+
+func (v stubEmailValidator) ValidateDKIM(string) error   { return v.dkimErr }
+func (v stubEmailValidator) ValidateSPF(string) error    { return nil }
+func (v stubEmailValidator) ValidateSender(string) error { return nil }
+
+func TestEmailPreviewHandler_Preview(t *testing.T) {
+	ner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"entities": []usecase.Entity{{Text: "10/03/2025 14:00", Label: "DATE", Confidence: 0.9}},
+		})
+	}))
+	defer ner.Close()
+
+	resolver := UserResolverFunc(func(_ context.Context, token string) (string, error) {
+		if token == "session-7" {
+			return "7", nil
+		}
+		return "", ErrInvalidSession
+	})
+
+	// newRouter serves the preview next to the endpoints that do create events. Their
+	// mocks have no expectations, so any call fails the test.
+	newRouter := func(validator usecase.EmailValidator, svc *mockCalendarService, uc *mockCalendarUseCase, publisher *mockEmailPublisher) http.Handler {
+		processor := usecase.NewEmailProcessorImpl(validator, usecase.NewNERService(ner.URL))
+		router := chi.NewRouter()
+		RegisterEmailPreviewEndPoint(router, NewEmailPreviewHandler(processor, resolver))
+		RegisterInboundEmailEndPoint(router, NewInboundEmailHandler(publisher, resolver))
+		RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(svc, uc, resolver))
+		return router
+	}
+
+	t.Run("event is previewed without being created", func(t *testing.T) {
+		svc, uc, publisher := new(mockCalendarService), new(mockCalendarUseCase), new(mockEmailPublisher)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/preview", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newRouter(stubEmailValidator{}, svc, uc, publisher).ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var preview emailPreviewResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&preview))
+		assert.Equal(t, "Planning meeting", preview.Subject)
+		assert.Equal(t, 2025, preview.StartTime.Year())
+		assert.Equal(t, time.March, preview.StartTime.Month())
+		assert.Equal(t, 14, preview.StartTime.Hour())
+		assert.True(t, preview.EndTime.After(preview.StartTime))
+		assert.Equal(t, []string{"calendar@mail2calendar.io"}, preview.Attendees)
+
+		svc.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything)
+		svc.AssertNotCalled(t, "ProcessEmailToCalendar", mock.Anything, mock.Anything)
+		uc.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "PublishEmailEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("email failing validation is rejected", func(t *testing.T) {
+		validator := stubEmailValidator{dkimErr: errors.New("signature invalid")}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/preview", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newRouter(validator, new(mockCalendarService), new(mockCalendarUseCase), new(mockEmailPublisher)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "DKIM validation failed")
+	})
+
+	t.Run("missing session token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/preview", strings.NewReader(inboundTestEmail))
+		rec := httptest.NewRecorder()
+		newRouter(stubEmailValidator{}, new(mockCalendarService), new(mockCalendarUseCase), new(mockEmailPublisher)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+)
+
+// gatewayMarshaler giữ tên field snake_case như trong calendar.proto
+var gatewayMarshaler = &runtime.JSONPb{
+	MarshalOptions: protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	},
+	UnmarshalOptions: protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+	},
+}
+
+// gatewayPrefix là tiền tố các route của gateway trong calendar.proto, tách khỏi các
+// route /api/v1/calendar của HTTPCalendarHandler
+const gatewayPrefix = "/api/v1/gateway"
+
+// NewGatewayHandler tạo reverse proxy JSON REST cho CalendarService qua conn, với các
+// route /api/v1/gateway/calendar/events sinh từ google.api.http trong calendar.proto.
+// Phản hồi theo định dạng JSON của proto, khác với HTTPCalendarHandler. Header
+// Authorization được chuyển thành metadata "authorization" nên request vẫn đi qua auth
+// interceptor của gRPC.
+func NewGatewayHandler(ctx context.Context, conn *grpc.ClientConn, opts ...runtime.ServeMuxOption) (http.Handler, error) {
+	opts = append([]runtime.ServeMuxOption{
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, gatewayMarshaler),
+	}, opts...)
+
+	mux := runtime.NewServeMux(opts...)
+	if err := pb.RegisterCalendarServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
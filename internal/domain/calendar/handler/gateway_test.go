@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+func newGatewayTestHandler(t *testing.T, uc *mockCalendarUseCase) http.Handler {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	gw, err := NewGatewayHandler(ctx, newCalendarConn(t, NewCalendarHandler(uc), staticUsers))
+	require.NoError(t, err)
+	return gw
+}
+
+func TestGateway_CreateEvent(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("CreateEvent", mock.Anything, mock.MatchedBy(func(event *pb.Event) bool {
+		return proto.Equal(event, &pb.Event{Title: "Sync", StartTime: 1700000000, EndTime: 1700003600})
+	}), "user-1").Return(&pb.Event{Id: "evt-1", Title: "Sync"}, nil)
+
+	gw := newGatewayTestHandler(t, uc)
+
+	body := `{"title": "Sync", "start_time": "1700000000", "end_time": "1700003600"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gateway/calendar/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token-user-1")
+	rec := httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Event struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"event"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "evt-1", resp.Event.ID)
+	assert.Equal(t, "Sync", resp.Event.Title)
+	uc.AssertExpectations(t)
+}
+
+func TestGateway_Unauthenticated(t *testing.T) {
+	gw := newGatewayTestHandler(t, new(mockCalendarUseCase))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/gateway/calendar/events/evt-1", nil)
+	rec := httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestGateway_CreateEvent_Recurring(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	gw, err := NewGatewayHandler(ctx, newCalendarConn(t, NewCalendarHandler(usecase.NewCalendarUseCase(nil, nil)), staticUsers))
+	require.NoError(t, err)
+
+	body := `{"title": "Weekly sync", "start_time": "1741010400", "end_time": "1741014000",
+		"recurrence_rule": "RRULE:FREQ=WEEKLY;BYDAY=MO"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gateway/calendar/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token-user-1")
+	rec := httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Event struct {
+			ID             string `json:"id"`
+			RecurrenceRule string `json:"recurrence_rule"`
+			IsRecurring    bool   `json:"is_recurring"`
+		} `json:"event"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Event.ID)
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO", resp.Event.RecurrenceRule)
+	assert.True(t, resp.Event.IsRecurring)
+}
+
+func TestGateway_MountedBesideHTTPRoutes(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("GetEvent", mock.Anything, "search", "user-1").Return(&pb.Event{Id: "search"}, nil).Once()
+	uc.On("SearchEvents", mock.Anything, "sync", mock.Anything, "user-1").Return([]*pb.Event{}, nil).Once()
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(new(mockCalendarService), uc, staticUsers))
+	RegisterGatewayEndPoints(router, newGatewayTestHandler(t, uc))
+
+	// The gateway serves its own prefix, so its {event_id} route does not shadow
+	// /api/v1/calendar/events/search
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/gateway/calendar/events/search", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Event struct {
+			ID string `json:"id"`
+		} `json:"event"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "search", resp.Event.ID)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/search?q=sync", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	uc.AssertExpectations(t)
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
 	"mail2calendar/internal/domain/calendar/usecase"
 )
 
@@ -26,7 +28,16 @@ func (h *CalendarHandler) CreateEvent(ctx context.Context, req *pb.CreateEventRe
 		return nil, status.Error(codes.InvalidArgument, "event cannot be nil")
 	}
 
-	event, err := h.useCase.CreateEvent(ctx, req.Event, req.UserId)
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = service.WithEventSource(ctx, service.SourceAPI)
+	if req.IdempotencyKey != "" {
+		ctx = service.WithIdempotencyKey(ctx, req.IdempotencyKey)
+	}
+	event, err := h.useCase.CreateEvent(ctx, req.Event, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +52,12 @@ func (h *CalendarHandler) UpdateEvent(ctx context.Context, req *pb.UpdateEventRe
 		return nil, status.Error(codes.InvalidArgument, "event cannot be nil")
 	}
 
-	event, err := h.useCase.UpdateEvent(ctx, req.Event, req.UserId)
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := h.useCase.UpdateEvent(ctx, req.Event, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -56,11 +72,15 @@ func (h *CalendarHandler) DeleteEvent(ctx context.Context, req *pb.DeleteEventRe
 		return nil, status.Error(codes.InvalidArgument, "event ID cannot be empty")
 	}
 
-	err := h.useCase.DeleteEvent(ctx, req.EventId, req.UserId)
+	userID, err := userFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := h.useCase.DeleteEvent(ctx, req.EventId, userID); err != nil {
+		return nil, err
+	}
+
 	return &pb.DeleteEventResponse{
 		Success: true,
 	}, nil
@@ -71,7 +91,12 @@ func (h *CalendarHandler) GetEvent(ctx context.Context, req *pb.GetEventRequest)
 		return nil, status.Error(codes.InvalidArgument, "event ID cannot be empty")
 	}
 
-	event, err := h.useCase.GetEvent(ctx, req.EventId, req.UserId)
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := h.useCase.GetEvent(ctx, req.EventId, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -82,11 +107,12 @@ func (h *CalendarHandler) GetEvent(ctx context.Context, req *pb.GetEventRequest)
 }
 
 func (h *CalendarHandler) ListEvents(ctx context.Context, req *pb.ListEventsRequest) (*pb.ListEventsResponse, error) {
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user ID cannot be empty")
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	events, nextPageToken, err := h.useCase.ListEvents(ctx, req.UserId, req.StartTime, req.EndTime, req.CalendarId, req.PageSize, req.PageToken)
+	events, nextPageToken, err := h.useCase.ListEvents(ctx, userID, req.StartTime, req.EndTime, req.CalendarId, req.PageSize, req.PageToken, usecase.EventSort{})
 	if err != nil {
 		return nil, err
 	}
@@ -96,3 +122,66 @@ func (h *CalendarHandler) ListEvents(ctx context.Context, req *pb.ListEventsRequ
 		NextPageToken: nextPageToken,
 	}, nil
 }
+
+// StreamEvents sends each event as soon as its page is fetched, so large date ranges
+// don't have to fit in a single response
+func (h *CalendarHandler) StreamEvents(req *pb.ListEventsRequest, stream pb.CalendarService_StreamEventsServer) error {
+	ctx := stream.Context()
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Every page must query the same range, so the default range is resolved once. A
+	// page token from the client still requires explicit bounds.
+	startTime, endTime := req.StartTime, req.EndTime
+	if req.PageToken == "" {
+		startTime, endTime = usecase.ListTimeRange(time.Now(), startTime, endTime)
+	}
+
+	pageToken := req.PageToken
+	for {
+		events, nextPageToken, err := h.useCase.ListEvents(ctx, userID, startTime, endTime, req.CalendarId, req.PageSize, pageToken, usecase.EventSort{})
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// BatchCreateEvents tạo nhiều event một lần. Event lỗi không làm hỏng cả batch mà
+// được báo trong kết quả của chính nó, theo thứ tự của request.
+func (h *CalendarHandler) BatchCreateEvents(ctx context.Context, req *pb.BatchCreateEventsRequest) (*pb.BatchCreateEventsResponse, error) {
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = service.WithEventSource(ctx, service.SourceAPI)
+	results, err := h.useCase.CreateEvents(ctx, req.Events, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.BatchCreateEventsResponse{
+		Results: make([]*pb.BatchCreateEventResult, len(results)),
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			resp.Results[i] = &pb.BatchCreateEventResult{ErrorMessage: status.Convert(result.Err).Message()}
+			continue
+		}
+		resp.Results[i] = &pb.BatchCreateEventResult{Event: result.Event}
+	}
+	return resp, nil
+}
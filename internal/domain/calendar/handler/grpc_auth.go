@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gmhafiz/scs/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"mail2calendar/internal/domain/calendar/service"
+	"mail2calendar/internal/middleware"
+)
+
+// authorizationMetadataKey chứa session token dạng "Bearer <token>"
+const authorizationMetadataKey = "authorization"
+
+// ErrInvalidSession được trả về khi token không ứng với session đã đăng nhập
+var ErrInvalidSession = errors.New("invalid or expired session")
+
+// UserResolver trả về user ID ứng với một session token
+type UserResolver interface {
+	ResolveUser(ctx context.Context, token string) (string, error)
+}
+
+// UserResolverFunc cho phép dùng một hàm làm UserResolver
+type UserResolverFunc func(ctx context.Context, token string) (string, error)
+
+func (f UserResolverFunc) ResolveUser(ctx context.Context, token string) (string, error) {
+	return f(ctx, token)
+}
+
+// NewSessionUserResolver xác thực token bằng chính session store của HTTP API
+func NewSessionUserResolver(session *scs.SessionManager) UserResolver {
+	return UserResolverFunc(func(ctx context.Context, token string) (string, error) {
+		ctx, err := session.Load(ctx, token)
+		if err != nil {
+			return "", err
+		}
+		userID, ok := session.Get(ctx, string(middleware.KeyID)).(uint64)
+		if !ok {
+			return "", ErrInvalidSession
+		}
+		return strconv.FormatUint(userID, 10), nil
+	})
+}
+
+// UnaryAuthInterceptor gắn user của session token trong metadata vào context.
+// Handler dùng user này thay cho UserId trong request, vốn do client tự khai.
+func UnaryAuthInterceptor(resolver UserResolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor giống UnaryAuthInterceptor cho các RPC dạng stream
+func StreamAuthInterceptor(resolver UserResolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), resolver)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream thay context của stream bằng context đã có user
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, resolver UserResolver) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var token string
+	if values := md.Get(authorizationMetadataKey); len(values) > 0 {
+		token = bearerToken(values[0])
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing session token")
+	}
+
+	userID, err := resolver.ResolveUser(ctx, token)
+	if err != nil || userID == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid session token")
+	}
+
+	return service.WithUserID(ctx, userID), nil
+}
+
+// authenticateRequest xác thực session token trong header Authorization của request HTTP
+// và trả về user của nó. Khi thiếu hoặc sai token, nó phản hồi 401 và trả về ok=false.
+func authenticateRequest(w http.ResponseWriter, r *http.Request, resolver UserResolver) (userID string, ok bool) {
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		http.Error(w, "missing session token", http.StatusUnauthorized)
+		return "", false
+	}
+
+	userID, err := resolver.ResolveUser(r.Context(), token)
+	if err != nil || userID == "" {
+		http.Error(w, "invalid session token", http.StatusUnauthorized)
+		return "", false
+	}
+	return userID, true
+}
+
+// bearerToken trả về token trong giá trị "Bearer <token>", hoặc chuỗi rỗng nếu sai dạng
+func bearerToken(authorization string) string {
+	scheme, value, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// userFromContext trả về user do interceptor gắn vào context
+func userFromContext(ctx context.Context) (string, error) {
+	userID, ok := service.UserIDFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return userID, nil
+}
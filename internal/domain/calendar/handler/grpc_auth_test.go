@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmhafiz/scs/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/middleware"
+)
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	session := scs.New()
+
+	// Tạo sẵn một session đã đăng nhập của user 7
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+	session.Put(ctx, string(middleware.KeyID), uint64(7))
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	// Session tồn tại nhưng chưa đăng nhập
+	ctx, err = session.Load(context.Background(), "")
+	require.NoError(t, err)
+	session.Put(ctx, "theme", "dark")
+	anonymous, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	uc := new(mockCalendarUseCase)
+	uc.On("GetEvent", mock.Anything, "evt-1", "7").Return(&pb.Event{Id: "evt-1"}, nil)
+
+	client := newCalendarClient(t, NewCalendarHandler(uc), NewSessionUserResolver(session))
+
+	t.Run("valid session uses the session user, not the request's", func(t *testing.T) {
+		resp, err := client.GetEvent(withToken(context.Background(), token), &pb.GetEventRequest{EventId: "evt-1", UserId: "someone-else"})
+		require.NoError(t, err)
+		assert.Equal(t, "evt-1", resp.Event.Id)
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "missing metadata", ctx: context.Background()},
+		{name: "unknown token", ctx: withToken(context.Background(), "not-a-session")},
+		{name: "session without user", ctx: withToken(context.Background(), anonymous)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetEvent(tt.ctx, &pb.GetEventRequest{EventId: "evt-1", UserId: "7"})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+
+	uc.AssertNumberOfCalls(t, "GetEvent", 1)
+}
+
+func TestCalendarHandler_RequiresContextUser(t *testing.T) {
+	h := NewCalendarHandler(new(mockCalendarUseCase))
+
+	_, err := h.DeleteEvent(context.Background(), &pb.DeleteEventRequest{EventId: "evt-1", UserId: "user-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = h.ListEvents(context.Background(), &pb.ListEventsRequest{UserId: "user-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+func TestCalendarHandler_CreateEvent_Source(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("CreateEvent", mock.MatchedBy(func(ctx context.Context) bool {
+		return service.EventSourceFromContext(ctx) == service.SourceAPI
+	}), mock.Anything, "user-1").Return(&pb.Event{Id: "evt-1"}, nil)
+
+	h := NewCalendarHandler(uc)
+	ctx := service.WithUserID(context.Background(), "user-1")
+	resp, err := h.CreateEvent(ctx, &pb.CreateEventRequest{Event: &pb.Event{Title: "Sync"}, UserId: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "evt-1", resp.Event.Id)
+	uc.AssertExpectations(t)
+}
+
+// staticUsers resolves the test tokens "token-<user>" to "<user>"
+var staticUsers = UserResolverFunc(func(_ context.Context, token string) (string, error) {
+	userID, ok := strings.CutPrefix(token, "token-")
+	if !ok {
+		return "", ErrInvalidSession
+	}
+	return userID, nil
+})
+
+// withToken attaches a session token to outgoing gRPC calls
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// newCalendarClient serves h behind the auth interceptors over an in-memory
+// connection and returns a client for it
+func newCalendarClient(t *testing.T, h pb.CalendarServiceServer, resolver UserResolver) pb.CalendarServiceClient {
+	return pb.NewCalendarServiceClient(newCalendarConn(t, h, resolver))
+}
+
+// newCalendarConn serves h behind the auth interceptors over an in-memory
+// connection and returns the connection to it
+func newCalendarConn(t *testing.T, h pb.CalendarServiceServer, resolver UserResolver) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(resolver)),
+		grpc.ChainStreamInterceptor(StreamAuthInterceptor(resolver)),
+	)
+	pb.RegisterCalendarServiceServer(srv, h)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestCalendarHandler_StreamEvents(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("ListEvents", mock.Anything, "user-1", int64(100), int64(200), "primary", int32(2), "", mock.Anything).
+		Return([]*pb.Event{{Id: "evt-1"}, {Id: "evt-2"}}, "2", nil).Once()
+	uc.On("ListEvents", mock.Anything, "user-1", int64(100), int64(200), "primary", int32(2), "2", mock.Anything).
+		Return([]*pb.Event{{Id: "evt-3"}}, "", nil).Once()
+
+	client := newCalendarClient(t, NewCalendarHandler(uc), staticUsers)
+	stream, err := client.StreamEvents(withToken(context.Background(), "token-user-1"), &pb.ListEventsRequest{
+		StartTime:  100,
+		EndTime:    200,
+		CalendarId: "primary",
+		PageSize:   2,
+	})
+	require.NoError(t, err)
+
+	var ids []string
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, event.Id)
+	}
+
+	assert.Equal(t, []string{"evt-1", "evt-2", "evt-3"}, ids)
+	uc.AssertExpectations(t)
+}
+
+func TestCalendarHandler_StreamEvents_Errors(t *testing.T) {
+	// Without a range the stream resolves the default one once and pages through it
+	var ranges [][2]int64
+	recordRange := func(args mock.Arguments) {
+		ranges = append(ranges, [2]int64{args.Get(2).(int64), args.Get(3).(int64)})
+	}
+	uc := new(mockCalendarUseCase)
+	uc.On("ListEvents", mock.Anything, "user-1", mock.Anything, mock.Anything, "", int32(0), "", mock.Anything).
+		Run(recordRange).Return([]*pb.Event{{Id: "evt-1"}}, "1", nil).Once()
+	uc.On("ListEvents", mock.Anything, "user-1", mock.Anything, mock.Anything, "", int32(0), "1", mock.Anything).
+		Run(recordRange).Return(nil, "", status.Error(codes.Internal, "calendar unavailable")).Once()
+
+	client := newCalendarClient(t, NewCalendarHandler(uc), staticUsers)
+
+	stream, err := client.StreamEvents(context.Background(), &pb.ListEventsRequest{UserId: "user-1"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Events sent before a failing page are still delivered
+	stream, err = client.StreamEvents(withToken(context.Background(), "token-user-1"), &pb.ListEventsRequest{})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", event.Id)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	require.Len(t, ranges, 2)
+	assert.NotZero(t, ranges[0][0])
+	assert.Greater(t, ranges[0][1], ranges[0][0])
+	assert.Equal(t, ranges[0], ranges[1])
+
+	uc.AssertExpectations(t)
+}
+
+func TestCalendarHandler_BatchCreateEvents(t *testing.T) {
+	calendar := &rangeCalendar{}
+	client := newCalendarClient(t, NewCalendarHandler(usecase.NewCalendarUseCase(nil, calendar)), staticUsers)
+	ctx := withToken(context.Background(), "token-user-1")
+
+	resp, err := client.BatchCreateEvents(ctx, &pb.BatchCreateEventsRequest{Events: []*pb.Event{
+		{Title: "Sync", StartTime: 1741183200, EndTime: 1741186800},
+		{StartTime: 1741183200, EndTime: 1741186800},
+		{Title: "Review", StartTime: 1741190400, EndTime: 1741194000},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 3)
+
+	assert.Equal(t, "Sync", resp.Results[0].Event.GetTitle())
+	assert.Equal(t, "evt-01", resp.Results[0].Event.GetId())
+	assert.Equal(t, string(service.SourceAPI), resp.Results[0].Event.GetMetadata()["source"])
+	assert.Empty(t, resp.Results[0].ErrorMessage)
+
+	assert.Nil(t, resp.Results[1].Event)
+	assert.Equal(t, "event title is required", resp.Results[1].ErrorMessage)
+
+	assert.Equal(t, "Review", resp.Results[2].Event.GetTitle())
+	assert.Equal(t, "evt-02", resp.Results[2].Event.GetId())
+	assert.Empty(t, resp.Results[2].ErrorMessage)
+
+	assert.Len(t, calendar.events, 2)
+}
+
+func TestCalendarHandler_BatchCreateEvents_Errors(t *testing.T) {
+	client := newCalendarClient(t, NewCalendarHandler(usecase.NewCalendarUseCase(nil, nil)), staticUsers)
+
+	_, err := client.BatchCreateEvents(context.Background(), &pb.BatchCreateEventsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.BatchCreateEvents(withToken(context.Background(), "token-user-1"), &pb.BatchCreateEventsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	tooMany := make([]*pb.Event, usecase.MaxBatchCreateEvents+1)
+	_, err = client.BatchCreateEvents(withToken(context.Background(), "token-user-1"), &pb.BatchCreateEventsRequest{Events: tooMany})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCalendarHandler_CreateEvent_Recurring(t *testing.T) {
+	client := newCalendarClient(t, NewCalendarHandler(usecase.NewCalendarUseCase(nil, nil)), staticUsers)
+	ctx := withToken(context.Background(), "token-user-1")
+
+	resp, err := client.CreateEvent(ctx, &pb.CreateEventRequest{Event: &pb.Event{
+		Title:          "Weekly sync",
+		StartTime:      1741010400,
+		EndTime:        1741014000,
+		RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+	}})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, resp.Event.GetId())
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO", resp.Event.GetRecurrenceRule())
+	assert.True(t, resp.Event.GetIsRecurring())
+	assert.False(t, resp.Event.GetIsAllDay())
+
+	tests := []struct {
+		name          string
+		event         *pb.Event
+		expectedError string
+	}{
+		{
+			name:          "invalid rule",
+			event:         &pb.Event{Title: "Weekly sync", StartTime: 1741010400, EndTime: 1741014000, RecurrenceRule: "RRULE:FREQ=FORTNIGHTLY"},
+			expectedError: `invalid recurrence rule: unknown FREQ "FORTNIGHTLY"`,
+		},
+		{
+			name:          "recurring without rule",
+			event:         &pb.Event{Title: "Weekly sync", StartTime: 1741010400, EndTime: 1741014000, IsRecurring: true},
+			expectedError: "recurrence rule is required for a recurring event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateEvent(ctx, &pb.CreateEventRequest{Event: tt.event})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Equal(t, tt.expectedError, status.Convert(err).Message())
+		})
+	}
+}
@@ -2,33 +2,82 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"mail2calendar/internal/domain/calendar/proto"
 	"mail2calendar/internal/domain/calendar/service"
+	"mail2calendar/internal/domain/calendar/usecase"
+	"mail2calendar/internal/utility/filter"
 )
 
 // HTTPCalendarHandler xử lý các yêu cầu HTTP cho calendar service
 type HTTPCalendarHandler struct {
-	svc service.CalendarService
+	svc      service.CalendarService
+	useCase  usecase.CalendarUseCase
+	resolver UserResolver
 }
 
-// NewHTTPCalendarHandler tạo một HTTPCalendarHandler mới
-func NewHTTPCalendarHandler(svc service.CalendarService) *HTTPCalendarHandler {
+// NewHTTPCalendarHandler tạo một HTTPCalendarHandler mới. resolver xác thực session token
+// trong header Authorization như InboundEmailHandler; các endpoint thao tác trên event của
+// user chỉ dùng user đã xác thực.
+func NewHTTPCalendarHandler(svc service.CalendarService, useCase usecase.CalendarUseCase, resolver UserResolver) *HTTPCalendarHandler {
 	return &HTTPCalendarHandler{
-		svc: svc,
+		svc:      svc,
+		useCase:  useCase,
+		resolver: resolver,
 	}
 }
 
-// CreateEvent xử lý yêu cầu tạo event mới
+// confirmationResponse là phản hồi khi event được lưu chờ xác nhận thay vì tạo ngay
+type confirmationResponse struct {
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// idempotencyKeyHeader chứa key để client gửi lại request tạo event mà không tạo trùng
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// CreateEvent xử lý yêu cầu tạo event mới. Với confirm=true, event được lưu chờ xác nhận
+// và phản hồi chứa token dùng cho POST /api/v1/calendar/confirm/{token}.
+// Request lặp lại với cùng header Idempotency-Key nhận về event đã tạo lần đầu.
 func (h *HTTPCalendarHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateRequest(w, r, h.resolver)
+	if !ok {
+		return
+	}
+
 	var req proto.NewCreateEventRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := h.svc.CreateEvent(r.Context(), &req)
+	ctx := service.WithUserID(r.Context(), userID)
+	ctx = service.WithEventSource(ctx, service.SourceAPI)
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+		ctx = service.WithIdempotencyKey(ctx, key)
+	}
+
+	if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm")); confirm {
+		h.requestConfirmation(w, r.WithContext(ctx), &req, userID)
+		return
+	}
+
+	resp, err := h.svc.CreateEvent(ctx, &req)
+	if errors.Is(err, usecase.ErrIdempotencyKeyInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -40,18 +89,300 @@ func (h *HTTPCalendarHandler) CreateEvent(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (h *HTTPCalendarHandler) requestConfirmation(w http.ResponseWriter, r *http.Request, req *proto.NewCreateEventRequest, userID string) {
+	if req.Event == nil {
+		http.Error(w, "event is required", http.StatusBadRequest)
+		return
+	}
+
+	event := &proto.Event{
+		Title:          req.Event.Title,
+		Description:    req.Event.Description,
+		Location:       req.Event.Location,
+		StartTime:      req.Event.StartTime.Unix(),
+		EndTime:        req.Event.EndTime.Unix(),
+		RecurrenceRule: req.Event.RecurrenceRule,
+		IsRecurring:    req.Event.IsRecurring,
+		IsAllDay:       req.Event.IsAllDay,
+	}
+
+	token, expiresAt, err := h.useCase.RequestEventConfirmation(r.Context(), event, userID)
+	if err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(&confirmationResponse{
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ConfirmEvent tạo event đang chờ xác nhận bằng token trong URL
+func (h *HTTPCalendarHandler) ConfirmEvent(w http.ResponseWriter, r *http.Request) {
+	format, err := timeFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.useCase.ConfirmEvent(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&getEventResponse{
+		Event: newEventResponse(event, format),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // GetEvent xử lý yêu cầu lấy thông tin event
 func (h *HTTPCalendarHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 	eventID := r.URL.Query().Get("event_id")
 
+	format, err := timeFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	resp, err := h.svc.GetEvent(r.Context(), &proto.GetEventRequestV2{EventID: eventID})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if err := json.NewEncoder(w).Encode(&getEventResponse{
+		Event: newEventResponse(resp.Event, format),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetEventByID trả về event theo ID trong URL, giống GetEvent của gRPC.
+// Trả về 404 khi không tìm thấy event.
+func (h *HTTPCalendarHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateRequest(w, r, h.resolver)
+	if !ok {
+		return
+	}
+
+	format, err := timeFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.useCase.GetEvent(r.Context(), chi.URLParam(r, "id"), userID)
+	if err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&getEventResponse{
+		Event: newEventResponse(event, format),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// respondToEventRequest là body của yêu cầu trả lời lời mời tham gia event
+type respondToEventRequest struct {
+	// Response là accepted, declined hoặc tentative
+	Response string `json:"response"`
+}
+
+// RespondToEvent trả lời lời mời tham gia event có ID trong URL thay cho user.
+// Trả về 204 khi thành công và 404 khi user không được mời vào event.
+func (h *HTTPCalendarHandler) RespondToEvent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateRequest(w, r, h.resolver)
+	if !ok {
+		return
+	}
+
+	var req respondToEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := usecase.ParseEventResponse(req.Response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.useCase.RespondToEvent(r.Context(), chi.URLParam(r, "id"), response, userID); err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListEvents xử lý yêu cầu liệt kê các event trong khoảng start-end (Unix giây), mặc định
+// 30 ngày kể từ hiện tại. Kết quả được phân trang bằng limit và page_token, trang tiếp theo
+// lấy bằng next_page_token của phản hồi với cùng start và end, bắt buộc khi có page_token.
+// Hỗ trợ một tham số sort (vd: sort=title,desc) và định dạng thời gian hiển thị
+// (time_layout, locale, tz).
+func (h *HTTPCalendarHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateRequest(w, r, h.resolver)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	startTime, err := int64Query(query, "start")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endTime, err := int64Query(query, "end")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The usecase clamps the limit, so an absent or zero value falls back to the default page size
+	limit, err := int64Query(query, "limit")
+	if err != nil || limit < 0 || limit > math.MaxInt32 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	format, err := timeFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Events are sorted by a single field, so several would leave the order undefined
+	sorts := filter.New(query).Sort
+	if len(sorts) > 1 {
+		http.Error(w, "only one sort field is supported", http.StatusBadRequest)
+		return
+	}
+	var sortBy usecase.EventSort
+	for field, order := range sorts {
+		parsed, err := usecase.ParseEventSort(field, order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sortBy = parsed
+	}
+
+	events, nextPageToken, err := h.useCase.ListEvents(r.Context(), userID, startTime, endTime, query.Get("calendar_id"), int32(limit), query.Get("page_token"), sortBy)
+	if err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+
+	resp := &listEventsResponse{
+		Events:        make([]*eventResponse, 0, len(events)),
+		NextPageToken: nextPageToken,
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, newEventResponse(event, format))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// SearchEvents tìm các event trong khoảng start-end (Unix giây), mặc định 30 ngày kể từ
+// hiện tại như ListEvents, có tiêu đề, mô tả hoặc địa điểm chứa mọi từ của tham số q,
+// không phân biệt hoa thường
+func (h *HTTPCalendarHandler) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateRequest(w, r, h.resolver)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	startTime, err := int64Query(query, "start")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endTime, err := int64Query(query, "end")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format, err := timeFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.useCase.SearchEvents(r.Context(), query.Get("q"), usecase.TimeRange{
+		StartTime: unixTime(startTime),
+		EndTime:   unixTime(endTime),
+	}, userID)
+	if err != nil {
+		http.Error(w, err.Error(), httpStatusFromError(err))
+		return
+	}
+
+	resp := &listEventsResponse{
+		Events: make([]*eventResponse, 0, len(events)),
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, newEventResponse(event, format))
+	}
+
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
+
+// unixTime chuyển Unix giây sang time.Time, 0 (không chỉ định) thành time.Time rỗng
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// int64Query trả về tham số số nguyên name của query, 0 nếu không có
+func int64Query(query url.Values, name string) (int64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return n, nil
+}
+
+// httpStatusFromError chuyển gRPC status code từ usecase sang HTTP status code
+func httpStatusFromError(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Aborted:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
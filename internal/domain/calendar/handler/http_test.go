@@ -0,0 +1,574 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+type mockCalendarUseCase struct {
+	mock.Mock
+}
+
+func (m *mockCalendarUseCase) CreateEvent(ctx context.Context, event *pb.Event, userID string) (*pb.Event, error) {
+	args := m.Called(ctx, event, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Event), args.Error(1)
+}
+
+func (m *mockCalendarUseCase) CreateEvents(ctx context.Context, events []*pb.Event, userID string) ([]usecase.EventCreateResult, error) {
+	args := m.Called(ctx, events, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]usecase.EventCreateResult), args.Error(1)
+}
+
+func (m *mockCalendarUseCase) UpdateEvent(ctx context.Context, event *pb.Event, userID string) (*pb.Event, error) {
+	args := m.Called(ctx, event, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Event), args.Error(1)
+}
+
+func (m *mockCalendarUseCase) DeleteEvent(ctx context.Context, eventID string, userID string) error {
+	args := m.Called(ctx, eventID, userID)
+	return args.Error(0)
+}
+
+func (m *mockCalendarUseCase) GetEvent(ctx context.Context, eventID string, userID string) (*pb.Event, error) {
+	args := m.Called(ctx, eventID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Event), args.Error(1)
+}
+
+func (m *mockCalendarUseCase) RespondToEvent(ctx context.Context, eventID string, response usecase.EventResponse, userID string) error {
+	args := m.Called(ctx, eventID, response, userID)
+	return args.Error(0)
+}
+
+func (m *mockCalendarUseCase) ListEvents(ctx context.Context, userID string, startTime int64, endTime int64, calendarID string, pageSize int32, pageToken string, sortBy usecase.EventSort) ([]*pb.Event, string, error) {
+	args := m.Called(ctx, userID, startTime, endTime, calendarID, pageSize, pageToken, sortBy)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*pb.Event), args.String(1), args.Error(2)
+}
+
+func (m *mockCalendarUseCase) SearchEvents(ctx context.Context, query string, timeRange usecase.TimeRange, userID string) ([]*pb.Event, error) {
+	args := m.Called(ctx, query, timeRange, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*pb.Event), args.Error(1)
+}
+
+func (m *mockCalendarUseCase) RequestEventConfirmation(ctx context.Context, event *pb.Event, userID string) (string, time.Time, error) {
+	args := m.Called(ctx, event, userID)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *mockCalendarUseCase) ConfirmEvent(ctx context.Context, token string) (*pb.Event, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Event), args.Error(1)
+}
+
+// newUserRequest creates a request carrying the session token of "user-1"
+func newUserRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer token-user-1")
+	return req
+}
+
+func TestHTTPCalendarHandler_RequiresSession(t *testing.T) {
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(new(mockCalendarService), new(mockCalendarUseCase), staticUsers))
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPost, path: "/api/v1/calendar/events"},
+		{method: http.MethodPost, path: "/api/v1/calendar/events?confirm=true"},
+		{method: http.MethodGet, path: "/api/v1/calendar/events"},
+		{method: http.MethodGet, path: "/api/v1/calendar/events/search?q=sync"},
+		{method: http.MethodGet, path: "/api/v1/calendar/events/evt-1"},
+		{method: http.MethodPost, path: "/api/v1/calendar/events/evt-1/respond"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			// A user_id query parameter does not stand in for a session
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(route.method, route.path+"?user_id=user-1", strings.NewReader(`{}`)))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+			req := httptest.NewRequest(route.method, route.path, strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer not-a-session")
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		})
+	}
+}
+
+func TestHTTPCalendarHandler_ListEvents_IgnoresUserIDQuery(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("ListEvents", mock.Anything, "user-1", int64(0), int64(0), "", int32(0), "", usecase.EventSort{}).
+		Return([]*pb.Event{}, "", nil)
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(nil, uc, staticUsers))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events?user_id=user-2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_ListEvents_Sort(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedSort   usecase.EventSort
+		expectedStatus int
+	}{
+		{
+			name:           "no sort param",
+			query:          "",
+			expectedSort:   usecase.EventSort{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "title descending",
+			query:          "sort=title,desc",
+			expectedSort:   usecase.EventSort{Field: usecase.SortByTitle, Desc: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "created without order",
+			query:          "sort=created",
+			expectedSort:   usecase.EventSort{Field: usecase.SortByCreated},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unsupported field",
+			query:          "sort=location",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "several fields",
+			query:          "sort=title&sort=created,desc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mockCalendarUseCase)
+			if tt.expectedStatus == http.StatusOK {
+				uc.On("ListEvents", mock.Anything, "user-1", int64(0), int64(0), "", int32(0), "", tt.expectedSort).
+					Return([]*pb.Event{{Id: "evt-1"}}, "", nil)
+			}
+
+			h := NewHTTPCalendarHandler(nil, uc, staticUsers)
+			req := newUserRequest(http.MethodGet, "/api/v1/calendar/events?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			h.ListEvents(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp pb.ListEventsResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Len(t, resp.Events, 1)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+type mockCalendarService struct {
+	mock.Mock
+}
+
+func (m *mockCalendarService) CreateEvent(ctx context.Context, req *pb.NewCreateEventRequest) (*pb.CreateEventResponseV2, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.CreateEventResponseV2), args.Error(1)
+}
+
+func (m *mockCalendarService) GetEvent(ctx context.Context, req *pb.GetEventRequestV2) (*pb.GetEventResponseV2, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetEventResponseV2), args.Error(1)
+}
+
+func (m *mockCalendarService) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*pb.CreateEventResponseV2, error) {
+	args := m.Called(ctx, emailContent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.CreateEventResponseV2), args.Error(1)
+}
+
+func TestHTTPCalendarHandler_CreateEvent_Source(t *testing.T) {
+	svc := new(mockCalendarService)
+	svc.On("CreateEvent", mock.MatchedBy(func(ctx context.Context) bool {
+		return service.EventSourceFromContext(ctx) == service.SourceAPI
+	}), mock.Anything).Return(&pb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	h := NewHTTPCalendarHandler(svc, nil, staticUsers)
+	req := newUserRequest(http.MethodPost, "/api/v1/calendar/events", strings.NewReader(`{"event":{"title":"Sync"}}`))
+	rec := httptest.NewRecorder()
+
+	h.CreateEvent(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_ConfirmationFlow(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	uc := new(mockCalendarUseCase)
+	uc.On("RequestEventConfirmation", mock.Anything, mock.MatchedBy(func(event *pb.Event) bool {
+		return event.Title == "Planning" && event.EndTime > event.StartTime
+	}), "user-1").Return("tok-123", expiresAt, nil)
+	uc.On("ConfirmEvent", mock.Anything, "tok-123").
+		Return(&pb.Event{Id: "evt-1", Title: "Planning", Status: "confirmed"}, nil)
+	uc.On("ConfirmEvent", mock.Anything, "expired").
+		Return(nil, status.Error(codes.NotFound, usecase.ErrPendingEventNotFound.Error()))
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(nil, uc, staticUsers))
+
+	body := `{"event":{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodPost, "/api/v1/calendar/events?confirm=true", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var pending confirmationResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&pending))
+	assert.Equal(t, "tok-123", pending.ConfirmationToken)
+	assert.True(t, expiresAt.Equal(pending.ExpiresAt))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodPost, "/api/v1/calendar/confirm/tok-123", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var confirmed struct {
+		Event pb.Event `json:"event"`
+	}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&confirmed))
+	assert.Equal(t, "evt-1", confirmed.Event.Id)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodPost, "/api/v1/calendar/confirm/expired", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	uc.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_GetEventByID(t *testing.T) {
+	svc := new(mockCalendarService)
+	svc.On("CreateEvent", mock.Anything, mock.Anything).Return(&pb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	uc := new(mockCalendarUseCase)
+	uc.On("GetEvent", mock.Anything, "evt-1", "user-1").
+		Return(&pb.Event{Id: "evt-1", Title: "Sync", StartTime: 1740819600, EndTime: 1740823200}, nil)
+	uc.On("GetEvent", mock.Anything, "missing", "user-1").
+		Return(nil, status.Error(codes.NotFound, "event not found"))
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(svc, uc, staticUsers))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodPost, "/api/v1/calendar/events", strings.NewReader(`{"event":{"title":"Sync"}}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var created pb.CreateEventResponseV2
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/"+created.EventID, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var fetched struct {
+		Event pb.Event `json:"event"`
+	}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&fetched))
+	assert.Equal(t, "evt-1", fetched.Event.Id)
+	assert.Equal(t, "Sync", fetched.Event.Title)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	svc.AssertExpectations(t)
+	uc.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_SearchEvents(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("SearchEvents", mock.Anything, "quarterly review", usecase.TimeRange{
+		StartTime: time.Unix(1740787200, 0),
+		EndTime:   time.Unix(1743465600, 0),
+	}, "user-1").Return([]*pb.Event{{Id: "evt-1", Title: "Quarterly review"}}, nil)
+	uc.On("SearchEvents", mock.Anything, "retrospective", usecase.TimeRange{}, "user-1").Return([]*pb.Event{}, nil)
+	uc.On("SearchEvents", mock.Anything, "", mock.Anything, "user-1").
+		Return(nil, status.Error(codes.InvalidArgument, "search query is required"))
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(nil, uc, staticUsers))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet,
+		"/api/v1/calendar/events/search?q=quarterly+review&start=1740787200&end=1743465600", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var found struct {
+		Events []pb.Event `json:"events"`
+	}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&found))
+	if assert.Len(t, found.Events, 1) {
+		assert.Equal(t, "evt-1", found.Events[0].Id)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/search?q=retrospective", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"events": []}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/search", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for _, query := range []string{"start=yesterday", "start=1740787200&end=soon"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events/search?q=sync&"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	uc.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_CreateEvent_IdempotencyKey(t *testing.T) {
+	inner := new(mockCalendarService)
+	inner.On("CreateEvent", mock.Anything, mock.Anything).Return(&pb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+
+	svc := usecase.NewIdempotentCalendarService(inner, usecase.NewMemoryIdempotencyStore(), time.Hour)
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(svc, nil, staticUsers))
+
+	for i := 0; i < 2; i++ {
+		req := newUserRequest(http.MethodPost, "/api/v1/calendar/events", strings.NewReader(`{"event":{"title":"Sync"}}`))
+		req.Header.Set("Idempotency-Key", "key-1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp pb.CreateEventResponseV2
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, "google-1", resp.EventID)
+	}
+
+	inner.AssertExpectations(t)
+}
+
+func TestHTTPCalendarHandler_RespondToEvent(t *testing.T) {
+	uc := new(mockCalendarUseCase)
+	uc.On("RespondToEvent", mock.Anything, "evt-1", usecase.EventDeclined, "user-1").Return(nil)
+	uc.On("RespondToEvent", mock.Anything, "evt-2", usecase.EventAccepted, "user-1").
+		Return(status.Error(codes.NotFound, "user is not invited to the event"))
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(new(mockCalendarService), uc, staticUsers))
+
+	tests := []struct {
+		name     string
+		eventID  string
+		body     string
+		expected int
+	}{
+		{name: "declined", eventID: "evt-1", body: `{"response":"declined"}`, expected: http.StatusNoContent},
+		{name: "not invited", eventID: "evt-2", body: `{"response":"accepted"}`, expected: http.StatusNotFound},
+		{name: "unknown response", eventID: "evt-1", body: `{"response":"maybe"}`, expected: http.StatusBadRequest},
+		{name: "invalid body", eventID: "evt-1", body: `{`, expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, newUserRequest(http.MethodPost,
+				"/api/v1/calendar/events/"+tt.eventID+"/respond", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+	uc.AssertExpectations(t)
+}
+
+// rangeCalendar returns the events starting within the requested range, as a calendar
+// provider would
+type rangeCalendar struct {
+	usecase.CalendarService
+	events []*usecase.CalendarEvent
+}
+
+func (c *rangeCalendar) GetEvents(_ context.Context, timeRange usecase.TimeRange, _ []string) ([]*usecase.CalendarEvent, error) {
+	var result []*usecase.CalendarEvent
+	for _, event := range c.events {
+		if !event.StartTime.Before(timeRange.StartTime) && event.StartTime.Before(timeRange.EndTime) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// CreateEvents adds the events to the calendar with the next free IDs
+func (c *rangeCalendar) CreateEvents(_ context.Context, events []*usecase.CalendarEvent) []error {
+	for _, event := range events {
+		event.ID = fmt.Sprintf("evt-%02d", len(c.events)+1)
+		c.events = append(c.events, event)
+	}
+	return make([]error, len(events))
+}
+
+// GetEventsPage pages the events in the range by offset, the way the provider hands
+// out its own opaque page tokens
+func (c *rangeCalendar) GetEventsPage(ctx context.Context, timeRange usecase.TimeRange, pageSize int, pageToken string) ([]*usecase.CalendarEvent, string, error) {
+	events, _ := c.GetEvents(ctx, timeRange, nil)
+
+	offset := 0
+	if pageToken != "" {
+		var err error
+		if offset, err = strconv.Atoi(pageToken); err != nil || offset < 0 || offset > len(events) {
+			return nil, "", usecase.ErrInvalidPageToken
+		}
+	}
+
+	end := offset + pageSize
+	if end >= len(events) {
+		return events[offset:], "", nil
+	}
+	return events[offset:end], strconv.Itoa(end), nil
+}
+
+// newRangeTestRouter serves the calendar endpoints over an hourly event on each of
+// the first ten days of March 2025
+func newRangeTestRouter() chi.Router {
+	calendar := &rangeCalendar{}
+	for day := 1; day <= 10; day++ {
+		start := time.Date(2025, 3, day, 9, 0, 0, 0, time.UTC)
+		calendar.events = append(calendar.events, &usecase.CalendarEvent{
+			ID:        fmt.Sprintf("evt-%02d", day),
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+		})
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, NewHTTPCalendarHandler(new(mockCalendarService), usecase.NewCalendarUseCase(nil, calendar), staticUsers))
+	return router
+}
+
+// listEventIDs calls the list endpoint with query and returns the event IDs and next
+// page token of the response
+func listEventIDs(t *testing.T, router chi.Router, query string) ([]string, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events?"+query, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Events []struct {
+			ID string `json:"id"`
+		} `json:"events"`
+		NextPageToken string `json:"next_page_token"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	ids := make([]string, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		ids = append(ids, event.ID)
+	}
+	return ids, resp.NextPageToken
+}
+
+func TestHTTPCalendarHandler_ListEvents_TimeRange(t *testing.T) {
+	router := newRangeTestRouter()
+
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	ids, next := listEventIDs(t, router, fmt.Sprintf("start=%d&end=%d", start.Unix(), start.AddDate(0, 0, 3).Unix()))
+	assert.Equal(t, []string{"evt-03", "evt-04", "evt-05"}, ids)
+	assert.Empty(t, next)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid start", query: "start=yesterday"},
+		{name: "invalid end", query: "start=1740787200&end=soon"},
+		{name: "end before start", query: "start=1740787200&end=1740700800"},
+		{name: "negative limit", query: "start=1740787200&limit=-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, newUserRequest(http.MethodGet, "/api/v1/calendar/events?"+tt.query, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestHTTPCalendarHandler_ListEvents_PageTraversal(t *testing.T) {
+	router := newRangeTestRouter()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	query := fmt.Sprintf("start=%d&end=%d&limit=4", start.Unix(), start.AddDate(0, 0, 31).Unix())
+
+	var pages [][]string
+	pageToken := ""
+	for {
+		ids, next := listEventIDs(t, router, query+"&page_token="+pageToken)
+		pages = append(pages, ids)
+		if next == "" {
+			break
+		}
+		require.Less(t, len(pages), 10, "pagination does not end")
+		pageToken = next
+	}
+
+	assert.Equal(t, [][]string{
+		{"evt-01", "evt-02", "evt-03", "evt-04"},
+		{"evt-05", "evt-06", "evt-07", "evt-08"},
+		{"evt-09", "evt-10"},
+	}, pages)
+}
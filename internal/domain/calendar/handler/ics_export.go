@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+// icsFilename là tên file gợi ý cho trình duyệt khi tải sự kiện về
+const icsFilename = "event.ics"
+
+// EmailEventParser trích xuất sự kiện từ email thô
+type EmailEventParser interface {
+	ProcessEmail(ctx context.Context, emailContent string) (*usecase.EmailEvent, error)
+}
+
+// EmailICSHandler trích xuất sự kiện từ email và trả về dưới dạng file .ics, cho người
+// dùng muốn tự thêm vào lịch thay vì đẩy lên Google Calendar
+type EmailICSHandler struct {
+	parser   EmailEventParser
+	resolver UserResolver
+	maxSize  int64
+}
+
+// NewEmailICSHandler tạo EmailICSHandler. resolver xác thực session token trong header
+// Authorization như InboundEmailHandler.
+func NewEmailICSHandler(parser EmailEventParser, resolver UserResolver) *EmailICSHandler {
+	return &EmailICSHandler{
+		parser:   parser,
+		resolver: resolver,
+		maxSize:  defaultMaxInboundEmailSize,
+	}
+}
+
+// ExportICS nhận email thô giống Inbound và trả về sự kiện trích xuất được dưới dạng
+// text/calendar. Email không chứa sự kiện hợp lệ trả về 422.
+func (h *EmailICSHandler) ExportICS(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authenticateRequest(w, r, h.resolver); !ok {
+		return
+	}
+
+	email, _, ok := readEmailRequest(w, r, h.maxSize)
+	if !ok {
+		return
+	}
+
+	event, err := h.parser.ProcessEmail(r.Context(), email)
+	if err != nil {
+		writeEmailProcessingError(w, err)
+		return
+	}
+
+	ics, err := event.ToICS()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+icsFilename+`"`)
+	_, _ = w.Write(ics)
+}
+
+// writeEmailProcessingError ghi lỗi trích xuất sự kiện từ email: email không hợp lệ
+// hoặc không chứa sự kiện trả về 422 kèm lý do, lỗi tạm thời trả về 503
+func writeEmailProcessingError(w http.ResponseWriter, err error) {
+	switch {
+	case calerrors.IsInvalidEmail(err), calerrors.IsParseError(err), calerrors.IsValidationError(err):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case calerrors.ShouldRetry(err):
+		http.Error(w, "failed to process email", http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "failed to process email", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+type mockEmailEventParser struct {
+	mock.Mock
+}
+
+func (m *mockEmailEventParser) ProcessEmail(ctx context.Context, emailContent string) (*usecase.EmailEvent, error) {
+	args := m.Called(ctx, emailContent)
+	event, _ := args.Get(0).(*usecase.EmailEvent)
+	return event, args.Error(1)
+}
+
+func newICSTestRouter(parser EmailEventParser) http.Handler {
+	resolver := UserResolverFunc(func(_ context.Context, token string) (string, error) {
+		if token == "session-7" {
+			return "7", nil
+		}
+		return "", ErrInvalidSession
+	})
+
+	router := chi.NewRouter()
+	RegisterEmailICSEndPoint(router, NewEmailICSHandler(parser, resolver))
+	return router
+}
+
+func TestEmailICSHandler_ExportICS(t *testing.T) {
+	t.Run("parsed event is downloaded as ics", func(t *testing.T) {
+		start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+		parser := new(mockEmailEventParser)
+		parser.On("ProcessEmail", mock.Anything, inboundTestEmail).Return(&usecase.EmailEvent{
+			Subject:   "Planning meeting",
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+			Attendees: []string{"calendar@mail2calendar.io"},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/ics", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newICSTestRouter(parser).ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="event.ics"`, rec.Header().Get("Content-Disposition"))
+
+		cal, err := ical.ParseCalendar(bytes.NewReader(rec.Body.Bytes()))
+		require.NoError(t, err)
+		require.Len(t, cal.Events(), 1)
+		assert.Equal(t, "Planning meeting", cal.Events()[0].GetProperty(ical.ComponentPropertySummary).Value)
+	})
+
+	t.Run("email without an event is rejected", func(t *testing.T) {
+		parser := new(mockEmailEventParser)
+		parser.On("ProcessEmail", mock.Anything, inboundTestEmail).
+			Return(nil, calerrors.NewParseError("failed to extract event info"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/ics", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newICSTestRouter(parser).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		parser := new(mockEmailEventParser)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/ics", strings.NewReader(inboundTestEmail))
+		rec := httptest.NewRecorder()
+		newICSTestRouter(parser).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		parser.AssertNotCalled(t, "ProcessEmail", mock.Anything, mock.Anything)
+	})
+}
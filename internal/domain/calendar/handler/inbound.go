@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"mail2calendar/internal/domain/calendar/service"
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+// defaultMaxInboundEmailSize giới hạn kích thước email thô nhận qua webhook, bằng giới
+// hạn của consumer hàng đợi
+const defaultMaxInboundEmailSize = usecase.DefaultMaxEmailSize
+
+// inboundFormOverhead là phần body được phép vượt giới hạn email với form multipart,
+// dành cho boundary và các field khác ngoài email
+const inboundFormOverhead = 64 << 10
+
+// inboundEmailField là field chứa email thô trong form của webhook inbound parse
+const inboundEmailField = "email"
+
+// inboundRecipientFields là các field chứa địa chỉ nhận (envelope) trong form của
+// webhook: "to" của SendGrid và "recipient" của Mailgun
+var inboundRecipientFields = []string{"to", "recipient"}
+
+// inboundRecipientHeaders là các header của email có thể chứa địa chỉ ingest, theo
+// thứ tự ưu tiên
+var inboundRecipientHeaders = []string{"Delivered-To", "X-Original-To", "To", "Cc"}
+
+// EmailPublisher đưa email thô vào hàng đợi xử lý
+type EmailPublisher interface {
+	PublishEmailEvent(ctx context.Context, emailContent string, userID string) error
+}
+
+// InboundEmailHandler nhận email gửi đến qua HTTP, ví dụ từ webhook inbound parse của
+// SendGrid hay Mailgun, và đưa vào hàng đợi
+type InboundEmailHandler struct {
+	publisher  EmailPublisher
+	resolver   UserResolver
+	recipients RecipientResolver
+	maxSize    int64
+}
+
+// InboundEmailOption cấu hình InboundEmailHandler
+type InboundEmailOption func(*InboundEmailHandler)
+
+// WithMaxInboundEmailSize đặt kích thước tối đa của email, tính bằng byte.
+// Giá trị không dương giữ mặc định 10 MiB.
+func WithMaxInboundEmailSize(size int64) InboundEmailOption {
+	return func(h *InboundEmailHandler) {
+		if size > 0 {
+			h.maxSize = size
+		}
+	}
+}
+
+// WithRecipientResolver cho phép nhận email không kèm session token: user được xác định
+// từ địa chỉ nhận, để một hộp thư ingest dùng chung phục vụ nhiều user. Email không có
+// địa chỉ nào thuộc về user bị từ chối với 403.
+func WithRecipientResolver(resolver RecipientResolver) InboundEmailOption {
+	return func(h *InboundEmailHandler) {
+		h.recipients = resolver
+	}
+}
+
+// NewInboundEmailHandler tạo InboundEmailHandler. resolver xác thực session token trong
+// header Authorization, giống auth interceptor của gRPC.
+func NewInboundEmailHandler(publisher EmailPublisher, resolver UserResolver, opts ...InboundEmailOption) *InboundEmailHandler {
+	h := &InboundEmailHandler{
+		publisher: publisher,
+		resolver:  resolver,
+		maxSize:   defaultMaxInboundEmailSize,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Inbound nhận email thô dạng message/rfc822 (hoặc text/plain), hoặc form multipart có
+// field email, rồi đưa vào hàng đợi cho user đã xác thực. Khi không có session token và
+// handler có RecipientResolver, user được xác định từ địa chỉ nhận của email.
+// Trả về 202 khi email được nhận.
+func (h *InboundEmailHandler) Inbound(w http.ResponseWriter, r *http.Request) {
+	var userID string
+	token := bearerToken(r.Header.Get("Authorization"))
+	switch {
+	case token != "":
+		resolved, err := h.resolver.ResolveUser(r.Context(), token)
+		if err != nil || resolved == "" {
+			http.Error(w, "invalid session token", http.StatusUnauthorized)
+			return
+		}
+		userID = resolved
+	case h.recipients == nil:
+		http.Error(w, "missing session token", http.StatusUnauthorized)
+		return
+	}
+
+	email, recipients, ok := readEmailRequest(w, r, h.maxSize)
+	if !ok {
+		return
+	}
+
+	if userID == "" {
+		var err error
+		userID, err = h.resolveRecipient(r.Context(), append(recipients, emailRecipients(email)...))
+		if errors.Is(err, ErrUnknownIngestAddress) {
+			http.Error(w, "unknown recipient", http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to resolve recipient", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	ctx := service.WithEventSource(r.Context(), service.SourceWebhook)
+	if err := h.publisher.PublishEmailEvent(ctx, email, userID); err != nil {
+		http.Error(w, "failed to enqueue email", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// resolveRecipient trả về user của địa chỉ đầu tiên trong recipients thuộc về một user
+func (h *InboundEmailHandler) resolveRecipient(ctx context.Context, recipients []string) (string, error) {
+	for _, address := range recipients {
+		userID, err := h.recipients.ResolveRecipient(ctx, address)
+		if errors.Is(err, ErrUnknownIngestAddress) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return userID, nil
+	}
+	return "", ErrUnknownIngestAddress
+}
+
+// readEmailRequest đọc email thô của request, tối đa maxSize byte. Body bị cắt ngay khi
+// vượt giới hạn, trước khi email được parse. Khi ok=false, lỗi đã được ghi vào w.
+func readEmailRequest(w http.ResponseWriter, r *http.Request, maxSize int64) (email string, recipients []string, ok bool) {
+	bodyLimit := maxSize
+	if isMultipartForm(r) {
+		bodyLimit += inboundFormOverhead
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, bodyLimit)
+
+	email, recipients, err := readEmail(r, maxSize)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "email too large", http.StatusRequestEntityTooLarge)
+			return "", nil, false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", nil, false
+	}
+	if err := usecase.CheckEmailSize(email, maxSize); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return "", nil, false
+	}
+	if strings.TrimSpace(email) == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return "", nil, false
+	}
+	return email, recipients, true
+}
+
+// readEmail đọc email thô từ body hoặc từ field email của form multipart, cùng các
+// địa chỉ nhận mà webhook gửi kèm trong form
+func readEmail(r *http.Request, maxSize int64) (string, []string, error) {
+	if !isMultipartForm(r) {
+		body, err := io.ReadAll(r.Body)
+		return string(body), nil, err
+	}
+
+	// Giới hạn tổng kích thước đã được MaxBytesReader đảm bảo
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		return "", nil, err
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var recipients []string
+	for _, field := range inboundRecipientFields {
+		for _, value := range r.MultipartForm.Value[field] {
+			recipients = append(recipients, addressList(value)...)
+		}
+	}
+
+	if values := r.MultipartForm.Value[inboundEmailField]; len(values) > 0 {
+		return values[0], recipients, nil
+	}
+	if files := r.MultipartForm.File[inboundEmailField]; len(files) > 0 {
+		file, err := files[0].Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer file.Close()
+		body, err := io.ReadAll(file)
+		return string(body), recipients, err
+	}
+	return "", recipients, nil
+}
+
+// isMultipartForm cho biết body của request là form multipart
+func isMultipartForm(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+// emailRecipients trả về các địa chỉ nhận trong header của email
+func emailRecipients(email string) []string {
+	msg, err := mail.ReadMessage(strings.NewReader(email))
+	if err != nil {
+		return nil
+	}
+
+	var recipients []string
+	for _, header := range inboundRecipientHeaders {
+		for _, value := range msg.Header[header] {
+			recipients = append(recipients, addressList(value)...)
+		}
+	}
+	return recipients
+}
+
+// addressList tách danh sách địa chỉ; giá trị không parse được được giữ nguyên
+func addressList(value string) []string {
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		return []string{strings.TrimSpace(value)}
+	}
+
+	addresses := make([]string, 0, len(list))
+	for _, addr := range list {
+		addresses = append(addresses, addr.Address)
+	}
+	return addresses
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+type mockEmailPublisher struct {
+	mock.Mock
+}
+
+func (m *mockEmailPublisher) PublishEmailEvent(ctx context.Context, emailContent string, userID string) error {
+	args := m.Called(ctx, emailContent, userID)
+	return args.Error(0)
+}
+
+const inboundTestEmail = "From: alice@example.com\r\n" +
+	"To: calendar@mail2calendar.io\r\n" +
+	"Subject: Planning meeting\r\n" +
+	"\r\n" +
+	"Planning meeting tomorrow at 2pm."
+
+func newInboundTestRouter(publisher EmailPublisher, opts ...InboundEmailOption) http.Handler {
+	resolver := UserResolverFunc(func(_ context.Context, token string) (string, error) {
+		if token == "session-7" {
+			return "7", nil
+		}
+		return "", ErrInvalidSession
+	})
+
+	router := chi.NewRouter()
+	RegisterInboundEmailEndPoint(router, NewInboundEmailHandler(publisher, resolver, opts...))
+	return router
+}
+
+func fromWebhook() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return service.EventSourceFromContext(ctx) == service.SourceWebhook
+	})
+}
+
+func TestInboundEmailHandler_Inbound(t *testing.T) {
+	t.Run("raw email is enqueued for the session user", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", fromWebhook(), inboundTestEmail, "7").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Content-Type", "message/rfc822")
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("multipart form email field is enqueued", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", fromWebhook(), inboundTestEmail, "7").Return(nil)
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		require.NoError(t, form.WriteField("to", "calendar@mail2calendar.io"))
+		require.NoError(t, form.WriteField("email", inboundTestEmail))
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("oversized email is rejected", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher, WithMaxInboundEmailSize(32)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		publisher.AssertNotCalled(t, "PublishEmailEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("email at the size limit is enqueued", func(t *testing.T) {
+		limit := int64(len(inboundTestEmail))
+
+		for _, multipartForm := range []bool{false, true} {
+			publisher := new(mockEmailPublisher)
+			publisher.On("PublishEmailEvent", fromWebhook(), inboundTestEmail, "7").Return(nil)
+
+			req := newInboundSizeTestRequest(t, inboundTestEmail, multipartForm)
+			rec := httptest.NewRecorder()
+			newInboundTestRouter(publisher, WithMaxInboundEmailSize(limit)).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			publisher.AssertExpectations(t)
+		}
+	})
+
+	t.Run("email one byte over the size limit is rejected", func(t *testing.T) {
+		limit := int64(len(inboundTestEmail)) - 1
+
+		for _, multipartForm := range []bool{false, true} {
+			publisher := new(mockEmailPublisher)
+
+			req := newInboundSizeTestRequest(t, inboundTestEmail, multipartForm)
+			rec := httptest.NewRecorder()
+			newInboundTestRouter(publisher, WithMaxInboundEmailSize(limit)).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			publisher.AssertNotCalled(t, "PublishEmailEvent", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+
+		for _, authorization := range []string{"", "Bearer expired"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(inboundTestEmail))
+			req.Header.Set("Authorization", authorization)
+			rec := httptest.NewRecorder()
+			newInboundTestRouter(publisher).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+		publisher.AssertNotCalled(t, "PublishEmailEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("queue failure is reported", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", mock.Anything, inboundTestEmail, "7").Return(errors.New("channel closed"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("ingest address resolves the owning user", func(t *testing.T) {
+		email := "From: alice@example.com\r\n" +
+			"To: bob@example.com, user+abc123@ingest.example.com\r\n" +
+			"Subject: Planning meeting\r\n" +
+			"\r\n" +
+			"Planning meeting tomorrow at 2pm."
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", fromWebhook(), email, "42").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(email))
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher, WithRecipientResolver(testIngestResolver())).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("webhook envelope recipient resolves the owning user", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", fromWebhook(), inboundTestEmail, "42").Return(nil)
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		require.NoError(t, form.WriteField("to", "user+abc123@ingest.example.com"))
+		require.NoError(t, form.WriteField("email", inboundTestEmail))
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher, WithRecipientResolver(testIngestResolver())).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("unknown ingest token is rejected", func(t *testing.T) {
+		email := "From: alice@example.com\r\n" +
+			"To: user+zzz999@ingest.example.com\r\n" +
+			"\r\n" +
+			"Planning meeting tomorrow at 2pm."
+		publisher := new(mockEmailPublisher)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(email))
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher, WithRecipientResolver(testIngestResolver())).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		publisher.AssertNotCalled(t, "PublishEmailEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("session token takes precedence over the ingest address", func(t *testing.T) {
+		publisher := new(mockEmailPublisher)
+		publisher.On("PublishEmailEvent", fromWebhook(), inboundTestEmail, "7").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(inboundTestEmail))
+		req.Header.Set("Authorization", "Bearer session-7")
+		rec := httptest.NewRecorder()
+		newInboundTestRouter(publisher, WithRecipientResolver(testIngestResolver())).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		publisher.AssertExpectations(t)
+	})
+}
+
+func testIngestResolver() RecipientResolver {
+	return NewPlusAddressResolver("ingest.example.com", ingestTokens(map[string]string{"abc123": "42"}))
+}
+
+// newInboundSizeTestRequest tạo request inbound chứa email, dạng thô hoặc form multipart
+func newInboundSizeTestRequest(t *testing.T, email string, multipartForm bool) *http.Request {
+	t.Helper()
+
+	if !multipartForm {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", strings.NewReader(email))
+		req.Header.Set("Content-Type", "message/rfc822")
+		req.Header.Set("Authorization", "Bearer session-7")
+		return req
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("email", email))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/email/inbound", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer session-7")
+	return req
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// ErrUnknownIngestAddress được trả về khi địa chỉ nhận không ứng với user nào
+var ErrUnknownIngestAddress = errors.New("unknown ingest address")
+
+// RecipientResolver trả về user sở hữu một địa chỉ nhận của hộp thư ingest dùng chung.
+// Địa chỉ không thuộc user nào trả về ErrUnknownIngestAddress.
+type RecipientResolver interface {
+	ResolveRecipient(ctx context.Context, address string) (string, error)
+}
+
+// RecipientResolverFunc cho phép dùng một hàm làm RecipientResolver, ví dụ để tra
+// bảng địa chỉ To -> user
+type RecipientResolverFunc func(ctx context.Context, address string) (string, error)
+
+func (f RecipientResolverFunc) ResolveRecipient(ctx context.Context, address string) (string, error) {
+	return f(ctx, address)
+}
+
+// IngestTokenLookup trả về user ứng với token trong địa chỉ ingest, hoặc
+// ErrUnknownIngestAddress nếu token không tồn tại
+type IngestTokenLookup func(ctx context.Context, token string) (string, error)
+
+// plusAddressResolver xác định user từ phần plus-addressing của địa chỉ nhận,
+// ví dụ user+abc123@ingest.example.com ứng với token abc123
+type plusAddressResolver struct {
+	domain string
+	lookup IngestTokenLookup
+}
+
+// NewPlusAddressResolver tạo RecipientResolver cho các địa chỉ dạng
+// <local>+<token>@domain. Địa chỉ thuộc domain khác hoặc không có token bị bỏ qua.
+func NewPlusAddressResolver(domain string, lookup IngestTokenLookup) RecipientResolver {
+	return &plusAddressResolver{
+		domain: strings.ToLower(strings.TrimSpace(domain)),
+		lookup: lookup,
+	}
+}
+
+func (r *plusAddressResolver) ResolveRecipient(ctx context.Context, address string) (string, error) {
+	token := ingestToken(address, r.domain)
+	if token == "" {
+		return "", ErrUnknownIngestAddress
+	}
+
+	userID, err := r.lookup(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return "", ErrUnknownIngestAddress
+	}
+	return userID, nil
+}
+
+// ingestToken trả về token plus-addressing của address nếu address thuộc domain
+func ingestToken(address, domain string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	at := strings.LastIndex(address, "@")
+	if at < 0 || !strings.EqualFold(address[at+1:], domain) {
+		return ""
+	}
+
+	_, token, found := strings.Cut(address[:at], "+")
+	if !found {
+		return ""
+	}
+	return token
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ingestTokens(tokens map[string]string) IngestTokenLookup {
+	return func(_ context.Context, token string) (string, error) {
+		if userID, ok := tokens[token]; ok {
+			return userID, nil
+		}
+		return "", ErrUnknownIngestAddress
+	}
+}
+
+func TestPlusAddressResolver_ResolveRecipient(t *testing.T) {
+	resolver := NewPlusAddressResolver("ingest.example.com", ingestTokens(map[string]string{"abc123": "42"}))
+
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+		err      error
+	}{
+		{name: "plus token", address: "user+abc123@ingest.example.com", expected: "42"},
+		{name: "display name and domain case", address: "Calendar <user+abc123@Ingest.Example.com>", expected: "42"},
+		{name: "unknown token", address: "user+zzz999@ingest.example.com", err: ErrUnknownIngestAddress},
+		{name: "no token", address: "user@ingest.example.com", err: ErrUnknownIngestAddress},
+		{name: "other domain", address: "user+abc123@example.com", err: ErrUnknownIngestAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, err := resolver.ResolveRecipient(context.Background(), tt.address)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, userID)
+		})
+	}
+}
+
+func TestPlusAddressResolver_LookupError(t *testing.T) {
+	lookupErr := errors.New("redis unavailable")
+	resolver := NewPlusAddressResolver("ingest.example.com", func(context.Context, string) (string, error) {
+		return "", lookupErr
+	})
+
+	_, err := resolver.ResolveRecipient(context.Background(), "user+abc123@ingest.example.com")
+	assert.ErrorIs(t, err, lookupErr)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterHTTPEndPoints đăng ký các endpoint HTTP của calendar
+func RegisterHTTPEndPoints(router chi.Router, h *HTTPCalendarHandler) {
+	router.Route("/api/v1/calendar", func(router chi.Router) {
+		router.Post("/events", h.CreateEvent)
+		router.Get("/events", h.ListEvents)
+		router.Get("/events/search", h.SearchEvents)
+		router.Get("/events/{id}", h.GetEventByID)
+		router.Post("/events/{id}/respond", h.RespondToEvent)
+		router.Get("/event", h.GetEvent)
+		router.Post("/confirm/{token}", h.ConfirmEvent)
+	})
+}
+
+// RegisterGatewayEndPoints đăng ký gateway tạo bởi NewGatewayHandler dưới /api/v1/gateway
+func RegisterGatewayEndPoints(router chi.Router, gateway http.Handler) {
+	router.Handle(gatewayPrefix+"/*", gateway)
+}
+
+// RegisterInboundEmailEndPoint đăng ký endpoint nhận email thô qua webhook
+func RegisterInboundEmailEndPoint(router chi.Router, h *InboundEmailHandler) {
+	router.Post("/api/v1/email/inbound", h.Inbound)
+}
+
+// RegisterEmailICSEndPoint đăng ký endpoint tải sự kiện trích xuất từ email dưới dạng .ics
+func RegisterEmailICSEndPoint(router chi.Router, h *EmailICSHandler) {
+	router.Post("/api/v1/email/ics", h.ExportICS)
+}
+
+// RegisterEmailPreviewEndPoint đăng ký endpoint xem trước sự kiện trích xuất từ email
+func RegisterEmailPreviewEndPoint(router chi.Router, h *EmailPreviewHandler) {
+	router.Post("/api/v1/email/preview", h.Preview)
+}
+
+// RegisterReprocessEndPoint đăng ký endpoint admin xử lý lại email thô đã lưu
+func RegisterReprocessEndPoint(router chi.Router, h *ReprocessHandler) {
+	router.Post("/api/v1/admin/reprocess/{messageID}", h.Reprocess)
+}
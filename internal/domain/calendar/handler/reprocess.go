@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+// EmailReprocessor xử lý lại các email thô đã lưu
+type EmailReprocessor interface {
+	Reprocess(ctx context.Context, messageID string) ([]usecase.ReprocessResult, error)
+}
+
+// reprocessResult là kết quả xử lý lại bản email của một user
+type reprocessResult struct {
+	UserID  string `json:"user_id"`
+	EventID string `json:"event_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type reprocessResponse struct {
+	Results []reprocessResult `json:"results"`
+}
+
+// ReprocessHandler cho admin xử lý lại email đã nhận, ví dụ sau khi cải thiện việc
+// trích xuất sự kiện
+type ReprocessHandler struct {
+	reprocessor EmailReprocessor
+	adminToken  string
+}
+
+// NewReprocessHandler tạo ReprocessHandler. Request phải gửi adminToken trong header
+// Authorization dạng Bearer; adminToken rỗng tắt endpoint.
+func NewReprocessHandler(reprocessor EmailReprocessor, adminToken string) *ReprocessHandler {
+	return &ReprocessHandler{
+		reprocessor: reprocessor,
+		adminToken:  adminToken,
+	}
+}
+
+// Reprocess xử lý lại email có Message-ID trong URL, có hoặc không có dấu <>, cho mọi
+// user đã nhận email. Trả về 401 khi thiếu admin token, 403 khi token sai, 404 khi email
+// chưa được lưu, và 200 kèm kết quả của từng user kể cả khi một số user thất bại.
+func (h *ReprocessHandler) Reprocess(w http.ResponseWriter, r *http.Request) {
+	if h.adminToken == "" {
+		http.Error(w, "admin endpoint disabled", http.StatusForbidden)
+		return
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "admin token required", http.StatusUnauthorized)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		http.Error(w, "invalid admin token", http.StatusForbidden)
+		return
+	}
+
+	messageID, err := url.PathUnescape(chi.URLParam(r, "messageID"))
+	if err != nil || strings.TrimSpace(messageID) == "" {
+		http.Error(w, "invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.reprocessor.Reprocess(r.Context(), messageID)
+	if errors.Is(err, usecase.ErrEmailNotArchived) {
+		http.Error(w, "email not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to load email", http.StatusInternalServerError)
+		return
+	}
+
+	resp := reprocessResponse{Results: make([]reprocessResult, 0, len(results))}
+	for _, result := range results {
+		item := reprocessResult{UserID: result.UserID, EventID: result.EventID}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		}
+		resp.Results = append(resp.Results, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
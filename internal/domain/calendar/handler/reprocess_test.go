@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mail2calendar/internal/domain/calendar/usecase"
+)
+
+type mockEmailReprocessor struct {
+	mock.Mock
+}
+
+func (m *mockEmailReprocessor) Reprocess(ctx context.Context, messageID string) ([]usecase.ReprocessResult, error) {
+	args := m.Called(ctx, messageID)
+	results, _ := args.Get(0).([]usecase.ReprocessResult)
+	return results, args.Error(1)
+}
+
+func TestReprocessHandler_Reprocess(t *testing.T) {
+	tests := []struct {
+		name           string
+		adminToken     string
+		authorization  string
+		path           string
+		setupMock      func(*mockEmailReprocessor)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "missing admin token",
+			adminToken:     "secret",
+			path:           "/api/v1/admin/reprocess/kickoff-1@example.com",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "empty admin token",
+			adminToken:     "secret",
+			authorization:  "Bearer ",
+			path:           "/api/v1/admin/reprocess/kickoff-1@example.com",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong admin token",
+			adminToken:     "secret",
+			authorization:  "Bearer guess",
+			path:           "/api/v1/admin/reprocess/kickoff-1@example.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "endpoint disabled without admin token",
+			authorization:  "Bearer ",
+			path:           "/api/v1/admin/reprocess/kickoff-1@example.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:          "email not archived",
+			adminToken:    "secret",
+			authorization: "Bearer secret",
+			path:          "/api/v1/admin/reprocess/missing@example.com",
+			setupMock: func(m *mockEmailReprocessor) {
+				m.On("Reprocess", mock.Anything, "missing@example.com").Return(nil, usecase.ErrEmailNotArchived)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:          "archive unavailable",
+			adminToken:    "secret",
+			authorization: "Bearer secret",
+			path:          "/api/v1/admin/reprocess/kickoff-1@example.com",
+			setupMock: func(m *mockEmailReprocessor) {
+				m.On("Reprocess", mock.Anything, "kickoff-1@example.com").Return(nil, errors.New("minio down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:          "escaped message ID is reprocessed for every user",
+			adminToken:    "secret",
+			authorization: "Bearer secret",
+			path:          "/api/v1/admin/reprocess/%3Ckickoff-1%40example.com%3E",
+			setupMock: func(m *mockEmailReprocessor) {
+				m.On("Reprocess", mock.Anything, "<kickoff-1@example.com>").Return([]usecase.ReprocessResult{
+					{UserID: "42", EventID: "evt-1"},
+					{UserID: "7", Err: errors.New("calendar unavailable")},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"results":[{"user_id":"42","event_id":"evt-1"},{"user_id":"7","error":"calendar unavailable"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reprocessor := new(mockEmailReprocessor)
+			if tt.setupMock != nil {
+				tt.setupMock(reprocessor)
+			}
+
+			router := chi.NewRouter()
+			RegisterReprocessEndPoint(router, NewReprocessHandler(reprocessor, tt.adminToken))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			reprocessor.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mail2calendar/internal/domain/calendar/proto"
+)
+
+const (
+	defaultLocale = "en"
+
+	queryParamTimeLayout = "time_layout"
+	queryParamLocale     = "locale"
+	queryParamTimezone   = "tz"
+
+	headerTimezone = "X-Timezone"
+)
+
+// localeNames chứa tên tháng và thứ theo từng ngôn ngữ, thứ tự giống time.Month và time.Weekday
+type localeNames struct {
+	months      [12]string
+	shortMonths [12]string
+	days        [7]string
+	shortDays   [7]string
+	layout      string
+}
+
+var locales = map[string]localeNames{
+	"en": {
+		months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		layout:      "Monday, January 2, 2006 3:04 PM MST",
+	},
+	"vi": {
+		months:      [12]string{"tháng 1", "tháng 2", "tháng 3", "tháng 4", "tháng 5", "tháng 6", "tháng 7", "tháng 8", "tháng 9", "tháng 10", "tháng 11", "tháng 12"},
+		shortMonths: [12]string{"Th1", "Th2", "Th3", "Th4", "Th5", "Th6", "Th7", "Th8", "Th9", "Th10", "Th11", "Th12"},
+		days:        [7]string{"Chủ Nhật", "Thứ Hai", "Thứ Ba", "Thứ Tư", "Thứ Năm", "Thứ Sáu", "Thứ Bảy"},
+		shortDays:   [7]string{"CN", "T2", "T3", "T4", "T5", "T6", "T7"},
+		layout:      "15:04 Monday, 02/01/2006 MST",
+	},
+}
+
+// TimeFormat mô tả cách hiển thị thời gian event cho client (layout + locale + timezone)
+type TimeFormat struct {
+	Layout   string
+	Locale   string
+	Location *time.Location
+}
+
+// timeFormatFromRequest đọc cấu hình hiển thị từ query (time_layout, locale, tz) hoặc header
+// (Accept-Language, X-Timezone). Trả về nil nếu client không yêu cầu định dạng nào.
+func timeFormatFromRequest(r *http.Request) (*TimeFormat, error) {
+	query := r.URL.Query()
+	layout := query.Get(queryParamTimeLayout)
+
+	locale := query.Get(queryParamLocale)
+	if locale == "" {
+		locale = r.Header.Get("Accept-Language")
+	}
+
+	tz := query.Get(queryParamTimezone)
+	if tz == "" {
+		tz = r.Header.Get(headerTimezone)
+	}
+
+	if layout == "" && locale == "" && tz == "" {
+		return nil, nil
+	}
+
+	location := time.UTC
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", tz)
+		}
+		location = loc
+	}
+
+	return &TimeFormat{
+		Layout:   layout,
+		Locale:   normalizeLocale(locale),
+		Location: location,
+	}, nil
+}
+
+// normalizeLocale lấy mã ngôn ngữ chính (vd: "vi-VN,vi;q=0.9" -> "vi"), mặc định là tiếng Anh
+func normalizeLocale(locale string) string {
+	tag, _, _ := strings.Cut(locale, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	tag, _, _ = strings.Cut(tag, "-")
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if _, ok := locales[tag]; !ok {
+		return defaultLocale
+	}
+	return tag
+}
+
+// Format hiển thị t theo layout và locale trong timezone đã chọn
+func (f *TimeFormat) Format(t time.Time) string {
+	names, ok := locales[f.Locale]
+	if !ok {
+		names = locales[defaultLocale]
+	}
+	layout := f.Layout
+	if layout == "" {
+		layout = names.layout
+	}
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+
+	// Tên tháng/thứ được chèn sau khi format, vì tên đã dịch có thể chứa chữ số
+	// mà time.Format sẽ hiểu nhầm là thành phần của layout
+	tokens := []struct {
+		layout string
+		value  string
+	}{
+		{"January", names.months[t.Month()-1]},
+		{"Monday", names.days[t.Weekday()]},
+		{"Jan", names.shortMonths[t.Month()-1]},
+		{"Mon", names.shortDays[t.Weekday()]},
+	}
+
+	var b strings.Builder
+	for layout != "" {
+		idx, token := -1, -1
+		for i, tok := range tokens {
+			if j := strings.Index(layout, tok.layout); j >= 0 && (idx < 0 || j < idx) {
+				idx, token = j, i
+			}
+		}
+		if idx < 0 {
+			b.WriteString(t.Format(layout))
+			break
+		}
+		b.WriteString(t.Format(layout[:idx]))
+		b.WriteString(tokens[token].value)
+		layout = layout[idx+len(tokens[token].layout):]
+	}
+
+	return b.String()
+}
+
+// eventResponse bổ sung thời gian dạng RFC3339 và chuỗi hiển thị đã định dạng cho event
+type eventResponse struct {
+	*proto.Event
+	StartTimeRFC3339 string `json:"start_time_rfc3339"`
+	EndTimeRFC3339   string `json:"end_time_rfc3339"`
+	StartTimeDisplay string `json:"start_time_display,omitempty"`
+	EndTimeDisplay   string `json:"end_time_display,omitempty"`
+}
+
+type listEventsResponse struct {
+	Events        []*eventResponse `json:"events"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+}
+
+type getEventResponse struct {
+	Event *eventResponse `json:"event"`
+}
+
+func newEventResponse(event *proto.Event, format *TimeFormat) *eventResponse {
+	if event == nil {
+		return nil
+	}
+
+	location := time.UTC
+	if format != nil && format.Location != nil {
+		location = format.Location
+	}
+	start := time.Unix(event.StartTime, 0).In(location)
+	end := time.Unix(event.EndTime, 0).In(location)
+
+	resp := &eventResponse{
+		Event:            event,
+		StartTimeRFC3339: start.Format(time.RFC3339),
+		EndTimeRFC3339:   end.Format(time.RFC3339),
+	}
+	if format != nil {
+		resp.StartTimeDisplay = format.Format(start)
+		resp.EndTimeDisplay = format.Format(end)
+	}
+	return resp
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	pb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestTimeFormat_Format(t *testing.T) {
+	hcm, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	assert.NoError(t, err)
+	// 2025-02-05 02:30 UTC = 09:30 thứ Tư giờ Việt Nam
+	moment := time.Date(2025, time.February, 5, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   TimeFormat
+		expected string
+	}{
+		{
+			name:     "english default layout",
+			format:   TimeFormat{Locale: "en", Location: hcm},
+			expected: "Wednesday, February 5, 2025 9:30 AM +07",
+		},
+		{
+			name:     "vietnamese default layout",
+			format:   TimeFormat{Locale: "vi", Location: hcm},
+			expected: "09:30 Thứ Tư, 05/02/2025 +07",
+		},
+		{
+			name:     "english custom layout",
+			format:   TimeFormat{Layout: "Mon Jan 2 15:04", Locale: "en", Location: time.UTC},
+			expected: "Wed Feb 5 02:30",
+		},
+		{
+			name:     "vietnamese custom layout",
+			format:   TimeFormat{Layout: "Monday, 2 January 2006", Locale: "vi", Location: hcm},
+			expected: "Thứ Tư, 5 tháng 2 2025",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.format.Format(moment))
+		})
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "vi", normalizeLocale("vi-VN,vi;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", normalizeLocale("EN-us"))
+	assert.Equal(t, "en", normalizeLocale("fr-FR"))
+	assert.Equal(t, "en", normalizeLocale(""))
+}
+
+func TestHTTPCalendarHandler_ListEvents_TimeFormat(t *testing.T) {
+	start := time.Date(2025, time.February, 5, 2, 30, 0, 0, time.UTC)
+	event := &pb.Event{Id: "evt-1", StartTime: start.Unix(), EndTime: start.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name            string
+		query           string
+		header          http.Header
+		expectedStatus  int
+		expectedRFC3339 string
+		expectedDisplay string
+	}{
+		{
+			name:            "no format keeps machine-readable time only",
+			query:           "",
+			expectedStatus:  http.StatusOK,
+			expectedRFC3339: "2025-02-05T02:30:00Z",
+		},
+		{
+			name:            "locale and timezone from query",
+			query:           "locale=vi&tz=Asia/Ho_Chi_Minh",
+			expectedStatus:  http.StatusOK,
+			expectedRFC3339: "2025-02-05T09:30:00+07:00",
+			expectedDisplay: "09:30 Thứ Tư, 05/02/2025 +07",
+		},
+		{
+			name:            "locale and timezone from headers",
+			query:           "time_layout=Jan 2, 15:04",
+			header:          http.Header{"Accept-Language": {"en-US"}, "X-Timezone": {"America/New_York"}},
+			expectedStatus:  http.StatusOK,
+			expectedRFC3339: "2025-02-04T21:30:00-05:00",
+			expectedDisplay: "Feb 4, 21:30",
+		},
+		{
+			name:           "invalid timezone",
+			query:          "tz=Mars/Olympus",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mockCalendarUseCase)
+			if tt.expectedStatus == http.StatusOK {
+				uc.On("ListEvents", mock.Anything, "user-1", int64(0), int64(0), "", int32(0), "", mock.Anything).
+					Return([]*pb.Event{event}, "", nil)
+			}
+
+			h := NewHTTPCalendarHandler(nil, uc, staticUsers)
+			req := newUserRequest(http.MethodGet, "/api/v1/calendar/events", nil)
+			req.URL.RawQuery = tt.query
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+
+			h.ListEvents(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp struct {
+					Events []struct {
+						StartTime        int64  `json:"start_time"`
+						StartTimeRFC3339 string `json:"start_time_rfc3339"`
+						StartTimeDisplay string `json:"start_time_display"`
+					} `json:"events"`
+				}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Len(t, resp.Events, 1)
+				assert.Equal(t, start.Unix(), resp.Events[0].StartTime)
+				assert.Equal(t, tt.expectedRFC3339, resp.Events[0].StartTimeRFC3339)
+				assert.Equal(t, tt.expectedDisplay, resp.Events[0].StartTimeDisplay)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// icsDurationPattern khớp giá trị DURATION theo RFC 5545, ví dụ P1D, PT1H30M, P2W, -PT15M
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ErrInvalidICSDuration được trả về khi giá trị DURATION không đúng định dạng
+var ErrInvalidICSDuration = errors.New("invalid ICS duration")
+
+// ParseICSDuration chuyển giá trị DURATION của ICS thành time.Duration
+func ParseICSDuration(value string) (time.Duration, error) {
+	matched := icsDurationPattern.FindStringSubmatch(value)
+	// "P", "PT" và "P1DT" khớp pattern nhưng không có thành phần nào sau ký tự cuối
+	if matched == nil || value[len(value)-1] == 'P' || value[len(value)-1] == 'T' {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidICSDuration, value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var d time.Duration
+	for i, unit := range units {
+		if matched[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(matched[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidICSDuration, value)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	if matched[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// EventEndAt trả về thời gian kết thúc của VEVENT: dùng DTEND nếu có,
+// nếu không thì tính start + DURATION. Thiếu cả hai thì sự kiện kết thúc tại start.
+func EventEndAt(event *ical.VEvent, start time.Time) (time.Time, error) {
+	if event.GetProperty(ical.ComponentPropertyDtEnd) != nil {
+		return event.GetEndAt()
+	}
+
+	prop := event.GetProperty(ical.ComponentPropertyDuration)
+	if prop == nil {
+		return start, nil
+	}
+
+	d, err := ParseICSDuration(prop.Value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d < 0 {
+		return time.Time{}, fmt.Errorf("%w: negative event duration %q", ErrInvalidICSDuration, prop.Value)
+	}
+
+	return start.Add(d), nil
+}
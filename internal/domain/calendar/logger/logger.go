@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,9 +15,23 @@ import (
 
 // Logger provides structured logging with tracing integration
 type Logger struct {
-	zap    *zap.Logger
-	tracer trace.Tracer
-	mu     sync.RWMutex
+	zap          *zap.Logger
+	tracer       trace.Tracer
+	redactedKeys map[string]struct{}
+	mu           sync.RWMutex
+}
+
+// RedactedValue replaces the value of redacted fields
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys are field keys whose values are never logged.
+// Attachment contents must not reach logs, whatever the caller passes.
+var DefaultRedactedKeys = []string{
+	"attachment",
+	"attachment_data",
+	"attachments",
+	"data",
+	"file_data",
 }
 
 // Fields represents logging fields
@@ -48,11 +63,61 @@ func New(tracer trace.Tracer) (*Logger, error) {
 	}
 
 	return &Logger{
-		zap:    zapLogger,
-		tracer: tracer,
+		zap:          zapLogger,
+		tracer:       tracer,
+		redactedKeys: keySet(DefaultRedactedKeys),
 	}, nil
 }
 
+// WithRedactedKeys returns a logger that also redacts the given field keys
+func (l *Logger) WithRedactedKeys(keys ...string) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	redacted := keySet(DefaultRedactedKeys)
+	for k := range l.redactedKeys {
+		redacted[k] = struct{}{}
+	}
+	for _, k := range keys {
+		redacted[strings.ToLower(k)] = struct{}{}
+	}
+
+	return &Logger{
+		zap:          l.zap,
+		tracer:       l.tracer,
+		redactedKeys: redacted,
+	}
+}
+
+// field builds a zap field, hiding the value of redacted keys
+func (l *Logger) field(key string, value interface{}) zap.Field {
+	redacted := l.redactedKeys
+	if redacted == nil {
+		redacted = keySet(DefaultRedactedKeys)
+	}
+	if _, ok := redacted[strings.ToLower(key)]; ok {
+		return zap.String(key, RedactedValue)
+	}
+	return zap.Any(key, value)
+}
+
+// fields converts logging fields to zap fields
+func (l *Logger) fields(fields Fields) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for k, v := range fields {
+		zapFields = append(zapFields, l.field(k, v))
+	}
+	return zapFields
+}
+
+func keySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+	return set
+}
+
 // WithContext adds trace context to log entries
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	l.mu.RLock()
@@ -69,8 +134,9 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	)
 
 	return &Logger{
-		zap:    logger,
-		tracer: l.tracer,
+		zap:          logger,
+		tracer:       l.tracer,
+		redactedKeys: l.redactedKeys,
 	}
 }
 
@@ -79,24 +145,10 @@ func (l *Logger) WithFields(fields Fields) *Logger {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	zapFields := make([]zap.Field, 0, len(fields))
-	var fieldsMu sync.Mutex
-
-	var wg sync.WaitGroup
-	for k, v := range fields {
-		wg.Add(1)
-		go func(key string, value interface{}) {
-			defer wg.Done()
-			fieldsMu.Lock()
-			zapFields = append(zapFields, zap.Any(key, value))
-			fieldsMu.Unlock()
-		}(k, v)
-	}
-	wg.Wait()
-
 	return &Logger{
-		zap:    l.zap.With(zapFields...),
-		tracer: l.tracer,
+		zap:          l.zap.With(l.fields(fields)...),
+		tracer:       l.tracer,
+		redactedKeys: l.redactedKeys,
 	}
 }
 
@@ -139,22 +191,7 @@ func (l *Logger) log(level LogLevel, msg string, fields ...Fields) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	mergedFields := mergeFields(fields...)
-
-	zapFields := make([]zap.Field, 0, len(mergedFields))
-	var fieldsMu sync.Mutex
-
-	var wg sync.WaitGroup
-	for k, v := range mergedFields {
-		wg.Add(1)
-		go func(key string, value interface{}) {
-			defer wg.Done()
-			fieldsMu.Lock()
-			zapFields = append(zapFields, zap.Any(key, value))
-			fieldsMu.Unlock()
-		}(k, v)
-	}
-	wg.Wait()
+	zapFields := l.fields(mergeFields(fields...))
 
 	switch level {
 	case DebugLevel:
@@ -168,12 +205,7 @@ func (l *Logger) log(level LogLevel, msg string, fields ...Fields) {
 	}
 }
 
-var mergeFieldsMu sync.Mutex
-
 func mergeFields(fields ...Fields) Fields {
-	mergeFieldsMu.Lock()
-	defer mergeFieldsMu.Unlock()
-
 	merged := Fields{}
 	for _, f := range fields {
 		for k, v := range f {
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newBenchmarkLogger writes JSON to io.Discard so only field handling and encoding are measured
+func newBenchmarkLogger() *Logger {
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(io.Discard),
+		zapcore.InfoLevel,
+	)
+	return &Logger{
+		zap:          zap.New(core),
+		redactedKeys: keySet(DefaultRedactedKeys),
+	}
+}
+
+var benchmarkFields = Fields{
+	"request_id":  "7f9c2a1e",
+	"user_id":     42,
+	"method":      "POST",
+	"path":        "/api/v1/calendar/events",
+	"duration_ms": int64(12),
+	"attachment":  []byte("secret"),
+	"status":      201,
+	"retry":       false,
+}
+
+func BenchmarkLogger_Info(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("event created", benchmarkFields)
+	}
+}
+
+func BenchmarkLogger_InfoParallel(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("event created", benchmarkFields)
+		}
+	})
+}
+
+func BenchmarkLogger_WithFields(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(benchmarkFields).Info("event created")
+	}
+}
@@ -193,6 +193,25 @@ func TestLogger_LogMetric(t *testing.T) {
 	mu.Unlock()
 }
 
+func TestLogger_RedactedKeys(t *testing.T) {
+	logger, recorded := createTestLogger()
+
+	logger.Info("default keys", Fields{"attachment_data": []byte("secret"), "filename": "a.pdf"})
+	entry := recorded.All()[0].ContextMap()
+	assert.Equal(t, RedactedValue, entry["attachment_data"])
+	assert.Equal(t, "a.pdf", entry["filename"])
+
+	custom := logger.WithRedactedKeys("Password")
+	custom.WithFields(Fields{"password": "hunter2"}).Warn("custom keys", Fields{"data": "secret"})
+	entry = recorded.All()[1].ContextMap()
+	assert.Equal(t, RedactedValue, entry["password"])
+	assert.Equal(t, RedactedValue, entry["data"])
+
+	// Redacted keys survive derived loggers
+	custom.WithContext(context.Background()).Info("derived", Fields{"password": "hunter2"})
+	assert.Equal(t, RedactedValue, recorded.All()[2].ContextMap()["password"])
+}
+
 func TestMergeFields(t *testing.T) {
 	fields1 := Fields{"key1": "value1", "key2": 2}
 	fields2 := Fields{"key2": "overwritten", "key3": true}
@@ -11,6 +11,10 @@ type CalendarEvent struct {
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
 	Location    string    `json:"location"`
+	// RecurrenceRule là RRULE dạng "RRULE:..." của event lặp lại
+	RecurrenceRule string `json:"recurrence_rule,omitempty"`
+	IsRecurring    bool   `json:"is_recurring,omitempty"`
+	IsAllDay       bool   `json:"is_all_day,omitempty"`
 }
 
 type NewCreateEventRequest struct {
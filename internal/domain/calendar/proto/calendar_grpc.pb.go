@@ -19,11 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CalendarService_CreateEvent_FullMethodName = "/calendar.CalendarService/CreateEvent"
-	CalendarService_UpdateEvent_FullMethodName = "/calendar.CalendarService/UpdateEvent"
-	CalendarService_DeleteEvent_FullMethodName = "/calendar.CalendarService/DeleteEvent"
-	CalendarService_GetEvent_FullMethodName    = "/calendar.CalendarService/GetEvent"
-	CalendarService_ListEvents_FullMethodName  = "/calendar.CalendarService/ListEvents"
+	CalendarService_CreateEvent_FullMethodName       = "/calendar.CalendarService/CreateEvent"
+	CalendarService_UpdateEvent_FullMethodName       = "/calendar.CalendarService/UpdateEvent"
+	CalendarService_DeleteEvent_FullMethodName       = "/calendar.CalendarService/DeleteEvent"
+	CalendarService_GetEvent_FullMethodName          = "/calendar.CalendarService/GetEvent"
+	CalendarService_ListEvents_FullMethodName        = "/calendar.CalendarService/ListEvents"
+	CalendarService_StreamEvents_FullMethodName      = "/calendar.CalendarService/StreamEvents"
+	CalendarService_BatchCreateEvents_FullMethodName = "/calendar.CalendarService/BatchCreateEvents"
 )
 
 // CalendarServiceClient is the client API for CalendarService service.
@@ -35,6 +37,11 @@ type CalendarServiceClient interface {
 	DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*DeleteEventResponse, error)
 	GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*GetEventResponse, error)
 	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	// StreamEvents sends the events of ListEvents one by one, fetching page after page
+	StreamEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// BatchCreateEvents creates several events at once. Events that fail don't fail
+	// the others; each has its own result.
+	BatchCreateEvents(ctx context.Context, in *BatchCreateEventsRequest, opts ...grpc.CallOption) (*BatchCreateEventsResponse, error)
 }
 
 type calendarServiceClient struct {
@@ -95,6 +102,35 @@ func (c *calendarServiceClient) ListEvents(ctx context.Context, in *ListEventsRe
 	return out, nil
 }
 
+func (c *calendarServiceClient) StreamEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CalendarService_ServiceDesc.Streams[0], CalendarService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalendarService_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *calendarServiceClient) BatchCreateEvents(ctx context.Context, in *BatchCreateEventsRequest, opts ...grpc.CallOption) (*BatchCreateEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateEventsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_BatchCreateEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CalendarServiceServer is the server API for CalendarService service.
 // All implementations must embed UnimplementedCalendarServiceServer
 // for forward compatibility.
@@ -104,6 +140,11 @@ type CalendarServiceServer interface {
 	DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error)
 	GetEvent(context.Context, *GetEventRequest) (*GetEventResponse, error)
 	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	// StreamEvents sends the events of ListEvents one by one, fetching page after page
+	StreamEvents(*ListEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// BatchCreateEvents creates several events at once. Events that fail don't fail
+	// the others; each has its own result.
+	BatchCreateEvents(context.Context, *BatchCreateEventsRequest) (*BatchCreateEventsResponse, error)
 	mustEmbedUnimplementedCalendarServiceServer()
 }
 
@@ -129,6 +170,12 @@ func (UnimplementedCalendarServiceServer) GetEvent(context.Context, *GetEventReq
 func (UnimplementedCalendarServiceServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
 }
+func (UnimplementedCalendarServiceServer) StreamEvents(*ListEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedCalendarServiceServer) BatchCreateEvents(context.Context, *BatchCreateEventsRequest) (*BatchCreateEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateEvents not implemented")
+}
 func (UnimplementedCalendarServiceServer) mustEmbedUnimplementedCalendarServiceServer() {}
 func (UnimplementedCalendarServiceServer) testEmbeddedByValue()                         {}
 
@@ -240,6 +287,35 @@ func _CalendarService_ListEvents_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CalendarServiceServer).StreamEvents(m, &grpc.GenericServerStream[ListEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalendarService_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+func _CalendarService_BatchCreateEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).BatchCreateEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_BatchCreateEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).BatchCreateEvents(ctx, req.(*BatchCreateEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CalendarService_ServiceDesc is the grpc.ServiceDesc for CalendarService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -267,7 +343,17 @@ var CalendarService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListEvents",
 			Handler:    _CalendarService_ListEvents_Handler,
 		},
+		{
+			MethodName: "BatchCreateEvents",
+			Handler:    _CalendarService_BatchCreateEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _CalendarService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/domain/calendar/proto/calendar.proto",
 }
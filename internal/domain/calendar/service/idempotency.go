@@ -0,0 +1,16 @@
+package service
+
+import "context"
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey gắn idempotency key của request vào context
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext trả về idempotency key đã gắn, hoặc chuỗi rỗng nếu chưa có
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
@@ -0,0 +1,36 @@
+package service
+
+import "context"
+
+// EventSource cho biết event được tạo từ kênh nhập liệu nào
+type EventSource string
+
+const (
+	SourceAPI       EventSource = "api"
+	SourceWebhook   EventSource = "webhook"
+	SourceQueue     EventSource = "queue"
+	SourceReprocess EventSource = "reprocess"
+)
+
+// Valid kiểm tra source có thuộc danh sách được hỗ trợ hay không
+func (s EventSource) Valid() bool {
+	switch s {
+	case SourceAPI, SourceWebhook, SourceQueue, SourceReprocess:
+		return true
+	default:
+		return false
+	}
+}
+
+type eventSourceKey struct{}
+
+// WithEventSource gắn kênh nhập liệu vào context tại điểm nhận dữ liệu
+func WithEventSource(ctx context.Context, source EventSource) context.Context {
+	return context.WithValue(ctx, eventSourceKey{}, source)
+}
+
+// EventSourceFromContext trả về kênh nhập liệu đã gắn, hoặc chuỗi rỗng nếu chưa có
+func EventSourceFromContext(ctx context.Context) EventSource {
+	source, _ := ctx.Value(eventSourceKey{}).(EventSource)
+	return source
+}
@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type timezoneKey struct{}
+
+// WithTimezone gắn múi giờ IANA ưa thích của user vào context, dùng khi đọc các thời
+// gian trong email không ghi múi giờ
+func WithTimezone(ctx context.Context, timezone string) context.Context {
+	return context.WithValue(ctx, timezoneKey{}, timezone)
+}
+
+// TimezoneFromContext trả về múi giờ của user, hoặc chuỗi rỗng nếu context không có
+func TimezoneFromContext(ctx context.Context) string {
+	timezone, _ := ctx.Value(timezoneKey{}).(string)
+	return timezone
+}
@@ -0,0 +1,16 @@
+package service
+
+import "context"
+
+type userIDKey struct{}
+
+// WithUserID gắn user đã xác thực vào context
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext trả về user đã xác thực, ok là false nếu context chưa có user
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(string)
+	return userID, ok && userID != ""
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"regexp"
+	"time"
+)
+
+// allDayPhrasePattern matches phrases that mark an event as lasting the whole day
+var allDayPhrasePattern = regexp.MustCompile(`(?i)\ball[- ]day\b|cả ngày|suốt ngày`)
+
+// isDateOnly reports whether all dates are bare days, as parsed from DATE entities
+// without a TIME entity
+func isDateOnly(dates []time.Time) bool {
+	if len(dates) == 0 {
+		return false
+	}
+	for _, d := range dates {
+		if d.Hour() != 0 || d.Minute() != 0 || d.Second() != 0 || d.Nanosecond() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// allDayRange widens start and end to whole days. The end is exclusive, so an event
+// on a single day ends at midnight the next day.
+func allDayRange(start, end time.Time) (time.Time, time.Time) {
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, 1)
+	if !endDay.After(startDay) {
+		endDay = startDay.AddDate(0, 0, 1)
+	}
+	return startDay, endDay
+}
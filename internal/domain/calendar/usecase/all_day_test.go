@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func allDayTestEmail(subject, body string) string {
+	return "From: organizer@example.com\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		body
+}
+
+func TestEmailProcessorImpl_ProcessEmail_AllDay(t *testing.T) {
+	march3 := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		subject       string
+		body          string
+		dates         []time.Time
+		expectAllDay  bool
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			name:          "date without time",
+			subject:       "Offsite",
+			body:          "Team offsite on March 3.",
+			dates:         []time.Time{march3},
+			expectAllDay:  true,
+			expectedStart: march3,
+			expectedEnd:   march3.AddDate(0, 0, 1),
+		},
+		{
+			name:          "date range without time includes the last day",
+			subject:       "Conference",
+			body:          "Conference from March 3 to March 5.",
+			dates:         []time.Time{march3.AddDate(0, 0, 2), march3},
+			expectAllDay:  true,
+			expectedStart: march3,
+			expectedEnd:   march3.AddDate(0, 0, 3),
+		},
+		{
+			name:          "explicit all day phrase",
+			subject:       "Offsite",
+			body:          "All-day offsite on March 3, doors open at 9am.",
+			dates:         []time.Time{march3.Add(9 * time.Hour)},
+			expectAllDay:  true,
+			expectedStart: march3,
+			expectedEnd:   march3.AddDate(0, 0, 1),
+		},
+		{
+			name:          "Vietnamese all day phrase",
+			subject:       "Đào tạo",
+			body:          "Đào tạo cả ngày 3/3 từ 8h.",
+			dates:         []time.Time{march3.Add(8 * time.Hour), march3.Add(17 * time.Hour)},
+			expectAllDay:  true,
+			expectedStart: march3,
+			expectedEnd:   march3.AddDate(0, 0, 1),
+		},
+		{
+			name:          "timed event",
+			subject:       "Standup",
+			body:          "Standup on March 3 at 9am.",
+			dates:         []time.Time{march3.Add(9 * time.Hour)},
+			expectedStart: march3.Add(9 * time.Hour),
+			expectedEnd:   march3.Add(10 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return(tt.dates, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+			event, err := processor.ProcessEmail(context.Background(), allDayTestEmail(tt.subject, tt.body))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectAllDay, event.IsAllDay)
+			assert.Equal(t, tt.expectedStart, event.StartTime)
+			assert.Equal(t, tt.expectedEnd, event.EndTime)
+		})
+	}
+}
+
+func TestGoogleCalendarService_convertToEventDateTime_AllDay(t *testing.T) {
+	g := &googleCalendarServiceImpl{}
+	start, end := allDayRange(
+		time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 3, 1, 0, 0, 0, time.UTC),
+	)
+
+	allDayStart := g.convertToEventDateTime(start, true, "")
+	allDayEnd := g.convertToEventDateTime(end, true, "")
+	assert.Equal(t, "2025-03-03", allDayStart.Date)
+	assert.Equal(t, "2025-03-04", allDayEnd.Date)
+	assert.Empty(t, allDayStart.DateTime)
+
+	timed := g.convertToEventDateTime(start, false, "")
+	assert.Equal(t, "2025-03-03T00:00:00Z", timed.DateTime)
+	assert.Empty(t, timed.Date)
+}
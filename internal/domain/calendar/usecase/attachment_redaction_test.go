@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"mail2calendar/internal/domain/calendar"
+)
+
+const secretAttachmentData = "TOP-SECRET-ATTACHMENT-CONTENT"
+
+// attachmentTypes lists every attachment type whose Data must stay out of logs.
+// Add new attachment types here so the guard below covers them.
+var attachmentTypes = []interface{}{
+	Attachment{Filename: "a.pdf", ContentType: "application/pdf", Data: []byte(secretAttachmentData)},
+	EmailAttachment{Filename: "a.pdf", ContentType: "application/pdf", Data: []byte(secretAttachmentData)},
+	calendar.EmailAttachment{Filename: "a.pdf", ContentType: "application/pdf", Data: []byte(secretAttachmentData)},
+}
+
+func TestAttachmentData_ExcludedFromStructuredOutput(t *testing.T) {
+	for _, a := range attachmentTypes {
+		typ := reflect.TypeOf(a)
+		t.Run(typ.String(), func(t *testing.T) {
+			field, ok := typ.FieldByName("Data")
+			require.True(t, ok)
+			assert.Equal(t, "-", field.Tag.Get("json"), "Data must be tagged json:\"-\"")
+
+			_, ok = a.(zapcore.ObjectMarshaler)
+			assert.True(t, ok, "attachment must implement zapcore.ObjectMarshaler")
+
+			raw, err := json.Marshal(a)
+			require.NoError(t, err)
+			assert.NotContains(t, string(raw), secretAttachmentData)
+
+			for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+				assert.NotContains(t, fmt.Sprintf(verb, a), secretAttachmentData, verb)
+			}
+
+			core, recorded := observer.New(zapcore.InfoLevel)
+			zap.New(core).Info("attachment", zap.Any("value", a))
+			for _, entry := range recorded.All() {
+				for k, v := range entry.ContextMap() {
+					assert.NotContains(t, fmt.Sprint(v), secretAttachmentData, k)
+				}
+			}
+		})
+	}
+}
+
+func TestAttachmentData_ExcludedFromEventLogs(t *testing.T) {
+	event := &EmailEvent{
+		Subject: "Meeting",
+		Attachments: []EmailAttachment{
+			{Filename: "agenda.pdf", ContentType: "application/pdf", Data: []byte(secretAttachmentData)},
+		},
+	}
+
+	core, recorded := observer.New(zapcore.InfoLevel)
+	zap.New(core).Error("failed to process email", zap.Any("event", event))
+	require.Equal(t, 1, recorded.Len())
+	for _, v := range recorded.All()[0].ContextMap() {
+		assert.NotContains(t, fmt.Sprint(v), secretAttachmentData)
+	}
+
+	raw, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(raw), secretAttachmentData))
+	assert.NotContains(t, fmt.Sprintf("%+v", event.Attachments), secretAttachmentData)
+}
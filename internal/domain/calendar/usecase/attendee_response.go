@@ -0,0 +1,38 @@
+package usecase
+
+import "strings"
+
+// ResponseStatus is an attendee's answer to an event invitation
+type ResponseStatus string
+
+const (
+	ResponseNeedsAction ResponseStatus = "needsAction"
+	ResponseAccepted    ResponseStatus = "accepted"
+	ResponseTentative   ResponseStatus = "tentative"
+	ResponseDeclined    ResponseStatus = "declined"
+)
+
+// Attendee is an invited address with its answer to the invitation
+type Attendee struct {
+	Email          string
+	ResponseStatus ResponseStatus
+}
+
+// declinedByAll reports whether every one of attendees is listed on event as having
+// declined it. Attendees missing from the event, such as its organizer, have not.
+func declinedByAll(event *CalendarEvent, attendees []string) bool {
+	if len(attendees) == 0 || len(event.AttendeeStatuses) == 0 {
+		return false
+	}
+
+	responses := make(map[string]ResponseStatus, len(event.AttendeeStatuses))
+	for _, attendee := range event.AttendeeStatuses {
+		responses[strings.ToLower(attendee.Email)] = attendee.ResponseStatus
+	}
+	for _, email := range attendees {
+		if responses[strings.ToLower(email)] != ResponseDeclined {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleCalendarService_ListEvents_ResponseStatus(t *testing.T) {
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[
+			{"id":"review","summary":"Review",
+			 "start":{"dateTime":"2025-03-12T14:00:00Z"},"end":{"dateTime":"2025-03-12T15:00:00Z"},
+			 "attendees":[
+				{"email":"alice@example.com","responseStatus":"declined"},
+				{"email":"bob@example.com","responseStatus":"tentative"},
+				{"email":"carol@example.com","responseStatus":"needsAction"}
+			 ]}
+		]}`))
+	})
+
+	from := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	events, err := NewCalendarService(svc).GetEvents(ctx, TimeRange{StartTime: from, EndTime: from.AddDate(0, 0, 1)}, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, events[0].Attendees)
+	assert.Equal(t, []Attendee{
+		{Email: "alice@example.com", ResponseStatus: ResponseDeclined},
+		{Email: "bob@example.com", ResponseStatus: ResponseTentative},
+		{Email: "carol@example.com", ResponseStatus: ResponseNeedsAction},
+	}, events[0].AttendeeStatuses)
+}
+
+func TestConflictChecker_GetBusyPeriods_Declined(t *testing.T) {
+	declined := &CalendarEvent{
+		ID:        "declined",
+		StartTime: parseTime("2025-03-12T09:00:00Z"),
+		EndTime:   parseTime("2025-03-12T10:00:00Z"),
+		Attendees: []string{"alice@example.com", "bob@example.com"},
+		AttendeeStatuses: []Attendee{
+			{Email: "Alice@Example.com", ResponseStatus: ResponseDeclined},
+			{Email: "bob@example.com", ResponseStatus: ResponseAccepted},
+		},
+	}
+	accepted := &CalendarEvent{
+		ID:        "accepted",
+		StartTime: parseTime("2025-03-12T14:00:00Z"),
+		EndTime:   parseTime("2025-03-12T15:00:00Z"),
+		Attendees: []string{"alice@example.com"},
+		AttendeeStatuses: []Attendee{
+			{Email: "alice@example.com", ResponseStatus: ResponseAccepted},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		attendees []string
+		expected  []TimeSlot
+	}{
+		{
+			name:      "event the attendee declined is not busy",
+			attendees: []string{"alice@example.com"},
+			expected:  []TimeSlot{{Start: accepted.StartTime, End: accepted.EndTime}},
+		},
+		{
+			name:      "event another attendee accepted stays busy",
+			attendees: []string{"alice@example.com", "bob@example.com"},
+			expected: []TimeSlot{
+				{Start: declined.StartTime, End: declined.EndTime},
+				{Start: accepted.StartTime, End: accepted.EndTime},
+			},
+		},
+		{
+			name: "without attendees every event is busy",
+			expected: []TimeSlot{
+				{Start: declined.StartTime, End: declined.EndTime},
+				{Start: accepted.StartTime, End: accepted.EndTime},
+			},
+		},
+		{
+			name:      "attendee not on the event is busy",
+			attendees: []string{"organizer@example.com"},
+			expected: []TimeSlot{
+				{Start: declined.StartTime, End: declined.EndTime},
+				{Start: accepted.StartTime, End: accepted.EndTime},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetEvents", mock.Anything, mock.Anything, tt.attendees).
+				Return([]*CalendarEvent{declined, accepted}, nil)
+
+			busy, err := NewConflictChecker(calendar).GetBusyPeriods(context.Background(), TimeRange{
+				StartTime: parseTime("2025-03-12T00:00:00Z"),
+				EndTime:   parseTime("2025-03-13T00:00:00Z"),
+			}, tt.attendees)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, busy)
+		})
+	}
+}
+
+func TestCalendarParser_ParseICSAttachment_ResponseStatus(t *testing.T) {
+	event, err := NewCalendarParser().ParseICSAttachment(icsWithEvent(
+		"DTEND:20250305T100000Z",
+		"ATTENDEE;PARTSTAT=DECLINED:mailto:alice@example.com",
+		"ATTENDEE;PARTSTAT=ACCEPTED:mailto:bob@example.com",
+		"ATTENDEE:mailto:carol@example.com",
+	))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Attendee{
+		{Email: "alice@example.com", ResponseStatus: ResponseDeclined},
+		{Email: "bob@example.com", ResponseStatus: ResponseAccepted},
+		{Email: "carol@example.com", ResponseStatus: ResponseNeedsAction},
+	}, event.AttendeeStatuses)
+}
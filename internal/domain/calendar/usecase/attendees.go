@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// defaultMaxAttendees caps how many attendees an extracted event can have
+const defaultMaxAttendees = 50
+
+// serviceLocalParts are mailbox names of automated senders that are never invited
+var serviceLocalParts = map[string]struct{}{
+	"noreply":          {},
+	"no-reply":         {},
+	"donotreply":       {},
+	"do-not-reply":     {},
+	"mailer-daemon":    {},
+	"postmaster":       {},
+	"bounce":           {},
+	"bounces":          {},
+	"notifications":    {},
+	"calendar-noreply": {},
+}
+
+// assembleAttendees merges attendee sources, in order, into one clean list. Addresses
+// are lowercased and deduplicated, the organizer and service addresses are dropped,
+// and the result is capped at maxAttendees entries.
+func (ep *emailProcessorImpl) assembleAttendees(organizer string, sources ...[]string) []string {
+	organizer = normalizeAddress(organizer)
+
+	seen := make(map[string]struct{})
+	result := make([]string, 0)
+	for _, source := range sources {
+		for _, addr := range source {
+			if len(result) >= ep.maxAttendees {
+				return result
+			}
+
+			addr = normalizeAddress(addr)
+			if addr == "" || addr == organizer || ep.isServiceAddress(addr) {
+				continue
+			}
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+
+			seen[addr] = struct{}{}
+			result = append(result, addr)
+		}
+	}
+
+	return result
+}
+
+// normalizeAddress returns the bare, lowercased address or "" if it is not one
+func normalizeAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return ""
+	}
+
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Address)
+}
+
+// isServiceAddress reports whether addr is an automated mailbox or one configured
+// with WithServiceAddresses
+func (ep *emailProcessorImpl) isServiceAddress(addr string) bool {
+	if _, ok := ep.serviceAddresses[addr]; ok {
+		return true
+	}
+
+	local, _, ok := strings.Cut(addr, "@")
+	if !ok {
+		return false
+	}
+	_, service := serviceLocalParts[local]
+	return service
+}
+
+// organizerAddress returns the organizer of the email: the From address, unless it is
+// an automated mailbox, in which case Sender or Reply-To name the person behind it.
+// It returns "" if the email has no usable address.
+func (ep *emailProcessorImpl) organizerAddress(header mail.Header) string {
+	from := firstAddress(header.Get("From"))
+	if from != "" && !ep.isServiceAddress(from) {
+		return from
+	}
+
+	for _, field := range []string{"Sender", "Reply-To"} {
+		if addr := firstAddress(header.Get(field)); addr != "" && !ep.isServiceAddress(addr) {
+			return addr
+		}
+	}
+	return from
+}
+
+// firstAddress returns the first address of a header address list, normalized, or ""
+func firstAddress(value string) string {
+	addresses, err := mail.ParseAddressList(value)
+	if err != nil || len(addresses) == 0 {
+		return ""
+	}
+	return strings.ToLower(addresses[0].Address)
+}
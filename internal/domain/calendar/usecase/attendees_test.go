@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailProcessorImpl_assembleAttendees(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []EmailProcessorOption
+		organizer string
+		sources   [][]string
+		expected  []string
+	}{
+		{
+			name:      "overlapping sources are merged in order",
+			organizer: "Boss <boss@example.com>",
+			sources: [][]string{
+				{"alice@example.com", "Bob@Example.com", "boss@example.com"},
+				{"bob@example.com", "ALICE@example.com", "carol@example.com", "Boss@Example.com"},
+			},
+			expected: []string{"alice@example.com", "bob@example.com", "carol@example.com"},
+		},
+		{
+			name: "service addresses are removed",
+			opts: []EmailProcessorOption{WithServiceAddresses("Calendar@Mail2Calendar.io")},
+			sources: [][]string{
+				{"no-reply@example.com", "alice@example.com", "calendar@mail2calendar.io"},
+				{"MAILER-DAEMON@example.com", "notifications@github.com", "bob@example.com"},
+			},
+			expected: []string{"alice@example.com", "bob@example.com"},
+		},
+		{
+			name: "invalid addresses are skipped",
+			sources: [][]string{
+				{"", "not an address", " alice@example.com "},
+			},
+			expected: []string{"alice@example.com"},
+		},
+		{
+			name: "capped after dedup",
+			opts: []EmailProcessorOption{WithMaxAttendees(2)},
+			sources: [][]string{
+				{"alice@example.com", "alice@example.com"},
+				{"bob@example.com", "carol@example.com"},
+			},
+			expected: []string{"alice@example.com", "bob@example.com"},
+		},
+		{
+			name:     "zero cap drops all attendees",
+			opts:     []EmailProcessorOption{WithMaxAttendees(0)},
+			sources:  [][]string{{"alice@example.com"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService), tt.opts...).(*emailProcessorImpl)
+			assert.Equal(t, tt.expected, processor.assembleAttendees(tt.organizer, tt.sources...))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_DedupedAttendees(t *testing.T) {
+	emailContent := "From: Organizer <organizer@example.com>\r\n" +
+		"To: Alice <Alice@example.com>, organizer@example.com\r\n" +
+		"Cc: bob@example.com, noreply@example.com\r\n" +
+		"Subject: Planning\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Planning at 2pm. Invite ALICE@example.com, carol@example.com and ORGANIZER@example.com."
+
+	ner := new(mockNERService)
+	start := time.Now().Add(24 * time.Hour)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start, start.Add(time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+	event, err := processor.ProcessEmail(context.Background(), emailContent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "organizer@example.com", event.Organizer)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, event.Attendees)
+	assert.NotContains(t, event.Attendees, event.Organizer)
+}
+
+func TestEmailProcessorImpl_organizerAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   mail.Header
+		expected string
+	}{
+		{
+			name:     "From address",
+			header:   mail.Header{"From": {"Alice <Alice@Example.com>"}, "Reply-To": {"bob@example.com"}},
+			expected: "alice@example.com",
+		},
+		{
+			name:     "Sender behind an automated From",
+			header:   mail.Header{"From": {"noreply@example.com"}, "Sender": {"carol@example.com"}},
+			expected: "carol@example.com",
+		},
+		{
+			name:     "first Reply-To behind an automated From",
+			header:   mail.Header{"From": {"notifications@example.com"}, "Reply-To": {"Bob <bob@example.com>, carol@example.com"}},
+			expected: "bob@example.com",
+		},
+		{
+			name:     "automated From without a better candidate",
+			header:   mail.Header{"From": {"no-reply@example.com"}},
+			expected: "no-reply@example.com",
+		},
+		{
+			name:     "no From",
+			header:   mail.Header{},
+			expected: "",
+		},
+	}
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService)).(*emailProcessorImpl)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, processor.organizerAddress(tt.header))
+		})
+	}
+}
+
+// recordingGoogleCalendar records the events passed to CreateEvent
+type recordingGoogleCalendar struct {
+	GoogleCalendarService
+	created []*GoogleCalendarEvent
+}
+
+func (r *recordingGoogleCalendar) CreateEvent(_ context.Context, event *GoogleCalendarEvent) error {
+	r.created = append(r.created, event)
+	return nil
+}
+
+func TestCalendarService_CreateEvent_Organizer(t *testing.T) {
+	google := &recordingGoogleCalendar{}
+	err := NewCalendarService(google).CreateEvent(context.Background(), &CalendarEvent{
+		Title:     "Planning",
+		Organizer: "organizer@example.com",
+		Attendees: []string{"alice@example.com"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, google.created, 1)
+	assert.Equal(t, "organizer@example.com", google.created[0].Organizer)
+	assert.Equal(t, []string{"alice@example.com"}, google.created[0].Attendees)
+}
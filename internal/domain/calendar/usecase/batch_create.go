@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+// MaxBatchCreateEvents is the largest number of events created in one batch
+const MaxBatchCreateEvents = 50
+
+// batchCreateConcurrency is how many events of a batch are created at the same time
+const batchCreateConcurrency = 4
+
+// EventCreateResult is the outcome of creating one event of a batch
+type EventCreateResult struct {
+	// Event is the created event, nil if Err is set
+	Event *calendarPb.Event
+	Err   error
+}
+
+// CreateEvents validates and deduplicates events as CreateEvent does, then inserts the
+// new ones on the calendar with CalendarService.CreateEvents. An event the duplicate
+// matcher would skip for an earlier event of the batch is created once and both get
+// the same result. Results are in the order of events; an event that fails doesn't
+// stop the others. Idempotency keys don't apply to batches.
+func (u *calendarUseCase) CreateEvents(ctx context.Context, events []*calendarPb.Event, userID string) ([]EventCreateResult, error) {
+	if len(events) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event is required")
+	}
+	if len(events) > MaxBatchCreateEvents {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d events can be created at once", MaxBatchCreateEvents)
+	}
+
+	// Duplicates are looked up for each event on its own, a few at a time
+	results := make([]EventCreateResult, len(events))
+	errs := createConcurrently(ctx, len(events), func(ctx context.Context, i int) error {
+		duplicate, err := u.prepareEvent(ctx, events[i])
+		results[i].Event = duplicate
+		return err
+	})
+
+	var pending []int
+	var toCreate []*CalendarEvent
+	// sameAs maps an event of the batch to the earlier one it duplicates
+	sameAs := make(map[int]int)
+	for i, err := range errs {
+		switch {
+		case err != nil:
+			results[i] = EventCreateResult{Err: err}
+		case results[i].Event == nil:
+			if j, ok := u.batchDuplicate(events[i], toCreate); ok {
+				sameAs[i] = pending[j]
+				continue
+			}
+			pending = append(pending, i)
+			toCreate = append(toCreate, fromProtoEvent(events[i]))
+		}
+	}
+	if len(toCreate) == 0 {
+		return results, nil
+	}
+
+	for j, err := range u.calendarService.CreateEvents(ctx, toCreate) {
+		i := pending[j]
+		if err != nil {
+			results[i].Err = status.Errorf(codes.Internal, "failed to create event: %v", err)
+			continue
+		}
+		results[i].Event = toProtoEvent(toCreate[j])
+	}
+	for i, j := range sameAs {
+		results[i] = results[j]
+	}
+	return results, nil
+}
+
+// batchDuplicate returns the index in toCreate of the event that event duplicates, as
+// decided by the duplicate matcher for existing events
+func (u *calendarUseCase) batchDuplicate(event *calendarPb.Event, toCreate []*CalendarEvent) (int, bool) {
+	if u.duplicates == nil {
+		return 0, false
+	}
+	for j, candidate := range toCreate {
+		if u.duplicates.MatchDuplicate(event, candidate) == DuplicateSkip {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// CreateEvents inserts events into Google Calendar a few at a time and returns the
+// error of each, nil for the ones created
+func (cs *calendarServiceImpl) CreateEvents(ctx context.Context, events []*CalendarEvent) []error {
+	return createConcurrently(ctx, len(events), func(ctx context.Context, i int) error {
+		return cs.CreateEvent(ctx, events[i])
+	})
+}
+
+// createConcurrently calls create for the indexes 0 to n-1, at most
+// batchCreateConcurrency at a time, and returns their errors by index. Indexes not
+// started before ctx is done fail with its error.
+func createConcurrently(ctx context.Context, n int, create func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, batchCreateConcurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = create(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestCalendarUseCase_CreateEvents(t *testing.T) {
+	events := []*calendarPb.Event{
+		{Title: "Sync", StartTime: 1741183200, EndTime: 1741186800},
+		{Title: "Broken", StartTime: 1741186800, EndTime: 1741183200},
+		{Title: "Review", StartTime: 1741190400, EndTime: 1741194000},
+		{Title: "Quota", StartTime: 1741190400, EndTime: 1741194000},
+		{Title: "Re: Standup", StartTime: 1741176000, EndTime: 1741177800},
+	}
+
+	// Standup is already on the calendar and Quota fails to be inserted
+	google := &failingGoogleCalendar{fail: "Quota", existing: []*GoogleCalendarEvent{{
+		ID:      "google-standup",
+		Summary: "Standup",
+		Start:   time.Unix(1741176000, 0),
+		End:     time.Unix(1741177800, 0),
+	}}}
+	results, err := NewCalendarUseCase(nil, NewCalendarService(google)).CreateEvents(context.Background(), events, "user-1")
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "Sync", results[0].Event.Title)
+	assert.Equal(t, "google-Sync", results[0].Event.Id)
+
+	assert.Nil(t, results[1].Event)
+	assert.Equal(t, codes.InvalidArgument, status.Code(results[1].Err))
+
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "google-Review", results[2].Event.Id)
+
+	assert.Nil(t, results[3].Event)
+	assert.Equal(t, codes.Internal, status.Code(results[3].Err))
+
+	require.NoError(t, results[4].Err)
+	assert.Equal(t, "google-standup", results[4].Event.Id)
+
+	// Only the new events reach the calendar
+	assert.ElementsMatch(t, []string{"Sync", "Review"}, google.created)
+}
+
+func TestCalendarUseCase_CreateEvents_DuplicatesInBatch(t *testing.T) {
+	// The same email sent twice in one batch, once as a reply
+	events := []*calendarPb.Event{
+		{Title: "Sync", StartTime: 1741183200, EndTime: 1741186800},
+		{Title: "Review", StartTime: 1741190400, EndTime: 1741194000},
+		{Title: "Re: Sync", StartTime: 1741183200, EndTime: 1741186800},
+	}
+
+	google := &failingGoogleCalendar{}
+	results, err := NewCalendarUseCase(nil, NewCalendarService(google)).CreateEvents(context.Background(), events, "user-1")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "google-Sync", results[0].Event.Id)
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "google-Sync", results[2].Event.Id)
+
+	assert.ElementsMatch(t, []string{"Sync", "Review"}, google.created)
+}
+
+func TestCalendarUseCase_CreateEvents_BatchSize(t *testing.T) {
+	uc := NewCalendarUseCase(nil, nil)
+
+	_, err := uc.CreateEvents(context.Background(), nil, "user-1")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = uc.CreateEvents(context.Background(), make([]*calendarPb.Event, MaxBatchCreateEvents+1), "user-1")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// failingGoogleCalendar holds the existing events and fails to create the events
+// titled fail. Created events get the ID google-<title>.
+type failingGoogleCalendar struct {
+	GoogleCalendarService
+	fail     string
+	existing []*GoogleCalendarEvent
+
+	mu      sync.Mutex
+	created []string
+}
+
+func (f *failingGoogleCalendar) ListEvents(_ context.Context, startTime, endTime time.Time, _ []string) ([]*GoogleCalendarEvent, error) {
+	var events []*GoogleCalendarEvent
+	for _, event := range f.existing {
+		if event.Start.Before(endTime) && startTime.Before(event.End) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (f *failingGoogleCalendar) CreateEvent(_ context.Context, event *GoogleCalendarEvent) error {
+	if event.Summary == f.fail {
+		return errors.New("quota exceeded")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, event.Summary)
+	event.ID = "google-" + event.Summary
+	return nil
+}
+
+func TestCalendarService_CreateEvents(t *testing.T) {
+	google := &failingGoogleCalendar{fail: "Broken"}
+	events := []*CalendarEvent{
+		{Title: "Sync"},
+		{Title: "Broken"},
+		{Title: "Review"},
+	}
+	errs := NewCalendarService(google).CreateEvents(context.Background(), events)
+
+	require.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	assert.EqualError(t, errs[1], "quota exceeded")
+	assert.NoError(t, errs[2])
+	assert.ElementsMatch(t, []string{"Sync", "Review"}, google.created)
+	assert.Equal(t, "google-Sync", events[0].ID)
+	assert.Empty(t, events[1].ID)
+}
+
+func TestCreateConcurrently_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	errs := createConcurrently(ctx, 3, func(context.Context, int) error {
+		called = true
+		return nil
+	})
+
+	assert.False(t, called)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
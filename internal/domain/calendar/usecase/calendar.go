@@ -3,36 +3,148 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
 	"mail2calendar/internal/domain/ner"
 	nerClient "mail2calendar/internal/grpc/client"
+	"mail2calendar/internal/utility/filter"
 )
 
+// eventSourceMetadataKey là khóa metadata lưu kênh nhập liệu của event
+const eventSourceMetadataKey = "source"
+
+// defaultListWindow là khoảng thời gian ListEvents trả về khi không chỉ định thời điểm kết thúc
+const defaultListWindow = 30 * 24 * time.Hour
+
+// ListTimeRange trả về khoảng thời gian ListEvents dùng cho start-end (Unix giây): start
+// mặc định là now, end mặc định là start cộng defaultListWindow
+func ListTimeRange(now time.Time, startTime, endTime int64) (int64, int64) {
+	if startTime == 0 {
+		startTime = now.Unix()
+	}
+	if endTime == 0 {
+		endTime = time.Unix(startTime, 0).Add(defaultListWindow).Unix()
+	}
+	return startTime, endTime
+}
+
 type calendarUseCase struct {
-	nerClient *nerClient.NERClient
+	nerClient       *nerClient.NERClient
+	calendarService CalendarService
+	pagination      filter.Pagination
+	pendingEvents   PendingEventStore
+	confirmationTTL time.Duration
+	idempotency     IdempotencyStore
+	idempotencyTTL  time.Duration
+	duplicates      DuplicateMatcher
+	now             func() time.Time
+}
+
+// CalendarUseCaseOption cấu hình thêm cho calendar usecase
+type CalendarUseCaseOption func(*calendarUseCase)
+
+// WithPagination đặt page size mặc định và tối đa cho ListEvents
+func WithPagination(pagination filter.Pagination) CalendarUseCaseOption {
+	return func(u *calendarUseCase) {
+		u.pagination = pagination
+	}
+}
+
+// WithEventConfirmation đặt nơi lưu event chờ xác nhận và thời gian token xác nhận còn hiệu lực
+func WithEventConfirmation(store PendingEventStore, ttl time.Duration) CalendarUseCaseOption {
+	return func(u *calendarUseCase) {
+		if store != nil {
+			u.pendingEvents = store
+		}
+		if ttl > 0 {
+			u.confirmationTTL = ttl
+		}
+	}
+}
+
+// WithIdempotency bật idempotency key cho CreateEvent: các request cùng key, gắn bằng
+// service.WithIdempotencyKey, chỉ tạo một event trong khoảng ttl
+func WithIdempotency(store IdempotencyStore, ttl time.Duration) CalendarUseCaseOption {
+	return func(u *calendarUseCase) {
+		u.idempotency = store
+		if ttl > 0 {
+			u.idempotencyTTL = ttl
+		}
+	}
 }
 
 // NewCalendarUseCase tạo một usecase mới cho calendar
-func NewCalendarUseCase(nerClient *nerClient.NERClient) CalendarUseCase {
-	return &calendarUseCase{
-		nerClient: nerClient,
+func NewCalendarUseCase(nerClient *nerClient.NERClient, calendarService CalendarService, opts ...CalendarUseCaseOption) CalendarUseCase {
+	u := &calendarUseCase{
+		nerClient:       nerClient,
+		calendarService: calendarService,
+		pagination:      filter.DefaultPagination(),
+		pendingEvents:   NewMemoryPendingEventStore(),
+		confirmationTTL: defaultConfirmationTTL,
+		idempotencyTTL:  defaultIdempotencyTTL,
+		duplicates:      TitleOverlapMatcher(),
+		now:             time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+func (u *calendarUseCase) CreateEvent(ctx context.Context, event *calendarPb.Event, userID string) (*calendarPb.Event, error) {
+	// Một request lặp lại nhận về event đã lưu của lần tạo đầu thay vì tạo event mới
+	created, _, err := idempotentCreate(ctx, u.idempotency, u.idempotencyTTL, userID, service.IdempotencyKeyFromContext(ctx), func(ctx context.Context) (*calendarPb.Event, error) {
+		return u.createEvent(ctx, event)
+	})
+	if errors.Is(err, ErrIdempotencyKeyInProgress) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if errors.Is(err, ErrIdempotencyKeyUnscoped) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (u *calendarUseCase) CreateEvent(ctx context.Context, event *calendarPb.Event, _ string) (*calendarPb.Event, error) {
+func (u *calendarUseCase) createEvent(ctx context.Context, event *calendarPb.Event) (*calendarPb.Event, error) {
+	duplicate, err := u.prepareEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate != nil {
+		return duplicate, nil
+	}
+
+	// Here you would typically save the event to a database
+	// For now, we'll just return the event with a generated ID
+	event.Id = generateEventID()
+	event.Status = "confirmed"
+
+	return event, nil
+}
+
+// prepareEvent validates event and fills it in before it is created. It returns the
+// existing event to hand back instead when event duplicates one.
+func (u *calendarUseCase) prepareEvent(ctx context.Context, event *calendarPb.Event) (*calendarPb.Event, error) {
 	if err := u.validateEvent(event); err != nil {
 		return nil, err
 	}
 
 	// Extract entities from event description if provided
 	if event.Description != "" {
-		entities, err := u.nerClient.ExtractEntities(ctx, event.Description)
+		entities, err := u.nerClient.ExtractEntities(ctx, event.Description, "")
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract entities: %v", err)
 		}
@@ -41,12 +153,16 @@ func (u *calendarUseCase) CreateEvent(ctx context.Context, event *calendarPb.Eve
 		u.updateEventWithEntities(event, entities.Entities)
 	}
 
-	// Here you would typically save the event to a database
-	// For now, we'll just return the event with a generated ID
-	event.Id = generateEventID()
-	event.Status = "confirmed"
+	// Tag the event with the ingestion channel it arrived through
+	if source := service.EventSourceFromContext(ctx); source != "" {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]string, 1)
+		}
+		event.Metadata[eventSourceMetadataKey] = string(source)
+	}
 
-	return event, nil
+	// Email trả lời cho cùng cuộc họp không được tạo thêm event thứ hai
+	return u.resolveDuplicate(ctx, event)
 }
 
 func (u *calendarUseCase) UpdateEvent(_ context.Context, event *calendarPb.Event, userID string) (*calendarPb.Event, error) {
@@ -72,31 +188,152 @@ func (u *calendarUseCase) DeleteEvent(_ context.Context, eventID string, userID
 	return nil
 }
 
-func (u *calendarUseCase) GetEvent(_ context.Context, eventID string, userID string) (*calendarPb.Event, error) {
+func (u *calendarUseCase) GetEvent(ctx context.Context, eventID string, userID string) (*calendarPb.Event, error) {
 	if eventID == "" {
 		return nil, status.Error(codes.InvalidArgument, "event ID is required")
 	}
 
-	// Here you would typically fetch the event from the database
-	// For now, we'll return a mock event
-	return &calendarPb.Event{
-		Id:          eventID,
-		Title:       "Mock Event",
-		Description: "This is a mock event",
-		StartTime:   time.Now().Unix(),
-		EndTime:     time.Now().Add(time.Hour).Unix(),
-		Status:      "confirmed",
-	}, nil
+	event, err := u.calendarService.GetEvent(ctx, eventID)
+	if errors.Is(err, ErrEventNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get event: %v", err)
+	}
+
+	return toProtoEvent(event), nil
 }
 
-func (u *calendarUseCase) ListEvents(_ context.Context, userID string, startTime int64, endTime int64, calendarID string, pageSize int32, pageToken string) ([]*calendarPb.Event, string, error) {
+func (u *calendarUseCase) ListEvents(ctx context.Context, userID string, startTime int64, endTime int64, calendarID string, pageSize int32, pageToken string, sortBy EventSort) ([]*calendarPb.Event, string, error) {
 	if userID == "" {
 		return nil, "", status.Error(codes.InvalidArgument, "user ID is required")
 	}
 
-	// Here you would typically fetch events from the database with pagination
-	// For now, we'll return an empty list
-	return []*calendarPb.Event{}, "", nil
+	// Page token chỉ hợp lệ với đúng khoảng thời gian của trang đầu, khoảng mặc định thì
+	// thay đổi theo thời điểm gọi
+	if pageToken != "" && (startTime == 0 || endTime == 0) {
+		return nil, "", status.Error(codes.InvalidArgument, "a page token requires explicit start and end times")
+	}
+
+	// Khoảng thời gian không chỉ định được giới hạn để kết quả không lấy toàn bộ lịch
+	startTime, endTime = ListTimeRange(u.now(), startTime, endTime)
+	if endTime < startTime {
+		return nil, "", status.Error(codes.InvalidArgument, "end time must not be before start time")
+	}
+
+	timeRange := TimeRange{
+		StartTime: time.Unix(startTime, 0),
+		EndTime:   time.Unix(endTime, 0),
+	}
+	limit := u.pagination.Clamp(int(pageSize))
+
+	// The provider pages events in start time order itself, so each page is a single
+	// request and the page token is the provider's own
+	if sortBy == (EventSort{}) || sortBy == (EventSort{Field: SortByStart}) {
+		events, nextPageToken, err := u.calendarService.GetEventsPage(ctx, timeRange, limit, pageToken)
+		if errors.Is(err, ErrInvalidPageToken) {
+			return nil, "", status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err != nil {
+			return nil, "", status.Errorf(codes.Internal, "failed to list events: %v", err)
+		}
+
+		result := make([]*calendarPb.Event, 0, len(events))
+		for _, event := range events {
+			result = append(result, toProtoEvent(event))
+		}
+		return result, nextPageToken, nil
+	}
+
+	events, err := u.calendarService.GetEvents(ctx, timeRange, nil)
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "failed to list events: %v", err)
+	}
+
+	// Other orders need the whole range, since the provider only orders by start time.
+	// Their page token is an offset into the sorted range.
+	if err := sortEvents(events, sortBy); err != nil {
+		return nil, "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	offset := 0
+	if pageToken != "" {
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, "", status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	if offset > len(events) {
+		offset = len(events)
+	}
+	end := offset + limit
+	if end > len(events) {
+		end = len(events)
+	}
+
+	result := make([]*calendarPb.Event, 0, end-offset)
+	for _, event := range events[offset:end] {
+		result = append(result, toProtoEvent(event))
+	}
+
+	nextPageToken := ""
+	if end < len(events) {
+		nextPageToken = strconv.Itoa(end)
+	}
+
+	return result, nextPageToken, nil
+}
+
+func (u *calendarUseCase) RequestEventConfirmation(ctx context.Context, event *calendarPb.Event, userID string) (string, time.Time, error) {
+	if err := u.validateEvent(event); err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := u.now()
+
+	// Event chưa được xác nhận sẽ hết hạn; dọn dẹp mỗi khi có yêu cầu mới
+	if _, err := u.pendingEvents.DeleteExpired(ctx, now); err != nil {
+		return "", time.Time{}, status.Errorf(codes.Internal, "failed to expire pending events: %v", err)
+	}
+
+	token, err := newConfirmationToken()
+	if err != nil {
+		return "", time.Time{}, status.Error(codes.Internal, err.Error())
+	}
+
+	expiresAt := now.Add(u.confirmationTTL)
+	if err := u.pendingEvents.Save(ctx, token, &PendingEvent{
+		Event:     event,
+		UserID:    userID,
+		Source:    service.EventSourceFromContext(ctx),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", time.Time{}, status.Errorf(codes.Internal, "failed to store pending event: %v", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+func (u *calendarUseCase) ConfirmEvent(ctx context.Context, token string) (*calendarPb.Event, error) {
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "confirmation token is required")
+	}
+
+	pending, err := u.pendingEvents.Take(ctx, token, u.now())
+	if err != nil {
+		if errors.Is(err, ErrPendingEventNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to load pending event: %v", err)
+	}
+
+	// Giữ kênh nhập liệu ban đầu thay vì kênh của request xác nhận
+	if pending.Source != "" {
+		ctx = service.WithEventSource(ctx, pending.Source)
+	}
+
+	return u.CreateEvent(ctx, pending.Event, pending.UserID)
 }
 
 func (u *calendarUseCase) validateEvent(event *calendarPb.Event) error {
@@ -120,6 +357,18 @@ func (u *calendarUseCase) validateEvent(event *calendarPb.Event) error {
 		return status.Error(codes.InvalidArgument, "end time must be after start time")
 	}
 
+	if event.RecurrenceRule == "" {
+		if event.IsRecurring {
+			return status.Error(codes.InvalidArgument, "recurrence rule is required for a recurring event")
+		}
+		return nil
+	}
+	if err := ValidateRecurrenceRule(event.RecurrenceRule); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Event có RRULE luôn là event lặp lại, kể cả khi client không đặt is_recurring
+	event.IsRecurring = true
+
 	return nil
 }
 
@@ -139,6 +388,55 @@ func (u *calendarUseCase) updateEventWithEntities(event *calendarPb.Event, entit
 	}
 }
 
+// fromProtoEvent converts an event of the API to the calendar model
+func fromProtoEvent(event *calendarPb.Event) *CalendarEvent {
+	return &CalendarEvent{
+		ID:             event.Id,
+		Title:          event.Title,
+		Description:    event.Description,
+		Location:       event.Location,
+		StartTime:      time.Unix(event.StartTime, 0),
+		EndTime:        time.Unix(event.EndTime, 0),
+		Attendees:      event.Attendees,
+		Organizer:      event.Organizer,
+		RecurrenceRule: event.RecurrenceRule,
+		IsRecurring:    event.IsRecurring,
+		IsAllDay:       event.IsAllDay,
+		Headers:        headersFromMetadata(event.Metadata),
+		Source:         service.EventSource(event.Metadata[eventSourceMetadataKey]),
+	}
+}
+
+func toProtoEvent(event *CalendarEvent) *calendarPb.Event {
+	return &calendarPb.Event{
+		Id:             event.ID,
+		Title:          event.Title,
+		Description:    event.Description,
+		Location:       event.Location,
+		StartTime:      event.StartTime.Unix(),
+		EndTime:        event.EndTime.Unix(),
+		Attendees:      event.Attendees,
+		Organizer:      event.Organizer,
+		Status:         "confirmed",
+		Metadata:       eventMetadata(event.Headers, event.Source),
+		RecurrenceRule: event.RecurrenceRule,
+		IsRecurring:    event.IsRecurring,
+		IsAllDay:       event.IsAllDay,
+	}
+}
+
+// eventMetadata gộp header email và kênh nhập liệu thành metadata lưu cùng event
+func eventMetadata(headers map[string]string, source service.EventSource) map[string]string {
+	metadata := headersToMetadata(headers)
+	if source != "" {
+		if metadata == nil {
+			metadata = make(map[string]string, 1)
+		}
+		metadata[eventSourceMetadataKey] = string(source)
+	}
+	return metadata
+}
+
 func generateEventID() string {
 	return fmt.Sprintf("evt_%d", time.Now().UnixNano())
 }
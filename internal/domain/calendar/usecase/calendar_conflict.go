@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"sort"
 	"time"
+
+	"mail2calendar/internal/domain/calendar/service"
 )
 
 // TimeSlot represents a time period
@@ -27,15 +30,38 @@ type ConflictResult struct {
 
 // CalendarEvent represents a calendar event
 type CalendarEvent struct {
-	ID             string
-	Title          string
-	StartTime      time.Time
-	EndTime        time.Time
-	Location       string
-	Attendees      []string
-	IsAllDay       bool
-	IsRecurring    bool
-	RecurrenceRule string
+	ID        string
+	Title     string
+	StartTime time.Time
+	EndTime   time.Time
+	Location  string
+	// Description is the body of the event
+	Description string
+	Organizer   string
+	Attendees   []string
+	// AttendeeStatuses holds the invitation response of each attendee of an event
+	// listed from a calendar. It is ignored when creating or updating events.
+	AttendeeStatuses []Attendee
+	IsAllDay         bool
+	IsRecurring      bool
+	RecurrenceRule   string
+	// SeriesID is the recurring series an expanded instance belongs to. Instances are
+	// not recurring themselves.
+	SeriesID string
+	// TimeZone is the IANA timezone of the event, "" for the calendar default
+	TimeZone string
+	Created  time.Time
+	// Headers holds the sanitized headers of the source email, if captured
+	Headers map[string]string
+	// Source is the ingestion channel the event was created from
+	Source service.EventSource
+	// UID and Sequence identify the ICS version the event was parsed from
+	UID      string
+	Sequence int
+	// ETag is the version of an event listed from a calendar. UpdateEvent sends it so
+	// that an event changed in the meantime fails with a conflict error instead of
+	// being overwritten.
+	ETag string
 }
 
 // Event represents a calendar event
@@ -67,16 +93,56 @@ type ConflictChecker interface {
 
 	// GetBusyPeriods returns busy periods for given attendees
 	GetBusyPeriods(ctx context.Context, timeRange TimeRange, attendees []string) ([]TimeSlot, error)
+
+	// SuggestMeetingTime returns the slots of duration within window where every
+	// attendee is free and working, earliest first
+	SuggestMeetingTime(ctx context.Context, duration time.Duration, attendees []string, window TimeRange) ([]TimeSlot, error)
 }
 
+// defaultMaxAlternatives is how many alternative slots a conflict suggests
+const defaultMaxAlternatives = 3
+
 type conflictCheckerImpl struct {
 	calendarService CalendarService
+	// maxAlternatives caps ConflictResult.Alternatives; 0 disables suggestions
+	maxAlternatives int
+	// allDayTransparent makes all-day events free/busy-transparent: they never
+	// conflict with other events and do not count as busy time.
+	allDayTransparent bool
+}
+
+// ConflictCheckerOption configures a ConflictChecker
+type ConflictCheckerOption func(*conflictCheckerImpl)
+
+// WithAllDayEventsTransparent controls whether all-day events are ignored for
+// conflict checks and busy periods. By default they block the whole day.
+func WithAllDayEventsTransparent(transparent bool) ConflictCheckerOption {
+	return func(cc *conflictCheckerImpl) {
+		cc.allDayTransparent = transparent
+	}
 }
 
-func NewConflictChecker(calendarService CalendarService) ConflictChecker {
-	return &conflictCheckerImpl{
+// WithMaxAlternatives sets how many alternative slots are suggested when an
+// event conflicts. 0 disables suggestions; negative values are ignored.
+func WithMaxAlternatives(n int) ConflictCheckerOption {
+	return func(cc *conflictCheckerImpl) {
+		if n >= 0 {
+			cc.maxAlternatives = n
+		}
+	}
+}
+
+func NewConflictChecker(calendarService CalendarService, opts ...ConflictCheckerOption) ConflictChecker {
+	cc := &conflictCheckerImpl{
 		calendarService: calendarService,
+		maxAlternatives: defaultMaxAlternatives,
+	}
+
+	for _, opt := range opts {
+		opt(cc)
 	}
+
+	return cc
 }
 
 func (cc *conflictCheckerImpl) CheckConflicts(ctx context.Context, event *CalendarEvent) (*ConflictResult, error) {
@@ -98,6 +164,10 @@ func (cc *conflictCheckerImpl) CheckConflicts(ctx context.Context, event *Calend
 			continue // Skip the same event
 		}
 
+		if cc.allDayTransparent && (event.IsAllDay || existing.IsAllDay) {
+			continue
+		}
+
 		// Check for recurring event conflicts
 		if event.RecurrenceRule != "" || existing.RecurrenceRule != "" {
 			if cc.checkRecurringConflict(event, existing) {
@@ -156,7 +226,7 @@ func (cc *conflictCheckerImpl) findAlternativeSlots(ctx context.Context, event *
 	alternatives := make([]TimeSlot, 0)
 
 	proposedStart := event.EndTime
-	for i := 0; i < 3; i++ { // Suggest up to 3 alternative slots
+	for i := 0; i < cc.maxAlternatives; i++ {
 		alternatives = append(alternatives, TimeSlot{
 			Start: proposedStart,
 			End:   proposedStart.Add(duration),
@@ -194,7 +264,15 @@ func (cc *conflictCheckerImpl) GetBusyPeriods(ctx context.Context, timeRange Tim
 
 	busyPeriods := make([]TimeSlot, 0, len(events))
 	for _, event := range events {
+		// An event declined by everyone asked about does not keep them busy
+		if declinedByAll(event, attendees) {
+			continue
+		}
+
 		if event.IsAllDay {
+			if cc.allDayTransparent {
+				continue
+			}
 			busyPeriods = append(busyPeriods, TimeSlot{
 				Start: time.Date(event.StartTime.Year(), event.StartTime.Month(), event.StartTime.Day(), 0, 0, 0, 0, event.StartTime.Location()),
 				End:   time.Date(event.EndTime.Year(), event.EndTime.Month(), event.EndTime.Day(), 23, 59, 59, 0, event.EndTime.Location()),
@@ -214,7 +292,33 @@ func (cc *conflictCheckerImpl) GetBusyPeriods(ctx context.Context, timeRange Tim
 		})
 	}
 
-	return busyPeriods, nil
+	return mergeBusyPeriods(busyPeriods), nil
+}
+
+// mergeBusyPeriods sorts periods by start and merges those that overlap or touch, so
+// that the result is the fewest periods covering the same time. periods may be in any
+// order and is sorted in place.
+func mergeBusyPeriods(periods []TimeSlot) []TimeSlot {
+	if len(periods) == 0 {
+		return periods
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+
+	merged := periods[:1]
+	for _, period := range periods[1:] {
+		last := &merged[len(merged)-1]
+		if period.Start.After(last.End) {
+			merged = append(merged, period)
+			continue
+		}
+		if period.End.After(last.End) {
+			last.End = period.End
+		}
+	}
+	return merged
 }
 
 func (cc *conflictCheckerImpl) expandRecurringEvent(event *CalendarEvent, timeRange TimeRange) []TimeSlot {
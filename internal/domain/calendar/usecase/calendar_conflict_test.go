@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock calendar service
@@ -18,11 +20,29 @@ func (m *mockCalendarService) GetEvents(ctx context.Context, timeRange TimeRange
 	return args.Get(0).([]*CalendarEvent), args.Error(1)
 }
 
+func (m *mockCalendarService) GetEvent(ctx context.Context, eventID string) (*CalendarEvent, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*CalendarEvent), args.Error(1)
+}
+
+func (m *mockCalendarService) GetEventsPage(ctx context.Context, timeRange TimeRange, pageSize int, pageToken string) ([]*CalendarEvent, string, error) {
+	args := m.Called(ctx, timeRange, pageSize, pageToken)
+	return args.Get(0).([]*CalendarEvent), args.String(1), args.Error(2)
+}
+
 func (m *mockCalendarService) CreateEvent(ctx context.Context, event *CalendarEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
 }
 
+func (m *mockCalendarService) CreateEvents(ctx context.Context, events []*CalendarEvent) []error {
+	args := m.Called(ctx, events)
+	return args.Get(0).([]error)
+}
+
 func (m *mockCalendarService) UpdateEvent(ctx context.Context, event *CalendarEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
@@ -33,8 +53,13 @@ func (m *mockCalendarService) DeleteEvent(ctx context.Context, eventID string) e
 	return args.Error(0)
 }
 
-func (m *mockCalendarService) GetWorkingHours(ctx context.Context, attendees []string) (map[string]*WorkingHours, error) {
-	args := m.Called(ctx, attendees)
+func (m *mockCalendarService) RespondToEvent(ctx context.Context, eventID string, response EventResponse) error {
+	args := m.Called(ctx, eventID, response)
+	return args.Error(0)
+}
+
+func (m *mockCalendarService) GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*WorkingHours, error) {
+	args := m.Called(ctx, timeRange, attendees)
 	return args.Get(0).(map[string]*WorkingHours), args.Error(1)
 }
 
@@ -108,6 +133,205 @@ func TestConflictChecker_CheckConflicts(t *testing.T) {
 	}
 }
 
+func TestConflictChecker_AllDayEvents(t *testing.T) {
+	allDay := &CalendarEvent{
+		ID:        "holiday",
+		StartTime: parseTime("2025-02-05T00:00:00Z"),
+		EndTime:   parseTime("2025-02-06T00:00:00Z"),
+		IsAllDay:  true,
+	}
+	meeting := &CalendarEvent{
+		ID:        "meeting",
+		StartTime: parseTime("2025-02-05T14:00:00Z"),
+		EndTime:   parseTime("2025-02-05T15:00:00Z"),
+	}
+
+	tests := []struct {
+		name           string
+		opts           []ConflictCheckerOption
+		expectConflict bool
+		expectBusy     int
+	}{
+		{
+			// The meeting is merged into the busy day
+			name:           "all-day events block by default",
+			expectConflict: true,
+			expectBusy:     1,
+		},
+		{
+			name:           "all-day events explicitly blocking",
+			opts:           []ConflictCheckerOption{WithAllDayEventsTransparent(false)},
+			expectConflict: true,
+			expectBusy:     1,
+		},
+		{
+			name:           "all-day events transparent",
+			opts:           []ConflictCheckerOption{WithAllDayEventsTransparent(true)},
+			expectConflict: false,
+			expectBusy:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockCalendarService)
+			mockService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+				Return([]*CalendarEvent{allDay, meeting}, nil)
+
+			checker := NewConflictChecker(mockService, tt.opts...)
+
+			result, err := checker.CheckConflicts(context.Background(), &CalendarEvent{
+				ID:        "new-event",
+				StartTime: parseTime("2025-02-05T09:00:00Z"),
+				EndTime:   parseTime("2025-02-05T10:00:00Z"),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.HasConflict != tt.expectConflict {
+				t.Errorf("expected conflict=%v, got=%v", tt.expectConflict, result.HasConflict)
+			}
+			if tt.expectConflict && result.ConflictingEvent.ID != allDay.ID {
+				t.Errorf("expected conflicting event ID=%s, got=%s", allDay.ID, result.ConflictingEvent.ID)
+			}
+
+			busy, err := checker.GetBusyPeriods(context.Background(), TimeRange{
+				StartTime: parseTime("2025-02-05T00:00:00Z"),
+				EndTime:   parseTime("2025-02-06T00:00:00Z"),
+			}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(busy) != tt.expectBusy {
+				t.Errorf("expected %d busy periods, got %d", tt.expectBusy, len(busy))
+			}
+		})
+	}
+}
+
+func TestConflictChecker_GetBusyPeriods_Merged(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		return parseTime("2025-03-12T" + hhmm + ":00Z")
+	}
+	events := []*CalendarEvent{
+		{ID: "afternoon", StartTime: at("14:00"), EndTime: at("15:00")},
+		{ID: "standup", StartTime: at("09:00"), EndTime: at("09:30")},
+		{ID: "review", StartTime: at("10:00"), EndTime: at("11:00")},
+		{ID: "overlaps-standup", StartTime: at("09:15"), EndTime: at("10:00")},
+		{ID: "inside-review", StartTime: at("10:15"), EndTime: at("10:45")},
+		{ID: "after-afternoon", StartTime: at("15:00"), EndTime: at("15:30")},
+		{ID: "lunch", StartTime: at("12:00"), EndTime: at("13:00")},
+	}
+
+	calendar := new(mockCalendarService)
+	calendar.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return(events, nil)
+
+	busy, err := NewConflictChecker(calendar).GetBusyPeriods(context.Background(), TimeRange{
+		StartTime: at("00:00"),
+		EndTime:   at("23:59"),
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []TimeSlot{
+		{Start: at("09:00"), End: at("11:00")},
+		{Start: at("12:00"), End: at("13:00")},
+		{Start: at("14:00"), End: at("15:30")},
+	}, busy)
+}
+
+func TestMergeBusyPeriods(t *testing.T) {
+	base := parseTime("2025-03-12T09:00:00Z")
+	slot := func(startMin, endMin int) TimeSlot {
+		return TimeSlot{
+			Start: base.Add(time.Duration(startMin) * time.Minute),
+			End:   base.Add(time.Duration(endMin) * time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		periods  []TimeSlot
+		expected []TimeSlot
+	}{
+		{name: "empty", periods: nil, expected: nil},
+		{name: "single", periods: []TimeSlot{slot(0, 30)}, expected: []TimeSlot{slot(0, 30)}},
+		{
+			name:     "disjoint periods are sorted",
+			periods:  []TimeSlot{slot(120, 150), slot(0, 30), slot(60, 90)},
+			expected: []TimeSlot{slot(0, 30), slot(60, 90), slot(120, 150)},
+		},
+		{
+			name:     "interleaved overlapping periods",
+			periods:  []TimeSlot{slot(50, 80), slot(0, 30), slot(70, 100), slot(20, 40)},
+			expected: []TimeSlot{slot(0, 40), slot(50, 100)},
+		},
+		{
+			name:     "adjacent periods are merged",
+			periods:  []TimeSlot{slot(30, 60), slot(0, 30)},
+			expected: []TimeSlot{slot(0, 60)},
+		},
+		{
+			name:     "contained period does not shorten the merge",
+			periods:  []TimeSlot{slot(10, 20), slot(0, 60), slot(30, 40)},
+			expected: []TimeSlot{slot(0, 60)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mergeBusyPeriods(tt.periods))
+		})
+	}
+}
+
+func TestConflictChecker_MaxAlternatives(t *testing.T) {
+	existing := []*CalendarEvent{
+		{
+			ID:        "existing-event",
+			StartTime: parseTime("2025-02-05T09:30:00Z"),
+			EndTime:   parseTime("2025-02-05T10:30:00Z"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ConflictCheckerOption
+		expected int
+	}{
+		{name: "default", expected: defaultMaxAlternatives},
+		{name: "custom", opts: []ConflictCheckerOption{WithMaxAlternatives(5)}, expected: 5},
+		{name: "disabled", opts: []ConflictCheckerOption{WithMaxAlternatives(0)}, expected: 0},
+		{name: "negative ignored", opts: []ConflictCheckerOption{WithMaxAlternatives(-1)}, expected: defaultMaxAlternatives},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockCalendarService)
+			mockService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+				Return(existing, nil)
+
+			checker := NewConflictChecker(mockService, tt.opts...)
+			result, err := checker.CheckConflicts(context.Background(), &CalendarEvent{
+				ID:        "new-event",
+				StartTime: parseTime("2025-02-05T09:00:00Z"),
+				EndTime:   parseTime("2025-02-05T10:00:00Z"),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !result.HasConflict {
+				t.Fatal("expected conflict")
+			}
+			if len(result.Alternatives) != tt.expected {
+				t.Errorf("expected %d alternatives, got %d", tt.expected, len(result.Alternatives))
+			}
+			if result.Alternatives == nil {
+				t.Error("expected non-nil alternatives")
+			}
+		})
+	}
+}
+
 func TestConflictChecker_FindAvailableSlots(t *testing.T) {
 	checker := NewConflictChecker(nil)
 	now := time.Now()
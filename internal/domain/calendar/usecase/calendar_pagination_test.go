@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"mail2calendar/internal/utility/filter"
+)
+
+func newPagedEvents(n int) []*CalendarEvent {
+	start := parseTime("2025-02-05T09:00:00Z")
+	events := make([]*CalendarEvent, 0, n)
+	for i := 0; i < n; i++ {
+		events = append(events, &CalendarEvent{
+			ID:        fmt.Sprintf("event-%03d", i),
+			StartTime: start.Add(time.Duration(i) * time.Minute),
+			EndTime:   start.Add(time.Duration(i+1) * time.Minute),
+		})
+	}
+	return events
+}
+
+func TestCalendarUseCase_ListEvents_PageSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []CalendarUseCaseOption
+		pageSize     int32
+		expectedSize int
+	}{
+		{name: "zero page size uses default", pageSize: 0, expectedSize: 30},
+		{name: "page size within bounds is kept", pageSize: 10, expectedSize: 10},
+		{name: "page size over max is clamped", pageSize: 1000, expectedSize: 100},
+		{
+			name:         "configured bounds",
+			opts:         []CalendarUseCaseOption{WithPagination(filter.Pagination{DefaultSize: 5, MaxSize: 20})},
+			pageSize:     1000,
+			expectedSize: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockCalendarService)
+			mockService.On("GetEventsPage", mock.Anything, mock.Anything, tt.expectedSize, "").
+				Return(newPagedEvents(tt.expectedSize), "next", nil)
+
+			uc := NewCalendarUseCase(nil, mockService, tt.opts...)
+			result, nextPageToken, err := uc.ListEvents(context.Background(), "user-1", 0, 0, "", tt.pageSize, "", EventSort{})
+			assert.NoError(t, err)
+			assert.Len(t, result, tt.expectedSize)
+			assert.Equal(t, "next", nextPageToken)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCalendarUseCase_ListEvents_PageToken(t *testing.T) {
+	events := newPagedEvents(25)
+
+	// Each page is one provider request carrying the provider's own page token
+	mockService := new(mockCalendarService)
+	mockService.On("GetEventsPage", mock.Anything, mock.Anything, 20, "").Return(events[:20], "provider-page-2", nil).Once()
+	mockService.On("GetEventsPage", mock.Anything, mock.Anything, 20, "provider-page-2").Return(events[20:], "", nil).Once()
+	mockService.On("GetEventsPage", mock.Anything, mock.Anything, 20, "not-a-token").Return([]*CalendarEvent(nil), "", ErrInvalidPageToken).Once()
+
+	uc := NewCalendarUseCase(nil, mockService)
+	from := parseTime("2025-03-01T00:00:00Z").Unix()
+	to := parseTime("2025-04-01T00:00:00Z").Unix()
+
+	first, token, err := uc.ListEvents(context.Background(), "user-1", from, to, "", 20, "", EventSort{})
+	assert.NoError(t, err)
+	assert.Len(t, first, 20)
+	assert.Equal(t, "provider-page-2", token)
+
+	second, token, err := uc.ListEvents(context.Background(), "user-1", from, to, "", 20, token, EventSort{})
+	assert.NoError(t, err)
+	assert.Len(t, second, 5)
+	assert.Equal(t, "event-020", second[0].Id)
+	assert.Empty(t, token)
+
+	_, _, err = uc.ListEvents(context.Background(), "user-1", from, to, "", 20, "not-a-token", EventSort{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The default range moves with the clock, so a token cannot be replayed against it
+	_, _, err = uc.ListEvents(context.Background(), "user-1", 0, 0, "", 20, "provider-page-2", EventSort{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, _, err = uc.ListEvents(context.Background(), "user-1", from, 0, "", 20, "provider-page-2", EventSort{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockService.AssertNotCalled(t, "GetEvents", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertExpectations(t)
+}
+
+func TestCalendarUseCase_ListEvents_SortedPageToken(t *testing.T) {
+	mockService := new(mockCalendarService)
+	mockService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+		Return(newPagedEvents(25), nil)
+
+	uc := NewCalendarUseCase(nil, mockService)
+	byCreated := EventSort{Field: SortByCreated}
+	from := parseTime("2025-03-01T00:00:00Z").Unix()
+	to := parseTime("2025-04-01T00:00:00Z").Unix()
+
+	// Orders the provider cannot page by read the whole range and page by offset
+	first, token, err := uc.ListEvents(context.Background(), "user-1", from, to, "", 20, "", byCreated)
+	assert.NoError(t, err)
+	assert.Len(t, first, 20)
+	assert.Equal(t, "20", token)
+
+	second, token, err := uc.ListEvents(context.Background(), "user-1", from, to, "", 20, token, byCreated)
+	assert.NoError(t, err)
+	assert.Len(t, second, 5)
+	assert.Empty(t, token)
+
+	_, _, err = uc.ListEvents(context.Background(), "user-1", from, to, "", 20, "not-a-token", byCreated)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCalendarUseCase_ListEvents_TimeRange(t *testing.T) {
+	now := parseTime("2025-03-01T08:00:00Z")
+	from := parseTime("2025-03-10T00:00:00Z")
+
+	tests := []struct {
+		name      string
+		startTime int64
+		endTime   int64
+		expected  TimeRange
+	}{
+		{
+			name:      "given range",
+			startTime: from.Unix(),
+			endTime:   from.Add(48 * time.Hour).Unix(),
+			expected:  TimeRange{StartTime: from, EndTime: from.Add(48 * time.Hour)},
+		},
+		{
+			name:     "no range starts now",
+			expected: TimeRange{StartTime: now, EndTime: now.Add(defaultListWindow)},
+		},
+		{
+			name:      "no end",
+			startTime: from.Unix(),
+			expected:  TimeRange{StartTime: from, EndTime: from.Add(defaultListWindow)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockCalendarService)
+			mockService.On("GetEventsPage", mock.Anything, mock.MatchedBy(func(timeRange TimeRange) bool {
+				return timeRange.StartTime.Equal(tt.expected.StartTime) && timeRange.EndTime.Equal(tt.expected.EndTime)
+			}), mock.Anything, "").Return([]*CalendarEvent{}, "", nil)
+
+			uc := NewCalendarUseCase(nil, mockService).(*calendarUseCase)
+			uc.now = func() time.Time { return now }
+
+			_, _, err := uc.ListEvents(context.Background(), "user-1", tt.startTime, tt.endTime, "", 0, "", EventSort{})
+			assert.NoError(t, err)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCalendarUseCase_ListEvents_EndBeforeStart(t *testing.T) {
+	mockService := new(mockCalendarService)
+	uc := NewCalendarUseCase(nil, mockService)
+
+	from := parseTime("2025-03-10T00:00:00Z")
+	_, _, err := uc.ListEvents(context.Background(), "user-1", from.Unix(), from.Add(-time.Hour).Unix(), "", 0, "", EventSort{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertNotCalled(t, "GetEvents", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "GetEventsPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGoogleCalendarService_ListEvents_Pages(t *testing.T) {
+	var pageTokens []string
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		pageTokens = append(pageTokens, r.URL.Query().Get("pageToken"))
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			_, _ = w.Write([]byte(`{"nextPageToken":"page-2","items":[
+				{"id":"event-1","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}},
+				{"id":"event-2","start":{"dateTime":"2025-03-04T09:00:00Z"},"end":{"dateTime":"2025-03-04T10:00:00Z"}}
+			]}`))
+		case "page-2":
+			_, _ = w.Write([]byte(`{"items":[
+				{"id":"event-3","start":{"dateTime":"2025-03-05T09:00:00Z"},"end":{"dateTime":"2025-03-05T10:00:00Z"}}
+			]}`))
+		default:
+			t.Errorf("unexpected page token %q", r.URL.Query().Get("pageToken"))
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	from := parseTime("2025-03-01T00:00:00Z")
+	events, err := svc.ListEvents(ctx, from, from.AddDate(0, 0, 7), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "page-2"}, pageTokens)
+	require.Len(t, events, 3)
+	assert.Equal(t, "event-3", events[2].ID)
+}
+
+func TestGoogleCalendarService_ListEventsPage(t *testing.T) {
+	var queries []url.Values
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			_, _ = w.Write([]byte(`{"nextPageToken":"page-2","items":[
+				{"id":"event-1","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}},
+				{"id":"event-2","start":{"dateTime":"2025-03-04T09:00:00Z"},"end":{"dateTime":"2025-03-04T10:00:00Z"}}
+			]}`))
+		case "page-2":
+			_, _ = w.Write([]byte(`{"items":[
+				{"id":"event-3","start":{"dateTime":"2025-03-05T09:00:00Z"},"end":{"dateTime":"2025-03-05T10:00:00Z"}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Invalid pageToken"}}`))
+		}
+	})
+
+	from := parseTime("2025-03-01T00:00:00Z")
+	to := from.AddDate(0, 0, 7)
+
+	// One request per page, asking Google for the page size
+	events, next, err := svc.ListEventsPage(ctx, from, to, 2, "")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "page-2", next)
+	assert.Equal(t, "2", queries[0].Get("maxResults"))
+
+	events, next, err = svc.ListEventsPage(ctx, from, to, 2, next)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "event-3", events[0].ID)
+	assert.Empty(t, next)
+	assert.Len(t, queries, 2)
+
+	_, _, err = svc.ListEventsPage(ctx, from, to, 2, "forged")
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
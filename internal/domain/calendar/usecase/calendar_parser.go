@@ -3,9 +3,12 @@ package usecase
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"time"
 
 	ical "github.com/arran4/golang-ical"
+
+	"mail2calendar/internal/domain/calendar"
 )
 
 // ICalendarParser defines methods for parsing calendar data
@@ -20,30 +23,78 @@ func NewCalendarParser() ICalendarParser {
 }
 
 func (p *calendarParserImpl) ParseICSAttachment(data []byte) (*CalendarEvent, error) {
-	calendar, err := ical.ParseCalendar(bytes.NewReader(data))
+	cal, err := ical.ParseCalendar(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ICS: %w", err)
 	}
 
 	// Get the first event from the calendar
-	for _, event := range calendar.Events() {
+	for _, event := range cal.Events() {
 		startTime, _ := time.Parse("20060102T150405Z", event.GetProperty(ical.ComponentPropertyDtStart).Value)
-		endTime, _ := time.Parse("20060102T150405Z", event.GetProperty(ical.ComponentPropertyDtEnd).Value)
+
+		// Events may give DURATION instead of DTEND
+		endTime, err := calendar.EventEndAt(event, startTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get end time: %w", err)
+		}
 
 		attendees := make([]string, 0)
+		var statuses []Attendee
 		for _, attendee := range event.Attendees() {
 			attendees = append(attendees, attendee.Email())
+			statuses = append(statuses, Attendee{Email: attendee.Email(), ResponseStatus: icsResponseStatus(attendee.ParticipationStatus())})
+		}
+
+		var recurrenceRule string
+		if rrule := event.GetProperty(ical.ComponentPropertyRrule); rrule != nil {
+			// Keep the "RRULE:..." form used by ParseRecurrenceRule and Google Calendar
+			recurrenceRule = "RRULE:" + rrule.Value
+		}
+
+		// SEQUENCE is bumped by the organizer on every update, absent means 0
+		var sequence int
+		if value := icsPropertyValue(event, ical.ComponentPropertySequence); value != "" {
+			if sequence, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid SEQUENCE %q: %w", value, err)
+			}
 		}
 
 		return &CalendarEvent{
-			Title:       event.GetProperty(ical.ComponentPropertySummary).Value,
-			StartTime:   startTime,
-			EndTime:     endTime,
-			Location:    event.GetProperty(ical.ComponentPropertyLocation).Value,
-			Attendees:   attendees,
-			IsRecurring: event.GetProperty(ical.ComponentPropertyRrule) != nil,
+			UID:              icsPropertyValue(event, ical.ComponentPropertyUniqueId),
+			Sequence:         sequence,
+			Title:            icsPropertyValue(event, ical.ComponentPropertySummary),
+			StartTime:        startTime,
+			EndTime:          endTime,
+			Location:         icsPropertyValue(event, ical.ComponentPropertyLocation),
+			Attendees:        attendees,
+			AttendeeStatuses: statuses,
+			IsRecurring:      recurrenceRule != "",
+			RecurrenceRule:   recurrenceRule,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("no events found in ICS file")
 }
+
+// icsPropertyValue returns the property value, or "" when the VEVENT lacks it
+func icsPropertyValue(event *ical.VEvent, property ical.ComponentProperty) string {
+	if prop := event.GetProperty(property); prop != nil {
+		return prop.Value
+	}
+	return ""
+}
+
+// icsResponseStatus maps the PARTSTAT of an attendee to a ResponseStatus; a missing
+// PARTSTAT means NEEDS-ACTION
+func icsResponseStatus(status ical.ParticipationStatus) ResponseStatus {
+	switch status {
+	case ical.ParticipationStatusAccepted:
+		return ResponseAccepted
+	case ical.ParticipationStatusTentative:
+		return ResponseTentative
+	case ical.ParticipationStatusDeclined:
+		return ResponseDeclined
+	default:
+		return ResponseNeedsAction
+	}
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/domain/calendar"
+)
+
+func icsWithEvent(lines ...string) []byte {
+	ics := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//mail2calendar//test//EN",
+		"BEGIN:VEVENT",
+		"UID:duration-test@example.com",
+		"SUMMARY:Standup",
+		"DTSTART:20250305T090000Z",
+	}
+	ics = append(ics, lines...)
+	ics = append(ics, "END:VEVENT", "END:VCALENDAR", "")
+	return []byte(strings.Join(ics, "\r\n"))
+}
+
+func TestCalendarParser_ParseICSAttachment_Duration(t *testing.T) {
+	start := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		lines       []string
+		expectedEnd time.Time
+		expectError bool
+	}{
+		{
+			name:        "DTEND",
+			lines:       []string{"DTEND:20250305T100000Z"},
+			expectedEnd: start.Add(time.Hour),
+		},
+		{
+			name:        "DURATION instead of DTEND",
+			lines:       []string{"DURATION:PT1H30M"},
+			expectedEnd: start.Add(90 * time.Minute),
+		},
+		{
+			name:        "DURATION in days",
+			lines:       []string{"DURATION:P1DT2H"},
+			expectedEnd: start.Add(26 * time.Hour),
+		},
+		{
+			name:        "neither DTEND nor DURATION",
+			expectedEnd: start,
+		},
+		{
+			name:        "invalid DURATION",
+			lines:       []string{"DURATION:1 hour"},
+			expectError: true,
+		},
+	}
+
+	parser := NewCalendarParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parser.ParseICSAttachment(icsWithEvent(tt.lines...))
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, start.Equal(event.StartTime))
+			assert.True(t, tt.expectedEnd.Equal(event.EndTime), "got end %v", event.EndTime)
+		})
+	}
+}
+
+func TestCalendarParser_DurationRecurrenceSlots(t *testing.T) {
+	event, err := NewCalendarParser().ParseICSAttachment(icsWithEvent(
+		"DURATION:PT45M",
+		"RRULE:FREQ=DAILY;COUNT=3",
+	))
+	require.NoError(t, err)
+	assert.True(t, event.IsRecurring)
+	assert.Equal(t, "RRULE:FREQ=DAILY;COUNT=3", event.RecurrenceRule)
+
+	cc := &conflictCheckerImpl{}
+	slots := cc.expandRecurringEvent(event, TimeRange{
+		StartTime: event.StartTime,
+		EndTime:   event.StartTime.AddDate(0, 0, 7),
+	})
+
+	require.Len(t, slots, 3)
+	for i, slot := range slots {
+		assert.True(t, event.StartTime.AddDate(0, 0, i).Equal(slot.Start))
+		assert.Equal(t, 45*time.Minute, slot.End.Sub(slot.Start))
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "PT15M", expected: 15 * time.Minute},
+		{value: "PT1H30M", expected: 90 * time.Minute},
+		{value: "P1D", expected: 24 * time.Hour},
+		{value: "P2W", expected: 14 * 24 * time.Hour},
+		{value: "P1DT2H3M4S", expected: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{value: "+PT10S", expected: 10 * time.Second},
+		{value: "-PT15M", expected: -15 * time.Minute},
+		{value: "P", expectError: true},
+		{value: "PT", expectError: true},
+		{value: "P1DT", expectError: true},
+		{value: "1H", expectError: true},
+		{value: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := calendar.ParseICSDuration(tt.value)
+			if tt.expectError {
+				assert.ErrorIs(t, err, calendar.ErrInvalidICSDuration)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func (u *calendarUseCase) SearchEvents(ctx context.Context, query string, timeRange TimeRange, userID string) ([]*calendarPb.Event, error) {
+	if userID == "" {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "search query is required")
+	}
+	timeRange = u.withDefaultWindow(timeRange)
+	if timeRange.EndTime.Before(timeRange.StartTime) {
+		return nil, status.Error(codes.InvalidArgument, "end time must not be before start time")
+	}
+
+	events, err := u.calendarService.GetEvents(ctx, timeRange, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search events: %v", err)
+	}
+
+	result := make([]*calendarPb.Event, 0)
+	for _, event := range events {
+		if matchesSearch(event, terms) {
+			result = append(result, toProtoEvent(event))
+		}
+	}
+	return result, nil
+}
+
+// withDefaultWindow fills in the bounds ListEvents defaults to: an unset start is
+// now and an unset end is defaultListWindow after the start
+func (u *calendarUseCase) withDefaultWindow(timeRange TimeRange) TimeRange {
+	if timeRange.StartTime.IsZero() {
+		timeRange.StartTime = u.now()
+	}
+	if timeRange.EndTime.IsZero() {
+		timeRange.EndTime = timeRange.StartTime.Add(defaultListWindow)
+	}
+	return timeRange
+}
+
+// searchTerms splits a search query into lower-cased words
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// matchesSearch reports whether every term appears, case-insensitively, in the title,
+// description or location of event
+func matchesSearch(event *CalendarEvent, terms []string) bool {
+	text := strings.ToLower(strings.Join([]string{event.Title, event.Description, event.Location}, "\n"))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
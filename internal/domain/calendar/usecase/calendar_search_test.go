@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCalendarUseCase_SearchEvents(t *testing.T) {
+	timeRange := TimeRange{
+		StartTime: parseTime("2025-03-01T00:00:00Z"),
+		EndTime:   parseTime("2025-04-01T00:00:00Z"),
+	}
+	events := []*CalendarEvent{
+		{ID: "standup", Title: "Daily standup", Location: "Room 1",
+			StartTime: parseTime("2025-03-10T09:00:00Z"), EndTime: parseTime("2025-03-10T09:15:00Z")},
+		{ID: "review", Title: "Quarterly review", Description: "Budget and hiring plan", Location: "Building 5",
+			StartTime: parseTime("2025-03-12T14:00:00Z"), EndTime: parseTime("2025-03-12T15:00:00Z")},
+		{ID: "offsite", Title: "Team offsite", Description: "Planning for Q2", Location: "Hanoi",
+			StartTime: parseTime("2025-03-20T08:00:00Z"), EndTime: parseTime("2025-03-20T17:00:00Z")},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "title", query: "standup", expected: []string{"standup"}},
+		{name: "description ignores case", query: "BUDGET", expected: []string{"review"}},
+		{name: "location", query: "hanoi", expected: []string{"offsite"}},
+		{name: "word in several events", query: "plan", expected: []string{"review", "offsite"}},
+		{name: "every word must match", query: "planning hanoi", expected: []string{"offsite"}},
+		{name: "words across fields", query: "review building", expected: []string{"review"}},
+		{name: "no match", query: "retrospective", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetEvents", mock.Anything, timeRange, mock.Anything).Return(events, nil)
+
+			found, err := NewCalendarUseCase(nil, calendar).SearchEvents(context.Background(), tt.query, timeRange, "user-1")
+			require.NoError(t, err)
+			require.NotNil(t, found)
+
+			ids := make([]string, 0, len(found))
+			for _, event := range found {
+				ids = append(ids, event.Id)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestCalendarUseCase_SearchEvents_Errors(t *testing.T) {
+	timeRange := TimeRange{
+		StartTime: parseTime("2025-03-01T00:00:00Z"),
+		EndTime:   parseTime("2025-04-01T00:00:00Z"),
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		timeRange TimeRange
+		userID    string
+		listErr   error
+		code      codes.Code
+	}{
+		{name: "missing user", query: "sync", timeRange: timeRange, code: codes.InvalidArgument},
+		{name: "blank query", query: "  ", timeRange: timeRange, userID: "user-1", code: codes.InvalidArgument},
+		{
+			name:      "inverted range",
+			query:     "sync",
+			timeRange: TimeRange{StartTime: timeRange.EndTime, EndTime: timeRange.StartTime},
+			userID:    "user-1",
+			code:      codes.InvalidArgument,
+		},
+		{name: "calendar error", query: "sync", timeRange: timeRange, userID: "user-1", listErr: fmt.Errorf("quota exceeded"), code: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*CalendarEvent(nil), tt.listErr).Maybe()
+
+			found, err := NewCalendarUseCase(nil, calendar).SearchEvents(context.Background(), tt.query, tt.timeRange, tt.userID)
+			assert.Nil(t, found)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestCalendarUseCase_SearchEvents_DefaultWindow(t *testing.T) {
+	now := parseTime("2025-03-10T08:00:00Z")
+	from := parseTime("2025-03-01T00:00:00Z")
+
+	tests := []struct {
+		name      string
+		timeRange TimeRange
+		expected  TimeRange
+	}{
+		{
+			name:     "no range starts now",
+			expected: TimeRange{StartTime: now, EndTime: now.Add(defaultListWindow)},
+		},
+		{
+			name:      "no end",
+			timeRange: TimeRange{StartTime: from},
+			expected:  TimeRange{StartTime: from, EndTime: from.Add(defaultListWindow)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetEvents", mock.Anything, tt.expected, mock.Anything).Return([]*CalendarEvent{}, nil)
+
+			uc := NewCalendarUseCase(nil, calendar).(*calendarUseCase)
+			uc.now = func() time.Time { return now }
+
+			_, err := uc.SearchEvents(context.Background(), "sync", tt.timeRange, "user-1")
+			assert.NoError(t, err)
+			calendar.AssertExpectations(t)
+		})
+	}
+}
@@ -2,25 +2,54 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
+
+	"mail2calendar/internal/domain/calendar/service"
 )
 
+// ErrInvalidPageToken is returned for a page token the calendar did not issue
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrEventNotFound is returned for an event the calendar does not have
+var ErrEventNotFound = errors.New("event not found")
+
 // CalendarService defines the interface for calendar operations
 type CalendarService interface {
 	// GetEvents returns calendar events for the given time range and attendees
 	GetEvents(ctx context.Context, timeRange TimeRange, attendees []string) ([]*CalendarEvent, error)
 
-	// CreateEvent creates a new calendar event
+	// GetEvent returns the event with the given ID, or ErrEventNotFound if the calendar
+	// has no such event
+	GetEvent(ctx context.Context, eventID string) (*CalendarEvent, error)
+
+	// GetEventsPage returns one page of at most pageSize events in the given time range,
+	// ordered by start time, and the token of the next page, "" after the last one.
+	// pageToken is "" for the first page or a token returned by an earlier call; a
+	// token the calendar did not issue fails with ErrInvalidPageToken.
+	GetEventsPage(ctx context.Context, timeRange TimeRange, pageSize int, pageToken string) ([]*CalendarEvent, string, error)
+
+	// CreateEvent creates a new calendar event and sets the ID the calendar gave it
 	CreateEvent(ctx context.Context, event *CalendarEvent) error
 
+	// CreateEvents creates several calendar events, as CreateEvent does, and returns the
+	// error of each, in the order of events. An event that fails doesn't stop the others.
+	CreateEvents(ctx context.Context, events []*CalendarEvent) []error
+
 	// UpdateEvent updates an existing calendar event
 	UpdateEvent(ctx context.Context, event *CalendarEvent) error
 
 	// DeleteEvent deletes an existing calendar event
 	DeleteEvent(ctx context.Context, eventID string) error
 
-	// GetWorkingHours returns working hours for given attendees
-	GetWorkingHours(ctx context.Context, attendees []string) (map[string]*WorkingHours, error)
+	// RespondToEvent sets the response of the user to the invitation of an event. It
+	// returns ErrNotInvited if the user is not an attendee.
+	RespondToEvent(ctx context.Context, eventID string, response EventResponse) error
+
+	// GetWorkingHours returns working hours for given attendees within timeRange. A zero
+	// range looks up the week starting now.
+	GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*WorkingHours, error)
 }
 
 // WorkingHours represents a user's working hours
@@ -36,6 +65,25 @@ type WeeklySchedule struct {
 	EndTime   time.Time
 }
 
+// defaultWorkingHoursWindow is how far ahead working hours are looked up when no range is given
+const defaultWorkingHoursWindow = 7 * 24 * time.Hour
+
+// workingHoursRange returns the start and end of a free/busy lookup, defaulting a zero
+// range to the week starting now
+func workingHoursRange(timeRange TimeRange) (time.Time, time.Time, error) {
+	start, end := timeRange.StartTime, timeRange.EndTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if end.IsZero() {
+		end = start.Add(defaultWorkingHoursWindow)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("working hours range ends at %s, before it starts at %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	return start, end, nil
+}
+
 // calendarServiceImpl implements CalendarService interface
 type calendarServiceImpl struct {
 	googleCalendar GoogleCalendarService
@@ -58,36 +106,82 @@ func (cs *calendarServiceImpl) GetEvents(ctx context.Context, timeRange TimeRang
 	// Convert Google Calendar events to our domain model
 	result := make([]*CalendarEvent, len(events))
 	for i, event := range events {
-		result[i] = &CalendarEvent{
-			ID:             event.ID,
-			Title:          event.Summary,
-			StartTime:      event.Start,
-			EndTime:        event.End,
-			Location:       event.Location,
-			Attendees:      event.Attendees,
-			IsAllDay:       event.IsAllDay,
-			IsRecurring:    event.IsRecurring,
-			RecurrenceRule: event.RecurrenceRule,
-		}
+		result[i] = fromGoogleCalendarEvent(event)
 	}
 
 	return result, nil
 }
 
+func (cs *calendarServiceImpl) GetEvent(ctx context.Context, eventID string) (*CalendarEvent, error) {
+	event, err := cs.googleCalendar.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return fromGoogleCalendarEvent(event), nil
+}
+
+func (cs *calendarServiceImpl) GetEventsPage(ctx context.Context, timeRange TimeRange, pageSize int, pageToken string) ([]*CalendarEvent, string, error) {
+	events, nextPageToken, err := cs.googleCalendar.ListEventsPage(ctx, timeRange.StartTime, timeRange.EndTime, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]*CalendarEvent, len(events))
+	for i, event := range events {
+		result[i] = fromGoogleCalendarEvent(event)
+	}
+
+	return result, nextPageToken, nil
+}
+
+// fromGoogleCalendarEvent converts a Google Calendar event to our domain model
+func fromGoogleCalendarEvent(event *GoogleCalendarEvent) *CalendarEvent {
+	return &CalendarEvent{
+		ID:               event.ID,
+		Title:            event.Summary,
+		StartTime:        event.Start,
+		EndTime:          event.End,
+		Location:         event.Location,
+		Description:      event.Description,
+		Organizer:        event.Organizer,
+		Attendees:        event.Attendees,
+		AttendeeStatuses: event.AttendeeStatuses,
+		IsAllDay:         event.IsAllDay,
+		IsRecurring:      event.IsRecurring,
+		RecurrenceRule:   event.RecurrenceRule,
+		SeriesID:         event.SeriesID,
+		TimeZone:         event.TimeZone,
+		Created:          event.Created,
+		Headers:          event.Headers,
+		Source:           event.Source,
+		ETag:             event.ETag,
+	}
+}
+
 func (cs *calendarServiceImpl) CreateEvent(ctx context.Context, event *CalendarEvent) error {
 	// Convert to Google Calendar event
 	gEvent := &GoogleCalendarEvent{
 		Summary:        event.Title,
+		Description:    event.Description,
 		Start:          event.StartTime,
 		End:            event.EndTime,
 		Location:       event.Location,
+		Organizer:      event.Organizer,
 		Attendees:      event.Attendees,
 		IsAllDay:       event.IsAllDay,
 		IsRecurring:    event.IsRecurring,
 		RecurrenceRule: event.RecurrenceRule,
+		TimeZone:       event.TimeZone,
+		Headers:        event.Headers,
+		Source:         event.Source,
 	}
 
-	return cs.googleCalendar.CreateEvent(ctx, gEvent)
+	if err := cs.googleCalendar.CreateEvent(ctx, gEvent); err != nil {
+		return err
+	}
+	event.ID = gEvent.ID
+	event.ETag = gEvent.ETag
+	return nil
 }
 
 func (cs *calendarServiceImpl) UpdateEvent(ctx context.Context, event *CalendarEvent) error {
@@ -95,13 +189,19 @@ func (cs *calendarServiceImpl) UpdateEvent(ctx context.Context, event *CalendarE
 	gEvent := &GoogleCalendarEvent{
 		ID:             event.ID,
 		Summary:        event.Title,
+		Description:    event.Description,
 		Start:          event.StartTime,
 		End:            event.EndTime,
 		Location:       event.Location,
+		Organizer:      event.Organizer,
 		Attendees:      event.Attendees,
 		IsAllDay:       event.IsAllDay,
 		IsRecurring:    event.IsRecurring,
 		RecurrenceRule: event.RecurrenceRule,
+		TimeZone:       event.TimeZone,
+		Headers:        event.Headers,
+		Source:         event.Source,
+		ETag:           event.ETag,
 	}
 
 	return cs.googleCalendar.UpdateEvent(ctx, gEvent)
@@ -111,9 +211,9 @@ func (cs *calendarServiceImpl) DeleteEvent(ctx context.Context, eventID string)
 	return cs.googleCalendar.DeleteEvent(ctx, eventID)
 }
 
-func (cs *calendarServiceImpl) GetWorkingHours(ctx context.Context, attendees []string) (map[string]*WorkingHours, error) {
+func (cs *calendarServiceImpl) GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*WorkingHours, error) {
 	// Get working hours from Google Calendar
-	workingHours, err := cs.googleCalendar.GetWorkingHours(ctx, attendees)
+	workingHours, err := cs.googleCalendar.GetWorkingHours(ctx, timeRange, attendees)
 	if err != nil {
 		return nil, err
 	}
@@ -137,15 +237,31 @@ func (cs *calendarServiceImpl) GetWorkingHours(ctx context.Context, attendees []
 
 // GoogleCalendarEvent represents a Google Calendar event
 type GoogleCalendarEvent struct {
-	ID             string
-	Summary        string
-	Start          time.Time
-	End            time.Time
-	Location       string
-	Attendees      []string
-	IsAllDay       bool
-	IsRecurring    bool
-	RecurrenceRule string
+	ID       string
+	Summary  string
+	Start    time.Time
+	End      time.Time
+	Location string
+	// Description is the body of the event
+	Description string
+	// Organizer is set as the event organizer and is never invited as an attendee
+	Organizer string
+	Attendees []string
+	// AttendeeStatuses holds the response of each attendee; only set by ListEvents
+	AttendeeStatuses []Attendee
+	IsAllDay         bool
+	IsRecurring      bool
+	RecurrenceRule   string
+	// SeriesID is the recurring event an instance listed by ListEvents was expanded from
+	SeriesID string
+	// TimeZone is the IANA timezone set on the start and end, "" for the calendar default
+	TimeZone string
+	Created  time.Time
+	Headers  map[string]string
+	Source   service.EventSource
+	// ETag is the version of the event set by ListEvents. UpdateEvent only applies when
+	// the event still has this version; "" updates unconditionally.
+	ETag string
 }
 
 // GoogleWorkingHours represents working hours from Google Calendar
@@ -166,7 +282,14 @@ type GoogleCalendarService interface {
 	// ListEvents lists events from Google Calendar
 	ListEvents(ctx context.Context, startTime, endTime time.Time, attendees []string) ([]*GoogleCalendarEvent, error)
 
-	// CreateEvent creates a new event in Google Calendar
+	// GetEvent gets an event from Google Calendar by ID
+	GetEvent(ctx context.Context, eventID string) (*GoogleCalendarEvent, error)
+
+	// ListEventsPage lists one page of at most pageSize events from Google Calendar and
+	// returns Google's token for the next page
+	ListEventsPage(ctx context.Context, startTime, endTime time.Time, pageSize int, pageToken string) ([]*GoogleCalendarEvent, string, error)
+
+	// CreateEvent creates a new event in Google Calendar and sets its ID and ETag
 	CreateEvent(ctx context.Context, event *GoogleCalendarEvent) error
 
 	// UpdateEvent updates an existing event in Google Calendar
@@ -175,6 +298,9 @@ type GoogleCalendarService interface {
 	// DeleteEvent deletes an event from Google Calendar
 	DeleteEvent(ctx context.Context, eventID string) error
 
-	// GetWorkingHours gets working hours for attendees from Google Calendar
-	GetWorkingHours(ctx context.Context, attendees []string) (map[string]*GoogleWorkingHours, error)
+	// RespondToEvent sets the responseStatus of the user among the attendees of an event
+	RespondToEvent(ctx context.Context, eventID string, response EventResponse) error
+
+	// GetWorkingHours gets working hours for attendees within timeRange from Google Calendar
+	GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*GoogleWorkingHours, error)
 }
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Supported fields for sorting listed events
+const (
+	SortByStart   = "start"
+	SortByCreated = "created"
+	SortByTitle   = "title"
+)
+
+// EventSort describes the order in which listed events are returned.
+// The zero value sorts by start time ascending, matching Google's default order.
+type EventSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseEventSort builds an EventSort from a field name and an ASC/DESC order
+func ParseEventSort(field, order string) (EventSort, error) {
+	field = strings.ToLower(strings.TrimSpace(field))
+	switch field {
+	case "", SortByStart, SortByCreated, SortByTitle:
+	default:
+		return EventSort{}, fmt.Errorf("unsupported sort field: %s", field)
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(order)) {
+	case "", "ASC":
+		return EventSort{Field: field}, nil
+	case "DESC":
+		return EventSort{Field: field, Desc: true}, nil
+	default:
+		return EventSort{}, fmt.Errorf("unsupported sort order: %s", order)
+	}
+}
+
+// sortEvents orders events in place according to the given sort option
+func sortEvents(events []*CalendarEvent, by EventSort) error {
+	var less func(a, b *CalendarEvent) bool
+
+	switch by.Field {
+	case "", SortByStart:
+		less = func(a, b *CalendarEvent) bool { return a.StartTime.Before(b.StartTime) }
+	case SortByCreated:
+		less = func(a, b *CalendarEvent) bool { return a.Created.Before(b.Created) }
+	case SortByTitle:
+		less = func(a, b *CalendarEvent) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	default:
+		return fmt.Errorf("unsupported sort field: %s", by.Field)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if by.Desc {
+			return less(events[j], events[i])
+		}
+		return less(events[i], events[j])
+	})
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCalendarUseCase_ListEvents_Sort(t *testing.T) {
+	events := func() []*CalendarEvent {
+		return []*CalendarEvent{
+			{
+				ID:        "b",
+				Title:     "budget review",
+				StartTime: parseTime("2025-02-05T11:00:00Z"),
+				EndTime:   parseTime("2025-02-05T12:00:00Z"),
+				Created:   parseTime("2025-01-01T08:00:00Z"),
+			},
+			{
+				ID:        "a",
+				Title:     "Standup",
+				StartTime: parseTime("2025-02-05T09:00:00Z"),
+				EndTime:   parseTime("2025-02-05T09:15:00Z"),
+				Created:   parseTime("2025-01-03T08:00:00Z"),
+			},
+			{
+				ID:        "c",
+				Title:     "Planning",
+				StartTime: parseTime("2025-02-05T14:00:00Z"),
+				EndTime:   parseTime("2025-02-05T15:00:00Z"),
+				Created:   parseTime("2025-01-02T08:00:00Z"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		sortBy        EventSort
+		providerOrder bool
+		expectedIDs   []string
+	}{
+		{
+			name:          "default sorts by start ascending",
+			sortBy:        EventSort{},
+			providerOrder: true,
+			expectedIDs:   []string{"a", "b", "c"},
+		},
+		{
+			name:          "start ascending",
+			sortBy:        EventSort{Field: SortByStart},
+			providerOrder: true,
+			expectedIDs:   []string{"a", "b", "c"},
+		},
+		{
+			name:        "start descending",
+			sortBy:      EventSort{Field: SortByStart, Desc: true},
+			expectedIDs: []string{"c", "b", "a"},
+		},
+		{
+			name:        "created ascending",
+			sortBy:      EventSort{Field: SortByCreated},
+			expectedIDs: []string{"b", "c", "a"},
+		},
+		{
+			name:        "created descending",
+			sortBy:      EventSort{Field: SortByCreated, Desc: true},
+			expectedIDs: []string{"a", "c", "b"},
+		},
+		{
+			name:        "title ascending is case-insensitive",
+			sortBy:      EventSort{Field: SortByTitle},
+			expectedIDs: []string{"b", "c", "a"},
+		},
+		{
+			name:        "title descending",
+			sortBy:      EventSort{Field: SortByTitle, Desc: true},
+			expectedIDs: []string{"a", "c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockCalendarService)
+			if tt.providerOrder {
+				// The provider returns pages already in start time order
+				byStart := events()
+				byStart[0], byStart[1] = byStart[1], byStart[0]
+				mockService.On("GetEventsPage", mock.Anything, mock.Anything, mock.Anything, "").
+					Return(byStart, "", nil)
+			} else {
+				mockService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+					Return(events(), nil)
+			}
+
+			uc := NewCalendarUseCase(nil, mockService)
+			result, _, err := uc.ListEvents(context.Background(), "user-1", 0, 0, "", 0, "", tt.sortBy)
+			assert.NoError(t, err)
+
+			ids := make([]string, 0, len(result))
+			for _, event := range result {
+				ids = append(ids, event.Id)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCalendarUseCase_ListEvents_InvalidSort(t *testing.T) {
+	mockService := new(mockCalendarService)
+	mockService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*CalendarEvent{}, nil)
+
+	uc := NewCalendarUseCase(nil, mockService)
+	_, _, err := uc.ListEvents(context.Background(), "user-1", 0, 0, "", 0, "", EventSort{Field: "location"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestParseEventSort(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		order       string
+		expected    EventSort
+		expectError bool
+	}{
+		{name: "start asc", field: "start", order: "ASC", expected: EventSort{Field: SortByStart}},
+		{name: "title desc", field: "title", order: "desc", expected: EventSort{Field: SortByTitle, Desc: true}},
+		{name: "created without order", field: "Created", order: "", expected: EventSort{Field: SortByCreated}},
+		{name: "unknown field", field: "location", order: "ASC", expectError: true},
+		{name: "unknown order", field: "start", order: "sideways", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortBy, err := ParseEventSort(tt.field, tt.order)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sortBy)
+		})
+	}
+}
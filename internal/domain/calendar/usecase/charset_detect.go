@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// guessedCharset is an encoding tried when guessing the charset of content
+type guessedCharset struct {
+	encoding encoding.Encoding
+	// minHighByte is the lowest non-ASCII byte the charset uses. The EUC-KR decoder
+	// also accepts the CP949 extension, which would otherwise read most CJK content
+	// as rare Hangul syllables.
+	minHighByte byte
+}
+
+// guessedCharsets are the encodings tried, in order of preference on a tie, when an
+// email body is not valid UTF-8 and its declared charset is missing or unknown
+var guessedCharsets = []guessedCharset{
+	{encoding: japanese.ShiftJIS, minHighByte: 0x80},
+	{encoding: japanese.EUCJP, minHighByte: 0x8e},
+	{encoding: korean.EUCKR, minHighByte: 0xa1},
+	{encoding: simplifiedchinese.GB18030, minHighByte: 0x80},
+	{encoding: traditionalchinese.Big5, minHighByte: 0x80},
+}
+
+// iso2022JPEscapes start the JIS X 0208 sequences of ISO-2022-JP, which is 7-bit and
+// therefore also valid UTF-8
+var iso2022JPEscapes = [][]byte{[]byte("\x1b$B"), []byte("\x1b$@")}
+
+// guessCharset returns the decoder that best fits content, or nil to keep it as is.
+// Each candidate decodes the content and is scored on the characters it produces:
+// kana and Hangul are rarely the result of decoding with the wrong charset, while
+// replacement characters, private use and control characters usually are.
+func guessCharset(content []byte) encoding.Encoding {
+	for _, escape := range iso2022JPEscapes {
+		if bytes.Contains(content, escape) {
+			return japanese.ISO2022JP
+		}
+	}
+	if utf8.Valid(content) {
+		return nil
+	}
+
+	var best encoding.Encoding
+	bestScore := 0
+	for _, candidate := range guessedCharsets {
+		if !usesHighBytesFrom(content, candidate.minHighByte) {
+			continue
+		}
+		decoded, err := candidate.encoding.NewDecoder().Bytes(content)
+		if err != nil {
+			continue
+		}
+		if score := charsetScore(decoded); best == nil || score > bestScore {
+			best, bestScore = candidate.encoding, score
+		}
+	}
+	return best
+}
+
+// usesHighBytesFrom reports whether every non-ASCII byte of content is at least lowest
+func usesHighBytesFrom(content []byte, lowest byte) bool {
+	for _, b := range content {
+		if b >= utf8.RuneSelf && b < lowest {
+			return false
+		}
+	}
+	return true
+}
+
+// charsetScore rates how plausible decoded text is. Korean text rarely mixes Hangul
+// with Han characters, which is what Chinese content read as EUC-KR looks like.
+func charsetScore(decoded []byte) int {
+	score, hangul, han := 0, 0, 0
+	for _, r := range string(decoded) {
+		switch {
+		case r == utf8.RuneError, unicode.Is(unicode.Co, r):
+			score -= 5
+		case r < utf8.RuneSelf:
+			if unicode.IsControl(r) && !unicode.IsSpace(r) {
+				score -= 5
+			}
+		case isHalfwidthKatakana(r):
+			// EUC and GB bytes read as Shift-JIS single-byte katakana
+			score--
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			score += 3
+		case isHangulSyllable(r):
+			score += 3
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			score++
+			han++
+		case unicode.IsPunct(r):
+			score++
+		default:
+			score--
+		}
+	}
+	if hangul > 0 && han > 0 {
+		score -= 4 * min(hangul, han)
+	}
+	return score
+}
+
+func isHalfwidthKatakana(r rune) bool {
+	return r >= 0xff61 && r <= 0xff9f
+}
+
+// isHangulSyllable excludes the compatibility jamo that misread content often produces
+func isHangulSyllable(r rune) bool {
+	return r >= 0xac00 && r <= 0xd7a3
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+func encodeText(t *testing.T, enc encoding.Encoding, text string) string {
+	t.Helper()
+	encoded, err := enc.NewEncoder().String(text)
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestGuessCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding encoding.Encoding
+		text     string
+	}{
+		{name: "Shift-JIS", encoding: japanese.ShiftJIS, text: "会議は明日の午後2時からです。よろしくお願いします。"},
+		{name: "EUC-JP", encoding: japanese.EUCJP, text: "会議は明日の午後2時からです。よろしくお願いします。"},
+		{name: "ISO-2022-JP", encoding: japanese.ISO2022JP, text: "会議は明日の午後2時からです。"},
+		{name: "EUC-KR", encoding: korean.EUCKR, text: "내일 오후 2시에 회의가 있습니다."},
+		{name: "GB18030", encoding: simplifiedchinese.GB18030, text: "明天下午两点开会，请准时参加。"},
+		{name: "Big5", encoding: traditionalchinese.Big5, text: "明天下午兩點開會，請準時參加。"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte(encodeText(t, tt.encoding, tt.text))
+
+			guessed := guessCharset(content)
+			require.NotNil(t, guessed)
+			decoded, err := guessed.NewDecoder().Bytes(content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.text, string(decoded))
+		})
+	}
+
+	assert.Nil(t, guessCharset([]byte("Meeting tomorrow at 2pm – see you")))
+}
+
+func TestMIMEParser_Parse_GuessesMissingCharset(t *testing.T) {
+	body := "会議は明日の午後2時からです。"
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "missing charset", contentType: "text/plain"},
+		{name: "wrong charset", contentType: "text/plain; charset=utf-8"},
+		{name: "unknown charset", contentType: "text/plain; charset=x-unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emailContent := "From: sender@example.com\r\n" +
+				"To: recipient@example.com\r\n" +
+				"Subject: Meeting\r\n" +
+				"Content-Type: " + tt.contentType + "\r\n" +
+				"Content-Transfer-Encoding: 8bit\r\n" +
+				"\r\n" +
+				encodeText(t, japanese.ShiftJIS, body)
+
+			email, err := NewMIMEParser().Parse(context.Background(), emailContent)
+			require.NoError(t, err)
+			assert.Equal(t, body, email.TextContent)
+		})
+	}
+
+	t.Run("multipart part without charset", func(t *testing.T) {
+		emailContent := "From: sender@example.com\r\n" +
+			"Subject: Meeting\r\n" +
+			"Content-Type: multipart/alternative; boundary=b1\r\n" +
+			"\r\n" +
+			"--b1\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"\r\n" +
+			encodeText(t, japanese.ShiftJIS, body) + "\r\n" +
+			"--b1--\r\n"
+
+		email, err := NewMIMEParser().Parse(context.Background(), emailContent)
+		require.NoError(t, err)
+		assert.Equal(t, body, email.TextContent)
+	})
+
+	t.Run("declared charset is used", func(t *testing.T) {
+		emailContent := "From: sender@example.com\r\n" +
+			"Subject: Meeting\r\n" +
+			"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+			"\r\n" +
+			"caf\xe9"
+
+		email, err := NewMIMEParser().Parse(context.Background(), emailContent)
+		require.NoError(t, err)
+		assert.Equal(t, "café", email.TextContent)
+	})
+}
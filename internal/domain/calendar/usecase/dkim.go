@@ -0,0 +1,429 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// dkimSignatureHeader is the header carrying a DKIM signature
+const dkimSignatureHeader = "DKIM-Signature"
+
+// ErrNoDKIMSignature is returned when an email carries no DKIM-Signature header
+var ErrNoDKIMSignature = errors.New("missing DKIM signature")
+
+// dkimSignatureValue matches the b= tag of a DKIM-Signature so it can be emptied
+var dkimSignatureValue = regexp.MustCompile(`(^|;)(\s*b\s*=)[^;]*`)
+
+// rawHeader is one header field exactly as it appears in the email, folding included
+type rawHeader struct {
+	name string
+	raw  string
+}
+
+// dkimSignature holds the tags of a DKIM-Signature header
+type dkimSignature struct {
+	algorithm       string
+	headerCanon     string
+	bodyCanon       string
+	domain          string
+	selector        string
+	headers         []string
+	bodyHash        []byte
+	signature       []byte
+	bodyLength      int64
+	expiration      int64
+	hasBodyLength   bool
+	hasExpiration   bool
+	signatureHeader rawHeader
+}
+
+// verifyDKIM checks every DKIM-Signature of the email and succeeds if one of them is
+// valid and signed for the domain of the From address. Signatures of other domains
+// don't count: anyone can sign for a domain they own and put someone else in From.
+func (v *emailValidatorImpl) verifyDKIM(ctx context.Context, email string) error {
+	headers, body := splitRawEmail(email)
+
+	// A second From would be shown to the user while the signature covers the other one
+	var from *rawHeader
+	for i, h := range headers {
+		if !strings.EqualFold(h.name, "From") {
+			continue
+		}
+		if from != nil {
+			return fmt.Errorf("email has more than one From header")
+		}
+		from = &headers[i]
+	}
+	if from == nil {
+		return fmt.Errorf("email has no From header")
+	}
+	fromDomain, err := rawHeaderDomain(*from)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error = ErrNoDKIMSignature
+	for _, h := range headers {
+		if !strings.EqualFold(h.name, dkimSignatureHeader) {
+			continue
+		}
+
+		sig, err := parseDKIMSignature(h)
+		if err == nil && !domainsAligned(sig.domain, fromDomain) {
+			err = fmt.Errorf("DKIM signature domain %s does not match From domain %s", sig.domain, fromDomain)
+		}
+		if err == nil {
+			err = v.verifyDKIMSignature(ctx, sig, headers, body)
+		}
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+func (v *emailValidatorImpl) verifyDKIMSignature(ctx context.Context, sig *dkimSignature, headers []rawHeader, body string) error {
+	if sig.hasExpiration && v.now().Unix() > sig.expiration {
+		return fmt.Errorf("DKIM signature of %s expired", sig.domain)
+	}
+
+	// The body hash is checked first, it is cheaper than fetching the key
+	canonBody := canonicalizeBody(body, sig.bodyCanon)
+	if sig.hasBodyLength {
+		if sig.bodyLength > int64(len(canonBody)) {
+			return fmt.Errorf("DKIM body length exceeds the body")
+		}
+		canonBody = canonBody[:sig.bodyLength]
+	}
+	bodyHash := sha256.Sum256([]byte(canonBody))
+	if !bytes.Equal(bodyHash[:], sig.bodyHash) {
+		return fmt.Errorf("DKIM body hash of %s does not match", sig.domain)
+	}
+
+	key, err := v.lookupDKIMKey(ctx, sig)
+	if err != nil {
+		return err
+	}
+
+	hashed := sha256.Sum256([]byte(dkimSignedData(sig, headers)))
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], sig.signature); err != nil {
+			return fmt.Errorf("DKIM signature of %s is invalid: %v", sig.domain, err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, hashed[:], sig.signature) {
+			return fmt.Errorf("DKIM signature of %s is invalid", sig.domain)
+		}
+	default:
+		return fmt.Errorf("unsupported DKIM key type %T", key)
+	}
+	return nil
+}
+
+// lookupDKIMKey fetches the public key published at <selector>._domainkey.<domain>
+func (v *emailValidatorImpl) lookupDKIMKey(ctx context.Context, sig *dkimSignature) (crypto.PublicKey, error) {
+	name := sig.selector + "._domainkey." + sig.domain
+	records, err := v.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("DKIM key lookup for %s failed: %v", name, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no DKIM key published at %s", name)
+	}
+
+	tags, err := parseTagList(strings.Join(records, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DKIM key record at %s: %v", name, err)
+	}
+	if version, ok := tags["v"]; ok && version != "DKIM1" {
+		return nil, fmt.Errorf("unsupported DKIM key version %q", version)
+	}
+	if tags["p"] == "" {
+		return nil, fmt.Errorf("DKIM key at %s has been revoked", name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(stripWhitespace(tags["p"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DKIM public key at %s: %v", name, err)
+	}
+
+	keyType := tags["k"]
+	if keyType == "" {
+		keyType = "rsa"
+	}
+	if !strings.HasPrefix(sig.algorithm, keyType+"-") {
+		return nil, fmt.Errorf("DKIM key type %s does not match algorithm %s", keyType, sig.algorithm)
+	}
+
+	switch keyType {
+	case "rsa":
+		if key, err := x509.ParsePKIXPublicKey(data); err == nil {
+			return key, nil
+		}
+		return x509.ParsePKCS1PublicKey(data)
+	case "ed25519":
+		if len(data) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 DKIM key size %d", len(data))
+		}
+		return ed25519.PublicKey(data), nil
+	default:
+		return nil, fmt.Errorf("unsupported DKIM key type %s", keyType)
+	}
+}
+
+// rawHeaderDomain returns the lowercased domain of the address in an address header
+func rawHeaderDomain(h rawHeader) (string, error) {
+	_, value, _ := strings.Cut(h.raw, ":")
+	addr, err := mail.ParseAddress(strings.TrimSpace(strings.ReplaceAll(value, "\r\n", "")))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s address: %v", h.name, err)
+	}
+	_, domain, found := strings.Cut(addr.Address, "@")
+	if !found || domain == "" {
+		return "", fmt.Errorf("%s address %s has no domain", h.name, addr.Address)
+	}
+	return strings.ToLower(domain), nil
+}
+
+// domainsAligned reports whether two domains are the same or share their organizational
+// domain, as in the relaxed alignment of DMARC: mail.example.com is aligned with
+// example.com, but example.co.uk is not aligned with other.co.uk.
+func domainsAligned(a, b string) bool {
+	a, b = strings.TrimSuffix(strings.ToLower(a), "."), strings.TrimSuffix(strings.ToLower(b), ".")
+	if a == b {
+		return true
+	}
+
+	orgA, err := publicsuffix.EffectiveTLDPlusOne(a)
+	if err != nil {
+		return false
+	}
+	orgB, err := publicsuffix.EffectiveTLDPlusOne(b)
+	if err != nil {
+		return false
+	}
+	return orgA == orgB
+}
+
+// parseDKIMSignature reads the tags of a DKIM-Signature header
+func parseDKIMSignature(h rawHeader) (*dkimSignature, error) {
+	_, value, _ := strings.Cut(h.raw, ":")
+	tags, err := parseTagList(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DKIM signature: %v", err)
+	}
+
+	if tags["v"] != "1" {
+		return nil, fmt.Errorf("unsupported DKIM signature version %q", tags["v"])
+	}
+	for _, required := range []string{"a", "b", "bh", "d", "h", "s"} {
+		if tags[required] == "" {
+			return nil, fmt.Errorf("DKIM signature is missing the %s= tag", required)
+		}
+	}
+
+	sig := &dkimSignature{
+		algorithm:       strings.ToLower(tags["a"]),
+		headerCanon:     "simple",
+		bodyCanon:       "simple",
+		domain:          strings.ToLower(tags["d"]),
+		selector:        tags["s"],
+		signatureHeader: h,
+	}
+	if sig.algorithm != "rsa-sha256" && sig.algorithm != "ed25519-sha256" {
+		return nil, fmt.Errorf("unsupported DKIM algorithm %s", sig.algorithm)
+	}
+
+	if c := strings.ToLower(tags["c"]); c != "" {
+		headerCanon, bodyCanon, found := strings.Cut(c, "/")
+		sig.headerCanon = headerCanon
+		if found {
+			sig.bodyCanon = bodyCanon
+		}
+	}
+	for _, canon := range []string{sig.headerCanon, sig.bodyCanon} {
+		if canon != "simple" && canon != "relaxed" {
+			return nil, fmt.Errorf("unsupported DKIM canonicalization %s", canon)
+		}
+	}
+
+	for _, name := range strings.Split(tags["h"], ":") {
+		if name = strings.TrimSpace(name); name != "" {
+			sig.headers = append(sig.headers, name)
+		}
+	}
+	if !containsFold(sig.headers, "From") {
+		return nil, fmt.Errorf("DKIM signature does not cover the From header")
+	}
+
+	if sig.bodyHash, err = base64.StdEncoding.DecodeString(stripWhitespace(tags["bh"])); err != nil {
+		return nil, fmt.Errorf("invalid DKIM body hash: %v", err)
+	}
+	if sig.signature, err = base64.StdEncoding.DecodeString(stripWhitespace(tags["b"])); err != nil {
+		return nil, fmt.Errorf("invalid DKIM signature data: %v", err)
+	}
+
+	if l, ok := tags["l"]; ok {
+		if sig.bodyLength, err = strconv.ParseInt(l, 10, 64); err != nil || sig.bodyLength < 0 {
+			return nil, fmt.Errorf("invalid DKIM body length %q", l)
+		}
+		sig.hasBodyLength = true
+	}
+	if x, ok := tags["x"]; ok {
+		if sig.expiration, err = strconv.ParseInt(x, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid DKIM expiration %q", x)
+		}
+		sig.hasExpiration = true
+	}
+
+	return sig, nil
+}
+
+// dkimSignedData returns the canonicalized headers covered by the signature followed by
+// the signature header itself with an empty b= tag
+func dkimSignedData(sig *dkimSignature, headers []rawHeader) string {
+	var b strings.Builder
+
+	// Each listed name takes the bottom-most instance not used yet
+	used := make(map[int]bool)
+	for _, name := range sig.headers {
+		for i := len(headers) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(headers[i].name, name) {
+				continue
+			}
+			used[i] = true
+			b.WriteString(canonicalizeHeader(headers[i], sig.headerCanon))
+			break
+		}
+	}
+
+	name, value, _ := strings.Cut(sig.signatureHeader.raw, ":")
+	unsigned := rawHeader{
+		name: sig.signatureHeader.name,
+		raw:  name + ":" + dkimSignatureValue.ReplaceAllString(value, "$1$2"),
+	}
+	b.WriteString(strings.TrimSuffix(canonicalizeHeader(unsigned, sig.headerCanon), "\r\n"))
+	return b.String()
+}
+
+// canonicalizeHeader applies the simple or relaxed header canonicalization of RFC 6376
+func canonicalizeHeader(h rawHeader, canon string) string {
+	if canon == "simple" {
+		return h.raw
+	}
+
+	name, value, _ := strings.Cut(h.raw, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	value = strings.Join(strings.FieldsFunc(value, isWSP), " ")
+	return strings.ToLower(strings.TrimRight(name, " \t")) + ":" + value + "\r\n"
+}
+
+// canonicalizeBody applies the simple or relaxed body canonicalization of RFC 6376
+func canonicalizeBody(body, canon string) string {
+	lines := strings.Split(body, "\r\n")
+	if canon == "relaxed" {
+		for i, line := range lines {
+			line = strings.TrimRight(line, " \t")
+			lines[i] = strings.Join(splitKeepingLeadingWSP(line), " ")
+		}
+	}
+
+	// Trailing empty lines are ignored
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		if canon == "relaxed" {
+			return ""
+		}
+		return "\r\n"
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// splitKeepingLeadingWSP splits a line on runs of whitespace; a leading run becomes an
+// empty first element so that joining with a single space keeps one space there
+func splitKeepingLeadingWSP(line string) []string {
+	fields := strings.FieldsFunc(line, isWSP)
+	if line != "" && isWSP(rune(line[0])) {
+		fields = append([]string{""}, fields...)
+	}
+	return fields
+}
+
+// splitRawEmail separates the raw header fields of an email from its body. Line endings
+// are normalized to CRLF, as DKIM canonicalization expects.
+func splitRawEmail(email string) ([]rawHeader, string) {
+	email = strings.ReplaceAll(email, "\r\n", "\n")
+	email = strings.ReplaceAll(email, "\n", "\r\n")
+
+	head, body, found := strings.Cut(email, "\r\n\r\n")
+	if !found {
+		head, body = strings.TrimSuffix(email, "\r\n"), ""
+	}
+
+	var headers []rawHeader
+	for _, line := range strings.Split(head, "\r\n") {
+		if line == "" {
+			continue
+		}
+		// Folded lines continue the previous field
+		if isWSP(rune(line[0])) && len(headers) > 0 {
+			headers[len(headers)-1].raw += line + "\r\n"
+			continue
+		}
+		name, _, _ := strings.Cut(line, ":")
+		headers = append(headers, rawHeader{name: strings.TrimSpace(name), raw: line + "\r\n"})
+	}
+	return headers, body
+}
+
+// parseTagList parses a DKIM tag=value list
+func parseTagList(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("malformed tag %q", part)
+		}
+		tags[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+func isWSP(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver answers DNS lookups from fixed records
+type fakeResolver struct {
+	txt map[string][]string
+	ips map[string][]string
+	mx  map[string][]string
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r.ips[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return addrs, nil
+}
+
+func (r *fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	hosts, ok := r.mx[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	mxs := make([]*net.MX, len(hosts))
+	for i, host := range hosts {
+		mxs[i] = &net.MX{Host: host, Pref: uint16(i)}
+	}
+	return mxs, nil
+}
+
+const dkimTestEmail = "Received: from mail.example.com (mail.example.com [192.0.2.10]) by mx.mail2calendar.io\r\n" +
+	"From: Alice <alice@example.com>\r\n" +
+	"To: calendar@mail2calendar.io\r\n" +
+	"Subject: Planning\r\n" +
+	"   meeting\r\n" +
+	"Date: Mon, 3 Mar 2025 08:00:00 +0700\r\n" +
+	"\r\n" +
+	"Planning  meeting tomorrow at 2pm.  \r\n" +
+	"\r\n" +
+	"\r\n"
+
+// signDKIM prepends a DKIM-Signature of example.com made with signer to email
+func signDKIM(t *testing.T, email, algorithm, canon string, signer crypto.Signer) string {
+	t.Helper()
+	return signDKIMForDomain(t, email, "example.com", algorithm, canon, signer)
+}
+
+// signDKIMForDomain prepends a DKIM-Signature of domain made with signer to email
+func signDKIMForDomain(t *testing.T, email, domain, algorithm, canon string, signer crypto.Signer) string {
+	t.Helper()
+
+	headers, body := splitRawEmail(email)
+	headerCanon, bodyCanon, _ := strings.Cut(canon, "/")
+	bodyHash := sha256.Sum256([]byte(canonicalizeBody(body, bodyCanon)))
+
+	value := " v=1; a=" + algorithm + "; c=" + canon + "; d=" + domain + "; s=sel;\r\n" +
+		"\th=From:To:Subject:Date; bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) + ";\r\n" +
+		"\tb="
+	sig := &dkimSignature{
+		headerCanon:     headerCanon,
+		headers:         []string{"From", "To", "Subject", "Date"},
+		signatureHeader: rawHeader{name: dkimSignatureHeader, raw: dkimSignatureHeader + ":" + value + "\r\n"},
+	}
+	hashed := sha256.Sum256([]byte(dkimSignedData(sig, headers)))
+
+	var opts crypto.SignerOpts = crypto.SHA256
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		opts = crypto.Hash(0)
+	}
+	signature, err := signer.Sign(rand.Reader, hashed[:], opts)
+	require.NoError(t, err)
+
+	return dkimSignatureHeader + ":" + value + base64.StdEncoding.EncodeToString(signature) + "\r\n" + email
+}
+
+func newDKIMTestValidator(t *testing.T, keyRecord string) *emailValidatorImpl {
+	t.Helper()
+	resolver := &fakeResolver{txt: map[string][]string{"sel._domainkey.example.com": {keyRecord}}}
+	return NewEmailValidator(nil, WithDNSResolver(resolver)).(*emailValidatorImpl)
+}
+
+func TestEmailValidator_ValidateDKIM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	rsaRecord := "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edRecord := "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(edPub)
+
+	tests := []struct {
+		name      string
+		record    string
+		algorithm string
+		canon     string
+		signer    crypto.Signer
+	}{
+		{name: "rsa relaxed", record: rsaRecord, algorithm: "rsa-sha256", canon: "relaxed/relaxed", signer: rsaKey},
+		{name: "rsa simple", record: rsaRecord, algorithm: "rsa-sha256", canon: "simple/simple", signer: rsaKey},
+		{name: "ed25519", record: edRecord, algorithm: "ed25519-sha256", canon: "relaxed/simple", signer: edKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newDKIMTestValidator(t, tt.record)
+			signed := signDKIM(t, dkimTestEmail, tt.algorithm, tt.canon, tt.signer)
+
+			assert.NoError(t, v.ValidateDKIM(signed))
+
+			// Line endings rewritten in transit do not break the signature
+			assert.NoError(t, v.ValidateDKIM(strings.ReplaceAll(signed, "\r\n", "\n")))
+
+			tamperedBody := strings.Replace(signed, "2pm", "3pm", 1)
+			assert.ErrorContains(t, v.ValidateDKIM(tamperedBody), "body hash")
+
+			tamperedHeader := strings.Replace(signed, "Subject: Planning", "Subject: Cancelled", 1)
+			assert.ErrorContains(t, v.ValidateDKIM(tamperedHeader), "invalid")
+
+			// A From header added on top is the one that counts
+			prepended := "From: mallory@example.net\r\n" + signed
+			assert.Error(t, v.ValidateDKIM(prepended))
+		})
+	}
+}
+
+func TestEmailValidator_ValidateDKIM_Failures(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signed := signDKIM(t, dkimTestEmail, "rsa-sha256", "relaxed/relaxed", rsaKey)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&otherKey.PublicKey)
+	require.NoError(t, err)
+
+	v := newDKIMTestValidator(t, "v=DKIM1; p="+base64.StdEncoding.EncodeToString(der))
+	assert.ErrorContains(t, v.ValidateDKIM(signed), "invalid")
+
+	v = newDKIMTestValidator(t, "v=DKIM1; p=")
+	assert.ErrorContains(t, v.ValidateDKIM(signed), "revoked")
+
+	v = NewEmailValidator(nil, WithDNSResolver(&fakeResolver{})).(*emailValidatorImpl)
+	assert.ErrorContains(t, v.ValidateDKIM(signed), "lookup")
+
+	assert.ErrorIs(t, v.ValidateDKIM(dkimTestEmail), ErrNoDKIMSignature)
+}
+
+func TestEmailValidator_ValidateDKIM_Alignment(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	record := "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+
+	resolver := &fakeResolver{txt: map[string][]string{
+		"sel._domainkey.evil.com":         {record},
+		"sel._domainkey.mail.example.com": {record},
+		"sel._domainkey.example.co.uk":    {record},
+	}}
+	v := NewEmailValidator(nil, WithDNSResolver(resolver)).(*emailValidatorImpl)
+
+	// A valid signature of the attacker's own domain doesn't vouch for example.com
+	spoofed := signDKIMForDomain(t, dkimTestEmail, "evil.com", "rsa-sha256", "relaxed/relaxed", rsaKey)
+	assert.EqualError(t, v.ValidateDKIM(spoofed), "DKIM signature domain evil.com does not match From domain example.com")
+
+	// Subdomains of the From domain are aligned with it
+	subdomain := signDKIMForDomain(t, dkimTestEmail, "mail.example.com", "rsa-sha256", "relaxed/relaxed", rsaKey)
+	assert.NoError(t, v.ValidateDKIM(subdomain))
+
+	// Sharing a public suffix is not enough
+	fromCoUK := strings.Replace(dkimTestEmail, "alice@example.com", "alice@other.co.uk", 1)
+	publicSuffix := signDKIMForDomain(t, fromCoUK, "example.co.uk", "rsa-sha256", "relaxed/relaxed", rsaKey)
+	assert.ErrorContains(t, v.ValidateDKIM(publicSuffix), "does not match From domain other.co.uk")
+}
+
+func TestDomainsAligned(t *testing.T) {
+	assert.True(t, domainsAligned("example.com", "EXAMPLE.com"))
+	assert.True(t, domainsAligned("mail.example.com", "example.com"))
+	assert.True(t, domainsAligned("example.com", "news.example.com."))
+	assert.False(t, domainsAligned("evil.com", "example.com"))
+	assert.False(t, domainsAligned("example.com.evil.com", "example.com"))
+	assert.False(t, domainsAligned("a.co.uk", "b.co.uk"))
+}
+
+// The canonicalization examples of RFC 6376 section 3.4.6
+func TestDKIMCanonicalization(t *testing.T) {
+	headers, body := splitRawEmail("A: X\r\nB : Y\t\r\n\tZ  \r\n\r\n C \r\nD \t E\r\n\r\n\r\n")
+
+	var relaxed, simple strings.Builder
+	for _, h := range headers {
+		relaxed.WriteString(canonicalizeHeader(h, "relaxed"))
+		simple.WriteString(canonicalizeHeader(h, "simple"))
+	}
+	assert.Equal(t, "a:X\r\nb:Y Z\r\n", relaxed.String())
+	assert.Equal(t, "A: X\r\nB : Y\t\r\n\tZ  \r\n", simple.String())
+
+	assert.Equal(t, " C\r\nD E\r\n", canonicalizeBody(body, "relaxed"))
+	assert.Equal(t, " C \r\nD \t E\r\n", canonicalizeBody(body, "simple"))
+
+	assert.Equal(t, "\r\n", canonicalizeBody("", "simple"))
+	assert.Equal(t, "", canonicalizeBody("\r\n\r\n", "relaxed"))
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+)
+
+// replyAttributionPattern matches the line introducing a quoted reply, such as "On Mon,
+// 3 Mar 2025 at 10:00, Alice <alice@example.com> wrote:" or Gmail's Vietnamese "Vào
+// ... đã viết:"
+var replyAttributionPattern = regexp.MustCompile(`(?i)^\s*(?:on\s.*\swrote|vào\s.*\sđã viết):\s*$`)
+
+// originalMessagePattern matches the separator Outlook puts above a quoted reply
+var originalMessagePattern = regexp.MustCompile(`(?i)^\s*-{2,}\s*original message\s*-{2,}\s*$`)
+
+// stripReplyAndSignature returns the text an email adds itself: quoted lines starting
+// with ">" are dropped, and the text stops at the attribution of a quoted reply chain
+// or at the "-- " signature delimiter. Text made only of quotes is returned as is.
+func stripReplyAndSignature(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var kept []string
+scan:
+	for i, line := range lines {
+		switch {
+		case strings.TrimRight(line, " \t") == "--":
+			// Mail clients often drop the trailing space of the "-- " delimiter
+			break scan
+		case replyAttributionPattern.MatchString(line), originalMessagePattern.MatchString(line):
+			break scan
+		case i+1 < len(lines) && replyAttributionPattern.MatchString(line+" "+lines[i+1]):
+			// Long attributions are wrapped before "wrote:"
+			break scan
+		case strings.HasPrefix(strings.TrimLeft(line, " \t"), ">"):
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	stripped := strings.TrimSpace(strings.Join(kept, "\n"))
+	if stripped == "" {
+		return strings.TrimSpace(text)
+	}
+	return stripped
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripReplyAndSignature(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name: "quoted reply chain",
+			text: "Sounds good, see you Thursday at 10:00 in Room 4.\n\n" +
+				"On Mon, 3 Mar 2025 at 09:12, Alice <alice@example.com> wrote:\n" +
+				"> Can we meet this week?\n" +
+				"> Alice",
+			expected: "Sounds good, see you Thursday at 10:00 in Room 4.",
+		},
+		{
+			name: "attribution wrapped over two lines",
+			text: "Thursday works.\r\n\r\n" +
+				"On Mon, 3 Mar 2025 at 09:12, Alice Nguyen <alice.nguyen@example.com>\r\n" +
+				"wrote:\r\n" +
+				"> Can we meet this week?",
+			expected: "Thursday works.",
+		},
+		{
+			name: "signature block",
+			text: "Project review on Friday at 14:00.\n" +
+				"Agenda attached.\n" +
+				"-- \n" +
+				"Bob Tran\n" +
+				"Engineering Manager | +84 912 345 678",
+			expected: "Project review on Friday at 14:00.\nAgenda attached.",
+		},
+		{
+			name:     "signature delimiter without trailing space",
+			text:     "Standup moved to 9:30.\n--\nBob",
+			expected: "Standup moved to 9:30.",
+		},
+		{
+			name: "inline quotes are dropped",
+			text: "> Does 3pm work?\n" +
+				"Yes, 3pm in the lobby.\n" +
+				"  > and bring the slides\n" +
+				"Will do.",
+			expected: "Yes, 3pm in the lobby.\nWill do.",
+		},
+		{
+			name:     "outlook original message",
+			text:     "Confirmed.\n\n-----Original Message-----\nFrom: Alice\nSubject: Sync",
+			expected: "Confirmed.",
+		},
+		{
+			name:     "vietnamese attribution",
+			text:     "Hẹn gặp lúc 10h.\n\nVào Th 2, 3 thg 3, 2025 lúc 09:12 Alice <alice@example.com> đã viết:\n> Họp nhé?",
+			expected: "Hẹn gặp lúc 10h.",
+		},
+		{
+			name:     "only quotes keep the text",
+			text:     "> Meeting at 10:00\n> Room 4",
+			expected: "> Meeting at 10:00\n> Room 4",
+		},
+		{
+			name:     "dashes in the text are kept",
+			text:     "Options -- A or B -- to decide on Monday.",
+			expected: "Options -- A or B -- to decide on Monday.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stripReplyAndSignature(tt.text))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_DescriptionWithoutReplyAndSignature(t *testing.T) {
+	start := time.Date(2025, 3, 6, 10, 0, 0, 0, time.UTC)
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("Room 4", nil)
+
+	body := "See you Thursday at 10:00 in Room 4.\r\n" +
+		"-- \r\n" +
+		"Bob Tran\r\n" +
+		"\r\n" +
+		"On Mon, 3 Mar 2025 at 09:12, Alice <alice@example.com> wrote:\r\n" +
+		"> Can we meet this week?"
+	emailContent := "From: bob@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Re: Meeting this week\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" + body
+
+	event, err := NewEmailProcessorImpl(new(mockEmailValidator), ner).ProcessEmail(context.Background(), emailContent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "See you Thursday at 10:00 in Room 4.", event.Description)
+	assert.Contains(t, event.OriginalText, "Bob Tran")
+	assert.Contains(t, event.OriginalText, "> Can we meet this week?")
+}
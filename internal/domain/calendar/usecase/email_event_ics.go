@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// icsProductID identifies mail2calendar as the producer of exported calendars
+const icsProductID = "-//mail2calendar//Email Event Export//EN"
+
+// icsUIDDomain qualifies the UIDs of exported events
+const icsUIDDomain = "mail2calendar"
+
+// icsLocalTimeFormat is the DATE-TIME format of values with a TZID
+const icsLocalTimeFormat = "20060102T150405"
+
+// ToICS exports the event as an iCalendar (RFC 5545) file with a single VEVENT.
+// Timed events with a known TimeZone use DTSTART/DTEND with TZID and include the
+// matching VTIMEZONE; other timed events are written in UTC. All-day events use
+// VALUE=DATE.
+func (e *EmailEvent) ToICS() ([]byte, error) {
+	if e.StartTime.IsZero() {
+		return nil, errors.New("event has no start time")
+	}
+	if !e.EndTime.IsZero() && e.EndTime.Before(e.StartTime) {
+		return nil, errors.New("event ends before it starts")
+	}
+
+	var loc *time.Location
+	if e.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(e.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid event time zone %q: %w", e.TimeZone, err)
+		}
+	}
+
+	cal := ical.NewCalendar()
+	cal.SetProductId(icsProductID)
+	cal.SetMethod(ical.MethodPublish)
+
+	event := ical.NewEvent(e.icsUID())
+	event.SetDtStampTime(time.Now())
+	switch {
+	case e.IsAllDay:
+		start, end := e.StartTime, e.EndTime
+		if loc != nil {
+			start, end = start.In(loc), end.In(loc)
+		}
+		// EndTime is the exclusive end day; an event without one lasts its start day
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+		event.SetAllDayStartAt(start)
+		event.SetAllDayEndAt(end)
+	case loc != nil:
+		cal.AddVTimezone(icsTimezone(loc, e.StartTime))
+		event.SetProperty(ical.ComponentPropertyDtStart, e.StartTime.In(loc).Format(icsLocalTimeFormat), ical.WithTZID(e.TimeZone))
+		if !e.EndTime.IsZero() {
+			event.SetProperty(ical.ComponentPropertyDtEnd, e.EndTime.In(loc).Format(icsLocalTimeFormat), ical.WithTZID(e.TimeZone))
+		}
+	default:
+		event.SetStartAt(e.StartTime)
+		if !e.EndTime.IsZero() {
+			event.SetEndAt(e.EndTime)
+		}
+	}
+
+	event.SetSummary(e.Subject)
+	if e.Location != "" {
+		event.SetLocation(e.Location)
+	}
+	if e.Description != "" {
+		event.SetDescription(e.Description)
+	}
+	if e.Organizer != "" {
+		event.SetOrganizer(e.Organizer)
+	}
+	for _, attendee := range e.Attendees {
+		event.AddAttendee(attendee,
+			ical.ParticipationRoleReqParticipant,
+			ical.ParticipationStatusNeedsAction,
+			ical.WithRSVP(true),
+		)
+	}
+	if rule := strings.TrimPrefix(e.RecurrenceRule, "RRULE:"); rule != "" {
+		event.AddRrule(rule)
+	}
+	cal.AddVEvent(event)
+
+	var b strings.Builder
+	if err := cal.SerializeTo(&b, ical.WithNewLine("\r\n")); err != nil {
+		return nil, fmt.Errorf("failed to serialize calendar: %w", err)
+	}
+	return []byte(b.String()), nil
+}
+
+// icsUID derives a stable UID from the email and the event start, so that exporting
+// the same email twice updates the imported event instead of duplicating it
+func (e *EmailEvent) icsUID() string {
+	source := e.Metadata.MessageID
+	if source == "" {
+		source = e.Subject + "\x00" + e.Organizer
+	}
+	sum := sha256.Sum256([]byte(source + "\x00" + e.StartTime.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:16]) + "@" + icsUIDDomain
+}
+
+// icsTimezone describes loc as a VTIMEZONE. Zones with daylight saving time get a
+// yearly STANDARD and DAYLIGHT observance built from their transitions in the year
+// of at; other zones get a single STANDARD observance.
+func icsTimezone(loc *time.Location, at time.Time) *ical.VTimezone {
+	tz := ical.NewTimezone(loc.String())
+
+	yearStart := time.Date(at.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	transitions := zoneTransitions(yearStart, yearStart.AddDate(1, 0, 0))
+	if len(transitions) == 0 {
+		name, offset := at.In(loc).Zone()
+		observance := tz.AddStandard()
+		observance.SetProperty(ical.ComponentPropertyDtStart, "19700101T000000")
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzoffsetfrom), icsUTCOffset(offset))
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzoffsetto), icsUTCOffset(offset))
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzname), name)
+		return tz
+	}
+
+	for _, transition := range transitions {
+		_, offsetFrom := transition.Add(-time.Second).Zone()
+		name, offsetTo := transition.Zone()
+
+		var observance *ical.ComponentBase
+		if transition.IsDST() {
+			daylight := &ical.Daylight{}
+			tz.Components = append(tz.Components, daylight)
+			observance = &daylight.ComponentBase
+		} else {
+			observance = &tz.AddStandard().ComponentBase
+		}
+
+		// DTSTART is the local time of the transition before it takes effect
+		before := transition.In(time.FixedZone(name, offsetFrom))
+		observance.SetProperty(ical.ComponentPropertyDtStart, before.Format(icsLocalTimeFormat))
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzoffsetfrom), icsUTCOffset(offsetFrom))
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzoffsetto), icsUTCOffset(offsetTo))
+		observance.SetProperty(ical.ComponentProperty(ical.PropertyTzname), name)
+		observance.AddRrule(yearlyTransitionRule(before))
+	}
+	return tz
+}
+
+// zoneTransitions returns the instants in [from, to) at which the UTC offset of the
+// location of from changes
+func zoneTransitions(from, to time.Time) []time.Time {
+	var transitions []time.Time
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, before := day.Zone()
+		_, after := next.Zone()
+		if before == after {
+			continue
+		}
+
+		// Narrow the transition down to the second
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, offset := mid.Zone(); offset == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		transitions = append(transitions, hi)
+	}
+	return transitions
+}
+
+// yearlyTransitionRule describes a transition, given as its local time, as the nth
+// (or last) weekday of its month, the way time zone rules are written
+func yearlyTransitionRule(local time.Time) string {
+	week := (local.Day()-1)/7 + 1
+	if local.AddDate(0, 0, 7).Month() != local.Month() {
+		week = -1
+	}
+	weekday := strings.ToUpper(local.Weekday().String()[:2])
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", local.Month(), week, weekday)
+}
+
+// icsUTCOffset formats an offset in seconds as the ±hhmm of TZOFFSETFROM/TZOFFSETTO
+func icsUTCOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset/60%60)
+}
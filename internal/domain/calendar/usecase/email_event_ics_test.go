@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseExportedICS(t *testing.T, data []byte) (*ical.Calendar, *ical.VEvent) {
+	t.Helper()
+	cal, err := ical.ParseCalendar(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, cal.Events(), 1)
+	return cal, cal.Events()[0]
+}
+
+func TestEmailEvent_ToICS(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, loc)
+
+	event := &EmailEvent{
+		Subject:        "Planning, Q2",
+		Description:    "Agenda:\n1. Budget; 2. Hiring",
+		StartTime:      start,
+		EndTime:        start.Add(90 * time.Minute),
+		TimeZone:       "Europe/Berlin",
+		Location:       "Room 4, Building B",
+		RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+		Organizer:      "organizer@example.com",
+		Attendees:      []string{"alice@example.com", "bob@example.com"},
+		Metadata:       EmailMetadata{MessageID: "<planning@example.com>"},
+	}
+
+	data, err := event.ToICS()
+	require.NoError(t, err)
+	cal, parsed := parseExportedICS(t, data)
+
+	assert.Contains(t, string(data), "DTSTART;TZID=Europe/Berlin:20250310T140000")
+	assert.Contains(t, string(data), "DTEND;TZID=Europe/Berlin:20250310T153000")
+
+	gotStart, err := parsed.GetStartAt()
+	require.NoError(t, err)
+	assert.True(t, start.Equal(gotStart))
+	gotEnd, err := parsed.GetEndAt()
+	require.NoError(t, err)
+	assert.True(t, event.EndTime.Equal(gotEnd))
+
+	assert.Equal(t, "Planning, Q2", parsed.GetProperty(ical.ComponentPropertySummary).Value)
+	assert.Equal(t, "Agenda:\n1. Budget; 2. Hiring", parsed.GetProperty(ical.ComponentPropertyDescription).Value)
+	assert.Equal(t, "Room 4, Building B", parsed.GetProperty(ical.ComponentPropertyLocation).Value)
+	assert.Equal(t, "mailto:organizer@example.com", parsed.GetProperty(ical.ComponentPropertyOrganizer).Value)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", parsed.GetProperty(ical.ComponentPropertyRrule).Value)
+	assert.NotEmpty(t, parsed.Id())
+
+	var attendees []string
+	for _, attendee := range parsed.Attendees() {
+		attendees = append(attendees, attendee.Email())
+		assert.Equal(t, ical.ParticipationStatusNeedsAction, attendee.ParticipationStatus())
+	}
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, attendees)
+
+	// Europe/Berlin observes daylight saving time: CET from the last Sunday of October,
+	// CEST from the last Sunday of March
+	require.Len(t, cal.Timezones(), 1)
+	timezone := cal.Timezones()[0]
+	assert.Equal(t, "Europe/Berlin", timezone.GetProperty(ical.ComponentPropertyTzid).Value)
+	require.Len(t, timezone.SubComponents(), 2)
+	ics := string(data)
+	assert.Contains(t, ics, "BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nEND:DAYLIGHT")
+	assert.Contains(t, ics, "BEGIN:STANDARD\r\nDTSTART:20251026T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nEND:STANDARD")
+
+	again, err := event.ToICS()
+	require.NoError(t, err)
+	_, reparsed := parseExportedICS(t, again)
+	assert.Equal(t, parsed.Id(), reparsed.Id(), "UID must be stable across exports")
+}
+
+func TestEmailEvent_ToICS_AllDay(t *testing.T) {
+	event := &EmailEvent{
+		Subject:   "Company offsite",
+		StartTime: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+		IsAllDay:  true,
+	}
+
+	data, err := event.ToICS()
+	require.NoError(t, err)
+	cal, parsed := parseExportedICS(t, data)
+
+	assert.Contains(t, string(data), "DTSTART;VALUE=DATE:20250310")
+	assert.Contains(t, string(data), "DTEND;VALUE=DATE:20250312")
+	assert.Empty(t, cal.Timezones())
+
+	start, err := parsed.GetAllDayStartAt()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-10", start.Format("2006-01-02"))
+	end, err := parsed.GetAllDayEndAt()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-12", end.Format("2006-01-02"))
+
+	event.EndTime = time.Time{}
+	data, err = event.ToICS()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "DTEND;VALUE=DATE:20250311")
+}
+
+func TestEmailEvent_ToICS_UTCAndFixedZone(t *testing.T) {
+	start := time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC)
+
+	data, err := (&EmailEvent{Subject: "Sync", StartTime: start, EndTime: start.Add(time.Hour)}).ToICS()
+	require.NoError(t, err)
+	cal, parsed := parseExportedICS(t, data)
+	assert.Contains(t, string(data), "DTSTART:20250310T070000Z")
+	assert.Empty(t, cal.Timezones())
+	assert.Empty(t, parsed.Attendees())
+	assert.Nil(t, parsed.GetProperty(ical.ComponentPropertyRrule))
+
+	data, err = (&EmailEvent{Subject: "Sync", StartTime: start, EndTime: start.Add(time.Hour), TimeZone: "Asia/Ho_Chi_Minh"}).ToICS()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "DTSTART;TZID=Asia/Ho_Chi_Minh:20250310T140000")
+	assert.True(t, strings.Contains(string(data), "BEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nTZOFFSETFROM:+0700\r\nTZOFFSETTO:+0700\r\nTZNAME:+07\r\nEND:STANDARD"))
+}
+
+func TestEmailEvent_ToICS_Invalid(t *testing.T) {
+	start := time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC)
+
+	_, err := (&EmailEvent{Subject: "Sync"}).ToICS()
+	assert.Error(t, err)
+
+	_, err = (&EmailEvent{Subject: "Sync", StartTime: start, EndTime: start.Add(-time.Hour)}).ToICS()
+	assert.Error(t, err)
+
+	_, err = (&EmailEvent{Subject: "Sync", StartTime: start, TimeZone: "Mars/Olympus_Mons"}).ToICS()
+	assert.Error(t, err)
+}
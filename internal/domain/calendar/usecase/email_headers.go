@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// headerMetadataPrefix namespaces email headers stored in event metadata
+const headerMetadataPrefix = "email_header."
+
+// maxHeaderValueLength follows the RFC 5322 line length limit
+const maxHeaderValueLength = 998
+
+// capturedHeaders lists the email headers kept with an event when header capture is enabled
+var capturedHeaders = []string{"Message-ID", "From", "Date", "Subject"}
+
+// sanitizeHeaders returns the captured headers with encoded words decoded,
+// control characters removed and values truncated. Empty headers are omitted.
+func sanitizeHeaders(header mail.Header) map[string]string {
+	decoder := new(mime.WordDecoder)
+	headers := make(map[string]string, len(capturedHeaders))
+	for _, name := range capturedHeaders {
+		value := header.Get(name)
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+
+		value = strings.Map(func(r rune) rune {
+			if r == '\r' || r == '\n' || r == '\t' {
+				return ' '
+			}
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, value)
+		value = strings.TrimSpace(value)
+
+		if runes := []rune(value); len(runes) > maxHeaderValueLength {
+			value = string(runes[:maxHeaderValueLength])
+		}
+		if value != "" {
+			headers[name] = value
+		}
+	}
+
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// headersToMetadata prefixes header names so they can share a metadata map with other keys
+func headersToMetadata(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(headers))
+	for name, value := range headers {
+		metadata[headerMetadataPrefix+name] = value
+	}
+	return metadata
+}
+
+// headersFromMetadata extracts the email headers stored by headersToMetadata
+func headersFromMetadata(metadata map[string]string) map[string]string {
+	var headers map[string]string
+	for key, value := range metadata {
+		name, found := strings.CutPrefix(key, headerMetadataPrefix)
+		if !found {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	return headers
+}
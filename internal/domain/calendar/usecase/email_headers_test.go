@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const headerTestEmail = "Message-ID: <abc123@example.com>\r\n" +
+	"From: Sender <sender@example.com>\r\n" +
+	"To: recipient@example.com\r\n" +
+	"Date: Wed, 05 Feb 2025 09:00:00 +0000\r\n" +
+	"Subject: =?UTF-8?Q?H=E1=BB=8Dp_team?=\r\n" +
+	"X-Internal-Token: secret\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Let's meet tomorrow at 2pm."
+
+func TestEmailProcessorImpl_ProcessEmail_Headers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []EmailProcessorOption
+		expected map[string]string
+	}{
+		{
+			name: "headers stored when enabled",
+			opts: []EmailProcessorOption{WithRawHeaders(true)},
+			expected: map[string]string{
+				"Message-ID": "<abc123@example.com>",
+				"From":       "Sender <sender@example.com>",
+				"Date":       "Wed, 05 Feb 2025 09:00:00 +0000",
+				"Subject":    "Họp team",
+			},
+		},
+		{
+			name:     "headers omitted by default",
+			expected: nil,
+		},
+		{
+			name:     "headers omitted when disabled",
+			opts:     []EmailProcessorOption{WithRawHeaders(false)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			startTime := parseTime("2025-02-06T14:00:00Z")
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+				Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).
+				Return("", nil)
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, tt.opts...)
+			event, err := processor.ProcessEmail(context.Background(), headerTestEmail)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, event.Headers)
+		})
+	}
+}
+
+func TestSanitizeHeaders(t *testing.T) {
+	header := mail.Header{
+		"Subject": {"Hello\x00 \x1bworld"},
+		"From":    {strings.Repeat("a", maxHeaderValueLength+10)},
+	}
+
+	headers := sanitizeHeaders(header)
+	assert.Equal(t, "Hello world", headers["Subject"])
+	assert.Len(t, headers["From"], maxHeaderValueLength)
+	assert.NotContains(t, headers, "Message-ID")
+
+	assert.Nil(t, sanitizeHeaders(mail.Header{}))
+}
+
+func TestToProtoEvent_Headers(t *testing.T) {
+	event := toProtoEvent(&CalendarEvent{
+		ID:      "evt-1",
+		Headers: map[string]string{"Message-ID": "<abc123@example.com>"},
+	})
+	assert.Equal(t, map[string]string{"email_header.Message-ID": "<abc123@example.com>"}, event.Metadata)
+	assert.Equal(t, map[string]string{"Message-ID": "<abc123@example.com>"}, headersFromMetadata(event.Metadata))
+
+	assert.Nil(t, toProtoEvent(&CalendarEvent{ID: "evt-2"}).Metadata)
+}
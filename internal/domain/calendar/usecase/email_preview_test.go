@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+func TestEmailProcessorImpl_ProcessEmailPreview(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	newNER := func() *mockNERService {
+		ner := new(mockNERService)
+		ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+			Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil).Maybe()
+		ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("Room 1", nil).Maybe()
+		return ner
+	}
+
+	t.Run("valid email is previewed", func(t *testing.T) {
+		validator := new(mockEmailValidator)
+		validator.On("ValidateDKIM", headerTestEmail).Return(nil)
+		validator.On("ValidateSPF", headerTestEmail).Return(nil)
+		validator.On("ValidateSender", headerTestEmail).Return(nil)
+
+		processor := NewEmailProcessorImpl(validator, newNER())
+
+		event, err := processor.ProcessEmailPreview(context.Background(), headerTestEmail)
+		require.NoError(t, err)
+		assert.Equal(t, startTime, event.StartTime)
+		assert.Equal(t, startTime.Add(time.Hour), event.EndTime)
+		assert.Equal(t, "Room 1", event.Location)
+
+		// Validation runs even though it is not required for ProcessEmail
+		validator.AssertExpectations(t)
+	})
+
+	t.Run("spoofed email is rejected", func(t *testing.T) {
+		validator := new(mockEmailValidator)
+		validator.On("ValidateDKIM", headerTestEmail).Return(fmt.Errorf("signature invalid"))
+		ner := newNER()
+
+		processor := NewEmailProcessorImpl(validator, ner)
+
+		event, err := processor.ProcessEmailPreview(context.Background(), headerTestEmail)
+		assert.Nil(t, event)
+		assert.True(t, calerrors.IsInvalidEmail(err))
+		ner.AssertNotCalled(t, "ExtractDateTime", mock.Anything, mock.Anything)
+	})
+}
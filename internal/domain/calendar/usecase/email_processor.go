@@ -7,6 +7,14 @@ import (
 // EmailProcessor defines interface for processing emails into calendar events
 type EmailProcessor interface {
 	ProcessEmail(ctx context.Context, emailContent string) (*EmailEvent, error)
+	// ProcessEmailMulti returns one event per meeting found in the email, such as the
+	// items of a daily agenda. Emails with a single meeting yield one event.
+	ProcessEmailMulti(ctx context.Context, emailContent string) ([]*EmailEvent, error)
+	// ProcessEmailPreview validates the email and extracts its event like ProcessEmail,
+	// so the user can review it before anything is created. Validation always runs,
+	// whether or not it is required for ProcessEmail. Nothing is written to a calendar
+	// or the queue.
+	ProcessEmailPreview(ctx context.Context, emailContent string) (*EmailEvent, error)
 	ValidateEmail(ctx context.Context, emailContent string) error
 }
 
@@ -2,14 +2,23 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"mime"
 	"mime/multipart"
+	"net/http"
 	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	"mail2calendar/internal/domain/calendar/service"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -24,51 +33,217 @@ type EmailContent struct {
 	Links       []string
 }
 
+// defaultMaxBodyAttendees caps how many attendees are resolved from mentions in the email body
+const defaultMaxBodyAttendees = 10
+
+// defaultEventDuration is used when an email gives a start time but no end time
+const defaultEventDuration = time.Hour
+
+// ErrNoEventDates is returned when no date is found in an email and the processor is
+// configured with MissingDatesSkip. Callers should ask the sender for clarification.
+var ErrNoEventDates = errors.New("no event dates found in email")
+
+// MissingDatesPolicy decides what the processor does with an email without any date
+type MissingDatesPolicy int
+
+const (
+	// MissingDatesUseNow schedules the event from now for the default duration
+	MissingDatesUseNow MissingDatesPolicy = iota
+	// MissingDatesSkip rejects the email with ErrNoEventDates
+	MissingDatesSkip
+)
+
+// hrefPattern matches an href attribute quoted with double or single quotes, capturing
+// its value in the first or second group
+var hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// baseTagPattern matches a <base> element, whose href relative links resolve against
+var baseTagPattern = regexp.MustCompile(`(?i)<base\b[^>]*>`)
+
+// anchorPattern matches an <a> element, capturing its attributes and its content
+var anchorPattern = regexp.MustCompile(`(?is)<a\b([^>]*)>(.*?)</a\s*>`)
+
+// bodyEmailPattern matches email addresses mentioned in the email body
+var bodyEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
 // emailProcessorImpl implements EmailProcessor interface with monitoring
 type emailProcessorImpl struct {
-	tracer     trace.Tracer
-	validator  EmailValidator
-	nerService NERService
+	tracer            trace.Tracer
+	validator         EmailValidator
+	nerService        NERService
+	includeHeaders    bool
+	maxBodyAttendees  int
+	maxAttendees      int
+	serviceAddresses  map[string]struct{}
+	normalizeLocation bool
+	missingDates      MissingDatesPolicy
+	eventDuration     time.Duration
+	tzUtil            *TimezoneUtil
+	requireValidation bool
+	metrics           *Metrics
+}
+
+// EmailProcessorOption configures optional behaviour of the email processor
+type EmailProcessorOption func(*emailProcessorImpl)
+
+// WithRawHeaders controls whether sanitized email headers are attached to extracted events
+func WithRawHeaders(enabled bool) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		ep.includeHeaders = enabled
+	}
+}
+
+// WithMaxBodyAttendees limits how many attendees are resolved from the email body.
+// A value of zero disables body resolution; negative values keep the default.
+func WithMaxBodyAttendees(max int) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		if max >= 0 {
+			ep.maxBodyAttendees = max
+		}
+	}
+}
+
+// WithMaxAttendees caps the total number of attendees on an extracted event.
+// A value of zero drops all attendees; negative values keep the default.
+func WithMaxAttendees(max int) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		if max >= 0 {
+			ep.maxAttendees = max
+		}
+	}
+}
+
+// WithServiceAddresses adds addresses, such as the service's own inbox, that are
+// never invited to extracted events
+func WithServiceAddresses(addrs ...string) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		if ep.serviceAddresses == nil {
+			ep.serviceAddresses = make(map[string]struct{}, len(addrs))
+		}
+		for _, addr := range addrs {
+			if addr = normalizeAddress(addr); addr != "" {
+				ep.serviceAddresses[addr] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithLocationNormalization controls whether extracted locations are trimmed, stripped
+// of trailing punctuation and title-cased before the event is created
+func WithLocationNormalization(enabled bool) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		ep.normalizeLocation = enabled
+	}
+}
+
+// WithMissingDatesPolicy sets what happens to emails in which no date is found.
+// The default is MissingDatesUseNow.
+func WithMissingDatesPolicy(policy MissingDatesPolicy) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		ep.missingDates = policy
+	}
+}
+
+// WithDefaultEventDuration sets the duration of events whose end time is not given and
+// whose email states no length, as in "for 45 minutes". Non-positive values keep the
+// default of one hour.
+func WithDefaultEventDuration(d time.Duration) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		if d > 0 {
+			ep.eventDuration = d
+		}
+	}
+}
+
+// WithRequireValidation makes ProcessEmail and ProcessEmailMulti run ValidateEmail first
+// and reject emails that fail it with an InvalidEmail calendar error. Off by default.
+func WithRequireValidation(enabled bool) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		ep.requireValidation = enabled
+	}
+}
+
+// WithProcessorMetrics records the outcome and latency of ProcessEmail and
+// ProcessEmailMulti into metrics. Previews are not recorded.
+func WithProcessorMetrics(metrics *Metrics) EmailProcessorOption {
+	return func(ep *emailProcessorImpl) {
+		ep.metrics = metrics
+	}
 }
 
 // NewEmailProcessorImpl creates a new instance of EmailProcessor with monitoring
-func NewEmailProcessorImpl(validator EmailValidator, nerService NERService) EmailProcessor {
-	return &emailProcessorImpl{
-		tracer:     otel.Tracer("email-processor"),
-		validator:  validator,
-		nerService: nerService,
+func NewEmailProcessorImpl(validator EmailValidator, nerService NERService, opts ...EmailProcessorOption) EmailProcessor {
+	ep := &emailProcessorImpl{
+		tracer:           otel.Tracer("email-processor"),
+		validator:        validator,
+		nerService:       nerService,
+		maxBodyAttendees: defaultMaxBodyAttendees,
+		maxAttendees:     defaultMaxAttendees,
+		eventDuration:    defaultEventDuration,
+		tzUtil:           NewTimezoneUtil(defaultTimezone),
 	}
+
+	for _, opt := range opts {
+		opt(ep)
+	}
+
+	return ep
 }
 
 func (ep *emailProcessorImpl) ProcessEmail(ctx context.Context, emailContent string) (*EmailEvent, error) {
 	ctx, span := ep.tracer.Start(ctx, "ProcessEmail")
 	defer span.End()
 
+	start := time.Now()
+	event, err := ep.processEmail(ctx, emailContent, ep.requireValidation)
+	ep.metrics.observeEmail(start, err)
+	return event, err
+}
+
+func (ep *emailProcessorImpl) ProcessEmailPreview(ctx context.Context, emailContent string) (*EmailEvent, error) {
+	ctx, span := ep.tracer.Start(ctx, "ProcessEmailPreview")
+	defer span.End()
+
+	return ep.processEmail(ctx, emailContent, true)
+}
+
+// processEmail extracts the event of an email, after running ValidateEmail when
+// validate is set. It records errors and the event on the span of ctx.
+func (ep *emailProcessorImpl) processEmail(ctx context.Context, emailContent string, validate bool) (*EmailEvent, error) {
+	span := trace.SpanFromContext(ctx)
+
+	if validate {
+		if err := ep.ValidateEmail(ctx, emailContent); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
 	// Parse email
 	msg, err := ep.parseEmail(ctx, emailContent)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to parse email: %v", err)
+		return nil, calerrors.NewParseError("failed to parse email").WithWrappedError(err)
 	}
 
 	// Extract full email content with attachments
 	content, err := ep.extractEmailContent(ctx, msg)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to extract email content: %v", err)
+		return nil, calerrors.NewParseError("failed to extract email content").WithWrappedError(err)
 	}
 
 	// Extract event information using NLP
 	event, err := ep.extractEventInfo(ctx, msg, content)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to extract event info: %v", err)
+		return nil, eventInfoError(err)
 	}
 
 	// Validate event data
 	if err := ep.validateEvent(ctx, event); err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("invalid event data: %v", err)
+		return nil, err
 	}
 
 	span.SetAttributes(
@@ -89,9 +264,10 @@ func (ep *emailProcessorImpl) extractEmailContent(ctx context.Context, msg *mail
 	// Extract metadata
 	content.Metadata = ep.extractMetadata(msg)
 
-	// Parse content based on MIME type
+	// Parse content based on MIME type; the type of undeclared content is sniffed below
 	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
-	if err != nil {
+	declared := err == nil
+	if !declared {
 		mediaType = "text/plain" // Default to plain text
 	}
 
@@ -112,6 +288,10 @@ func (ep *emailProcessorImpl) extractEmailContent(ctx context.Context, msg *mail
 			}
 
 			contentType := part.Header.Get("Content-Type")
+			if contentType == "" && part.FileName() == "" {
+				// Parts without a type are plain text (RFC 2046 section 5.1)
+				contentType = "text/plain"
+			}
 			switch {
 			case strings.HasPrefix(contentType, "text/plain"):
 				content.PlainText = string(partContent)
@@ -134,6 +314,9 @@ func (ep *emailProcessorImpl) extractEmailContent(ctx context.Context, msg *mail
 		// Handle single part messages
 		body, err := io.ReadAll(msg.Body)
 		if err == nil {
+			if !declared && strings.HasPrefix(http.DetectContentType(body), "text/html") {
+				mediaType = "text/html"
+			}
 			if strings.HasPrefix(mediaType, "text/html") {
 				content.HTML = string(body)
 			} else {
@@ -148,26 +331,43 @@ func (ep *emailProcessorImpl) extractEmailContent(ctx context.Context, msg *mail
 	return content, nil
 }
 
+// extractLinks returns the distinct http(s) links of the HTML in order of first
+// appearance. Relative links are resolved against the document's <base href>, and
+// dropped when it has none.
 func (ep *emailProcessorImpl) extractLinks(htmlContent string) []string {
 	// Simple link extraction - can be improved with proper HTML parsing
+	var base *url.URL
+	if tag := baseTagPattern.FindString(htmlContent); tag != "" {
+		if m := hrefPattern.FindStringSubmatch(tag); m != nil {
+			base, _ = url.Parse(html.UnescapeString(m[1] + m[2]))
+		}
+	}
+	// The base href is not a link of its own
+	htmlContent = baseTagPattern.ReplaceAllString(htmlContent, "")
+
 	links := []string{}
-	startIdx := 0
-	for {
-		hrefIdx := strings.Index(htmlContent[startIdx:], "href=\"")
-		if hrefIdx == -1 {
-			break
+	seen := make(map[string]struct{})
+	for _, m := range hrefPattern.FindAllStringSubmatch(htmlContent, -1) {
+		ref, err := url.Parse(strings.TrimSpace(html.UnescapeString(m[1] + m[2])))
+		if err != nil {
+			continue
 		}
-		hrefIdx += startIdx + 6 // len("href=\"")
-		endIdx := strings.Index(htmlContent[hrefIdx:], "\"")
-		if endIdx == -1 {
-			break
+		if !ref.IsAbs() {
+			if base == nil || !base.IsAbs() {
+				continue
+			}
+			ref = base.ResolveReference(ref)
 		}
-		endIdx += hrefIdx
-		link := htmlContent[hrefIdx:endIdx]
-		if strings.HasPrefix(link, "http") {
-			links = append(links, link)
+		if ref.Scheme != "http" && ref.Scheme != "https" {
+			continue
 		}
-		startIdx = endIdx
+
+		link := ref.String()
+		if _, ok := seen[link]; ok {
+			continue
+		}
+		seen[link] = struct{}{}
+		links = append(links, link)
 	}
 	return links
 }
@@ -218,38 +418,71 @@ func (ep *emailProcessorImpl) parseAddressList(addresses string) []*mail.Address
 }
 
 func (ep *emailProcessorImpl) ValidateEmail(ctx context.Context, email string) error {
-	span := trace.SpanFromContext(ctx)
+	_, span := ep.tracer.Start(ctx, "ValidateEmail")
 	defer span.End()
 
 	if err := ep.validator.ValidateDKIM(email); err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("DKIM validation failed: %v", err)
+		return calerrors.NewInvalidEmailError("DKIM validation failed").WithWrappedError(err)
 	}
 
 	if err := ep.validator.ValidateSPF(email); err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("SPF validation failed: %v", err)
+		return calerrors.NewInvalidEmailError("SPF validation failed").WithWrappedError(err)
 	}
 
 	if err := ep.validator.ValidateSender(email); err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("sender validation failed: %v", err)
+		return calerrors.NewInvalidEmailError("sender validation failed").WithWrappedError(err)
 	}
 
 	return nil
 }
 
+// checkValidation rejects emails failing ValidateEmail when validation is required
+func (ep *emailProcessorImpl) checkValidation(ctx context.Context, email string) error {
+	if !ep.requireValidation {
+		return nil
+	}
+	return ep.ValidateEmail(ctx, email)
+}
+
 func (ep *emailProcessorImpl) parseEmail(ctx context.Context, emailContent string) (*mail.Message, error) {
 	span := trace.SpanFromContext(ctx)
 	defer span.End()
 
 	msg, err := mail.ReadMessage(strings.NewReader(emailContent))
-	if err != nil {
+	if err == nil && hasMessageHeaders(msg.Header) {
+		return msg, nil
+	}
+
+	// Text pasted without headers may fail to parse, or have its first line taken for
+	// a header; the whole content is then the body
+	if strings.TrimSpace(emailContent) == "" {
+		if err == nil {
+			err = errors.New("email is empty")
+		}
 		span.RecordError(err)
 		return nil, err
 	}
+	return &mail.Message{
+		Header: mail.Header{},
+		Body:   strings.NewReader(strings.TrimLeft(emailContent, "\r\n")),
+	}, nil
+}
+
+// messageHeaders are header fields of which at least one is present in any real email
+var messageHeaders = []string{"From", "To", "Cc", "Subject", "Date", "Message-Id", "Mime-Version", "Content-Type", "Received", "Sender", "Reply-To"}
 
-	return msg, nil
+// hasMessageHeaders reports whether header holds email headers rather than body text
+// that happened to parse as headers
+func hasMessageHeaders(header mail.Header) bool {
+	for _, field := range messageHeaders {
+		if _, ok := header[field]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (ep *emailProcessorImpl) extractEventInfo(ctx context.Context, msg *mail.Message, content *EmailContent) (*EmailEvent, error) {
@@ -257,21 +490,24 @@ func (ep *emailProcessorImpl) extractEventInfo(ctx context.Context, msg *mail.Me
 	defer span.End()
 
 	subject := msg.Header.Get("Subject")
-
-	// Combine all text content for NER processing
-	textContent := strings.Join([]string{
-		content.PlainText,
-		ep.stripHTML(content.HTML), // Strip HTML tags for text processing
-		content.RichText,
-	}, "\n")
+	textContent := ep.textContent(content)
+	ctx = ep.withSenderTimezone(ctx, content.Metadata)
 
 	// Extract dates using NER service
-	dates, err := ep.extractDates(ctx, subject, textContent)
+	dates, dateOnly, err := ep.extractDates(ctx, subject, textContent)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
 	}
 
+	return ep.newEmailEvent(ctx, msg, content, subject, textContent, dates, dateOnly)
+}
+
+// newEmailEvent builds the event for text, using the first two dates as start and end.
+// The event lasts whole days when the dates are date-only or the text says so.
+func (ep *emailProcessorImpl) newEmailEvent(ctx context.Context, msg *mail.Message, content *EmailContent, subject, text string, dates []time.Time, dateOnly bool) (*EmailEvent, error) {
+	span := trace.SpanFromContext(ctx)
+
 	if len(dates) < 2 {
 		return nil, fmt.Errorf("could not extract start and end times")
 	}
@@ -279,32 +515,71 @@ func (ep *emailProcessorImpl) extractEventInfo(ctx context.Context, msg *mail.Me
 	// First date is start time, second is end time
 	startTime := dates[0]
 	endTime := dates[1]
+	timeZone := ep.inferTimezone(startTime, content.Metadata)
+
+	allDay := dateOnly || allDayPhrasePattern.MatchString(subject+"\n"+text)
+	if allDay {
+		startTime, endTime = allDayRange(startTime, endTime)
+	}
 
 	// Extract location using NER
-	location, err := ep.nerService.ExtractLocation(ctx, textContent)
+	location, err := ep.nerService.ExtractLocation(ctx, text)
 	if err != nil {
 		// Log error but don't fail - location is optional
 		span.RecordError(err)
 	}
+	if location == "" {
+		// A map link often names the venue when the text does not
+		location = mapsLocation(text)
+	}
+	if ep.normalizeLocation {
+		location = normalizeLocation(location)
+	}
 
 	// Extract attendees from headers and content
-	attendees := ep.extractAttendees(msg.Header)
-
-	return &EmailEvent{
-		Subject:     subject,
-		Description: textContent,
-		StartTime:   startTime,
-		EndTime:     endTime,
-		Location:    location,
-		Attendees:   attendees,
-		Metadata:    content.Metadata,
-		Attachments: content.Attachments,
-	}, nil
+	headerAttendees := ep.extractAttendees(msg.Header)
+	bodyAttendees := ep.extractBodyAttendees(text, headerAttendees)
+	organizer := ep.organizerAddress(msg.Header)
+	attendees := ep.assembleAttendees(organizer, headerAttendees, bodyAttendees)
+
+	event := &EmailEvent{
+		Subject:      subject,
+		Description:  stripReplyAndSignature(text),
+		OriginalText: text,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		IsAllDay:     allDay,
+		TimeZone:     timeZone,
+		Location:     location,
+		Organizer:    organizer,
+		Attendees:    attendees,
+		Metadata:     content.Metadata,
+		Attachments:  content.Attachments,
+		Source:       service.EventSourceFromContext(ctx),
+	}
+
+	if ep.includeHeaders {
+		event.Headers = sanitizeHeaders(msg.Header)
+	}
+
+	return event, nil
 }
 
+// textContent combines all text parts of an email for NER processing
+func (ep *emailProcessorImpl) textContent(content *EmailContent) string {
+	return strings.Join([]string{
+		content.PlainText,
+		ep.stripHTML(content.HTML), // Strip HTML tags for text processing
+		content.RichText,
+	}, "\n")
+}
+
+// stripHTML returns the text of an HTML body. Links keep their URL in parentheses
+// after their text, so that location hints such as map links reach the NER service.
 func (ep *emailProcessorImpl) stripHTML(html string) string {
 	// Simple HTML stripping - can be improved with proper HTML parsing
-	text := strings.ReplaceAll(html, "<br>", "\n")
+	text := anchorPattern.ReplaceAllStringFunc(html, annotateAnchor)
+	text = strings.ReplaceAll(text, "<br>", "\n")
 	text = strings.ReplaceAll(text, "<br/>", "\n")
 	text = strings.ReplaceAll(text, "<br />", "\n")
 
@@ -324,80 +599,141 @@ func (ep *emailProcessorImpl) stripHTML(html string) string {
 	return strings.TrimSpace(text)
 }
 
-func (ep *emailProcessorImpl) extractDates(ctx context.Context, subject, body string) ([]time.Time, error) {
+// annotateAnchor returns the content of an <a> element followed by its http or https
+// URL in parentheses. Other links, and links whose text is already the URL, keep only
+// their content.
+func annotateAnchor(anchor string) string {
+	m := anchorPattern.FindStringSubmatch(anchor)
+	content := m[2]
+
+	href := hrefPattern.FindStringSubmatch(m[1])
+	if href == nil {
+		return content
+	}
+	link := strings.TrimSpace(html.UnescapeString(href[1] + href[2]))
+	ref, err := url.Parse(link)
+	if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") {
+		return content
+	}
+	if strings.Contains(content, link) {
+		return content
+	}
+	return content + " (" + link + ")"
+}
+
+// extractDates returns the start and end candidates found in the email and whether
+// they are bare days without a time of day
+func (ep *emailProcessorImpl) extractDates(ctx context.Context, subject, body string) ([]time.Time, bool, error) {
 	// Combine subject and body for date extraction
 	text := subject + "\n" + body
 
 	// Extract dates using NER service
 	dates, err := ep.nerService.ExtractDateTime(ctx, text)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract dates: %v", err)
+		// An unreachable NER service is already reported as unavailable
+		if calerrors.IsServiceUnavailable(err) {
+			return nil, false, err
+		}
+		return nil, false, calerrors.NewServiceUnavailableError("failed to extract dates").WithWrappedError(err)
 	}
 
 	// Sort dates by time
 	sortDates(dates)
+	dateOnly := isDateOnly(dates)
 
-	// If only one date found, use it as start time and add 1 hour for end time
+	// If only one date found, use it as start time and end after the stated or default duration
 	if len(dates) == 1 {
-		dates = append(dates, dates[0].Add(1*time.Hour))
+		dates = append(dates, dates[0].Add(ep.eventLength(text)))
 	}
 
-	// Ensure we have at least two dates
-	if len(dates) < 2 {
+	// No date at all, fall back according to the configured policy
+	if len(dates) == 0 {
+		if ep.missingDates == MissingDatesSkip {
+			return nil, false, ErrNoEventDates
+		}
 		now := time.Now()
-		dates = []time.Time{now, now.Add(1 * time.Hour)}
+		dates = []time.Time{now, now.Add(ep.eventDuration)}
 	}
 
-	return dates, nil
+	return dates, dateOnly, nil
 }
 
+// extractAttendees returns the To and Cc addresses in header order; assembleAttendees
+// takes care of deduplication
 func (ep *emailProcessorImpl) extractAttendees(header mail.Header) []string {
-	attendees := make(map[string]struct{})
+	result := make([]string, 0)
 
-	// Extract from To field
-	if to := header.Get("To"); to != "" {
-		addresses, err := mail.ParseAddressList(to)
-		if err == nil {
-			for _, addr := range addresses {
-				attendees[addr.Address] = struct{}{}
-			}
+	for _, field := range []string{"To", "Cc"} {
+		value := header.Get(field)
+		if value == "" {
+			continue
 		}
-	}
 
-	// Extract from Cc field
-	if cc := header.Get("Cc"); cc != "" {
-		addresses, err := mail.ParseAddressList(cc)
-		if err == nil {
-			for _, addr := range addresses {
-				attendees[addr.Address] = struct{}{}
-			}
+		addresses, err := mail.ParseAddressList(value)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addresses {
+			result = append(result, addr.Address)
 		}
 	}
 
-	// Convert to slice
-	result := make([]string, 0, len(attendees))
-	for addr := range attendees {
-		result = append(result, addr)
+	return result
+}
+
+// extractBodyAttendees resolves email addresses mentioned in the body, skipping ones
+// already known and stopping once maxBodyAttendees have been resolved
+func (ep *emailProcessorImpl) extractBodyAttendees(text string, known []string) []string {
+	if ep.maxBodyAttendees <= 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(known))
+	for _, addr := range known {
+		seen[strings.ToLower(addr)] = struct{}{}
+	}
+
+	var result []string
+	for _, match := range bodyEmailPattern.FindAllString(text, -1) {
+		key := strings.ToLower(match)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, match)
+		if len(result) == ep.maxBodyAttendees {
+			break
+		}
 	}
 
 	return result
 }
 
+// eventInfoError types a failure to extract the event from the email. Errors that are
+// already typed, such as an unavailable NER service, keep their type.
+func eventInfoError(err error) error {
+	var cerr *calerrors.CalendarError
+	if errors.As(err, &cerr) {
+		return err
+	}
+	return calerrors.NewParseError("failed to extract event info").WithWrappedError(err)
+}
+
 func (ep *emailProcessorImpl) validateEvent(ctx context.Context, event *EmailEvent) error {
 	if event.Subject == "" {
-		return fmt.Errorf("event subject is required")
+		return calerrors.NewValidationError("event subject is required")
 	}
 
 	if event.StartTime.IsZero() {
-		return fmt.Errorf("event start time is required")
+		return calerrors.NewValidationError("event start time is required")
 	}
 
 	if event.EndTime.IsZero() {
-		return fmt.Errorf("event end time is required")
+		return calerrors.NewValidationError("event end time is required")
 	}
 
 	if event.EndTime.Before(event.StartTime) {
-		return fmt.Errorf("event end time cannot be before start time")
+		return calerrors.NewValidationError("event end time cannot be before start time")
 	}
 
 	return nil
@@ -405,11 +741,7 @@ func (ep *emailProcessorImpl) validateEvent(ctx context.Context, event *EmailEve
 
 // sortDates sorts a slice of dates in ascending order
 func sortDates(dates []time.Time) {
-	for i := 0; i < len(dates)-1; i++ {
-		for j := i + 1; j < len(dates); j++ {
-			if dates[j].Before(dates[i]) {
-				dates[i], dates[j] = dates[j], dates[i]
-			}
-		}
-	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
 }
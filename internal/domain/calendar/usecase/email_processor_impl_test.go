@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net/mail"
 	"strings"
 	"testing"
@@ -45,6 +46,14 @@ func (m *mockNERService) ExtractEntities(ctx context.Context, text string, langu
 	return args.Get(0).([]Entity), args.Error(1)
 }
 
+func (m *mockNERService) ExtractEntitiesBatch(ctx context.Context, texts []string, language string) ([][]Entity, error) {
+	args := m.Called(ctx, texts, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]Entity), args.Error(1)
+}
+
 func (m *mockNERService) ExtractDateTime(ctx context.Context, text string) ([]time.Time, error) {
 	args := m.Called(ctx, text)
 	if args.Get(0) == nil {
@@ -58,6 +67,11 @@ func (m *mockNERService) ExtractLocation(ctx context.Context, text string) (stri
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockNERService) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func TestEmailProcessorImpl_ProcessEmail(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -92,9 +106,15 @@ func TestEmailProcessorImpl_ProcessEmail(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name:          "invalid email format",
-			emailContent:  "invalid email content",
-			setupMocks:    func(validator *mockEmailValidator, ner *mockNERService) {},
+			name:         "email without headers has no subject",
+			emailContent: "invalid email content",
+			setupMocks: func(validator *mockEmailValidator, ner *mockNERService) {
+				startTime := time.Now().Add(24 * time.Hour)
+				ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+					Return([]time.Time{startTime}, nil)
+				ner.On("ExtractLocation", mock.Anything, mock.Anything).
+					Return("", nil)
+			},
 			expectedEvent: nil,
 			expectError:   true,
 		},
@@ -299,6 +319,97 @@ func TestEmailProcessorImpl_extractEmailContent(t *testing.T) {
 	}
 }
 
+func TestEmailProcessorImpl_extractLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []string
+	}{
+		{
+			name:     "double and single quotes",
+			html:     `<a href="https://meet.example.com/abc">Join</a> <a href='https://docs.example.com/agenda'>Agenda</a>`,
+			expected: []string{"https://meet.example.com/abc", "https://docs.example.com/agenda"},
+		},
+		{
+			name: "duplicates keep the first position",
+			html: `<p>Join at <a href="https://meet.example.com/abc">https://meet.example.com/abc</a></p>
+				<a HREF = 'https://docs.example.com/agenda'>Agenda</a>
+				<a class="button" href='https://meet.example.com/abc'>Join meeting</a>`,
+			expected: []string{"https://meet.example.com/abc", "https://docs.example.com/agenda"},
+		},
+		{
+			name: "relative links resolve against the base href",
+			html: `<head><base href="https://intranet.example.com/events/"></head>
+				<a href="2025/kickoff?ref=mail&amp;lang=en">Details</a>
+				<a href="/rooms/5">Room</a>
+				<a href="mailto:alice@example.com">Alice</a>`,
+			expected: []string{
+				"https://intranet.example.com/events/2025/kickoff?ref=mail&lang=en",
+				"https://intranet.example.com/rooms/5",
+			},
+		},
+		{
+			name:     "relative links without a base are dropped",
+			html:     `<a href="/rooms/5">Room</a> <a href="http://example.com">Site</a>`,
+			expected: []string{"http://example.com"},
+		},
+		{
+			name:     "no links",
+			html:     `<p>See you there</p>`,
+			expected: []string{},
+		},
+	}
+
+	ep := &emailProcessorImpl{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ep.extractLinks(tt.html))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_stripHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "link URL follows its text",
+			html:     `<p>Meet at <a href="https://maps.google.com/?q=Highlands+Coffee&amp;hl=vi">the cafe</a></p>`,
+			expected: "Meet at the cafe (https://maps.google.com/?q=Highlands+Coffee&hl=vi)",
+		},
+		{
+			name: "formatted link text",
+			html: `<A class='button' HREF='https://meet.example.com/abc'><b>Join</b></A>`,
+			// Tags are replaced with spaces
+			expected: "Join  (https://meet.example.com/abc)",
+		},
+		{
+			name:     "link text that is the URL is not repeated",
+			html:     `<a href="https://meet.example.com/abc">https://meet.example.com/abc</a>`,
+			expected: "https://meet.example.com/abc",
+		},
+		{
+			name:     "non-web links keep only their text",
+			html:     `<a href="mailto:alice@example.com">Alice</a> and <a href="/rooms/5">room 5</a>`,
+			expected: "Alice and room 5",
+		},
+		{
+			name:     "line breaks",
+			html:     "Line 1<br>Line 2<br />Line 3",
+			expected: "Line 1\nLine 2\nLine 3",
+		},
+	}
+
+	ep := &emailProcessorImpl{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ep.stripHTML(tt.html))
+		})
+	}
+}
+
 func TestEmailProcessorImpl_extractAttendees(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -353,3 +464,73 @@ func TestEmailProcessorImpl_extractAttendees(t *testing.T) {
 		})
 	}
 }
+
+func TestEmailProcessorImpl_extractBodyAttendees(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&body, "Please invite person%02d@example.com to the meeting.\n", i)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []EmailProcessorOption
+		known    []string
+		expected []string
+	}{
+		{
+			name: "default cap",
+			expected: []string{
+				"person00@example.com", "person01@example.com", "person02@example.com", "person03@example.com", "person04@example.com",
+				"person05@example.com", "person06@example.com", "person07@example.com", "person08@example.com", "person09@example.com",
+			},
+		},
+		{
+			name:     "configured cap",
+			opts:     []EmailProcessorOption{WithMaxBodyAttendees(3)},
+			expected: []string{"person00@example.com", "person01@example.com", "person02@example.com"},
+		},
+		{
+			name:     "known attendees do not count towards the cap",
+			opts:     []EmailProcessorOption{WithMaxBodyAttendees(2)},
+			known:    []string{"Person00@example.com"},
+			expected: []string{"person01@example.com", "person02@example.com"},
+		},
+		{
+			name:     "zero disables body resolution",
+			opts:     []EmailProcessorOption{WithMaxBodyAttendees(0)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService), tt.opts...).(*emailProcessorImpl)
+			assert.Equal(t, tt.expected, processor.extractBodyAttendees(body.String(), tt.known))
+		})
+	}
+}
+
+func TestSortDates(t *testing.T) {
+	base := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	expected := make([]time.Time, 50)
+	for i := range expected {
+		expected[i] = base.Add(time.Duration(i) * 15 * time.Minute)
+	}
+	// Equal instants in different zones must not break the order
+	expected[10] = expected[9].In(time.FixedZone("ICT", 7*60*60))
+
+	dates := append([]time.Time(nil), expected...)
+	rand.New(rand.NewSource(1)).Shuffle(len(dates), func(i, j int) {
+		dates[i], dates[j] = dates[j], dates[i]
+	})
+
+	sortDates(dates)
+	for i := range expected {
+		assert.True(t, expected[i].Equal(dates[i]), "index %d: expected %v, got %v", i, expected[i], dates[i])
+	}
+
+	sortDates(nil)
+	single := []time.Time{base}
+	sortDates(single)
+	assert.Equal(t, []time.Time{base}, single)
+}
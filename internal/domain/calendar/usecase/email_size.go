@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"fmt"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+// DefaultMaxEmailSize is the largest raw email accepted for processing, 10 MiB
+const DefaultMaxEmailSize int64 = 10 << 20
+
+// CheckEmailSize returns an EmailTooLarge error when emailContent is larger than
+// maxSize bytes, so that oversized emails are rejected before they are parsed. A
+// non-positive maxSize uses DefaultMaxEmailSize.
+func CheckEmailSize(emailContent string, maxSize int64) error {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxEmailSize
+	}
+
+	size := int64(len(emailContent))
+	if size <= maxSize {
+		return nil
+	}
+	return calerrors.NewEmailTooLargeError(fmt.Sprintf("email of %d bytes exceeds the %d byte limit", size, maxSize)).
+		WithDetails(map[string]interface{}{
+			"size":     size,
+			"max_size": maxSize,
+		})
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestCheckEmailSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		maxSize int64
+		wantErr bool
+	}{
+		{
+			name:    "email under the limit",
+			email:   "email",
+			maxSize: 6,
+		},
+		{
+			name:    "email at the limit",
+			email:   "email",
+			maxSize: 5,
+		},
+		{
+			name:    "email just over the limit",
+			email:   "email",
+			maxSize: 4,
+			wantErr: true,
+		},
+		{
+			name:  "default limit",
+			email: strings.Repeat("a", int(DefaultMaxEmailSize)),
+		},
+		{
+			name:    "over the default limit",
+			email:   strings.Repeat("a", int(DefaultMaxEmailSize)+1),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckEmailSize(tt.email, tt.maxSize)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, calerrors.IsEmailTooLarge(err))
+			assert.False(t, calerrors.ShouldRetry(err))
+			assert.Equal(t, int64(len(tt.email)), calerrors.GetErrorDetails(err)["size"])
+		})
+	}
+}
+
+func TestMessagingService_handleDelivery_MaxEmailSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSize     int64
+		expectedDLQ bool
+	}{
+		{
+			name:    "email under the limit is processed",
+			maxSize: int64(len(headerTestEmail)),
+		},
+		{
+			name:        "email just over the limit is dead-lettered",
+			maxSize:     int64(len(headerTestEmail)) - 1,
+			expectedDLQ: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+				Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil).Maybe()
+
+			channel := &fakeQueueChannel{}
+			s := &messagingService{
+				channel:  channel,
+				config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+				calendar: calendar,
+				tracer:   otel.Tracer("test"),
+				logger:   logrus.New(),
+			}
+			WithMaxEmailSize(tt.maxSize)(s)
+
+			body, err := json.Marshal(EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+			require.NoError(t, err)
+			s.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: channel, DeliveryTag: 1, Body: body})
+
+			if tt.expectedDLQ {
+				assert.Len(t, channel.published["emails.dlq"], 1)
+				calendar.AssertNotCalled(t, "ProcessEmailToCalendar", mock.Anything, mock.Anything)
+				assert.Equal(t, []string{"ack 1"}, channel.recorded())
+				return
+			}
+			assert.Empty(t, channel.published["emails.dlq"])
+			calendar.AssertNumberOfCalls(t, "ProcessEmailToCalendar", 1)
+			assert.Equal(t, []string{"ack 1"}, channel.recorded())
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package usecase
 
 import (
+	"fmt"
 	"net/mail"
 	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"mail2calendar/internal/domain/calendar/service"
 )
 
 // EmailMetadata represents metadata from email headers
@@ -20,21 +25,58 @@ type EmailMetadata struct {
 	ContentDispostion string
 }
 
-// EmailAttachment represents an email attachment
+// EmailAttachment represents an email attachment.
+// Data is user file content and must never reach logs or traces: it is
+// excluded from JSON, fmt and zap output.
 type EmailAttachment struct {
 	Filename    string
 	ContentType string
-	Data        []byte
+	Data        []byte `json:"-"`
+}
+
+// String describes the attachment without its content
+func (a EmailAttachment) String() string {
+	return fmt.Sprintf("EmailAttachment{Filename: %q, ContentType: %q, Size: %d}", a.Filename, a.ContentType, len(a.Data))
+}
+
+// GoString keeps %#v from printing the attachment content
+func (a EmailAttachment) GoString() string {
+	return a.String()
+}
+
+// MarshalLogObject lets zap log the attachment without its content
+func (a EmailAttachment) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("filename", a.Filename)
+	enc.AddString("content_type", a.ContentType)
+	enc.AddInt("size", len(a.Data))
+	return nil
 }
 
 // EmailEvent represents a calendar event extracted from an email
 type EmailEvent struct {
-	Subject     string
+	Subject string
+	// Description is the text of the email without quoted replies and signature
 	Description string
-	StartTime   time.Time
-	EndTime     time.Time
-	Location    string
+	// OriginalText is the full text the event was extracted from, quotes and
+	// signature included
+	OriginalText string
+	StartTime    time.Time
+	EndTime      time.Time
+	// IsAllDay marks an event lasting whole days; EndTime is then the exclusive end day
+	IsAllDay bool
+	// TimeZone is the IANA timezone the event was described in, "" if unknown
+	TimeZone string
+	Location string
+	// RecurrenceRule is the "RRULE:..." rule of a recurring event, "" otherwise
+	RecurrenceRule string
+	// Organizer is the address organizing the event; it is never one of Attendees
+	Organizer   string
 	Attendees   []string
 	Metadata    EmailMetadata
 	Attachments []EmailAttachment
+	// Headers holds the sanitized Message-ID, From, Date and Subject headers.
+	// It is only populated when the processor is created with WithRawHeaders(true).
+	Headers map[string]string
+	// Source is the ingestion channel the email arrived through
+	Source service.EventSource
 }
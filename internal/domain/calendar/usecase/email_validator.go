@@ -1,65 +1,148 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/mail"
+	"regexp"
 	"strings"
+	"time"
 )
 
+// defaultDNSTimeout bounds the DNS lookups of one DKIM or SPF check
+const defaultDNSTimeout = 5 * time.Second
+
+// receivedFromIP matches the client IP our MTA records in its Received header,
+// as in "from mail.example.com (mail.example.com [192.0.2.1])"
+var receivedFromIP = regexp.MustCompile(`\[(?:IPv6:)?([0-9A-Fa-f:.]+)\]`)
+
 // emailValidatorImpl implements EmailValidator interface
 type emailValidatorImpl struct {
 	trustedDomains map[string]struct{} // Whitelist of trusted email domains
+	blockedSenders map[string]struct{} // Blacklist of sender domains and addresses
+	resolver       DNSResolver
+	dnsTimeout     time.Duration
+	now            func() time.Time
+}
+
+// EmailValidatorOption configures optional behaviour of the email validator
+type EmailValidatorOption func(*emailValidatorImpl)
+
+// WithDNSResolver replaces the resolver used for DKIM key and SPF lookups
+func WithDNSResolver(resolver DNSResolver) EmailValidatorOption {
+	return func(v *emailValidatorImpl) {
+		if resolver != nil {
+			v.resolver = resolver
+		}
+	}
 }
 
-// NewEmailValidator creates a new instance of EmailValidator
-func NewEmailValidator(trustedDomains []string) EmailValidator {
+// WithBlockedSenders rejects emails from the given domains or addresses, even if their
+// domain is trusted
+func WithBlockedSenders(senders ...string) EmailValidatorOption {
+	return func(v *emailValidatorImpl) {
+		for _, sender := range senders {
+			if sender = strings.ToLower(strings.TrimSpace(sender)); sender != "" {
+				v.blockedSenders[sender] = struct{}{}
+			}
+		}
+	}
+}
+
+// NewEmailValidator creates a new instance of EmailValidator. When trustedDomains is
+// empty, every sender domain that is not blocked is accepted.
+func NewEmailValidator(trustedDomains []string, opts ...EmailValidatorOption) EmailValidator {
 	domains := make(map[string]struct{}, len(trustedDomains))
 	for _, domain := range trustedDomains {
-		domains[domain] = struct{}{}
+		domains[strings.ToLower(domain)] = struct{}{}
 	}
 
-	return &emailValidatorImpl{
+	v := &emailValidatorImpl{
 		trustedDomains: domains,
+		blockedSenders: make(map[string]struct{}),
+		resolver:       net.DefaultResolver,
+		dnsTimeout:     defaultDNSTimeout,
+		now:            time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(v)
 	}
+
+	return v
 }
 
+// ValidateDKIM verifies the DKIM signatures of the email against the public keys
+// published in DNS. One valid signature is enough.
 func (v *emailValidatorImpl) ValidateDKIM(email string) error {
-	// TODO: Implement DKIM signature verification using a DKIM library
-	// For now, doing basic header check
-	if !strings.Contains(email, "DKIM-Signature:") {
-		return fmt.Errorf("missing DKIM signature")
-	}
-	return nil
+	ctx, cancel := context.WithTimeout(context.Background(), v.dnsTimeout)
+	defer cancel()
+
+	return v.verifyDKIM(ctx, email)
 }
 
+// ValidateSPF checks that the server which handed the email to our MTA may send for the
+// envelope sender domain. The server IP is read from the topmost Received header; use
+// CheckSPF when the IP is known from the SMTP session.
 func (v *emailValidatorImpl) ValidateSPF(email string) error {
-	// TODO: Implement proper SPF record checking
-	// For example:
-	// 1. Extract sender domain from email
-	// 2. Lookup SPF record for domain
-	// 3. Validate sending IP against SPF policy
-
-	// Basic example:
-	domain := v.extractDomain(email)
+	msg, err := mail.ReadMessage(strings.NewReader(email))
+	if err != nil {
+		return fmt.Errorf("failed to parse email: %v", err)
+	}
+
+	domain := v.senderDomain(msg.Header)
 	if domain == "" {
 		return fmt.Errorf("could not extract domain from email")
 	}
 
-	_, err := net.LookupTXT(domain)
-	if err != nil {
-		return fmt.Errorf("SPF record lookup failed: %v", err)
+	ip := sendingIP(msg.Header)
+	if ip == nil {
+		return fmt.Errorf("could not find the sending IP in the Received header")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), v.dnsTimeout)
+	defer cancel()
+
+	result, err := v.CheckSPF(ctx, ip, domain)
+	if result != SPFPass {
+		if err != nil {
+			return fmt.Errorf("SPF check for %s from %s: %s: %v", domain, ip, result, err)
+		}
+		return fmt.Errorf("SPF check for %s from %s: %s", domain, ip, result)
+	}
 	return nil
 }
 
+// ValidateSender checks the From address against the blocked senders and trusted domains
 func (v *emailValidatorImpl) ValidateSender(email string) error {
-	domain := v.extractDomain(email)
+	msg, err := mail.ReadMessage(strings.NewReader(email))
+	if err != nil {
+		return fmt.Errorf("failed to parse email: %v", err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return fmt.Errorf("invalid From address: %v", err)
+	}
+	address := strings.ToLower(from.Address)
+
+	domain := v.extractDomain(address)
 	if domain == "" {
 		return fmt.Errorf("could not extract domain from email")
 	}
 
+	if _, blocked := v.blockedSenders[address]; blocked {
+		return fmt.Errorf("sender %s is blocked", address)
+	}
+	if _, blocked := v.blockedSenders[domain]; blocked {
+		return fmt.Errorf("sender domain %s is blocked", domain)
+	}
+
 	// Check if domain is in trusted list
+	if len(v.trustedDomains) == 0 {
+		return nil
+	}
 	if _, trusted := v.trustedDomains[domain]; !trusted {
 		return fmt.Errorf("sender domain %s is not trusted", domain)
 	}
@@ -67,22 +150,48 @@ func (v *emailValidatorImpl) ValidateSender(email string) error {
 	return nil
 }
 
+// senderDomain returns the envelope sender domain from Return-Path, or the From domain
+func (v *emailValidatorImpl) senderDomain(header mail.Header) string {
+	for _, field := range []string{"Return-Path", "From"} {
+		if addr, err := mail.ParseAddress(header.Get(field)); err == nil {
+			if domain := v.extractDomain(addr.Address); domain != "" {
+				return domain
+			}
+		}
+	}
+	return ""
+}
+
+// sendingIP returns the client IP recorded in the topmost Received header
+func sendingIP(header mail.Header) net.IP {
+	received := header["Received"]
+	if len(received) == 0 {
+		return nil
+	}
+
+	// Only the "from" clause names the client; "by" names our own server
+	from, _, _ := strings.Cut(received[0], " by ")
+	match := receivedFromIP.FindStringSubmatch(from)
+	if match == nil {
+		return nil
+	}
+	return net.ParseIP(match[1])
+}
+
 func (v *emailValidatorImpl) extractDomain(email string) string {
-	// Basic domain extraction from email
-	// In production, use a proper email parsing library
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
 		return ""
 	}
-	return parts[1]
+	return strings.ToLower(parts[1])
 }
 
 // AddTrustedDomain adds a domain to the trusted domains list
 func (v *emailValidatorImpl) AddTrustedDomain(domain string) {
-	v.trustedDomains[domain] = struct{}{}
+	v.trustedDomains[strings.ToLower(domain)] = struct{}{}
 }
 
 // RemoveTrustedDomain removes a domain from the trusted domains list
 func (v *emailValidatorImpl) RemoveTrustedDomain(domain string) {
-	delete(v.trustedDomains, domain)
+	delete(v.trustedDomains, strings.ToLower(domain))
 }
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+// DuplicateAction tells the usecase what to do with a new event that matches an existing one
+type DuplicateAction int
+
+const (
+	// DuplicateNone means the existing event is unrelated to the new one
+	DuplicateNone DuplicateAction = iota
+	// DuplicateSkip keeps the existing event as is and creates nothing
+	DuplicateSkip
+	// DuplicateUpdate moves the existing event to the time and place of the new one and
+	// takes its title and description
+	DuplicateUpdate
+)
+
+// DuplicateMatcher decides whether a new event duplicates an existing calendar event
+type DuplicateMatcher interface {
+	MatchDuplicate(event *calendarPb.Event, existing *CalendarEvent) DuplicateAction
+}
+
+// DuplicateMatcherFunc adapts a function to a DuplicateMatcher
+type DuplicateMatcherFunc func(event *calendarPb.Event, existing *CalendarEvent) DuplicateAction
+
+// MatchDuplicate calls f(event, existing)
+func (f DuplicateMatcherFunc) MatchDuplicate(event *calendarPb.Event, existing *CalendarEvent) DuplicateAction {
+	return f(event, existing)
+}
+
+// replyPrefixPattern matches the reply and forward prefixes mail clients add to subjects
+var replyPrefixPattern = regexp.MustCompile(`(?i)^\s*((re|fwd?|aw|sv)\s*(\[\d+\])?\s*:\s*)+`)
+
+// TitleOverlapMatcher is the default DuplicateMatcher. Titles are compared ignoring
+// case, extra whitespace and reply prefixes such as "Re:" or "Fwd:". An event with
+// the same title and the same start and end is skipped; one with the same title that
+// overlaps in time updates the existing event.
+func TitleOverlapMatcher() DuplicateMatcher {
+	return DuplicateMatcherFunc(func(event *calendarPb.Event, existing *CalendarEvent) DuplicateAction {
+		if normalizeEventTitle(event.Title) != normalizeEventTitle(existing.Title) {
+			return DuplicateNone
+		}
+
+		start, end := time.Unix(event.StartTime, 0), time.Unix(event.EndTime, 0)
+		if start.Equal(existing.StartTime) && end.Equal(existing.EndTime) {
+			return DuplicateSkip
+		}
+		if start.Before(existing.EndTime) && existing.StartTime.Before(end) {
+			return DuplicateUpdate
+		}
+		return DuplicateNone
+	})
+}
+
+// normalizeEventTitle returns the title without reply prefixes, lowercased and with
+// whitespace collapsed
+func normalizeEventTitle(title string) string {
+	title = replyPrefixPattern.ReplaceAllString(title, "")
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// WithDuplicateMatcher sets how new events are matched against existing calendar
+// events before they are created. A nil matcher disables duplicate detection.
+func WithDuplicateMatcher(matcher DuplicateMatcher) CalendarUseCaseOption {
+	return func(u *calendarUseCase) {
+		u.duplicates = matcher
+	}
+}
+
+// resolveDuplicate looks for an existing event in the window of event. It returns
+// the event to hand back instead of creating a new one, or nil if there is none.
+func (u *calendarUseCase) resolveDuplicate(ctx context.Context, event *calendarPb.Event) (*calendarPb.Event, error) {
+	if u.duplicates == nil || u.calendarService == nil {
+		return nil, nil
+	}
+
+	existing, err := u.calendarService.GetEvents(ctx, TimeRange{
+		StartTime: time.Unix(event.StartTime, 0),
+		EndTime:   time.Unix(event.EndTime, 0),
+	}, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up existing events: %v", err)
+	}
+
+	for _, candidate := range existing {
+		switch u.duplicates.MatchDuplicate(event, candidate) {
+		case DuplicateSkip:
+			return toProtoEvent(candidate), nil
+		case DuplicateUpdate:
+			updated := *candidate
+			if title := strings.TrimSpace(replyPrefixPattern.ReplaceAllString(event.Title, "")); title != "" {
+				updated.Title = title
+			}
+			if event.Description != "" {
+				updated.Description = event.Description
+			}
+			updated.StartTime = time.Unix(event.StartTime, 0)
+			updated.EndTime = time.Unix(event.EndTime, 0)
+			if event.Location != "" {
+				updated.Location = event.Location
+			}
+			if event.RecurrenceRule != "" {
+				updated.IsRecurring = true
+				updated.RecurrenceRule = event.RecurrenceRule
+			}
+			if err := u.calendarService.UpdateEvent(ctx, &updated); err != nil {
+				// Another worker changed the event since it was listed
+				if calerrors.IsConflict(err) {
+					return nil, status.Errorf(codes.Aborted, "failed to update existing event: %v", err)
+				}
+				return nil, status.Errorf(codes.Internal, "failed to update existing event: %v", err)
+			}
+			return toProtoEvent(&updated), nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestCalendarUseCase_CreateEvent_Duplicates(t *testing.T) {
+	start := time.Date(2025, 3, 5, 14, 0, 0, 0, time.UTC)
+	existing := &CalendarEvent{
+		ID:        "google-1",
+		Title:     "Project kickoff",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Location:  "Room 1",
+	}
+
+	tests := []struct {
+		name          string
+		event         *calendarPb.Event
+		expectUpdate  bool
+		expectedID    string
+		expectedStart time.Time
+		expectedTitle string
+		expectedRule  string
+	}{
+		{
+			name: "exact duplicate from a reply is skipped",
+			event: &calendarPb.Event{
+				Title:     "Re: RE:  project kickoff",
+				StartTime: start.Unix(),
+				EndTime:   start.Add(time.Hour).Unix(),
+			},
+			expectedID:    "google-1",
+			expectedStart: start,
+			expectedTitle: "Project kickoff",
+		},
+		{
+			name: "near duplicate updates the existing event",
+			event: &calendarPb.Event{
+				Title:     "Fwd: Project Kickoff",
+				StartTime: start.Add(30 * time.Minute).Unix(),
+				EndTime:   start.Add(90 * time.Minute).Unix(),
+			},
+			expectUpdate:  true,
+			expectedID:    "google-1",
+			expectedStart: start.Add(30 * time.Minute),
+			expectedTitle: "Project Kickoff",
+		},
+		{
+			name: "near duplicate with a recurrence rule makes the existing event recurring",
+			event: &calendarPb.Event{
+				Title:          "Project kickoff",
+				StartTime:      start.Add(30 * time.Minute).Unix(),
+				EndTime:        start.Add(90 * time.Minute).Unix(),
+				RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=WE",
+			},
+			expectUpdate:  true,
+			expectedID:    "google-1",
+			expectedStart: start.Add(30 * time.Minute),
+			expectedTitle: "Project kickoff",
+			expectedRule:  "RRULE:FREQ=WEEKLY;BYDAY=WE",
+		},
+		{
+			name: "different title creates a new event",
+			event: &calendarPb.Event{
+				Title:     "Budget review",
+				StartTime: start.Unix(),
+				EndTime:   start.Add(time.Hour).Unix(),
+			},
+			expectedStart: start,
+			expectedTitle: "Budget review",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendarService := new(mockCalendarService)
+			calendarService.On("GetEvents", mock.Anything, TimeRange{
+				StartTime: time.Unix(tt.event.StartTime, 0),
+				EndTime:   time.Unix(tt.event.EndTime, 0),
+			}, []string(nil)).Return([]*CalendarEvent{existing}, nil)
+			if tt.expectUpdate {
+				calendarService.On("UpdateEvent", mock.Anything, mock.MatchedBy(func(e *CalendarEvent) bool {
+					return e.ID == "google-1" && e.StartTime.Equal(tt.expectedStart) && e.Location == "Room 1" && e.Title == tt.expectedTitle &&
+						e.RecurrenceRule == tt.expectedRule && e.IsRecurring == (tt.expectedRule != "")
+				})).Return(nil)
+			}
+
+			u := NewCalendarUseCase(nil, calendarService)
+			event, err := u.CreateEvent(context.Background(), tt.event, "user-1")
+			require.NoError(t, err)
+
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, event.Id)
+			} else {
+				assert.NotEqual(t, "google-1", event.Id)
+			}
+			assert.Equal(t, tt.expectedStart.Unix(), event.StartTime)
+			assert.Equal(t, tt.expectedTitle, event.Title)
+			assert.Equal(t, tt.expectedRule, event.RecurrenceRule)
+			calendarService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCalendarUseCase_CreateEvent_CustomDuplicateMatcher(t *testing.T) {
+	start := time.Date(2025, 3, 5, 14, 0, 0, 0, time.UTC)
+	calendarService := new(mockCalendarService)
+	calendarService.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*CalendarEvent{{ID: "google-1", Title: "Other", StartTime: start, EndTime: start.Add(time.Hour)}}, nil)
+
+	// A matcher that treats any overlapping event as the same meeting
+	matcher := DuplicateMatcherFunc(func(*calendarPb.Event, *CalendarEvent) DuplicateAction {
+		return DuplicateSkip
+	})
+	u := NewCalendarUseCase(nil, calendarService, WithDuplicateMatcher(matcher))
+
+	event, err := u.CreateEvent(context.Background(), &calendarPb.Event{
+		Title:     "Kickoff",
+		StartTime: start.Unix(),
+		EndTime:   start.Add(time.Hour).Unix(),
+	}, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "google-1", event.Id)
+
+	// Without a matcher the calendar is not consulted
+	disabled := NewCalendarUseCase(nil, new(mockCalendarService), WithDuplicateMatcher(nil))
+	event, err = disabled.CreateEvent(context.Background(), &calendarPb.Event{
+		Title:     "Kickoff",
+		StartTime: start.Unix(),
+		EndTime:   start.Add(time.Hour).Unix(),
+	}, "user-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, "google-1", event.Id)
+}
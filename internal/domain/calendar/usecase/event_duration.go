@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationPattern matches an explicit meeting length such as "for 45 minutes",
+// "lasts 1.5 hours" or "trong 30 phút". Bare amounts are not matched, as in "2 hours
+// before the deadline".
+var durationPattern = regexp.MustCompile(`(?i)(?:\bfor|\blasting|\blasts|\bduration:?|\btrong|\bkéo dài)\s+(?:about\s+|khoảng\s+)?(\d+(?:[.,]\d+)?)\s*(hours?|hrs?|h|minutes?|mins?|giờ|tiếng|phút)(?:[^\p{L}]|$)`)
+
+// maxExplicitDuration caps durations read from the text; longer ones are more likely
+// a misread than a meeting
+const maxExplicitDuration = 24 * time.Hour
+
+// explicitDuration returns the meeting length stated in text, if any
+func explicitDuration(text string) (time.Duration, bool) {
+	match := durationPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+
+	unit := time.Minute
+	switch strings.ToLower(match[2]) {
+	case "hour", "hours", "hr", "hrs", "h", "giờ", "tiếng":
+		unit = time.Hour
+	}
+
+	d := time.Duration(amount * float64(unit))
+	if d > maxExplicitDuration {
+		return 0, false
+	}
+	return d.Round(time.Minute), true
+}
+
+// eventLength returns the duration of an event whose end is not given: the length
+// stated in text, or the configured default
+func (ep *emailProcessorImpl) eventLength(text string) time.Duration {
+	if d, ok := explicitDuration(text); ok {
+		return d
+	}
+	return ep.eventDuration
+}
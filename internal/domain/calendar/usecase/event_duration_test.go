@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplicitDuration(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+		found    bool
+	}{
+		{text: "Sync tomorrow at 9am for 45 minutes.", expected: 45 * time.Minute, found: true},
+		{text: "The workshop lasts 1.5 hours", expected: 90 * time.Minute, found: true},
+		{text: "Duration: 2h", expected: 2 * time.Hour, found: true},
+		{text: "Họp lúc 9h sáng mai trong 30 phút", expected: 30 * time.Minute, found: true},
+		{text: "Buổi đào tạo kéo dài 2 tiếng", expected: 2 * time.Hour, found: true},
+		{text: "Please arrive 2 hours before the talk", found: false},
+		{text: "Standup at 9am", found: false},
+		{text: "Offsite for 3 days", found: false},
+		{text: "Blocked for 48 hours", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			d, found := explicitDuration(tt.text)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_DefaultDuration(t *testing.T) {
+	email := func(body string) string {
+		return "From: organizer@example.com\r\n" +
+			"To: alice@example.com\r\n" +
+			"Subject: Review\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"\r\n" + body
+	}
+	start := parseTime("2025-03-05T09:00:00Z")
+
+	tests := []struct {
+		name     string
+		body     string
+		opts     []EmailProcessorOption
+		expected time.Duration
+	}{
+		{name: "zero value keeps one hour", body: "Review at 9am.", expected: time.Hour},
+		{name: "configured default", body: "Review at 9am.", opts: []EmailProcessorOption{WithDefaultEventDuration(30 * time.Minute)}, expected: 30 * time.Minute},
+		{name: "stated length wins over the default", body: "Review at 9am for 15 minutes.", opts: []EmailProcessorOption{WithDefaultEventDuration(30 * time.Minute)}, expected: 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start}, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, tt.opts...)
+			event, err := processor.ProcessEmail(context.Background(), email(tt.body))
+			require.NoError(t, err)
+
+			assert.Equal(t, start, event.StartTime)
+			assert.Equal(t, start.Add(tt.expected), event.EndTime)
+		})
+	}
+}
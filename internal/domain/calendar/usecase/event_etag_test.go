@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestGoogleCalendarService_UpdateEvent_ETag(t *testing.T) {
+	const etag = `"3181161784712000"`
+	var ifMatch []string
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"items":[
+				{"id":"review","etag":"\"3181161784712000\"","summary":"Review",
+				 "start":{"dateTime":"2025-03-12T14:00:00Z"},"end":{"dateTime":"2025-03-12T15:00:00Z"}}
+			]}`))
+		case http.MethodPut:
+			ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+			if r.Header.Get("If-Match") == etag {
+				// Another worker updated the event since it was listed
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`{"error":{"code":412,"message":"Precondition Failed"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"review"}`))
+		}
+	})
+	calendar := NewCalendarService(svc)
+
+	from := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	events, err := calendar.GetEvents(ctx, TimeRange{StartTime: from, EndTime: from.AddDate(0, 0, 1)}, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, etag, events[0].ETag)
+
+	events[0].Location = "Room 2"
+	err = calendar.UpdateEvent(ctx, events[0])
+	assert.True(t, calerrors.IsConflict(err), "expected a conflict error, got %v", err)
+
+	// Without an ETag the update is unconditional
+	events[0].ETag = ""
+	assert.NoError(t, calendar.UpdateEvent(ctx, events[0]))
+
+	assert.Equal(t, []string{etag, ""}, ifMatch)
+}
+
+func TestCalendarUseCase_CreateEvent_DuplicateUpdateConflict(t *testing.T) {
+	start := time.Date(2025, 3, 5, 14, 0, 0, 0, time.UTC)
+	existing := &CalendarEvent{
+		ID:        "google-1",
+		Title:     "Project kickoff",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		ETag:      `"1"`,
+	}
+
+	calendar := new(mockCalendarService)
+	calendar.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*CalendarEvent{existing}, nil)
+	calendar.On("UpdateEvent", mock.Anything, mock.MatchedBy(func(event *CalendarEvent) bool {
+		return event.ETag == existing.ETag
+	})).Return(calerrors.NewConflictError("event was modified concurrently"))
+
+	_, err := NewCalendarUseCase(nil, calendar).CreateEvent(context.Background(), &calendarPb.Event{
+		Title:     "Fwd: Project Kickoff",
+		StartTime: start.Add(30 * time.Minute).Unix(),
+		EndTime:   start.Add(90 * time.Minute).Unix(),
+	}, "user-1")
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	calendar.AssertExpectations(t)
+}
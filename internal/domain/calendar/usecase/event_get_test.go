@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCalendarUseCase_GetEvent(t *testing.T) {
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		switch r.URL.Path {
+		case "/calendar/v3/calendars/primary/events/evt-1":
+			_, _ = w.Write([]byte(`{"id":"evt-1","summary":"Sync","status":"confirmed",
+				"start":{"dateTime":"2025-03-01T09:00:00Z"},"end":{"dateTime":"2025-03-01T10:00:00Z"}}`))
+		case "/calendar/v3/calendars/primary/events/deleted":
+			_, _ = w.Write([]byte(`{"id":"deleted","status":"cancelled",
+				"start":{"dateTime":"2025-03-01T09:00:00Z"},"end":{"dateTime":"2025-03-01T10:00:00Z"}}`))
+		case "/calendar/v3/calendars/primary/events/failing":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":{"code":500,"message":"backend error"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Not Found"}}`))
+		}
+	})
+	uc := NewCalendarUseCase(nil, NewCalendarService(svc))
+
+	event, err := uc.GetEvent(ctx, "evt-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", event.Id)
+	assert.Equal(t, "Sync", event.Title)
+	assert.Equal(t, parseTime("2025-03-01T09:00:00Z").Unix(), event.StartTime)
+
+	tests := []struct {
+		name     string
+		eventID  string
+		expected codes.Code
+	}{
+		{name: "unknown event", eventID: "missing", expected: codes.NotFound},
+		{name: "deleted event", eventID: "deleted", expected: codes.NotFound},
+		{name: "calendar failure", eventID: "failing", expected: codes.Internal},
+		{name: "empty ID", eventID: "", expected: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.GetEvent(ctx, tt.eventID, "user-1")
+			assert.Equal(t, tt.expected, status.Code(err))
+		})
+	}
+}
+
+func TestOutlookCalendarService_GetEvent(t *testing.T) {
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/me/events/AAMk-1":
+			_, _ = w.Write([]byte(`{"id": "AAMk-1", "subject": "Standup",
+				"start": {"dateTime": "2025-03-05T09:00:00.0000000"}, "end": {"dateTime": "2025-03-05T09:15:00.0000000"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": "ErrorItemNotFound", "message": "The specified object was not found in the store."}}`))
+		}
+	})
+
+	event, err := svc.GetEvent(ctx, "AAMk-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Standup", event.Title)
+
+	_, err = svc.GetEvent(ctx, "AAMk-2")
+	assert.ErrorIs(t, err, ErrEventNotFound)
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// EventRecord is an event created on a calendar from a processed email, kept in the
+// events table for audit and reconciliation
+type EventRecord struct {
+	ID        uint64
+	UserID    string
+	Subject   string
+	StartTime time.Time
+	EndTime   time.Time
+	Location  string
+	Attendees []string
+	// MessageID is the Message-ID header of the source email, "" if it had none
+	MessageID string
+	// GoogleEventID is the ID of the event created on the calendar
+	GoogleEventID string
+	CreatedAt     time.Time
+}
+
+// ErrEventRecordNotFound is returned when no event was recorded for an email
+var ErrEventRecordNotFound = errors.New("event record not found")
+
+// EventRepository stores the events created from processed emails
+type EventRepository interface {
+	// Create inserts record and sets its ID and CreatedAt
+	Create(ctx context.Context, record *EventRecord) error
+	// ListByUser returns the events of a user, most recently created first
+	ListByUser(ctx context.Context, userID string) ([]*EventRecord, error)
+	// FindByMessageID returns the event most recently created for a user from the
+	// email with messageID, or ErrEventRecordNotFound if there is none
+	FindByMessageID(ctx context.Context, userID, messageID string) (*EventRecord, error)
+}
+
+// sqlEventRepository stores event records in the events table
+type sqlEventRepository struct {
+	db *sql.DB
+}
+
+// NewSQLEventRepository creates an EventRepository backed by the events table
+func NewSQLEventRepository(db *sql.DB) EventRepository {
+	return &sqlEventRepository{db: db}
+}
+
+func (r *sqlEventRepository) Create(ctx context.Context, record *EventRecord) error {
+	userID, err := parseUserID(record.UserID)
+	if err != nil {
+		return err
+	}
+
+	attendees, err := json.Marshal(record.Attendees)
+	if err != nil {
+		return fmt.Errorf("failed to encode attendees: %w", err)
+	}
+
+	query := `
+		INSERT INTO events (user_id, subject, start_time, end_time, location, attendees, message_id, google_event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = r.db.QueryRowContext(ctx, query,
+		userID,
+		record.Subject,
+		record.StartTime,
+		record.EndTime,
+		nullString(record.Location),
+		attendees,
+		nullString(record.MessageID),
+		record.GoogleEventID,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert event record: %w", err)
+	}
+	return nil
+}
+
+func (r *sqlEventRepository) ListByUser(ctx context.Context, userID string) ([]*EventRecord, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, subject, start_time, end_time, location, attendees, message_id, google_event_id, created_at
+		FROM events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*EventRecord, 0)
+	for rows.Next() {
+		record, err := scanEventRecord(rows, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list event records: %w", err)
+	}
+
+	return records, nil
+}
+
+func (r *sqlEventRepository) FindByMessageID(ctx context.Context, userID, messageID string) (*EventRecord, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, subject, start_time, end_time, location, attendees, message_id, google_event_id, created_at
+		FROM events
+		WHERE user_id = $1 AND message_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	record, err := scanEventRecord(r.db.QueryRowContext(ctx, query, id, messageID), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEventRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find event record: %w", err)
+	}
+	return record, nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEventRecord reads an event record of userID selected with the columns of
+// ListByUser
+func scanEventRecord(row rowScanner, userID string) (*EventRecord, error) {
+	record := &EventRecord{UserID: userID}
+	var location, messageID sql.NullString
+	var attendees []byte
+	if err := row.Scan(
+		&record.ID,
+		&record.Subject,
+		&record.StartTime,
+		&record.EndTime,
+		&location,
+		&attendees,
+		&messageID,
+		&record.GoogleEventID,
+		&record.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(attendees) > 0 {
+		if err := json.Unmarshal(attendees, &record.Attendees); err != nil {
+			return nil, fmt.Errorf("failed to decode attendees of event record %d: %w", record.ID, err)
+		}
+	}
+	record.Location = location.String
+	record.MessageID = messageID.String
+	return record, nil
+}
+
+// parseUserID returns the users table ID of userID
+func parseUserID(userID string) (uint64, error) {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	return id, nil
+}
+
+// nullString stores "" as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// auditedCalendarService records the events created from emails by a
+// service.CalendarService
+type auditedCalendarService struct {
+	service.CalendarService
+	repository EventRepository
+	logger     *logrus.Logger
+}
+
+// NewAuditedCalendarService wraps svc so that every event it creates from an email is
+// written to repository, with the details of the event as svc created it. Emails are
+// only recorded for the user set with service.WithUserID. Failing to record an event
+// is logged and does not fail its creation.
+func NewAuditedCalendarService(svc service.CalendarService, repository EventRepository) service.CalendarService {
+	return &auditedCalendarService{
+		CalendarService: svc,
+		repository:      repository,
+		logger:          logrus.New(),
+	}
+}
+
+func (s *auditedCalendarService) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*calendarPb.CreateEventResponseV2, error) {
+	resp, err := s.CalendarService.ProcessEmailToCalendar(ctx, emailContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.record(ctx, emailContent, resp.EventID); err != nil {
+		s.logger.WithError(err).WithField("event_id", resp.EventID).Warn("Failed to record created event")
+	}
+	return resp, nil
+}
+
+// record writes the event created from emailContent with ID eventID
+func (s *auditedCalendarService) record(ctx context.Context, emailContent, eventID string) error {
+	userID, ok := service.UserIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Extracting the email again could find other details than the ones the event was
+	// created with, so the created event is read back instead
+	created, err := s.CalendarService.GetEvent(ctx, &calendarPb.GetEventRequestV2{EventID: eventID})
+	if err != nil {
+		return fmt.Errorf("failed to get created event: %w", err)
+	}
+	if created.Event == nil {
+		return fmt.Errorf("created event %s not found", eventID)
+	}
+	event := created.Event
+
+	var messageID string
+	if msg, err := mail.ReadMessage(strings.NewReader(emailContent)); err == nil {
+		messageID = msg.Header.Get("Message-ID")
+	}
+
+	return s.repository.Create(ctx, &EventRecord{
+		UserID:        userID,
+		Subject:       event.Title,
+		StartTime:     time.Unix(event.StartTime, 0).UTC(),
+		EndTime:       time.Unix(event.EndTime, 0).UTC(),
+		Location:      event.Location,
+		Attendees:     event.Attendees,
+		MessageID:     messageID,
+		GoogleEventID: eventID,
+	})
+}
@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+var eventRecordColumns = []string{
+	"id", "subject", "start_time", "end_time", "location", "attendees", "message_id", "google_event_id", "created_at",
+}
+
+func TestSQLEventRepository_Create(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	createdAt := parseTime("2025-02-05T09:00:01Z")
+
+	tests := []struct {
+		name      string
+		record    *EventRecord
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name: "full record",
+			record: &EventRecord{
+				UserID:        "42",
+				Subject:       "Team sync",
+				StartTime:     startTime,
+				EndTime:       startTime.Add(time.Hour),
+				Location:      "Room 1",
+				Attendees:     []string{"a@example.com", "b@example.com"},
+				MessageID:     "<abc123@example.com>",
+				GoogleEventID: "google-1",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO events`).
+					WithArgs(uint64(42), "Team sync", startTime, startTime.Add(time.Hour), "Room 1",
+						[]byte(`["a@example.com","b@example.com"]`), "<abc123@example.com>", "google-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, createdAt))
+			},
+		},
+		{
+			name: "no location or message ID",
+			record: &EventRecord{
+				UserID:        "42",
+				Subject:       "Team sync",
+				StartTime:     startTime,
+				EndTime:       startTime.Add(time.Hour),
+				GoogleEventID: "google-1",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO events`).
+					WithArgs(uint64(42), "Team sync", startTime, startTime.Add(time.Hour), nil,
+						[]byte(`null`), nil, "google-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, createdAt))
+			},
+		},
+		{
+			name:   "database error",
+			record: &EventRecord{UserID: "42", Subject: "Team sync"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO events`).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "non-numeric user ID",
+			record:    &EventRecord{UserID: "user-1"},
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			err = NewSQLEventRepository(db).Create(context.Background(), tt.record)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, uint64(7), tt.record.ID)
+				assert.Equal(t, createdAt, tt.record.CreatedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSQLEventRepository_ListByUser(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	createdAt := parseTime("2025-02-05T09:00:01Z")
+
+	tests := []struct {
+		name      string
+		userID    string
+		mockSetup func(mock sqlmock.Sqlmock)
+		expected  []*EventRecord
+		wantErr   bool
+	}{
+		{
+			name:   "events of user",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events WHERE user_id = \$1 ORDER BY created_at DESC`).
+					WithArgs(uint64(42)).
+					WillReturnRows(sqlmock.NewRows(eventRecordColumns).
+						AddRow(8, "Review", startTime.Add(24*time.Hour), startTime.Add(25*time.Hour), nil, []byte(`null`), nil, "google-2", createdAt.Add(time.Hour)).
+						AddRow(7, "Team sync", startTime, startTime.Add(time.Hour), "Room 1", []byte(`["a@example.com"]`), "<abc123@example.com>", "google-1", createdAt))
+			},
+			expected: []*EventRecord{
+				{
+					ID:            8,
+					UserID:        "42",
+					Subject:       "Review",
+					StartTime:     startTime.Add(24 * time.Hour),
+					EndTime:       startTime.Add(25 * time.Hour),
+					GoogleEventID: "google-2",
+					CreatedAt:     createdAt.Add(time.Hour),
+				},
+				{
+					ID:            7,
+					UserID:        "42",
+					Subject:       "Team sync",
+					StartTime:     startTime,
+					EndTime:       startTime.Add(time.Hour),
+					Location:      "Room 1",
+					Attendees:     []string{"a@example.com"},
+					MessageID:     "<abc123@example.com>",
+					GoogleEventID: "google-1",
+					CreatedAt:     createdAt,
+				},
+			},
+		},
+		{
+			name:   "user without events",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events`).
+					WithArgs(uint64(42)).
+					WillReturnRows(sqlmock.NewRows(eventRecordColumns))
+			},
+			expected: []*EventRecord{},
+		},
+		{
+			name:   "database error",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events`).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "non-numeric user ID",
+			userID:    "user-1",
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			records, err := NewSQLEventRepository(db).ListByUser(context.Background(), tt.userID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, records)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSQLEventRepository_FindByMessageID(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	createdAt := parseTime("2025-02-05T09:00:01Z")
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expected    *EventRecord
+		expectedErr error
+		wantErr     bool
+	}{
+		{
+			name: "latest event of the email",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events WHERE user_id = \$1 AND message_id = \$2 ORDER BY created_at DESC, id DESC LIMIT 1`).
+					WithArgs(uint64(42), "<abc123@example.com>").
+					WillReturnRows(sqlmock.NewRows(eventRecordColumns).
+						AddRow(7, "Team sync", startTime, startTime.Add(time.Hour), "Room 1", []byte(`["a@example.com"]`), "<abc123@example.com>", "google-1", createdAt))
+			},
+			expected: &EventRecord{
+				ID:            7,
+				UserID:        "42",
+				Subject:       "Team sync",
+				StartTime:     startTime,
+				EndTime:       startTime.Add(time.Hour),
+				Location:      "Room 1",
+				Attendees:     []string{"a@example.com"},
+				MessageID:     "<abc123@example.com>",
+				GoogleEventID: "google-1",
+				CreatedAt:     createdAt,
+			},
+		},
+		{
+			name: "email without event",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events`).
+					WithArgs(uint64(42), "<abc123@example.com>").
+					WillReturnRows(sqlmock.NewRows(eventRecordColumns))
+			},
+			expectedErr: ErrEventRecordNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT (.+) FROM events`).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			record, err := NewSQLEventRepository(db).FindByMessageID(context.Background(), "42", "<abc123@example.com>")
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.wantErr:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrEventRecordNotFound)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, record)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuditedCalendarService_ProcessEmailToCalendar(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	created := &calendarPb.Event{
+		Id:        "google-1",
+		Title:     "Project sync",
+		Location:  "Room 1",
+		StartTime: startTime.Unix(),
+		EndTime:   startTime.Add(time.Hour).Unix(),
+		Attendees: []string{"bob@example.com"},
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		createErr error
+		getErr    error
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name: "created event is recorded",
+			ctx:  service.WithUserID(context.Background(), "42"),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO events`).
+					WithArgs(uint64(42), "Project sync", startTime, startTime.Add(time.Hour), "Room 1",
+						[]byte(`["bob@example.com"]`), "<abc123@example.com>", "google-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+			},
+		},
+		{
+			name:      "event that cannot be read back is not recorded",
+			ctx:       service.WithUserID(context.Background(), "42"),
+			getErr:    errors.New("calendar unavailable"),
+			mockSetup: func(sqlmock.Sqlmock) {},
+		},
+		{
+			name: "recording failure does not fail creation",
+			ctx:  service.WithUserID(context.Background(), "42"),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO events`).WillReturnError(errors.New("connection refused"))
+			},
+		},
+		{
+			name:      "email without user is not recorded",
+			ctx:       context.Background(),
+			mockSetup: func(sqlmock.Sqlmock) {},
+		},
+		{
+			name:      "failed creation is not recorded",
+			ctx:       service.WithUserID(context.Background(), "42"),
+			createErr: errors.New("calendar unavailable"),
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(dbMock)
+
+			calendar := new(mockDomainCalendarService)
+			if tt.createErr != nil {
+				calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).Return(nil, tt.createErr)
+			} else {
+				calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+					Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil)
+			}
+			if tt.getErr != nil {
+				calendar.On("GetEvent", mock.Anything, &calendarPb.GetEventRequestV2{EventID: "google-1"}).Return(nil, tt.getErr)
+			} else {
+				calendar.On("GetEvent", mock.Anything, &calendarPb.GetEventRequestV2{EventID: "google-1"}).
+					Return(&calendarPb.GetEventResponseV2{Event: created}, nil)
+			}
+
+			svc := NewAuditedCalendarService(calendar, NewSQLEventRepository(db))
+			resp, err := svc.ProcessEmailToCalendar(tt.ctx, headerTestEmail)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "google-1", resp.EventID)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+// EventResponse is the answer of the user to an event invitation. Values match the
+// attendee responseStatus of Google Calendar.
+type EventResponse string
+
+const (
+	EventAccepted  EventResponse = "accepted"
+	EventDeclined  EventResponse = "declined"
+	EventTentative EventResponse = "tentative"
+)
+
+// ErrNotInvited is returned when responding to an event the user is not invited to
+var ErrNotInvited = errors.New("user is not invited to the event")
+
+// ParseEventResponse returns the EventResponse named by s, ignoring case
+func ParseEventResponse(s string) (EventResponse, error) {
+	switch response := EventResponse(strings.ToLower(strings.TrimSpace(s))); response {
+	case EventAccepted, EventDeclined, EventTentative:
+		return response, nil
+	default:
+		return "", fmt.Errorf("invalid response %q: must be accepted, declined or tentative", s)
+	}
+}
+
+func (u *calendarUseCase) RespondToEvent(ctx context.Context, eventID string, response EventResponse, userID string) error {
+	if eventID == "" {
+		return status.Error(codes.InvalidArgument, "event ID is required")
+	}
+	response, err := ParseEventResponse(string(response))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = u.calendarService.RespondToEvent(ctx, eventID, response)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotInvited):
+		return status.Error(codes.NotFound, err.Error())
+	case calerrors.IsConflict(err):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to respond to event: %v", err)
+	}
+}
+
+func (cs *calendarServiceImpl) RespondToEvent(ctx context.Context, eventID string, response EventResponse) error {
+	return cs.googleCalendar.RespondToEvent(ctx, eventID, response)
+}
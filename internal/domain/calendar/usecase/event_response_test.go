@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+const invitedEventJSON = `{
+	"etag": "\"3\"",
+	"attendees": [
+		{"email": "organizer@example.com", "organizer": true, "responseStatus": "accepted"},
+		{"email": "me@example.com", "self": true, "responseStatus": "needsAction"},
+		{"email": "bob@example.com", "responseStatus": "tentative"}
+	]
+}`
+
+func TestGoogleCalendarService_RespondToEvent(t *testing.T) {
+	var patch struct {
+		Attendees []struct {
+			Email          string `json:"email"`
+			ResponseStatus string `json:"responseStatus"`
+		} `json:"attendees"`
+	}
+	var ifMatch string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/calendar/v3/calendars/primary/events/evt-1":
+			_, _ = w.Write([]byte(invitedEventJSON))
+		case r.Method == http.MethodPatch && r.URL.Path == "/calendar/v3/calendars/primary/events/evt-1":
+			ifMatch = r.Header.Get("If-Match")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	svc, ctx := newGoogleTestService(handler)
+	require.NoError(t, svc.RespondToEvent(ctx, "evt-1", EventDeclined))
+
+	assert.Equal(t, `"3"`, ifMatch)
+	require.Len(t, patch.Attendees, 3)
+	statuses := make(map[string]string, len(patch.Attendees))
+	for _, attendee := range patch.Attendees {
+		statuses[attendee.Email] = attendee.ResponseStatus
+	}
+	assert.Equal(t, map[string]string{
+		"organizer@example.com": "accepted",
+		"me@example.com":        "declined",
+		"bob@example.com":       "tentative",
+	}, statuses)
+}
+
+func TestGoogleCalendarService_RespondToEvent_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		patch   int
+		checkFn func(t *testing.T, err error)
+	}{
+		{
+			name:  "user not among attendees",
+			event: `{"attendees":[{"email":"organizer@example.com","organizer":true}]}`,
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrNotInvited)
+			},
+		},
+		{
+			name:  "attendees changed concurrently",
+			event: invitedEventJSON,
+			patch: http.StatusPreconditionFailed,
+			checkFn: func(t *testing.T, err error) {
+				assert.True(t, calerrors.IsConflict(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched := false
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					patched = true
+					w.WriteHeader(tt.patch)
+					_, _ = w.Write([]byte(`{"error":{"code":412,"message":"Precondition Failed"}}`))
+					return
+				}
+				_, _ = w.Write([]byte(tt.event))
+			}
+
+			svc, ctx := newGoogleTestService(handler)
+			err := svc.RespondToEvent(ctx, "evt-1", EventAccepted)
+			tt.checkFn(t, err)
+			assert.Equal(t, tt.patch != 0, patched)
+		})
+	}
+}
+
+func TestCalendarUseCase_RespondToEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventID    string
+		response   EventResponse
+		serviceErr error
+		callsSvc   bool
+		expected   codes.Code
+	}{
+		{name: "accepted", eventID: "evt-1", response: "Accepted", callsSvc: true, expected: codes.OK},
+		{name: "missing event ID", response: EventAccepted, expected: codes.InvalidArgument},
+		{name: "unknown response", eventID: "evt-1", response: "maybe", expected: codes.InvalidArgument},
+		{name: "not invited", eventID: "evt-1", response: EventDeclined, serviceErr: ErrNotInvited, callsSvc: true, expected: codes.NotFound},
+		{name: "concurrent change", eventID: "evt-1", response: EventDeclined, serviceErr: calerrors.NewConflictError("event was modified concurrently"), callsSvc: true, expected: codes.Aborted},
+		{name: "calendar failure", eventID: "evt-1", response: EventTentative, serviceErr: errors.New("calendar unavailable"), callsSvc: true, expected: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("RespondToEvent", mock.Anything, tt.eventID, mock.Anything).Return(tt.serviceErr)
+
+			err := NewCalendarUseCase(nil, calendar).RespondToEvent(context.Background(), tt.eventID, tt.response, "user-1")
+			assert.Equal(t, tt.expected, status.Code(err))
+			if tt.callsSvc {
+				calendar.AssertCalled(t, "RespondToEvent", mock.Anything, tt.eventID, mock.Anything)
+			} else {
+				calendar.AssertNotCalled(t, "RespondToEvent", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestParseEventResponse(t *testing.T) {
+	response, err := ParseEventResponse(" Tentative ")
+	require.NoError(t, err)
+	assert.Equal(t, EventTentative, response)
+
+	_, err = ParseEventResponse("needsAction")
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+type mockDomainCalendarService struct {
+	mock.Mock
+}
+
+func (m *mockDomainCalendarService) CreateEvent(ctx context.Context, req *calendarPb.NewCreateEventRequest) (*calendarPb.CreateEventResponseV2, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*calendarPb.CreateEventResponseV2), args.Error(1)
+}
+
+func (m *mockDomainCalendarService) GetEvent(ctx context.Context, req *calendarPb.GetEventRequestV2) (*calendarPb.GetEventResponseV2, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*calendarPb.GetEventResponseV2), args.Error(1)
+}
+
+func (m *mockDomainCalendarService) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*calendarPb.CreateEventResponseV2, error) {
+	args := m.Called(ctx, emailContent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*calendarPb.CreateEventResponseV2), args.Error(1)
+}
+
+func withSource(source service.EventSource) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return service.EventSourceFromContext(ctx) == source
+	})
+}
+
+func TestMessagingService_handleDelivery_Source(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  EmailMessage
+		expected service.EventSource
+	}{
+		{
+			name:     "queued email is tagged as queue",
+			message:  EmailMessage{EmailContent: "email", UserID: "user-1"},
+			expected: service.SourceQueue,
+		},
+		{
+			name:     "reprocessed email keeps its source",
+			message:  EmailMessage{EmailContent: "email", UserID: "user-1", Source: service.SourceReprocess},
+			expected: service.SourceReprocess,
+		},
+		{
+			name:     "unknown source falls back to queue",
+			message:  EmailMessage{EmailContent: "email", UserID: "user-1", Source: "carrier-pigeon"},
+			expected: service.SourceQueue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", withSource(tt.expected), "email").
+				Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+			s := &messagingService{
+				calendar: calendar,
+				tracer:   otel.Tracer("test"),
+				logger:   logrus.New(),
+			}
+
+			body, err := json.Marshal(tt.message)
+			assert.NoError(t, err)
+
+			s.handleDelivery(context.Background(), amqp.Delivery{Body: body})
+			calendar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_Source(t *testing.T) {
+	ner := new(mockNERService)
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+		Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).
+		Return("", nil)
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+
+	ctx := service.WithEventSource(context.Background(), service.SourceWebhook)
+	event, err := processor.ProcessEmail(ctx, headerTestEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, service.SourceWebhook, event.Source)
+
+	event, err = processor.ProcessEmail(context.Background(), headerTestEmail)
+	assert.NoError(t, err)
+	assert.Empty(t, event.Source)
+}
+
+func TestCalendarUseCase_CreateEvent_Source(t *testing.T) {
+	uc := NewCalendarUseCase(nil, nil)
+	ctx := service.WithEventSource(context.Background(), service.SourceAPI)
+
+	event, err := uc.CreateEvent(ctx, &calendarPb.Event{Title: "Sync", StartTime: 100, EndTime: 200}, "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "api", event.Metadata[eventSourceMetadataKey])
+
+	assert.Equal(t, map[string]string{"source": "queue"}, toProtoEvent(&CalendarEvent{Source: service.SourceQueue}).Metadata)
+}
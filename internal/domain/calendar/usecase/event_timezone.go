@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// inferTimezone returns the IANA timezone of an event: the zone its start time was
+// parsed in when the text named one, such as "3pm EST", or else the zone matching the
+// offset of the email Date header. It returns "" when neither is known.
+func (ep *emailProcessorImpl) inferTimezone(start time.Time, metadata EmailMetadata) string {
+	if name := start.Location().String(); name != "" && name != "Local" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+
+	if metadata.Date.IsZero() {
+		return ""
+	}
+	_, offset := metadata.Date.Zone()
+	return ep.tzUtil.TimezoneForOffset(offset, metadata.Date)
+}
+
+// withSenderTimezone makes the times of the email that name no timezone read in the
+// zone of its Date header offset, as the sender likely wrote them in their own time.
+// A preferred timezone of the user already in ctx is kept, and so is ctx when the
+// email has no Date header or its offset maps to no zone.
+func (ep *emailProcessorImpl) withSenderTimezone(ctx context.Context, metadata EmailMetadata) context.Context {
+	if metadata.Date.IsZero() || service.TimezoneFromContext(ctx) != "" {
+		return ctx
+	}
+
+	_, offset := metadata.Date.Zone()
+	if timezone := ep.tzUtil.TimezoneForOffset(offset, metadata.Date); timezone != "" {
+		return service.WithTimezone(ctx, timezone)
+	}
+	return ctx
+}
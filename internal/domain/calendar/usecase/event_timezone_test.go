@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+func TestParseDateTime_TimezoneAbbreviation(t *testing.T) {
+	tzUtil := NewTimezoneUtil("Asia/Ho_Chi_Minh")
+
+	parsed, err := parseDateTime(context.Background(), tzUtil, "3pm EST")
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", parsed.Location().String())
+	assert.Equal(t, 15, parsed.Hour())
+
+	parsed, err = parseDateTime(context.Background(), tzUtil, "10:30 (JST)")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", parsed.Location().String())
+	assert.Equal(t, 10, parsed.Hour())
+	assert.Equal(t, 30, parsed.Minute())
+
+	// AEST must not be mistaken for EST
+	parsed, err = parseDateTime(context.Background(), tzUtil, "9am AEST")
+	require.NoError(t, err)
+	assert.Equal(t, "Australia/Sydney", parsed.Location().String())
+}
+
+func TestEmailProcessorImpl_ProcessEmail_TimezoneFromText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(nerResponse{Entities: []Entity{{Text: "3pm EST", Label: "TIME"}}})
+	}))
+	defer server.Close()
+
+	emailContent := "From: organizer@example.com\r\n" +
+		"Date: Mon, 3 Mar 2025 08:00:00 +0700\r\n" +
+		"Subject: Call with the New York office\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Let's talk at 3pm EST."
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), NewNERService(server.URL))
+	event, err := processor.ProcessEmail(context.Background(), emailContent)
+	require.NoError(t, err)
+
+	// The abbreviation in the text wins over the Date header offset
+	assert.Equal(t, "America/New_York", event.TimeZone)
+	assert.Equal(t, 15, event.StartTime.Hour())
+}
+
+func TestEmailProcessorImpl_ProcessEmail_TimezoneFromDateHeader(t *testing.T) {
+	tests := []struct {
+		date     string
+		expected string
+	}{
+		{date: "Mon, 3 Mar 2025 08:00:00 +0700", expected: "Asia/Ho_Chi_Minh"},
+		{date: "Mon, 3 Mar 2025 08:00:00 -0500", expected: "Etc/GMT+5"},
+		{date: "Mon, 3 Mar 2025 08:00:00 +0530", expected: "Asia/Kolkata"},
+		{date: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			start := time.Date(2025, 3, 4, 9, 0, 0, 0, time.Local)
+			ner := new(mockNERService)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start}, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+			emailContent := "From: organizer@example.com\r\n"
+			if tt.date != "" {
+				emailContent += "Date: " + tt.date + "\r\n"
+			}
+			emailContent += "Subject: Planning\r\nContent-Type: text/plain\r\n\r\nPlanning at 9am."
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+			event, err := processor.ProcessEmail(context.Background(), emailContent)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, event.TimeZone)
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_BareTimeInDateHeaderZone(t *testing.T) {
+	tests := []struct {
+		name       string
+		userTz     string
+		entity     string
+		timezone   string
+		offset     int
+		multiEvent bool
+	}{
+		{name: "bare time read at the Date header offset", entity: "10:00", timezone: "Etc/GMT-9", offset: 9 * 3600},
+		{name: "multi-event email", entity: "10:00", timezone: "Etc/GMT-9", offset: 9 * 3600, multiEvent: true},
+		{name: "abbreviation in the text wins", entity: "10:00 EST", timezone: "America/New_York"},
+		{name: "user timezone wins", userTz: "Europe/Paris", entity: "10:00", timezone: "Europe/Paris"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(nerResponse{Entities: []Entity{{Text: tt.entity, Label: "TIME"}}})
+			}))
+			defer server.Close()
+
+			emailContent := "From: organizer@example.jp\r\n" +
+				"Date: Mon, 3 Mar 2025 08:00:00 +0900\r\n" +
+				"Subject: Sync with the Tokyo office\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Let's meet at " + tt.entity + "."
+
+			ctx := context.Background()
+			if tt.userTz != "" {
+				ctx = service.WithTimezone(ctx, tt.userTz)
+			}
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), NewNERService(server.URL))
+			var event *EmailEvent
+			if tt.multiEvent {
+				events, err := processor.ProcessEmailMulti(ctx, emailContent)
+				require.NoError(t, err)
+				require.Len(t, events, 1)
+				event = events[0]
+			} else {
+				var err error
+				event, err = processor.ProcessEmail(ctx, emailContent)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.timezone, event.TimeZone)
+			assert.Equal(t, 10, event.StartTime.Hour())
+			assert.Equal(t, 0, event.StartTime.Minute())
+			if tt.offset != 0 {
+				_, offset := event.StartTime.Zone()
+				assert.Equal(t, tt.offset, offset)
+			}
+		})
+	}
+}
+
+func TestTimezoneUtil_TimezoneForOffset(t *testing.T) {
+	tzUtil := NewTimezoneUtil("Asia/Ho_Chi_Minh")
+	at := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "Asia/Ho_Chi_Minh", tzUtil.TimezoneForOffset(7*3600, at))
+	assert.Equal(t, "UTC", tzUtil.TimezoneForOffset(0, at))
+	assert.Equal(t, "Etc/GMT-9", tzUtil.TimezoneForOffset(9*3600, at))
+	assert.Equal(t, "Etc/GMT+8", tzUtil.TimezoneForOffset(-8*3600, at))
+	assert.Equal(t, "Asia/Kathmandu", tzUtil.TimezoneForOffset(5*3600+2700, at))
+	assert.Equal(t, "", tzUtil.TimezoneForOffset(1234, at))
+}
+
+func TestCalendarService_CreateEvent_TimeZone(t *testing.T) {
+	google := &recordingGoogleCalendar{}
+	err := NewCalendarService(google).CreateEvent(context.Background(), &CalendarEvent{
+		Title:     "Call",
+		StartTime: time.Date(2025, 3, 3, 20, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 3, 21, 0, 0, 0, time.UTC),
+		TimeZone:  "America/New_York",
+	})
+	require.NoError(t, err)
+	require.Len(t, google.created, 1)
+
+	g := &googleCalendarServiceImpl{}
+	created := google.created[0]
+	start := g.convertToEventDateTime(created.Start, created.IsAllDay, created.TimeZone)
+	assert.Equal(t, "America/New_York", start.TimeZone)
+	assert.Equal(t, "2025-03-03T20:00:00Z", start.DateTime)
+}
@@ -2,30 +2,129 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	calendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	"mail2calendar/internal/domain/calendar/service"
 )
 
 const defaultTimezone = "Asia/Ho_Chi_Minh"
 
+// defaultWorkingDays and the default working hours, 9 AM to 5 PM, are the baseline
+// schedule reported by GetWorkingHours
+var defaultWorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+const (
+	defaultWorkdayStart = 9 * time.Hour
+	defaultWorkdayEnd   = 17 * time.Hour
+)
+
+// googleListPageSize is the number of events fetched per Google Calendar list request,
+// the most the API allows
+const googleListPageSize = 2500
+
 type googleCalendarServiceImpl struct {
 	oauthConfig *OAuthConfig
 	tracer      trace.Tracer
 	userID      string
+	// workingDays, workdayStart and workdayEnd are the baseline working week, as Google
+	// Calendar does not expose the working hours of a user. Hours are offsets from
+	// midnight.
+	workingDays  []time.Weekday
+	workdayStart time.Duration
+	workdayEnd   time.Duration
+	// sendUpdates tells Google whom to notify of created and updated events
+	sendUpdates SendUpdates
+}
+
+// SendUpdates is the sendUpdates parameter of Google Calendar: which attendees Google
+// emails about an event the service creates or updates
+type SendUpdates string
+
+const (
+	// SendUpdatesAll notifies every attendee
+	SendUpdatesAll SendUpdates = "all"
+	// SendUpdatesExternalOnly notifies only attendees not using Google Calendar
+	SendUpdatesExternalOnly SendUpdates = "externalOnly"
+	// SendUpdatesNone notifies no one, the default so that attendees are not spammed
+	// with the events of test runs
+	SendUpdatesNone SendUpdates = "none"
+)
+
+// GoogleCalendarOption configures optional behaviour of the Google Calendar service
+type GoogleCalendarOption func(*googleCalendarServiceImpl)
+
+// WithWorkingDays sets the days of the baseline working week, such as Sunday to
+// Thursday. Duplicates are dropped. Without any day the default, Monday to Friday, is
+// kept.
+func WithWorkingDays(days ...time.Weekday) GoogleCalendarOption {
+	return func(g *googleCalendarServiceImpl) {
+		seen := make(map[time.Weekday]bool, len(days))
+		var workingDays []time.Weekday
+		for _, day := range days {
+			if day < time.Sunday || day > time.Saturday || seen[day] {
+				continue
+			}
+			seen[day] = true
+			workingDays = append(workingDays, day)
+		}
+		if len(workingDays) > 0 {
+			g.workingDays = workingDays
+		}
+	}
+}
+
+// WithWorkingHours sets the hours of a baseline working day as offsets from midnight,
+// e.g. 10*time.Hour and 18*time.Hour for 10 AM to 6 PM. Ranges not within a single
+// day, or ending before they start, keep the default of 9 AM to 5 PM.
+func WithWorkingHours(start, end time.Duration) GoogleCalendarOption {
+	return func(g *googleCalendarServiceImpl) {
+		if start < 0 || end > 24*time.Hour || end <= start {
+			return
+		}
+		g.workdayStart = start
+		g.workdayEnd = end
+	}
+}
+
+// WithSendUpdates sets whom Google notifies by email of the events the service creates
+// and updates. Values other than all, externalOnly and none keep the default, none.
+func WithSendUpdates(sendUpdates SendUpdates) GoogleCalendarOption {
+	return func(g *googleCalendarServiceImpl) {
+		switch sendUpdates {
+		case SendUpdatesAll, SendUpdatesExternalOnly, SendUpdatesNone:
+			g.sendUpdates = sendUpdates
+		}
+	}
 }
 
 // NewGoogleCalendarService creates a new instance of GoogleCalendarService
-func NewGoogleCalendarService(oauth *OAuthConfig, tracer trace.Tracer, userID string) GoogleCalendarService {
-	return &googleCalendarServiceImpl{
-		oauthConfig: oauth,
-		tracer:      tracer,
-		userID:      userID,
+func NewGoogleCalendarService(oauth *OAuthConfig, tracer trace.Tracer, userID string, opts ...GoogleCalendarOption) GoogleCalendarService {
+	g := &googleCalendarServiceImpl{
+		oauthConfig:  oauth,
+		tracer:       tracer,
+		userID:       userID,
+		workingDays:  defaultWorkingDays,
+		workdayStart: defaultWorkdayStart,
+		workdayEnd:   defaultWorkdayEnd,
+		sendUpdates:  SendUpdatesNone,
 	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
 }
 
 func (g *googleCalendarServiceImpl) ListEvents(ctx context.Context, startTime, endTime time.Time, attendees []string) ([]*GoogleCalendarEvent, error) {
@@ -44,58 +143,166 @@ func (g *googleCalendarServiceImpl) ListEvents(ctx context.Context, startTime, e
 		return nil, fmt.Errorf("failed to get calendar service: %v", err)
 	}
 
-	// Query for events
-	events, err := client.Events.List("primary").
+	// Query for events, following nextPageToken until the whole range is fetched
+	var items []*calendar.Event
+	err = client.Events.List("primary").
 		TimeMin(startTime.Format(time.RFC3339)).
 		TimeMax(endTime.Format(time.RFC3339)).
 		SingleEvents(true).
 		OrderBy("startTime").
-		Do()
+		MaxResults(googleListPageSize).
+		Pages(ctx, func(page *calendar.Events) error {
+			items = append(items, page.Items...)
+			return nil
+		})
 
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to list events: %v", err)
 	}
+	span.SetAttributes(attribute.Int("events_count", len(items)))
 
-	// Convert to domain events
-	result := make([]*GoogleCalendarEvent, 0, len(events.Items))
-	for _, event := range events.Items {
-		// Extract attendees
-		attendeesList := make([]string, 0, len(event.Attendees))
-		for _, attendee := range event.Attendees {
-			attendeesList = append(attendeesList, attendee.Email)
-		}
+	result := make([]*GoogleCalendarEvent, 0, len(items))
+	for _, event := range items {
+		result = append(result, fromGoogleEvent(event))
+	}
 
-		// Convert start time
-		var startTime time.Time
-		if event.Start.DateTime != "" {
-			startTime, _ = time.Parse(time.RFC3339, event.Start.DateTime)
-		} else {
-			startTime, _ = time.Parse("2006-01-02", event.Start.Date)
+	return result, nil
+}
+
+func (g *googleCalendarServiceImpl) GetEvent(ctx context.Context, eventID string) (*GoogleCalendarEvent, error) {
+	ctx, span := g.tracer.Start(ctx, "GoogleCalendar.GetEvent")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	client, err := g.getCalendarService(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get calendar service: %v", err)
+	}
+
+	event, err := client.Events.Get("primary", eventID).Context(ctx).Do()
+	if err != nil {
+		span.RecordError(err)
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone) {
+			return nil, ErrEventNotFound
 		}
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	// Google still returns deleted events by ID, marked as cancelled
+	if event.Status == "cancelled" {
+		return nil, ErrEventNotFound
+	}
+
+	return fromGoogleEvent(event), nil
+}
+
+func (g *googleCalendarServiceImpl) ListEventsPage(ctx context.Context, startTime, endTime time.Time, pageSize int, pageToken string) ([]*GoogleCalendarEvent, string, error) {
+	ctx, span := g.tracer.Start(ctx, "GoogleCalendar.ListEventsPage")
+	defer span.End()
 
-		// Convert end time
-		var endTime time.Time
-		if event.End.DateTime != "" {
-			endTime, _ = time.Parse(time.RFC3339, event.End.DateTime)
-		} else {
-			endTime, _ = time.Parse("2006-01-02", event.End.Date)
+	span.SetAttributes(
+		attribute.String("start_time", startTime.Format(time.RFC3339)),
+		attribute.String("end_time", endTime.Format(time.RFC3339)),
+		attribute.Int("page_size", pageSize),
+	)
+
+	client, err := g.getCalendarService(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", fmt.Errorf("failed to get calendar service: %v", err)
+	}
+
+	call := client.Events.List("primary").
+		TimeMin(startTime.Format(time.RFC3339)).
+		TimeMax(endTime.Format(time.RFC3339)).
+		SingleEvents(true).
+		OrderBy("startTime").
+		MaxResults(int64(pageSize)).
+		Context(ctx)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+
+	page, err := call.Do()
+	if err != nil {
+		span.RecordError(err)
+		// The other parameters are validated before the call, so a rejected request
+		// with a token means Google did not issue the token
+		var apiErr *googleapi.Error
+		if pageToken != "" && errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest {
+			return nil, "", ErrInvalidPageToken
 		}
+		return nil, "", fmt.Errorf("failed to list events: %v", err)
+	}
+	span.SetAttributes(attribute.Int("events_count", len(page.Items)))
 
-		result = append(result, &GoogleCalendarEvent{
-			ID:             event.Id,
-			Summary:        event.Summary,
-			Start:          startTime,
-			End:            endTime,
-			Location:       event.Location,
-			Attendees:      attendeesList,
-			IsAllDay:       event.Start.DateTime == "",
-			IsRecurring:    event.RecurringEventId != "",
-			RecurrenceRule: firstOrEmpty(event.Recurrence),
-		})
+	result := make([]*GoogleCalendarEvent, 0, len(page.Items))
+	for _, event := range page.Items {
+		result = append(result, fromGoogleEvent(event))
 	}
 
-	return result, nil
+	return result, page.NextPageToken, nil
+}
+
+// fromGoogleEvent converts an event returned by the Google Calendar API
+func fromGoogleEvent(event *calendar.Event) *GoogleCalendarEvent {
+	// Extract attendees
+	attendeesList := make([]string, 0, len(event.Attendees))
+	statuses := make([]Attendee, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		attendeesList = append(attendeesList, attendee.Email)
+		// Google uses the same response names as ResponseStatus
+		statuses = append(statuses, Attendee{Email: attendee.Email, ResponseStatus: ResponseStatus(attendee.ResponseStatus)})
+	}
+
+	// Convert start time
+	var startTime time.Time
+	if event.Start.DateTime != "" {
+		startTime, _ = time.Parse(time.RFC3339, event.Start.DateTime)
+	} else {
+		startTime, _ = time.Parse("2006-01-02", event.Start.Date)
+	}
+
+	// Convert end time
+	var endTime time.Time
+	if event.End.DateTime != "" {
+		endTime, _ = time.Parse(time.RFC3339, event.End.DateTime)
+	} else {
+		endTime, _ = time.Parse("2006-01-02", event.End.Date)
+	}
+
+	created, _ := time.Parse(time.RFC3339, event.Created)
+
+	var organizer string
+	if event.Organizer != nil {
+		organizer = event.Organizer.Email
+	}
+
+	// SingleEvents expands recurring events into instances, which carry no rule of
+	// their own and only point back to their series
+	return &GoogleCalendarEvent{
+		ID:               event.Id,
+		Summary:          event.Summary,
+		Start:            startTime,
+		End:              endTime,
+		Location:         event.Location,
+		Description:      event.Description,
+		Organizer:        organizer,
+		Attendees:        attendeesList,
+		AttendeeStatuses: statuses,
+		IsAllDay:         event.Start.DateTime == "",
+		IsRecurring:      len(event.Recurrence) > 0,
+		RecurrenceRule:   firstOrEmpty(event.Recurrence),
+		SeriesID:         event.RecurringEventId,
+		TimeZone:         event.Start.TimeZone,
+		Created:          created,
+		Headers:          privateHeaders(event.ExtendedProperties),
+		Source:           privateSource(event.ExtendedProperties),
+		ETag:             event.Etag,
+	}
 }
 
 func (g *googleCalendarServiceImpl) CreateEvent(ctx context.Context, event *GoogleCalendarEvent) error {
@@ -116,28 +323,33 @@ func (g *googleCalendarServiceImpl) CreateEvent(ctx context.Context, event *Goog
 	calendarEvent := &calendar.Event{
 		Summary:     event.Summary,
 		Location:    event.Location,
-		Description: "",
-		Start:       g.convertToEventDateTime(event.Start, event.IsAllDay),
-		End:         g.convertToEventDateTime(event.End, event.IsAllDay),
+		Description: event.Description,
+		Start:       g.convertToEventDateTime(event.Start, event.IsAllDay, event.TimeZone),
+		End:         g.convertToEventDateTime(event.End, event.IsAllDay, event.TimeZone),
 	}
 
-	// Add attendees
-	for _, email := range event.Attendees {
-		calendarEvent.Attendees = append(calendarEvent.Attendees, &calendar.EventAttendee{
-			Email: email,
-		})
-	}
+	// Set the organizer and add attendees
+	calendarEvent.Organizer, calendarEvent.Attendees = g.convertToParticipants(event)
 
 	// Add recurrence if specified
 	if event.IsRecurring && event.RecurrenceRule != "" {
 		calendarEvent.Recurrence = []string{event.RecurrenceRule}
 	}
 
-	_, err = client.Events.Insert("primary", calendarEvent).Do()
+	// Store source email headers and ingestion channel as private extended properties
+	if metadata := eventMetadata(event.Headers, event.Source); metadata != nil {
+		calendarEvent.ExtendedProperties = &calendar.EventExtendedProperties{
+			Private: metadata,
+		}
+	}
+
+	created, err := client.Events.Insert("primary", calendarEvent).SendUpdates(string(g.sendUpdates)).Do()
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to create event: %v", err)
 	}
+	event.ID = created.Id
+	event.ETag = created.Etag
 
 	return nil
 }
@@ -160,26 +372,39 @@ func (g *googleCalendarServiceImpl) UpdateEvent(ctx context.Context, event *Goog
 	calendarEvent := &calendar.Event{
 		Summary:     event.Summary,
 		Location:    event.Location,
-		Description: "",
-		Start:       g.convertToEventDateTime(event.Start, event.IsAllDay),
-		End:         g.convertToEventDateTime(event.End, event.IsAllDay),
+		Description: event.Description,
+		Start:       g.convertToEventDateTime(event.Start, event.IsAllDay, event.TimeZone),
+		End:         g.convertToEventDateTime(event.End, event.IsAllDay, event.TimeZone),
 	}
 
-	// Add attendees
-	for _, email := range event.Attendees {
-		calendarEvent.Attendees = append(calendarEvent.Attendees, &calendar.EventAttendee{
-			Email: email,
-		})
-	}
+	// Set the organizer and add attendees
+	calendarEvent.Organizer, calendarEvent.Attendees = g.convertToParticipants(event)
 
 	// Add recurrence if specified
 	if event.IsRecurring && event.RecurrenceRule != "" {
 		calendarEvent.Recurrence = []string{event.RecurrenceRule}
 	}
 
-	_, err = client.Events.Update("primary", event.ID, calendarEvent).Do()
+	// Store source email headers and ingestion channel as private extended properties
+	if metadata := eventMetadata(event.Headers, event.Source); metadata != nil {
+		calendarEvent.ExtendedProperties = &calendar.EventExtendedProperties{
+			Private: metadata,
+		}
+	}
+
+	call := client.Events.Update("primary", event.ID, calendarEvent).SendUpdates(string(g.sendUpdates))
+	if event.ETag != "" {
+		// Google rejects the update with 412 when the event changed since it was listed
+		call.Header().Set("If-Match", event.ETag)
+	}
+
+	_, err = call.Do()
 	if err != nil {
 		span.RecordError(err)
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return calerrors.NewConflictError("event was modified concurrently").WithWrappedError(err)
+		}
 		return fmt.Errorf("failed to update event: %v", err)
 	}
 
@@ -207,12 +432,71 @@ func (g *googleCalendarServiceImpl) DeleteEvent(ctx context.Context, eventID str
 	return nil
 }
 
-func (g *googleCalendarServiceImpl) GetWorkingHours(ctx context.Context, attendees []string) (map[string]*GoogleWorkingHours, error) {
+func (g *googleCalendarServiceImpl) RespondToEvent(ctx context.Context, eventID string, response EventResponse) error {
+	ctx, span := g.tracer.Start(ctx, "GoogleCalendar.RespondToEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", eventID),
+		attribute.String("response", string(response)),
+	)
+
+	client, err := g.getCalendarService(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to get calendar service: %v", err)
+	}
+
+	event, err := client.Events.Get("primary", eventID).Fields("attendees", "etag").Do()
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to get event: %v", err)
+	}
+
+	// Patch replaces the whole attendee list, so every attendee is sent back with only
+	// the status of the user changed
+	found := false
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			attendee.ResponseStatus = string(response)
+			found = true
+		}
+	}
+	if !found {
+		return ErrNotInvited
+	}
+
+	call := client.Events.Patch("primary", eventID, &calendar.Event{Attendees: event.Attendees})
+	if event.Etag != "" {
+		// Attendees changed since the event was read must not be overwritten
+		call.Header().Set("If-Match", event.Etag)
+	}
+
+	_, err = call.Do()
+	if err != nil {
+		span.RecordError(err)
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return calerrors.NewConflictError("event was modified concurrently").WithWrappedError(err)
+		}
+		return fmt.Errorf("failed to respond to event: %v", err)
+	}
+
+	return nil
+}
+
+func (g *googleCalendarServiceImpl) GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*GoogleWorkingHours, error) {
 	ctx, span := g.tracer.Start(ctx, "GoogleCalendar.GetWorkingHours")
 	defer span.End()
 
 	span.SetAttributes(attribute.Int("attendees_count", len(attendees)))
 
+	start, end, err := workingHoursRange(timeRange)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	client, err := g.getCalendarService(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -231,8 +515,8 @@ func (g *googleCalendarServiceImpl) GetWorkingHours(ctx context.Context, attende
 		}
 	}
 
-	timeMin := time.Now().Format(time.RFC3339)
-	timeMax := time.Now().AddDate(0, 0, 7).Format(time.RFC3339)
+	timeMin := start.Format(time.RFC3339)
+	timeMax := end.Format(time.RFC3339)
 
 	// Build calendar items for query
 	items := make([]*calendar.FreeBusyRequestItem, len(attendees))
@@ -291,7 +575,9 @@ func (g *googleCalendarServiceImpl) getCalendarService(ctx context.Context) (*ca
 	return calendar.NewService(ctx, option.WithHTTPClient(client))
 }
 
-func (g *googleCalendarServiceImpl) convertToEventDateTime(t time.Time, isAllDay bool) *calendar.EventDateTime {
+// convertToEventDateTime returns t as a date for all-day events or as a date-time in
+// timeZone. An empty timeZone leaves the calendar default.
+func (g *googleCalendarServiceImpl) convertToEventDateTime(t time.Time, isAllDay bool, timeZone string) *calendar.EventDateTime {
 	if isAllDay {
 		return &calendar.EventDateTime{
 			Date: t.Format("2006-01-02"),
@@ -299,25 +585,67 @@ func (g *googleCalendarServiceImpl) convertToEventDateTime(t time.Time, isAllDay
 	}
 	return &calendar.EventDateTime{
 		DateTime: t.Format(time.RFC3339),
+		TimeZone: timeZone,
+	}
+}
+
+// convertToParticipants returns the organizer and attendees of an event. The organizer
+// owns the event, so they are left out of the attendees to avoid inviting them.
+func (g *googleCalendarServiceImpl) convertToParticipants(event *GoogleCalendarEvent) (*calendar.EventOrganizer, []*calendar.EventAttendee) {
+	var organizer *calendar.EventOrganizer
+	if event.Organizer != "" {
+		organizer = &calendar.EventOrganizer{Email: event.Organizer}
+	}
+
+	var attendees []*calendar.EventAttendee
+	for _, email := range event.Attendees {
+		if strings.EqualFold(email, event.Organizer) {
+			continue
+		}
+		attendees = append(attendees, &calendar.EventAttendee{
+			Email: email,
+		})
 	}
+	return organizer, attendees
 }
 
+// extractWorkingSchedule returns the baseline working week configured on the service
 func (g *googleCalendarServiceImpl) extractWorkingSchedule(busySlots []*calendar.TimePeriod) []GoogleWeeklySchedule {
-	// Default working hours (9 AM - 5 PM, Mon-Fri)
-	schedules := make([]GoogleWeeklySchedule, 5)
-	for i := 0; i < 5; i++ {
+	schedules := make([]GoogleWeeklySchedule, len(g.workingDays))
+	for i, day := range g.workingDays {
 		schedules[i] = GoogleWeeklySchedule{
-			DayOfWeek: time.Weekday(i + 1), // Monday = 1
-			StartTime: time.Date(0, 0, 0, 9, 0, 0, 0, time.Local),
-			EndTime:   time.Date(0, 0, 0, 17, 0, 0, 0, time.Local),
+			DayOfWeek: day,
+			StartTime: timeOfDay(g.workdayStart),
+			EndTime:   timeOfDay(g.workdayEnd),
 		}
 	}
 	return schedules
 }
 
+// timeOfDay returns the clock time offset from midnight, on the zero date schedules use
+func timeOfDay(offset time.Duration) time.Time {
+	return time.Date(0, 0, 0, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, time.Local)
+}
+
 func firstOrEmpty(slice []string) string {
 	if len(slice) > 0 {
 		return slice[0]
 	}
 	return ""
 }
+
+// privateHeaders reads the email headers stored in an event's private extended properties
+func privateHeaders(properties *calendar.EventExtendedProperties) map[string]string {
+	if properties == nil {
+		return nil
+	}
+	return headersFromMetadata(properties.Private)
+}
+
+// privateSource reads the ingestion channel stored in an event's private extended properties
+func privateSource(properties *calendar.EventExtendedProperties) service.EventSource {
+	if properties == nil {
+		return ""
+	}
+	return service.EventSource(properties.Private[eventSourceMetadataKey])
+}
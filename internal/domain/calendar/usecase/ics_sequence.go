@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStaleICSUpdate is returned for an ICS update older than the version already applied
+var ErrStaleICSUpdate = errors.New("stale ICS update")
+
+// SequenceStore remembers the highest SEQUENCE applied for each ICS UID
+type SequenceStore interface {
+	// Advance records sequence as applied for uid. It returns ErrStaleICSUpdate, and
+	// records nothing, if a higher sequence was already applied.
+	Advance(ctx context.Context, uid string, sequence int) error
+}
+
+// memorySequenceStore is an in-memory SequenceStore
+type memorySequenceStore struct {
+	mu        sync.Mutex
+	sequences map[string]int
+}
+
+// NewMemorySequenceStore creates an in-memory SequenceStore
+func NewMemorySequenceStore() SequenceStore {
+	return &memorySequenceStore{
+		sequences: make(map[string]int),
+	}
+}
+
+func (s *memorySequenceStore) Advance(_ context.Context, uid string, sequence int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.sequences[uid]; ok && sequence < last {
+		return ErrStaleICSUpdate
+	}
+	s.sequences[uid] = sequence
+	return nil
+}
+
+// ICSUpdateFilter drops ICS updates that arrive after a newer version of the same event
+type ICSUpdateFilter struct {
+	store SequenceStore
+}
+
+// NewICSUpdateFilter creates a filter backed by store
+func NewICSUpdateFilter(store SequenceStore) *ICSUpdateFilter {
+	return &ICSUpdateFilter{store: store}
+}
+
+// Apply calls apply unless a higher SEQUENCE was already applied for the event's UID,
+// and reports whether it did. The sequence is recorded before apply runs so a late,
+// older update racing with it is dropped; an equal sequence is applied again, which
+// lets a failed apply be retried. Events without a UID are always applied.
+func (f *ICSUpdateFilter) Apply(ctx context.Context, event *CalendarEvent, apply func(context.Context, *CalendarEvent) error) (bool, error) {
+	if event.UID != "" {
+		err := f.store.Advance(ctx, event.UID, event.Sequence)
+		if errors.Is(err, ErrStaleICSUpdate) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := apply(ctx, event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
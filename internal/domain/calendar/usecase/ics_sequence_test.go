@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICSUpdateFilter_Apply(t *testing.T) {
+	parser := NewCalendarParser()
+	filter := NewICSUpdateFilter(NewMemorySequenceStore())
+
+	var applied []string
+	apply := func(_ context.Context, event *CalendarEvent) error {
+		applied = append(applied, event.Title)
+		return nil
+	}
+
+	update := func(sequence int, summary string) *CalendarEvent {
+		event, err := parser.ParseICSAttachment(icsWithEvent(
+			fmt.Sprintf("SEQUENCE:%d", sequence),
+			"DTEND:20250305T100000Z",
+		))
+		require.NoError(t, err)
+		require.Equal(t, "duration-test@example.com", event.UID)
+		require.Equal(t, sequence, event.Sequence)
+		event.Title = summary
+		return event
+	}
+
+	ok, err := filter.Apply(context.Background(), update(2, "moved to 10am"), apply)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// SEQUENCE 1 was sent earlier but arrives late, it must not undo SEQUENCE 2
+	ok, err = filter.Apply(context.Background(), update(1, "original"), apply)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// A redelivery of the applied version and newer versions still go through
+	ok, err = filter.Apply(context.Background(), update(2, "moved to 10am"), apply)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = filter.Apply(context.Background(), update(3, "cancelled"), apply)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, []string{"moved to 10am", "moved to 10am", "cancelled"}, applied)
+}
+
+func TestICSUpdateFilter_Apply_WithoutUID(t *testing.T) {
+	filter := NewICSUpdateFilter(NewMemorySequenceStore())
+
+	calls := 0
+	apply := func(context.Context, *CalendarEvent) error {
+		calls++
+		return nil
+	}
+
+	for _, sequence := range []int{2, 1} {
+		ok, err := filter.Apply(context.Background(), &CalendarEvent{Sequence: sequence}, apply)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestCalendarParser_Sequence(t *testing.T) {
+	event, err := NewCalendarParser().ParseICSAttachment(icsWithEvent("DTEND:20250305T100000Z"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, event.Sequence)
+
+	_, err = NewCalendarParser().ParseICSAttachment(icsWithEvent("SEQUENCE:two"))
+	assert.Error(t, err)
+}
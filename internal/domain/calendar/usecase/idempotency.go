@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// defaultIdempotencyTTL is how long an idempotency key is remembered after the event is created
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyClaimTTL is how long a claimed key waits for its event to be created. A
+// claim abandoned by a crashed request expires after it and the key can be retried.
+const idempotencyClaimTTL = 2 * time.Minute
+
+// idempotencyRedisKeyPrefix namespaces idempotency keys in Redis
+const idempotencyRedisKeyPrefix = "calendar_idempotency:"
+
+// sharedIdempotencyScope namespaces keys of emails processed without a user. Those
+// events all go to the shared calendar, so their keys only collide with each other.
+const sharedIdempotencyScope = "shared"
+
+var (
+	// ErrIdempotencyKeyInProgress is returned when another request with the same
+	// idempotency key is still creating its event
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrIdempotencyKeyUnscoped is returned for an idempotency key sent without an
+	// authenticated user, which would otherwise be shared by every client
+	ErrIdempotencyKeyUnscoped = errors.New("an idempotency key requires an authenticated user")
+)
+
+// IdempotencyStore remembers the response of the creation made for an idempotency key
+type IdempotencyStore interface {
+	// Claim reserves key for a new creation and reports claimed=true. If a creation
+	// already completed for key, it returns its response and claimed=false. If another
+	// request holds the key, it returns ErrIdempotencyKeyInProgress. The claim lasts ttl
+	// unless it is completed or released first.
+	Claim(ctx context.Context, key string, ttl time.Duration) (response []byte, claimed bool, err error)
+	// Complete stores the response of the creation made for a claimed key and keeps it
+	// for ttl
+	Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error
+	// Release frees a claimed key after a failed creation so that it can be retried
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyEntry struct {
+	response  []byte
+	expiresAt time.Time
+}
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+	now     func() time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory IdempotencyStore
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		entries: make(map[string]idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryIdempotencyStore) Claim(_ context.Context, key string, ttl time.Duration) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if len(entry.response) == 0 {
+			return nil, false, ErrIdempotencyKeyInProgress
+		}
+		return entry.response, false, nil
+	}
+
+	s.entries[key] = idempotencyEntry{expiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key string, response []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = idempotencyEntry{response: response, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// redisIdempotencyStore keeps idempotency keys in Redis so that duplicates are caught
+// across instances. A claimed key holds an empty value until its event is created.
+type redisIdempotencyStore struct {
+	redis *redis.Client
+}
+
+// NewRedisIdempotencyStore creates an IdempotencyStore backed by Redis
+func NewRedisIdempotencyStore(client *redis.Client) IdempotencyStore {
+	return &redisIdempotencyStore{redis: client}
+}
+
+func (s *redisIdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) ([]byte, bool, error) {
+	redisKey := idempotencyRedisKeyPrefix + key
+
+	// The key may expire between SETNX and GET, in which case it is claimed again
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := s.redis.SetNX(ctx, redisKey, "", ttl).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if claimed {
+			return nil, true, nil
+		}
+
+		response, err := s.redis.Get(ctx, redisKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read idempotency key: %w", err)
+		}
+		if len(response) == 0 {
+			return nil, false, ErrIdempotencyKeyInProgress
+		}
+		return response, false, nil
+	}
+
+	return nil, false, ErrIdempotencyKeyInProgress
+}
+
+func (s *redisIdempotencyStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	if err := s.redis.Set(ctx, idempotencyRedisKeyPrefix+key, response, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.redis.Del(ctx, idempotencyRedisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// idempotentCreate runs create at most once per key and scope, and returns its
+// response. replayed is true when the response is the one stored for an earlier
+// request. Keys are scoped, usually by user, so that clients cannot collide with each
+// other; a key without a scope fails with ErrIdempotencyKeyUnscoped.
+func idempotentCreate[T any](ctx context.Context, store IdempotencyStore, ttl time.Duration, scope, key string, create func(context.Context) (T, error)) (response T, replayed bool, err error) {
+	if store == nil || key == "" {
+		response, err = create(ctx)
+		return response, false, err
+	}
+	if scope == "" {
+		return response, false, ErrIdempotencyKeyUnscoped
+	}
+	key = scope + ":" + key
+
+	claimTTL := idempotencyClaimTTL
+	if ttl < claimTTL {
+		claimTTL = ttl
+	}
+	stored, claimed, err := store.Claim(ctx, key, claimTTL)
+	if err != nil {
+		return response, false, err
+	}
+	if !claimed {
+		if err := json.Unmarshal(stored, &response); err != nil {
+			return response, false, fmt.Errorf("failed to read stored idempotent response: %w", err)
+		}
+		return response, true, nil
+	}
+
+	response, err = create(ctx)
+	if err != nil {
+		// Let a retry with the same key try again
+		_ = store.Release(ctx, key)
+		return response, false, err
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		_ = store.Release(ctx, key)
+		return response, false, fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	if err := store.Complete(ctx, key, encoded, ttl); err != nil {
+		return response, false, err
+	}
+	return response, false, nil
+}
+
+// messageIDIdempotencyKey derives an idempotency key from the Message-ID of an email,
+// or returns "" if the email has none
+func messageIDIdempotencyKey(emailContent string) string {
+	msg, err := mail.ReadMessage(strings.NewReader(emailContent))
+	if err != nil {
+		return ""
+	}
+	messageID := strings.TrimSpace(msg.Header.Get("Message-ID"))
+	if messageID == "" {
+		return ""
+	}
+	return "message-id:" + messageID
+}
+
+// idempotentCalendarService makes event creation of a service.CalendarService idempotent
+type idempotentCalendarService struct {
+	service.CalendarService
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+// NewIdempotentCalendarService wraps svc so that creations carrying the same idempotency
+// key, set with service.WithIdempotencyKey, create a single event. A repeat returns the
+// original response. Keys are scoped by the user set with service.WithUserID; a key
+// without a user fails with ErrIdempotencyKeyUnscoped. Emails processed without a key are
+// deduplicated by their Message-ID. A non-positive ttl keeps keys for 24 hours.
+func NewIdempotentCalendarService(svc service.CalendarService, store IdempotencyStore, ttl time.Duration) service.CalendarService {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &idempotentCalendarService{
+		CalendarService: svc,
+		store:           store,
+		ttl:             ttl,
+	}
+}
+
+func (s *idempotentCalendarService) CreateEvent(ctx context.Context, req *calendarPb.NewCreateEventRequest) (*calendarPb.CreateEventResponseV2, error) {
+	scope, _ := service.UserIDFromContext(ctx)
+	return s.create(ctx, scope, service.IdempotencyKeyFromContext(ctx), func(ctx context.Context) (*calendarPb.CreateEventResponseV2, error) {
+		return s.CalendarService.CreateEvent(ctx, req)
+	})
+}
+
+func (s *idempotentCalendarService) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*calendarPb.CreateEventResponseV2, error) {
+	scope, ok := service.UserIDFromContext(ctx)
+	if !ok {
+		// Emails without a user all create events in the shared calendar
+		scope = sharedIdempotencyScope
+	}
+	key := service.IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		key = messageIDIdempotencyKey(emailContent)
+	}
+	return s.create(ctx, scope, key, func(ctx context.Context) (*calendarPb.CreateEventResponseV2, error) {
+		return s.CalendarService.ProcessEmailToCalendar(ctx, emailContent)
+	})
+}
+
+func (s *idempotentCalendarService) create(ctx context.Context, scope, key string, create func(context.Context) (*calendarPb.CreateEventResponseV2, error)) (*calendarPb.CreateEventResponseV2, error) {
+	resp, _, err := idempotentCreate(ctx, s.store, s.ttl, scope, key, create)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
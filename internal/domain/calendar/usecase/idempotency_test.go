@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+func TestIdempotentCalendarService_CreateEvent(t *testing.T) {
+	inner := new(mockDomainCalendarService)
+	inner.On("CreateEvent", mock.Anything, mock.Anything).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+	inner.On("CreateEvent", mock.Anything, mock.Anything).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-2"}, nil).Once()
+
+	svc := NewIdempotentCalendarService(inner, NewMemoryIdempotencyStore(), time.Hour)
+	req := &calendarPb.NewCreateEventRequest{Event: &calendarPb.CalendarEvent{Title: "Sync"}}
+
+	userCtx := service.WithUserID(context.Background(), "user-1")
+	ctx := service.WithIdempotencyKey(userCtx, "key-1")
+	first, err := svc.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	second, err := svc.CreateEvent(ctx, req)
+	require.NoError(t, err)
+
+	// The repeat is a no-op that returns the original event
+	assert.Equal(t, "google-1", first.EventID)
+	assert.Equal(t, "google-1", second.EventID)
+
+	// A different key creates a new event
+	other, err := svc.CreateEvent(service.WithIdempotencyKey(userCtx, "key-2"), req)
+	require.NoError(t, err)
+	assert.Equal(t, "google-2", other.EventID)
+
+	inner.AssertExpectations(t)
+}
+
+func TestIdempotentCalendarService_ProcessEmailToCalendar_MessageID(t *testing.T) {
+	email := "Message-ID: <abc@example.com>\r\n" +
+		"Subject: Planning\r\n" +
+		"\r\n" +
+		"Planning tomorrow at 2pm."
+
+	inner := new(mockDomainCalendarService)
+	inner.On("ProcessEmailToCalendar", mock.Anything, email).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+	inner.On("ProcessEmailToCalendar", mock.Anything, email).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-2"}, nil).Once()
+
+	svc := NewIdempotentCalendarService(inner, NewMemoryIdempotencyStore(), 0)
+
+	// Duplicate deliveries for the same user create a single event
+	ctx := service.WithUserID(context.Background(), "user-1")
+	for i := 0; i < 2; i++ {
+		resp, err := svc.ProcessEmailToCalendar(ctx, email)
+		require.NoError(t, err)
+		assert.Equal(t, "google-1", resp.EventID)
+	}
+
+	// The same email for another user is a different event
+	resp, err := svc.ProcessEmailToCalendar(service.WithUserID(context.Background(), "user-2"), email)
+	require.NoError(t, err)
+	assert.Equal(t, "google-2", resp.EventID)
+
+	inner.AssertExpectations(t)
+}
+
+func TestIdempotentCalendarService_UnscopedKey(t *testing.T) {
+	inner := new(mockDomainCalendarService)
+	inner.On("ProcessEmailToCalendar", mock.Anything, mock.Anything).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+
+	svc := NewIdempotentCalendarService(inner, NewMemoryIdempotencyStore(), time.Hour)
+
+	// A client key without a user would be shared by every client
+	_, err := svc.CreateEvent(service.WithIdempotencyKey(context.Background(), "key-1"), &calendarPb.NewCreateEventRequest{})
+	assert.ErrorIs(t, err, ErrIdempotencyKeyUnscoped)
+
+	// Emails without a user are deduplicated by Message-ID within the shared calendar
+	email := "Message-ID: <shared@example.com>\r\n\r\nPlanning tomorrow at 2pm."
+	for i := 0; i < 2; i++ {
+		resp, err := svc.ProcessEmailToCalendar(context.Background(), email)
+		require.NoError(t, err)
+		assert.Equal(t, "google-1", resp.EventID)
+	}
+
+	inner.AssertExpectations(t)
+}
+
+func TestIdempotentCalendarService_RetryAfterFailure(t *testing.T) {
+	inner := new(mockDomainCalendarService)
+	inner.On("CreateEvent", mock.Anything, mock.Anything).Return(nil, errors.New("google unavailable")).Once()
+	inner.On("CreateEvent", mock.Anything, mock.Anything).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+
+	svc := NewIdempotentCalendarService(inner, NewMemoryIdempotencyStore(), time.Hour)
+	ctx := service.WithIdempotencyKey(service.WithUserID(context.Background(), "user-1"), "key-1")
+
+	_, err := svc.CreateEvent(ctx, &calendarPb.NewCreateEventRequest{})
+	require.Error(t, err)
+
+	resp, err := svc.CreateEvent(ctx, &calendarPb.NewCreateEventRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "google-1", resp.EventID)
+	inner.AssertExpectations(t)
+}
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	client, mr, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	store := NewRedisIdempotencyStore(client)
+	ctx := context.Background()
+
+	_, claimed, err := store.Claim(ctx, "user-1:key-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	_, _, err = store.Claim(ctx, "user-1:key-1", time.Hour)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+
+	require.NoError(t, store.Complete(ctx, "user-1:key-1", []byte(`{"event_id":"google-1"}`), time.Hour))
+	response, claimed, err := store.Claim(ctx, "user-1:key-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.JSONEq(t, `{"event_id":"google-1"}`, string(response))
+
+	// Keys are forgotten after their TTL
+	mr.FastForward(2 * time.Hour)
+	_, claimed, err = store.Claim(ctx, "user-1:key-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, store.Release(ctx, "user-1:key-1"))
+	assert.False(t, mr.Exists(idempotencyRedisKeyPrefix+"user-1:key-1"))
+
+	// A claim that is never completed expires after its lease
+	_, claimed, err = store.Claim(ctx, "user-1:key-2", idempotencyClaimTTL)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	mr.FastForward(idempotencyClaimTTL)
+	_, claimed, err = store.Claim(ctx, "user-1:key-2", idempotencyClaimTTL)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestIdempotentCreate_AbandonedClaimExpires(t *testing.T) {
+	store := NewMemoryIdempotencyStore().(*memoryIdempotencyStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A request that crashed after claiming the key never completes or releases it
+	_, claimed, err := store.Claim(ctx, "user-1:key-1", idempotencyClaimTTL)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	create := func(context.Context) (string, error) { return "google-1", nil }
+
+	_, _, err = idempotentCreate(ctx, store, time.Hour, "user-1", "key-1", create)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+
+	now = now.Add(idempotencyClaimTTL)
+	resp, replayed, err := idempotentCreate(ctx, store, time.Hour, "user-1", "key-1", create)
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "google-1", resp)
+
+	// The completed response is kept for the full TTL, not the claim lease
+	now = now.Add(30 * time.Minute)
+	resp, replayed, err = idempotentCreate(ctx, store, time.Hour, "user-1", "key-1", create)
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, "google-1", resp)
+}
+
+func TestCalendarUseCase_CreateEvent_Idempotent(t *testing.T) {
+	u := NewCalendarUseCase(nil, nil, WithIdempotency(NewMemoryIdempotencyStore(), time.Hour))
+	newEvent := func() *calendarPb.Event {
+		return &calendarPb.Event{
+			Title:     "Sync",
+			StartTime: time.Now().Add(time.Hour).Unix(),
+			EndTime:   time.Now().Add(2 * time.Hour).Unix(),
+		}
+	}
+
+	ctx := service.WithIdempotencyKey(context.Background(), "key-1")
+	first, err := u.CreateEvent(ctx, newEvent(), "user-1")
+	require.NoError(t, err)
+	second, err := u.CreateEvent(ctx, newEvent(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, second.Id)
+
+	// The repeat replays the stored event rather than reading it back
+	assert.Equal(t, "Sync", second.Title)
+	assert.Equal(t, first.StartTime, second.StartTime)
+	assert.Equal(t, first.Status, second.Status)
+
+	// The key is scoped to the user
+	other, err := u.CreateEvent(ctx, newEvent(), "user-2")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Id, other.Id)
+
+	// Without a key every request creates an event
+	third, err := u.CreateEvent(context.Background(), newEvent(), "user-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Id, third.Id)
+
+	// A key without a user is refused
+	_, err = u.CreateEvent(ctx, newEvent(), "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
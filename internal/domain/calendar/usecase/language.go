@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultNERLanguage is the language hint sent to the NER service when the language
+// of a text cannot be told
+const defaultNERLanguage = "vi"
+
+// LanguageDetector guesses the language of a text
+type LanguageDetector interface {
+	// DetectLanguage returns the ISO 639-1 code of the language of text, or "" if it
+	// cannot tell
+	DetectLanguage(text string) string
+}
+
+// scriptLanguageDetector tells languages apart by their script and, for Latin text,
+// by Vietnamese letters and English stopwords
+type scriptLanguageDetector struct{}
+
+// NewLanguageDetector creates a lightweight LanguageDetector recognising Vietnamese,
+// English, Japanese, Chinese and Korean
+func NewLanguageDetector() LanguageDetector {
+	return scriptLanguageDetector{}
+}
+
+// englishStopwords are frequent English words, used to tell English from other Latin
+// text such as Vietnamese typed without diacritics
+var englishStopwords = map[string]struct{}{
+	"the": {}, "and": {}, "at": {}, "on": {}, "in": {}, "to": {}, "of": {}, "for": {},
+	"is": {}, "are": {}, "will": {}, "with": {}, "please": {}, "meeting": {}, "let's": {},
+	"we": {}, "you": {}, "our": {}, "from": {}, "tomorrow": {}, "today": {},
+}
+
+func (scriptLanguageDetector) DetectLanguage(text string) string {
+	var kana, han, hangul, latin, vietnamese int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+			if isVietnameseLetter(r) {
+				vietnamese++
+			}
+		}
+	}
+
+	// A CJK character carries about as much as a Latin word
+	if cjk := kana + han + hangul; cjk > 0 && cjk*4 >= latin {
+		switch {
+		case kana > 0:
+			return "ja"
+		case hangul > han:
+			return "ko"
+		default:
+			return "zh"
+		}
+	}
+	if latin == 0 {
+		return ""
+	}
+
+	// Vietnamese names in English text are too rare to tip the balance
+	if vietnamese*30 >= latin {
+		return "vi"
+	}
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if _, ok := englishStopwords[strings.Trim(word, ".,;:!?\"()")]; ok {
+			return "en"
+		}
+	}
+	return ""
+}
+
+// isVietnameseLetter reports whether r is a letter only Vietnamese uses among the
+// languages written in Latin script: đ, ă, ơ, ư and the letters with tone marks of
+// the Latin Extended Additional block, such as ạ or ế
+func isVietnameseLetter(r rune) bool {
+	switch unicode.ToLower(r) {
+	case 'đ', 'ă', 'ơ', 'ư':
+		return true
+	}
+	return r >= 0x1EA0 && r <= 0x1EF9
+}
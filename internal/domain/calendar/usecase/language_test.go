@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageDetector_DetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "Vietnamese",
+			text:     "Họp team lúc 2 giờ chiều ngày mai tại phòng họp tầng 5",
+			expected: "vi",
+		},
+		{
+			name:     "English",
+			text:     "Let's meet tomorrow at 2pm in the meeting room on the 5th floor",
+			expected: "en",
+		},
+		{
+			name:     "English mentioning a Vietnamese name",
+			text:     "Please join the quarterly review with Nguyễn Văn An tomorrow at 2pm in the main conference room",
+			expected: "en",
+		},
+		{
+			name:     "Japanese",
+			text:     "明日の午後2時に会議室で打ち合わせをしましょう",
+			expected: "ja",
+		},
+		{
+			name:     "Japanese with English words",
+			text:     "明日の14時からZoomでミーティングです",
+			expected: "ja",
+		},
+		{
+			name:     "Chinese",
+			text:     "明天下午两点在会议室开会",
+			expected: "zh",
+		},
+		{
+			name:     "Korean",
+			text:     "내일 오후 2시에 회의실에서 만나요",
+			expected: "ko",
+		},
+		{
+			name: "Vietnamese without diacritics is unknown",
+			text: "hop team luc 2h chieu mai",
+		},
+		{
+			name: "no letters",
+			text: "14:00 - 15:00",
+		},
+	}
+
+	detector := NewLanguageDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detector.DetectLanguage(tt.text))
+		})
+	}
+}
+
+// languageDetectorFunc lets a test provide a LanguageDetector
+type languageDetectorFunc func(string) string
+
+func (f languageDetectorFunc) DetectLanguage(text string) string {
+	return f(text)
+}
+
+func TestNERService_Language(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		opts     []NERServiceOption
+		expected string
+	}{
+		{
+			name:     "Vietnamese text",
+			text:     "Họp team lúc 2 giờ chiều ngày mai tại phòng họp",
+			expected: "vi",
+		},
+		{
+			name:     "English text",
+			text:     "Let's meet tomorrow at 2pm in the meeting room",
+			expected: "en",
+		},
+		{
+			name:     "Japanese text",
+			text:     "明日の午後2時に会議室で打ち合わせをしましょう",
+			expected: "ja",
+		},
+		{
+			name:     "undetected language defaults to Vietnamese",
+			text:     "14:00 - 15:00",
+			expected: "vi",
+		},
+		{
+			name:     "configured language overrides detection",
+			text:     "Let's meet tomorrow at 2pm in the meeting room",
+			opts:     []NERServiceOption{WithNERLanguage("vi")},
+			expected: "vi",
+		},
+		{
+			name: "injected detector",
+			text: "Let's meet tomorrow at 2pm in the meeting room",
+			opts: []NERServiceOption{WithLanguageDetector(languageDetectorFunc(func(string) string {
+				return "fr"
+			}))},
+			expected: "fr",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var languages []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req nerRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				languages = append(languages, req.Language)
+				_ = json.NewEncoder(w).Encode(nerResponse{Entities: []Entity{
+					{Text: "2pm", Label: "TIME", Confidence: 0.9},
+				}})
+			}))
+			defer server.Close()
+
+			s := NewNERService(server.URL, tt.opts...)
+			_, err := s.ExtractDateTime(context.Background(), tt.text)
+			require.NoError(t, err)
+			_, err = s.ExtractLocation(context.Background(), tt.text)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{tt.expected, tt.expected}, languages)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trailingLocationPunctuation is stripped from the end of extracted locations
+const trailingLocationPunctuation = ".,;:!?-–—"
+
+// normalizeLocation trims and collapses whitespace, removes trailing punctuation and
+// upper-cases the first letter of each word. The rest of each word is left as is so
+// acronyms such as "ABC" or "HQ" keep their casing.
+func normalizeLocation(location string) string {
+	words := strings.Fields(location)
+	if len(words) == 0 {
+		return ""
+	}
+
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToTitle(r)) + word[size:]
+	}
+
+	return strings.TrimRightFunc(strings.Join(words, " "), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(trailingLocationPunctuation, r)
+	})
+}
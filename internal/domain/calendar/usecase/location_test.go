@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{location: "  conference room   b!!! ", expected: "Conference Room B"},
+		{location: "starbucks, 12 nguyen hue...;", expected: "Starbucks, 12 Nguyen Hue"},
+		{location: "ABC tower - floor 3 -", expected: "ABC Tower - Floor 3"},
+		{location: "phòng họp lớn?!", expected: "Phòng Họp Lớn"},
+		{location: "?!.", expected: ""},
+		{location: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeLocation(tt.location))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_LocationNormalization(t *testing.T) {
+	emailContent := "From: organizer@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Planning\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Planning at 2pm in the main meeting room."
+
+	tests := []struct {
+		name     string
+		opts     []EmailProcessorOption
+		expected string
+	}{
+		{
+			name:     "disabled by default",
+			expected: "  main   meeting room, floor 5!!;",
+		},
+		{
+			name:     "enabled",
+			opts:     []EmailProcessorOption{WithLocationNormalization(true)},
+			expected: "Main Meeting Room, Floor 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			start := time.Now().Add(24 * time.Hour)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start, start.Add(time.Hour)}, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("  main   meeting room, floor 5!!;", nil)
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, tt.opts...)
+			event, err := processor.ProcessEmail(context.Background(), emailContent)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, event.Location)
+		})
+	}
+}
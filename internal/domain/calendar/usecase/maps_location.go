@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlPattern matches http and https URLs in text
+var urlPattern = regexp.MustCompile(`https?://[^\s()<>"']+`)
+
+// mapsQueryParams are the query parameters of map links that name a place, in order
+// of preference
+var mapsQueryParams = []string{"q", "query", "destination", "daddr"}
+
+// mapsLocation returns the place named by the first map link in text, such as
+// https://maps.google.com/?q=Highlands+Coffee or
+// https://www.google.com/maps/place/Highlands+Coffee/@10.77,106.70,17z, or "" if there
+// is none. Google Maps, Apple Maps and Bing Maps links are recognised.
+func mapsLocation(text string) string {
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		link, err := url.Parse(match)
+		if err != nil || !isMapsLink(link) {
+			continue
+		}
+
+		query := link.Query()
+		for _, param := range mapsQueryParams {
+			if place := strings.TrimSpace(query.Get(param)); place != "" {
+				return place
+			}
+		}
+
+		// Google Maps place links carry the name in the path
+		if _, rest, ok := strings.Cut(link.EscapedPath(), "/maps/place/"); ok {
+			name, _, _ := strings.Cut(rest, "/")
+			name, err := url.PathUnescape(strings.ReplaceAll(name, "+", " "))
+			if err == nil && strings.TrimSpace(name) != "" {
+				return strings.TrimSpace(name)
+			}
+		}
+	}
+	return ""
+}
+
+// isMapsLink reports whether link points to a maps service
+func isMapsLink(link *url.URL) bool {
+	host := strings.TrimPrefix(strings.ToLower(link.Hostname()), "www.")
+	switch {
+	case strings.HasPrefix(host, "maps.google."), host == "maps.apple.com":
+		return true
+	case strings.HasPrefix(host, "google."), host == "bing.com":
+		return strings.HasPrefix(link.Path, "/maps")
+	}
+	return false
+}
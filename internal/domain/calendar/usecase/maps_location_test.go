@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapsLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "Google Maps query link",
+			text:     "Meet at the cafe (https://maps.google.com/?q=Highlands+Coffee,+1+L%C3%AA+L%E1%BB%A3i&hl=vi)",
+			expected: "Highlands Coffee, 1 Lê Lợi",
+		},
+		{
+			name:     "Google Maps place link",
+			text:     "Directions (https://www.google.com/maps/place/Highlands+Coffee+Nguyen+Hue/@10.77,106.70,17z)",
+			expected: "Highlands Coffee Nguyen Hue",
+		},
+		{
+			name:     "Google Maps search link",
+			text:     "https://www.google.com/maps/search/?api=1&query=Bitexco+Tower",
+			expected: "Bitexco Tower",
+		},
+		{
+			name:     "Apple Maps link",
+			text:     "https://maps.apple.com/?q=Ben+Thanh+Market",
+			expected: "Ben Thanh Market",
+		},
+		{
+			name:     "first map link wins",
+			text:     "https://meet.example.com/abc https://maps.google.com/?q=Room+A https://maps.google.com/?q=Room+B",
+			expected: "Room A",
+		},
+		{
+			name: "Google search link is not a map link",
+			text: "https://www.google.com/search?q=Highlands+Coffee",
+		},
+		{
+			name: "no links",
+			text: "Let's meet tomorrow at 2pm.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mapsLocation(tt.text))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_MapsLink(t *testing.T) {
+	email := "From: sender@example.com\r\n" +
+		"To: recipient@example.com\r\n" +
+		"Subject: Coffee chat\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		`<p>Let's meet tomorrow at 2pm at <a href="https://www.google.com/maps/place/Highlands+Coffee+Nguyen+Hue/@10.77,106.70,17z">the usual place</a>.</p>`
+
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+		Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil)
+	// The NER service finds no location in the text, but sees the link
+	ner.On("ExtractLocation", mock.Anything, mock.MatchedBy(func(text string) bool {
+		return strings.Contains(text, "the usual place (https://www.google.com/maps/place/Highlands+Coffee+Nguyen+Hue/")
+	})).Return("", nil)
+
+	validator := new(mockEmailValidator)
+	validator.On("ValidateDKIM", email).Return(fmt.Errorf("signature invalid"))
+
+	event, err := NewEmailProcessorImpl(validator, ner).ProcessEmail(context.Background(), email)
+	require.NoError(t, err)
+	assert.Equal(t, "Highlands Coffee Nguyen Hue", event.Location)
+	ner.AssertExpectations(t)
+}
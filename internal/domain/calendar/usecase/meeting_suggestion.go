@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (cc *conflictCheckerImpl) SuggestMeetingTime(ctx context.Context, duration time.Duration, attendees []string, window TimeRange) ([]TimeSlot, error) {
+	if duration <= 0 {
+		return nil, errors.New("meeting duration must be positive")
+	}
+	if len(attendees) == 0 {
+		return nil, errors.New("at least one attendee is required")
+	}
+	if !window.EndTime.After(window.StartTime) {
+		return nil, fmt.Errorf("window ends at %s, before it starts at %s", window.EndTime.Format(time.RFC3339), window.StartTime.Format(time.RFC3339))
+	}
+
+	workingHours, err := cc.calendarService.GetWorkingHours(ctx, window, attendees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working hours: %w", err)
+	}
+
+	free := []TimeSlot{{Start: window.StartTime, End: window.EndTime}}
+	for _, attendee := range attendees {
+		// Attendees whose working hours are unknown may meet at any time
+		available := []TimeSlot{{Start: window.StartTime, End: window.EndTime}}
+		if hours, ok := workingHours[attendee]; ok && hours != nil {
+			available = workingSlots(hours, window)
+		}
+
+		busy, err := cc.GetBusyPeriods(ctx, window, []string{attendee})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get busy periods of %s: %w", attendee, err)
+		}
+
+		free = intersectSlots(free, subtractSlots(available, busy))
+		if len(free) == 0 {
+			break
+		}
+	}
+
+	suggestions := make([]TimeSlot, 0, len(free))
+	for _, slot := range free {
+		if slot.End.Sub(slot.Start) >= duration {
+			suggestions = append(suggestions, TimeSlot{Start: slot.Start, End: slot.Start.Add(duration)})
+		}
+	}
+	return suggestions, nil
+}
+
+// workingSlots returns the working periods of hours within window, sorted and merged.
+// Schedule times are clock times in the timezone of hours, or in their own location
+// when it is unset or unknown.
+func workingSlots(hours *WorkingHours, window TimeRange) []TimeSlot {
+	timezone, err := time.LoadLocation(hours.TimeZone)
+	if hours.TimeZone == "" || err != nil {
+		timezone = nil
+	}
+
+	slots := make([]TimeSlot, 0)
+	for _, schedule := range hours.Schedule {
+		loc := timezone
+		if loc == nil {
+			loc = schedule.StartTime.Location()
+		}
+
+		first := window.StartTime.In(loc)
+		day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+		for ; day.Before(window.EndTime); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() != schedule.DayOfWeek {
+				continue
+			}
+
+			start := time.Date(day.Year(), day.Month(), day.Day(), schedule.StartTime.Hour(), schedule.StartTime.Minute(), 0, 0, loc)
+			end := time.Date(day.Year(), day.Month(), day.Day(), schedule.EndTime.Hour(), schedule.EndTime.Minute(), 0, 0, loc)
+			if start.Before(window.StartTime) {
+				start = window.StartTime
+			}
+			if end.After(window.EndTime) {
+				end = window.EndTime
+			}
+			if end.After(start) {
+				slots = append(slots, TimeSlot{Start: start, End: end})
+			}
+		}
+	}
+	return mergeBusyPeriods(slots)
+}
+
+// subtractSlots returns the parts of slots not covered by busy. Both must be sorted
+// and non-overlapping, like the result of mergeBusyPeriods.
+func subtractSlots(slots, busy []TimeSlot) []TimeSlot {
+	result := make([]TimeSlot, 0, len(slots))
+	for _, slot := range slots {
+		start := slot.Start
+		for _, period := range busy {
+			if !period.End.After(start) {
+				continue
+			}
+			if !period.Start.Before(slot.End) {
+				break
+			}
+			if period.Start.After(start) {
+				result = append(result, TimeSlot{Start: start, End: period.Start})
+			}
+			start = period.End
+		}
+		if slot.End.After(start) {
+			result = append(result, TimeSlot{Start: start, End: slot.End})
+		}
+	}
+	return result
+}
+
+// intersectSlots returns the periods covered by both a and b. Both must be sorted and
+// non-overlapping.
+func intersectSlots(a, b []TimeSlot) []TimeSlot {
+	result := make([]TimeSlot, 0)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if end.After(start) {
+			result = append(result, TimeSlot{Start: start, End: end})
+		}
+
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// weekdaySchedule returns working hours from 9 AM to 5 PM UTC, Monday to Friday
+func weekdaySchedule() *WorkingHours {
+	schedule := make([]WeeklySchedule, 0, 5)
+	for day := time.Monday; day <= time.Friday; day++ {
+		schedule = append(schedule, WeeklySchedule{
+			DayOfWeek: day,
+			StartTime: time.Date(0, 0, 0, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(0, 0, 0, 17, 0, 0, 0, time.UTC),
+		})
+	}
+	return &WorkingHours{TimeZone: "UTC", Schedule: schedule}
+}
+
+// busyEvents returns an event for each start and end pair
+func busyEvents(times ...string) []*CalendarEvent {
+	events := make([]*CalendarEvent, 0, len(times)/2)
+	for i := 0; i+1 < len(times); i += 2 {
+		events = append(events, &CalendarEvent{StartTime: parseTime(times[i]), EndTime: parseTime(times[i+1])})
+	}
+	return events
+}
+
+func TestConflictChecker_SuggestMeetingTime(t *testing.T) {
+	// Monday 3 February to Wednesday 5 February 2025
+	window := TimeRange{StartTime: parseTime("2025-02-03T00:00:00Z"), EndTime: parseTime("2025-02-05T00:00:00Z")}
+	attendees := []string{"alice@example.com", "bob@example.com"}
+
+	// Alice is free on Tuesday from 2 PM to 3 PM, Bob on Monday from noon to 1 PM and
+	// on Tuesday from 1 PM to 3 PM
+	alice := busyEvents(
+		"2025-02-03T09:00:00Z", "2025-02-03T17:00:00Z",
+		"2025-02-04T09:00:00Z", "2025-02-04T14:00:00Z",
+		"2025-02-04T15:00:00Z", "2025-02-04T17:00:00Z",
+	)
+	bob := busyEvents(
+		"2025-02-03T09:00:00Z", "2025-02-03T12:00:00Z",
+		"2025-02-03T13:00:00Z", "2025-02-03T17:00:00Z",
+		"2025-02-04T09:00:00Z", "2025-02-04T13:00:00Z",
+		"2025-02-04T15:00:00Z", "2025-02-04T17:00:00Z",
+	)
+
+	tests := []struct {
+		name         string
+		duration     time.Duration
+		workingHours map[string]*WorkingHours
+		expected     []TimeSlot
+	}{
+		{
+			name:     "only common free slot",
+			duration: time.Hour,
+			workingHours: map[string]*WorkingHours{
+				"alice@example.com": weekdaySchedule(),
+				"bob@example.com":   weekdaySchedule(),
+			},
+			expected: []TimeSlot{{Start: parseTime("2025-02-04T14:00:00Z"), End: parseTime("2025-02-04T15:00:00Z")}},
+		},
+		{
+			name:     "shorter meeting starts when the common slot does",
+			duration: 30 * time.Minute,
+			workingHours: map[string]*WorkingHours{
+				"alice@example.com": weekdaySchedule(),
+				"bob@example.com":   weekdaySchedule(),
+			},
+			expected: []TimeSlot{{Start: parseTime("2025-02-04T14:00:00Z"), End: parseTime("2025-02-04T14:30:00Z")}},
+		},
+		{
+			name:     "meeting longer than the common slot",
+			duration: 90 * time.Minute,
+			workingHours: map[string]*WorkingHours{
+				"alice@example.com": weekdaySchedule(),
+				"bob@example.com":   weekdaySchedule(),
+			},
+			expected: []TimeSlot{},
+		},
+		{
+			name:     "attendee without working hours is free outside them",
+			duration: time.Hour,
+			workingHours: map[string]*WorkingHours{
+				"bob@example.com": weekdaySchedule(),
+			},
+			expected: []TimeSlot{{Start: parseTime("2025-02-04T14:00:00Z"), End: parseTime("2025-02-04T15:00:00Z")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetWorkingHours", mock.Anything, window, attendees).Return(tt.workingHours, nil)
+			calendar.On("GetEvents", mock.Anything, window, []string{"alice@example.com"}).Return(alice, nil)
+			calendar.On("GetEvents", mock.Anything, window, []string{"bob@example.com"}).Return(bob, nil)
+
+			slots, err := NewConflictChecker(calendar).SuggestMeetingTime(context.Background(), tt.duration, attendees, window)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, slots)
+		})
+	}
+}
+
+func TestConflictChecker_SuggestMeetingTime_Errors(t *testing.T) {
+	window := TimeRange{StartTime: parseTime("2025-02-03T00:00:00Z"), EndTime: parseTime("2025-02-05T00:00:00Z")}
+
+	tests := []struct {
+		name      string
+		duration  time.Duration
+		attendees []string
+		window    TimeRange
+		hoursErr  error
+	}{
+		{
+			name:      "non-positive duration",
+			attendees: []string{"alice@example.com"},
+			window:    window,
+		},
+		{
+			name:     "no attendees",
+			duration: time.Hour,
+			window:   window,
+		},
+		{
+			name:      "window ending before it starts",
+			duration:  time.Hour,
+			attendees: []string{"alice@example.com"},
+			window:    TimeRange{StartTime: window.EndTime, EndTime: window.StartTime},
+		},
+		{
+			name:      "working hours unavailable",
+			duration:  time.Hour,
+			attendees: []string{"alice@example.com"},
+			window:    window,
+			hoursErr:  errors.New("calendar unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockCalendarService)
+			calendar.On("GetWorkingHours", mock.Anything, mock.Anything, mock.Anything).
+				Return(map[string]*WorkingHours(nil), tt.hoursErr)
+
+			_, err := NewConflictChecker(calendar).SuggestMeetingTime(context.Background(), tt.duration, tt.attendees, tt.window)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWorkingSlots_Timezone(t *testing.T) {
+	hours := &WorkingHours{
+		TimeZone: "Asia/Ho_Chi_Minh",
+		Schedule: []WeeklySchedule{{
+			DayOfWeek: time.Tuesday,
+			StartTime: time.Date(0, 0, 0, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(0, 0, 0, 17, 0, 0, 0, time.UTC),
+		}},
+	}
+	window := TimeRange{StartTime: parseTime("2025-02-03T00:00:00Z"), EndTime: parseTime("2025-02-10T00:00:00Z")}
+
+	slots := workingSlots(hours, window)
+	require.Len(t, slots, 1)
+	// 9 AM to 5 PM in UTC+7
+	assert.True(t, slots[0].Start.Equal(parseTime("2025-02-04T02:00:00Z")))
+	assert.True(t, slots[0].End.Equal(parseTime("2025-02-04T10:00:00Z")))
+}
@@ -3,15 +3,21 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
+	calerrors "mail2calendar/internal/domain/calendar/errors"
 	"mail2calendar/internal/domain/calendar/service"
 
+	"github.com/go-redis/redis/v8"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -20,25 +26,143 @@ import (
 type MessageQueueService interface {
 	PublishEmailEvent(ctx context.Context, emailContent string, userID string) error
 	ProcessMessages(ctx context.Context) error
+	// Shutdown stops consuming, waits for the messages being handled to be acked or
+	// dead-lettered until ctx is done, then closes the channel and connection
+	Shutdown(ctx context.Context) error
 	Close() error
 }
 
 // QueueConfig holds RabbitMQ configuration
 type QueueConfig struct {
-	URI               string
-	EmailQueueName    string
-	DeadLetterQueue   string
+	URI             string
+	EmailQueueName  string
+	DeadLetterQueue string
+	// RetryQueue holds failed emails until their retry delay expires; "" uses the
+	// email queue name followed by ".retry". RabbitMQ only expires the message at the
+	// head of a queue, so a retry waits for those queued before it.
+	RetryQueue        string
 	MaxRetries        int
 	RetryDelaySeconds int
+	// Concurrency is how many messages are handled at the same time, which caps the
+	// load on the NER service and Google Calendar. Non-positive uses
+	// DefaultQueueConcurrency.
+	Concurrency int
+	// PrefetchCount is how many unacked messages the broker sends the consumer.
+	// Non-positive uses Concurrency; fewer than Concurrency leaves workers idle, so
+	// the number of workers is capped at it.
+	PrefetchCount int
 }
 
+// DefaultQueueConcurrency is how many messages are handled at the same time when the
+// configuration doesn't say
+const DefaultQueueConcurrency = 4
+
+// workers returns how many messages are handled at the same time
+func (c QueueConfig) workers() int {
+	workers := c.Concurrency
+	if workers <= 0 {
+		workers = DefaultQueueConcurrency
+	}
+	if c.PrefetchCount > 0 && c.PrefetchCount < workers {
+		workers = c.PrefetchCount
+	}
+	return workers
+}
+
+// retryQueue returns the name of the queue failed emails wait in before a retry
+func (c QueueConfig) retryQueue() string {
+	if c.RetryQueue != "" {
+		return c.RetryQueue
+	}
+	return c.EmailQueueName + ".retry"
+}
+
+// prefetch returns the Qos prefetch count of the consumer
+func (c QueueConfig) prefetch() int {
+	if c.PrefetchCount > 0 {
+		return c.PrefetchCount
+	}
+	return c.workers()
+}
+
+// queueChannel is the subset of *amqp.Channel used by the messaging service
+type queueChannel interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Close() error
+}
+
+// emailConsumerTag identifies the email consumer on the channel so it can be cancelled
+const emailConsumerTag = "mail2calendar-email-consumer"
+
 type messagingService struct {
 	conn     *amqp.Connection
-	channel  *amqp.Channel
+	channel  queueChannel
 	config   QueueConfig
 	calendar service.CalendarService // Changed to use the correct interface
 	tracer   trace.Tracer
 	logger   *logrus.Logger
+	// propagator carries the trace context across the queue; nil uses the global one
+	propagator propagation.TextMapPropagator
+	// validator rejects spoofed emails before processing; nil disables validation
+	validator EmailProcessor
+	// timezones provides the timezone times in a user's emails are read in; nil uses
+	// the NER service default
+	timezones UserTimezoneStore
+	// metrics records calendar create failures and dead-lettered messages; nil
+	// records nothing
+	metrics *Metrics
+	// dedup remembers handled emails so that redeliveries are skipped; nil processes
+	// every delivery
+	dedup    *redis.Client
+	dedupTTL time.Duration
+	// lock lets one worker at a time process a given email; nil processes deliveries
+	// without locking
+	lock *processingLock
+	// maxEmailSize is the largest email processed, in bytes; larger ones are
+	// dead-lettered. Non-positive uses DefaultMaxEmailSize.
+	maxEmailSize int64
+	// consumerDone is closed once the consumer has handled its last delivery; nil until
+	// ProcessMessages is called
+	consumerDone chan struct{}
+}
+
+// MessageQueueOption configures optional behaviour of the message queue service
+type MessageQueueOption func(*messagingService)
+
+// WithEmailValidation makes the consumer run the processor's ValidateEmail on every
+// queued email. Emails failing it go straight to the dead letter queue, as retrying
+// cannot make them valid.
+func WithEmailValidation(processor EmailProcessor) MessageQueueOption {
+	return func(s *messagingService) {
+		s.validator = processor
+	}
+}
+
+// WithUserTimezones makes the consumer read times that name no timezone in the
+// preferred timezone of the email's user. Users without one keep the default.
+func WithUserTimezones(store UserTimezoneStore) MessageQueueOption {
+	return func(s *messagingService) {
+		s.timezones = store
+	}
+}
+
+// WithQueueMetrics records queued emails whose calendar event could not be created
+// and messages moved to the dead letter queue into metrics
+func WithQueueMetrics(metrics *Metrics) MessageQueueOption {
+	return func(s *messagingService) {
+		s.metrics = metrics
+	}
+}
+
+// WithMaxEmailSize sets the largest queued email processed, in bytes. Larger emails
+// go straight to the dead letter queue without being parsed. Non-positive sizes keep
+// the default, DefaultMaxEmailSize.
+func WithMaxEmailSize(size int64) MessageQueueOption {
+	return func(s *messagingService) {
+		s.maxEmailSize = size
+	}
 }
 
 // EmailMessage represents a message in the queue
@@ -47,10 +171,12 @@ type EmailMessage struct {
 	UserID       string    `json:"user_id"`
 	RetryCount   int       `json:"retry_count"`
 	Timestamp    time.Time `json:"timestamp"`
+	// Source overrides the queue ingestion channel, e.g. for reprocessed emails
+	Source service.EventSource `json:"source,omitempty"`
 }
 
 // NewMessageQueueService creates a new instance of MessageQueueService
-func NewMessageQueueService(config QueueConfig, calendar service.CalendarService) (MessageQueueService, error) { // Updated parameter type
+func NewMessageQueueService(config QueueConfig, calendar service.CalendarService, opts ...MessageQueueOption) (MessageQueueService, error) { // Updated parameter type
 	conn, err := amqp.Dial(config.URI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
@@ -69,18 +195,32 @@ func NewMessageQueueService(config QueueConfig, calendar service.CalendarService
 		return nil, fmt.Errorf("failed to declare queues: %v", err)
 	}
 
-	return &messagingService{
-		conn:     conn,
-		channel:  ch,
-		config:   config,
-		calendar: calendar,
-		tracer:   otel.Tracer("message-queue-service"),
-		logger:   logrus.New(),
-	}, nil
+	// Without a prefetch limit the broker pushes every queued message to the consumer
+	if err := ch.Qos(config.prefetch(), 0, false); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to set prefetch count: %v", err)
+	}
+
+	s := &messagingService{
+		conn:       conn,
+		channel:    ch,
+		config:     config,
+		calendar:   calendar,
+		tracer:     otel.Tracer("message-queue-service"),
+		logger:     logrus.New(),
+		propagator: otel.GetTextMapPropagator(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
 func (s *messagingService) PublishEmailEvent(ctx context.Context, emailContent string, userID string) error {
-	ctx, span := s.tracer.Start(ctx, "PublishEmailEvent")
+	ctx, span := s.tracer.Start(ctx, "PublishEmailEvent", trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
 	span.SetAttributes(
@@ -93,6 +233,7 @@ func (s *messagingService) PublishEmailEvent(ctx context.Context, emailContent s
 		UserID:       userID,
 		RetryCount:   0,
 		Timestamp:    time.Now(),
+		Source:       service.EventSourceFromContext(ctx),
 	}
 
 	body, err := json.Marshal(msg)
@@ -108,6 +249,7 @@ func (s *messagingService) PublishEmailEvent(ctx context.Context, emailContent s
 		false,                   // immediate
 		amqp.Publishing{
 			ContentType: "application/json",
+			Headers:     s.injectTraceContext(ctx),
 			Body:        body,
 		},
 	)
@@ -123,7 +265,7 @@ func (s *messagingService) PublishEmailEvent(ctx context.Context, emailContent s
 func (s *messagingService) ProcessMessages(ctx context.Context) error {
 	msgs, err := s.channel.Consume(
 		s.config.EmailQueueName, // queue
-		"",                      // consumer
+		emailConsumerTag,        // consumer
 		false,                   // auto-ack
 		false,                   // exclusive
 		false,                   // no-local
@@ -134,61 +276,216 @@ func (s *messagingService) ProcessMessages(ctx context.Context) error {
 		return fmt.Errorf("failed to register consumer: %v", err)
 	}
 
-	go func() {
-		for msg := range msgs {
-			processCtx, span := s.tracer.Start(ctx, "ProcessMessage")
-
-			var emailMsg EmailMessage
-			if err := json.Unmarshal(msg.Body, &emailMsg); err != nil {
-				span.RecordError(err)
-				if err := s.moveToDeadLetter(processCtx, msg); err != nil {
-					s.logger.Error("Failed to move message to dead letter queue", zap.Error(err))
-				}
-				span.End()
-				continue
+	done := make(chan struct{})
+	s.consumerDone = done
+
+	// Each worker handles one delivery at a time. The deliveries channel is closed
+	// once the consumer is cancelled and the deliveries already received are handled.
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range msgs {
+				s.handleDelivery(ctx, msg)
 			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return nil
+}
+
+func (s *messagingService) Shutdown(ctx context.Context) error {
+	if s.consumerDone != nil {
+		if err := s.channel.Cancel(emailConsumerTag, false); err != nil {
+			s.logger.Error("Failed to cancel consumer", zap.Error(err))
+		}
 
-			span.SetAttributes(
-				attribute.String("user_id", emailMsg.UserID),
-				attribute.Int("retry_count", emailMsg.RetryCount),
-			)
-
-			_, err := s.calendar.ProcessEmailToCalendar(processCtx, emailMsg.EmailContent) // Updated to match interface
-			if err != nil {
-				span.RecordError(err)
-				if emailMsg.RetryCount < s.config.MaxRetries {
-					if err := s.retryMessage(processCtx, emailMsg); err != nil {
-						s.logger.Error("Failed to retry message", zap.Error(err))
-					}
-				} else {
-					if err := s.moveToDeadLetter(processCtx, msg); err != nil {
-						s.logger.Error("Failed to move message to dead letter queue", zap.Error(err))
-					}
-				}
-			} else {
-				if err := msg.Ack(false); err != nil {
-					s.logger.Error("Failed to acknowledge message", zap.Error(err))
-				}
+		select {
+		case <-s.consumerDone:
+		case <-ctx.Done():
+			// Unacked messages are redelivered by the broker once the channel is closed
+			if err := s.Close(); err != nil {
+				s.logger.Error("Failed to close message queue", zap.Error(err))
 			}
+			return fmt.Errorf("message queue shutdown interrupted: %w", ctx.Err())
+		}
+	}
+
+	return s.Close()
+}
+
+// withUserTimezone adds the user's preferred timezone to ctx. Failing to load it is
+// not fatal: the email is processed in the default timezone.
+func (s *messagingService) withUserTimezone(ctx context.Context, userID string) context.Context {
+	if s.timezones == nil {
+		return ctx
+	}
+
+	timezone, err := s.timezones.UserTimezone(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to load user timezone")
+		return ctx
+	}
+	if timezone == "" {
+		return ctx
+	}
+	return service.WithTimezone(ctx, timezone)
+}
 
-			span.End()
+// handleDelivery processes a single queued email, tagging it with its ingestion channel.
+// The span continues the trace of the publisher when the message carries one.
+func (s *messagingService) handleDelivery(ctx context.Context, msg amqp.Delivery) {
+	ctx = s.extractTraceContext(ctx, msg.Headers)
+	processCtx, span := s.tracer.Start(ctx, "ProcessMessage", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	var emailMsg EmailMessage
+	if err := json.Unmarshal(msg.Body, &emailMsg); err != nil {
+		span.RecordError(err)
+		s.deadLetter(processCtx, msg)
+		return
+	}
+
+	source := service.SourceQueue
+	if emailMsg.Source.Valid() {
+		source = emailMsg.Source
+	}
+	processCtx = service.WithEventSource(processCtx, source)
+	if emailMsg.UserID != "" {
+		// Duplicate deliveries of the same email are deduplicated per user
+		processCtx = service.WithUserID(processCtx, emailMsg.UserID)
+		processCtx = s.withUserTimezone(processCtx, emailMsg.UserID)
+	}
+
+	span.SetAttributes(
+		attribute.String("user_id", emailMsg.UserID),
+		attribute.Int("retry_count", emailMsg.RetryCount),
+		attribute.String("source", string(source)),
+	)
+
+	if err := CheckEmailSize(emailMsg.EmailContent, s.maxEmailSize); err != nil {
+		span.RecordError(err)
+		s.logger.WithError(err).Warn("Rejected oversized queued email")
+		s.deadLetter(processCtx, msg)
+		return
+	}
+
+	if s.validator != nil {
+		if err := s.validator.ValidateEmail(processCtx, emailMsg.EmailContent); err != nil {
+			err = calerrors.NewInvalidEmailError("email failed validation").WithWrappedError(err)
+			span.RecordError(err)
+			s.logger.WithError(err).Warn("Rejected queued email")
+			s.deadLetter(processCtx, msg)
+			return
 		}
-	}()
+	}
 
-	return nil
+	// Wait for any worker processing the same email, so that the dedup claim and the
+	// idempotency check see what it did
+	unlock, err := s.lockEmail(processCtx, emailMsg)
+	if err != nil {
+		span.RecordError(err)
+		// Shutting down: hand the email back to the broker for another worker
+		s.requeue(msg)
+		return
+	}
+	defer unlock()
+
+	dedupKey, claimed := s.claimDelivery(processCtx, emailMsg)
+	if !claimed {
+		span.AddEvent("duplicate email skipped")
+		s.logger.WithField("user_id", emailMsg.UserID).Info("Skipping already processed queued email")
+		s.ack(msg)
+		return
+	}
+
+	_, err = s.calendar.ProcessEmailToCalendar(processCtx, emailMsg.EmailContent) // Updated to match interface
+	if err != nil {
+		span.RecordError(err)
+		s.metrics.calendarCreateFailed()
+		s.releaseDelivery(processCtx, dedupKey)
+		if emailMsg.RetryCount < s.config.MaxRetries && isRetryable(err) {
+			if err := s.retryMessage(processCtx, emailMsg, s.retryDelay(err)); err != nil {
+				s.logger.Error("Failed to retry message", zap.Error(err))
+				s.requeue(msg)
+				return
+			}
+			s.ack(msg)
+		} else {
+			s.deadLetter(processCtx, msg)
+		}
+	} else {
+		s.ack(msg)
+	}
+}
+
+// deadLetter moves msg to the dead letter queue and acks it. If the move fails the
+// delivery is requeued rather than lost.
+func (s *messagingService) deadLetter(ctx context.Context, msg amqp.Delivery) {
+	if err := s.moveToDeadLetter(ctx, msg); err != nil {
+		s.logger.Error("Failed to move message to dead letter queue", zap.Error(err))
+		s.requeue(msg)
+		return
+	}
+	s.ack(msg)
+}
+
+// ack acknowledges msg. Every delivery must be acked or nacked: an unsettled one keeps
+// its prefetch slot and the broker stops sending once all slots are taken.
+func (s *messagingService) ack(msg amqp.Delivery) {
+	if err := msg.Ack(false); err != nil {
+		s.logger.Error("Failed to acknowledge message", zap.Error(err))
+	}
+}
+
+// requeue hands msg back to the broker to be delivered again
+func (s *messagingService) requeue(msg amqp.Delivery) {
+	if err := msg.Nack(false, true); err != nil {
+		s.logger.Error("Failed to requeue message", zap.Error(err))
+	}
+}
+
+// isRetryable reports whether a failed email is worth queueing again. Typed calendar
+// errors say so themselves; a parse or validation failure will fail the same way on
+// every attempt. Untyped errors are assumed to be transient.
+func isRetryable(err error) bool {
+	var cerr *calerrors.CalendarError
+	if !errors.As(err, &cerr) {
+		return true
+	}
+	return calerrors.ShouldRetry(err)
+}
+
+// retryDelay is the wait before queueing a failed email again: the delay suggested by
+// the error when it has one, the configured delay otherwise
+func (s *messagingService) retryDelay(err error) time.Duration {
+	if retryAfter := calerrors.GetRetryAfter(err); retryAfter != nil {
+		return *retryAfter
+	}
+	return time.Duration(s.config.RetryDelaySeconds) * time.Second
 }
 
 func (s *messagingService) Close() error {
 	if err := s.channel.Close(); err != nil {
 		return fmt.Errorf("failed to close channel: %v", err)
 	}
-	if err := s.conn.Close(); err != nil {
-		return fmt.Errorf("failed to close connection: %v", err)
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close connection: %v", err)
+		}
 	}
 	return nil
 }
 
-func (s *messagingService) retryMessage(ctx context.Context, msg EmailMessage) error {
+// retryMessage queues msg again after delay. It is published to the retry queue with
+// the delay as its TTL, and the broker dead-letters it back to the email queue once it
+// expires, so no worker waits for the delay.
+func (s *messagingService) retryMessage(ctx context.Context, msg EmailMessage, delay time.Duration) error {
 	msg.RetryCount++
 	msg.Timestamp = time.Now()
 
@@ -197,22 +494,25 @@ func (s *messagingService) retryMessage(ctx context.Context, msg EmailMessage) e
 		return err
 	}
 
-	// Publish with delay
-	time.Sleep(time.Duration(s.config.RetryDelaySeconds) * time.Second)
+	if delay < 0 {
+		delay = 0
+	}
 	return s.channel.PublishWithContext(ctx,
 		"",
-		s.config.EmailQueueName,
+		s.config.retryQueue(),
 		false,
 		false,
 		amqp.Publishing{
 			ContentType: "application/json",
+			Headers:     s.injectTraceContext(ctx),
 			Body:        body,
+			Expiration:  strconv.FormatInt(delay.Milliseconds(), 10),
 		},
 	)
 }
 
 func (s *messagingService) moveToDeadLetter(ctx context.Context, msg amqp.Delivery) error {
-	return s.channel.PublishWithContext(ctx,
+	err := s.channel.PublishWithContext(ctx,
 		"",
 		s.config.DeadLetterQueue,
 		false,
@@ -223,6 +523,12 @@ func (s *messagingService) moveToDeadLetter(ctx context.Context, msg amqp.Delive
 			Headers:     msg.Headers,
 		},
 	)
+	if err != nil {
+		return err
+	}
+
+	s.metrics.deadLetter()
+	return nil
 }
 
 func declareQueues(ch *amqp.Channel, config QueueConfig) error {
@@ -248,5 +554,21 @@ func declareQueues(ch *amqp.Channel, config QueueConfig) error {
 		false, // no-wait
 		nil,   // arguments
 	)
+	if err != nil {
+		return err
+	}
+
+	// Declare retry queue; expired messages go back to the main queue
+	_, err = ch.QueueDeclare(
+		config.retryQueue(),
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": config.EmailQueueName,
+		},
+	)
 	return err
 }
@@ -0,0 +1,452 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+// fakeQueueChannel records the messages published to each queue and serves deliveries
+// from a channel the test feeds
+type fakeQueueChannel struct {
+	published  map[string][]amqp.Publishing
+	deliveries chan amqp.Delivery
+	// events records acks, cancels and closes in order
+	events []string
+	mu     sync.Mutex
+	// publishErr, when set, fails every publish
+	publishErr error
+}
+
+func (c *fakeQueueChannel) record(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *fakeQueueChannel) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.events...)
+}
+
+func (c *fakeQueueChannel) PublishWithContext(_ context.Context, _, key string, _, _ bool, msg amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.publishErr != nil {
+		return c.publishErr
+	}
+	if c.published == nil {
+		c.published = make(map[string][]amqp.Publishing)
+	}
+	c.published[key] = append(c.published[key], msg)
+	return nil
+}
+
+func (c *fakeQueueChannel) Consume(string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.deliveries, nil
+}
+
+// Cancel stops deliveries like the broker does, once those already sent are received
+func (c *fakeQueueChannel) Cancel(consumer string, _ bool) error {
+	c.record("cancel " + consumer)
+	close(c.deliveries)
+	return nil
+}
+
+func (c *fakeQueueChannel) Close() error {
+	c.record("close")
+	return nil
+}
+
+// Ack, Nack and Reject make the fake channel the acknowledger of its deliveries
+func (c *fakeQueueChannel) Ack(tag uint64, _ bool) error {
+	c.record(fmt.Sprintf("ack %d", tag))
+	return nil
+}
+
+func (c *fakeQueueChannel) Nack(tag uint64, _ bool, _ bool) error {
+	c.record(fmt.Sprintf("nack %d", tag))
+	return nil
+}
+
+func (c *fakeQueueChannel) Reject(tag uint64, _ bool) error {
+	c.record(fmt.Sprintf("reject %d", tag))
+	return nil
+}
+
+func TestMessagingService_handleDelivery_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		retryCount   int
+		validateErr  error
+		expectedDLQ  bool
+		expectedNext int
+	}{
+		{
+			name:        "validation error goes straight to the dead letter queue",
+			err:         calerrors.NewValidationError("event subject is required"),
+			expectedDLQ: true,
+		},
+		{
+			name:        "wrapped parse error goes straight to the dead letter queue",
+			err:         fmt.Errorf("process email: %w", calerrors.NewParseError("failed to parse email")),
+			expectedDLQ: true,
+		},
+		{
+			name:         "service unavailable error is retried",
+			err:          calerrors.NewServiceUnavailableError("NER service down"),
+			expectedNext: 1,
+		},
+		{
+			name:         "untyped error is retried",
+			err:          fmt.Errorf("connection reset"),
+			retryCount:   1,
+			expectedNext: 2,
+		},
+		{
+			name:        "retries are exhausted",
+			err:         calerrors.NewServiceUnavailableError("NER service down"),
+			retryCount:  3,
+			expectedDLQ: true,
+		},
+		{
+			name:        "email failing validation is not processed",
+			validateErr: fmt.Errorf("DKIM error"),
+			expectedDLQ: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockDomainCalendarService)
+			if tt.validateErr == nil {
+				calendar.On("ProcessEmailToCalendar", mock.Anything, "email").Return(nil, tt.err)
+			}
+
+			validator := new(mockEmailValidator)
+			validator.On("ValidateDKIM", "email").Return(tt.validateErr)
+			validator.On("ValidateSPF", "email").Return(nil).Maybe()
+			validator.On("ValidateSender", "email").Return(nil).Maybe()
+
+			channel := &fakeQueueChannel{}
+			s := &messagingService{
+				channel:  channel,
+				config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+				calendar: calendar,
+				tracer:   otel.Tracer("test"),
+				logger:   logrus.New(),
+			}
+			WithEmailValidation(NewEmailProcessorImpl(validator, new(mockNERService)))(s)
+
+			body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1", RetryCount: tt.retryCount})
+			require.NoError(t, err)
+
+			s.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: channel, DeliveryTag: 1, Body: body})
+			calendar.AssertExpectations(t)
+
+			// The original delivery is settled either way so that it frees its prefetch slot
+			assert.Equal(t, []string{"ack 1"}, channel.recorded())
+
+			if tt.expectedDLQ {
+				assert.Len(t, channel.published["emails.dlq"], 1)
+				assert.Empty(t, channel.published["emails.retry"])
+				return
+			}
+
+			assert.Empty(t, channel.published["emails.dlq"])
+			require.Len(t, channel.published["emails.retry"], 1)
+			retry := channel.published["emails.retry"][0]
+			assert.Equal(t, "0", retry.Expiration)
+			var retried EmailMessage
+			require.NoError(t, json.Unmarshal(retry.Body, &retried))
+			assert.Equal(t, tt.expectedNext, retried.RetryCount)
+		})
+	}
+}
+
+func TestMessagingService_handleDelivery_Settles(t *testing.T) {
+	validBody, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		body            []byte
+		processErr      error
+		publishErr      error
+		expectedEvents  []string
+		expectedQueue   string
+		expectedExpires string
+	}{
+		{
+			name:           "malformed message is dead-lettered and acked",
+			body:           []byte("{"),
+			expectedEvents: []string{"ack 1"},
+			expectedQueue:  "emails.dlq",
+		},
+		{
+			name:           "malformed message is requeued when dead-lettering fails",
+			body:           []byte("{"),
+			publishErr:     fmt.Errorf("channel closed"),
+			expectedEvents: []string{"nack 1"},
+		},
+		{
+			name:            "long retry delay doesn't hold the worker",
+			body:            validBody,
+			processErr:      calerrors.NewServiceUnavailableError("rate limited").WithRetry(30 * time.Second),
+			expectedEvents:  []string{"ack 1"},
+			expectedQueue:   "emails.retry",
+			expectedExpires: "30000",
+		},
+		{
+			name:           "failed email is requeued when the retry can't be published",
+			body:           validBody,
+			processErr:     calerrors.NewServiceUnavailableError("NER service down"),
+			publishErr:     fmt.Errorf("channel closed"),
+			expectedEvents: []string{"nack 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", mock.Anything, "email").Return(nil, tt.processErr).Maybe()
+
+			channel := &fakeQueueChannel{publishErr: tt.publishErr}
+			s := &messagingService{
+				channel:  channel,
+				config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+				calendar: calendar,
+				tracer:   otel.Tracer("test"),
+				logger:   logrus.New(),
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				s.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: channel, DeliveryTag: 1, Body: tt.body})
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("handleDelivery did not return")
+			}
+
+			assert.Equal(t, tt.expectedEvents, channel.recorded())
+			if tt.expectedQueue == "" {
+				assert.Empty(t, channel.published)
+				return
+			}
+			require.Len(t, channel.published[tt.expectedQueue], 1)
+			assert.Equal(t, tt.expectedExpires, channel.published[tt.expectedQueue][0].Expiration)
+		})
+	}
+}
+
+func TestQueueConfig_retryQueue(t *testing.T) {
+	assert.Equal(t, "emails.retry", QueueConfig{EmailQueueName: "emails"}.retryQueue())
+	assert.Equal(t, "emails.later", QueueConfig{EmailQueueName: "emails", RetryQueue: "emails.later"}.retryQueue())
+}
+
+func TestMessagingService_retryDelay(t *testing.T) {
+	s := &messagingService{config: QueueConfig{RetryDelaySeconds: 5}}
+
+	assert.Equal(t, 5*time.Second, s.retryDelay(calerrors.NewServiceUnavailableError("down")))
+	assert.Equal(t, 30*time.Second, s.retryDelay(calerrors.NewServiceUnavailableError("rate limited").WithRetry(30*time.Second)))
+	assert.Equal(t, time.Minute, s.retryDelay(fmt.Errorf("create event: %w", calerrors.NewError("QUOTA", "quota").WithRetry(time.Minute))))
+	assert.Equal(t, 5*time.Second, s.retryDelay(fmt.Errorf("connection reset")))
+}
+
+func TestMessagingService_Shutdown_DrainsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	channel := &fakeQueueChannel{deliveries: make(chan amqp.Delivery, 1)}
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails"},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	require.NoError(t, s.ProcessMessages(context.Background()))
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+	channel.deliveries <- amqp.Delivery{Acknowledger: channel, DeliveryTag: 7, Body: body}
+	<-started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+
+	// The channel stays open while the email is being processed
+	time.Sleep(20 * time.Millisecond)
+	assert.NotContains(t, channel.recorded(), "close")
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	assert.Equal(t, []string{"cancel " + emailConsumerTag, "ack 7", "close"}, channel.recorded())
+}
+
+func TestMessagingService_Shutdown_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Run(func(mock.Arguments) { <-release }).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	channel := &fakeQueueChannel{deliveries: make(chan amqp.Delivery, 1)}
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails"},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	require.NoError(t, s.ProcessMessages(context.Background()))
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email"})
+	require.NoError(t, err)
+	channel.deliveries <- amqp.Delivery{Acknowledger: channel, DeliveryTag: 1, Body: body}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"cancel " + emailConsumerTag, "close"}, channel.recorded())
+}
+
+// concurrencyCalendar blocks every email until release is closed and records how many
+// are processed at the same time
+type concurrencyCalendar struct {
+	mockDomainCalendarService
+	release chan struct{}
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (c *concurrencyCalendar) ProcessEmailToCalendar(context.Context, string) (*calendarPb.CreateEventResponseV2, error) {
+	c.mu.Lock()
+	c.running++
+	if c.running > c.peak {
+		c.peak = c.running
+	}
+	c.mu.Unlock()
+
+	<-c.release
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	return &calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil
+}
+
+func (c *concurrencyCalendar) counts() (running, peak int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running, c.peak
+}
+
+func TestMessagingService_ProcessMessages_Concurrency(t *testing.T) {
+	calendar := &concurrencyCalendar{release: make(chan struct{})}
+	channel := &fakeQueueChannel{deliveries: make(chan amqp.Delivery, 6)}
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails", Concurrency: 2},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	require.NoError(t, s.ProcessMessages(context.Background()))
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+	for tag := uint64(1); tag <= 6; tag++ {
+		channel.deliveries <- amqp.Delivery{Acknowledger: channel, DeliveryTag: tag, Body: body}
+	}
+
+	require.Eventually(t, func() bool {
+		running, _ := calendar.counts()
+		return running == 2
+	}, time.Second, time.Millisecond)
+	// Give a third worker, if there were one, time to start
+	time.Sleep(20 * time.Millisecond)
+	running, _ := calendar.counts()
+	assert.Equal(t, 2, running)
+
+	close(calendar.release)
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	_, peak := calendar.counts()
+	assert.Equal(t, 2, peak)
+	assert.ElementsMatch(t, []string{
+		"cancel " + emailConsumerTag,
+		"ack 1", "ack 2", "ack 3", "ack 4", "ack 5", "ack 6",
+		"close",
+	}, channel.recorded())
+}
+
+func TestQueueConfig_workers(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           QueueConfig
+		expectedWorkers  int
+		expectedPrefetch int
+	}{
+		{
+			name:             "defaults",
+			expectedWorkers:  DefaultQueueConcurrency,
+			expectedPrefetch: DefaultQueueConcurrency,
+		},
+		{
+			name:             "prefetch follows concurrency",
+			config:           QueueConfig{Concurrency: 8},
+			expectedWorkers:  8,
+			expectedPrefetch: 8,
+		},
+		{
+			name:             "prefetch above concurrency",
+			config:           QueueConfig{Concurrency: 2, PrefetchCount: 10},
+			expectedWorkers:  2,
+			expectedPrefetch: 10,
+		},
+		{
+			name:             "prefetch caps workers",
+			config:           QueueConfig{Concurrency: 8, PrefetchCount: 3},
+			expectedWorkers:  3,
+			expectedPrefetch: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedWorkers, tt.config.workers())
+			assert.Equal(t, tt.expectedPrefetch, tt.config.prefetch())
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsNamespace prefixes the name of every metric of the service
+const metricsNamespace = "mail2calendar"
+
+// Outcomes of a processed email, used as the result label
+const (
+	metricsResultSuccess = "success"
+	metricsResultFailure = "failure"
+)
+
+// Metrics holds the Prometheus collectors of email processing. The processor, NER
+// service and message queue record into it when given one with their WithXxxMetrics
+// option. A nil *Metrics records nothing.
+type Metrics struct {
+	emailsProcessed        *prometheus.CounterVec
+	processingDuration     prometheus.Histogram
+	nerRequestDuration     *prometheus.HistogramVec
+	calendarCreateFailures prometheus.Counter
+	deadLettered           prometheus.Counter
+}
+
+// NewMetrics creates the collectors and registers them with reg. Pass
+// prometheus.DefaultRegisterer to expose them on the server's /metrics endpoint.
+// It panics if they are already registered with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		emailsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "emails_processed_total",
+			Help:      "Emails processed into calendar events, by result.",
+		}, []string{"result"}),
+		processingDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "email_processing_duration_seconds",
+			Help:      "Time taken to extract the events of an email.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		nerRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "ner_request_duration_seconds",
+			Help:      "Latency of requests to the NER service, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		calendarCreateFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "calendar_create_failures_total",
+			Help:      "Queued emails whose calendar event could not be created.",
+		}),
+		deadLettered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dead_letter_messages_total",
+			Help:      "Messages moved to the dead letter queue.",
+		}),
+	}
+
+	reg.MustRegister(
+		m.emailsProcessed,
+		m.processingDuration,
+		m.nerRequestDuration,
+		m.calendarCreateFailures,
+		m.deadLettered,
+	)
+
+	return m
+}
+
+// observeEmail records an email whose processing started at start and ended with err
+func (m *Metrics) observeEmail(start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	result := metricsResultSuccess
+	if err != nil {
+		result = metricsResultFailure
+	}
+	m.emailsProcessed.WithLabelValues(result).Inc()
+	m.processingDuration.Observe(time.Since(start).Seconds())
+}
+
+// observeNERRequest records a request to endpoint of the NER service that started at start
+func (m *Metrics) observeNERRequest(endpoint string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.nerRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+}
+
+// calendarCreateFailed records a queued email whose calendar event could not be created
+func (m *Metrics) calendarCreateFailed() {
+	if m == nil {
+		return
+	}
+	m.calendarCreateFailures.Inc()
+}
+
+// deadLetter records a message moved to the dead letter queue
+func (m *Metrics) deadLetter() {
+	if m == nil {
+		return
+	}
+	m.deadLettered.Inc()
+}
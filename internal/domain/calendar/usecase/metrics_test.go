@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+// scrapeMetrics returns the body of a scrape of the /metrics endpoint serving reg
+func scrapeMetrics(t *testing.T, reg *prometheus.Registry) string {
+	t.Helper()
+
+	server := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_EmailProcessor(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+		Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("Room 1", nil)
+
+	validator := new(mockEmailValidator)
+	validator.On("ValidateDKIM", headerTestEmail).Return(fmt.Errorf("signature invalid"))
+
+	reg := prometheus.NewRegistry()
+	processor := NewEmailProcessorImpl(validator, ner, WithProcessorMetrics(NewMetrics(reg)))
+
+	_, err := processor.ProcessEmail(context.Background(), headerTestEmail)
+	require.NoError(t, err)
+	assert.Contains(t, scrapeMetrics(t, reg), `mail2calendar_emails_processed_total{result="success"} 1`)
+
+	_, err = processor.ProcessEmail(context.Background(), headerTestEmail)
+	require.NoError(t, err)
+	body := scrapeMetrics(t, reg)
+	assert.Contains(t, body, `mail2calendar_emails_processed_total{result="success"} 2`)
+	assert.Contains(t, body, "mail2calendar_email_processing_duration_seconds_count 2")
+
+	// Previews are not processed emails
+	_, err = processor.ProcessEmailPreview(context.Background(), headerTestEmail)
+	assert.Error(t, err)
+	assert.NotContains(t, scrapeMetrics(t, reg), `result="failure"`)
+}
+
+func TestMetrics_MessageQueue(t *testing.T) {
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Return(nil, calerrors.NewParseError("failed to parse email"))
+
+	reg := prometheus.NewRegistry()
+	channel := &fakeQueueChannel{}
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	WithQueueMetrics(NewMetrics(reg))(s)
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+	s.handleDelivery(context.Background(), amqp.Delivery{Body: body})
+
+	require.Len(t, channel.published["emails.dlq"], 1)
+	metrics := scrapeMetrics(t, reg)
+	assert.Contains(t, metrics, "mail2calendar_calendar_create_failures_total 1")
+	assert.Contains(t, metrics, "mail2calendar_dead_letter_messages_total 1")
+}
+
+func TestMetrics_NERService(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"entities": []}`)),
+		}, nil
+	})}
+
+	reg := prometheus.NewRegistry()
+	s := NewNERService("http://ner", WithNERHTTPClient(client), WithNERMetrics(NewMetrics(reg)))
+
+	_, err := s.ExtractEntities(context.Background(), "Meeting tomorrow", "en")
+	require.NoError(t, err)
+	assert.Contains(t, scrapeMetrics(t, reg), `mail2calendar_ner_request_duration_seconds_count{endpoint="extract"} 1`)
+}
@@ -18,11 +18,11 @@ import (
 	"golang.org/x/text/encoding/korean"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/traditionalchinese"
-	"golang.org/x/text/transform"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
 )
 
 // MIMEParser handles parsing of email content
@@ -41,11 +41,30 @@ type ParsedEmail struct {
 	Attachments []Attachment
 }
 
-// Attachment represents an email attachment
+// Attachment represents an email attachment.
+// Data is excluded from JSON, fmt and zap output, like EmailAttachment.
 type Attachment struct {
 	Filename    string
 	ContentType string
-	Data        []byte
+	Data        []byte `json:"-"`
+}
+
+// String describes the attachment without its content
+func (a Attachment) String() string {
+	return fmt.Sprintf("Attachment{Filename: %q, ContentType: %q, Size: %d}", a.Filename, a.ContentType, len(a.Data))
+}
+
+// GoString keeps %#v from printing the attachment content
+func (a Attachment) GoString() string {
+	return a.String()
+}
+
+// MarshalLogObject lets zap log the attachment without its content
+func (a Attachment) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("filename", a.Filename)
+	enc.AddString("content_type", a.ContentType)
+	enc.AddInt("size", len(a.Data))
+	return nil
 }
 
 type mimeParserImpl struct {
@@ -196,31 +215,28 @@ func (p *mimeParserImpl) parseTextPartFromHeader(r io.Reader, header textproto.M
 }
 
 func (p *mimeParserImpl) parseTextContent(r io.Reader, transferEncoding string, charset string) (string, error) {
-	if charset == "" {
-		charset = "utf-8"
-	}
-
-	var contentReader io.Reader = r
-
 	// Apply transfer encoding
-	switch strings.ToLower(transferEncoding) {
-	case "base64":
-		contentReader = base64.NewDecoder(base64.StdEncoding, contentReader)
-	case "quoted-printable":
-		contentReader = quotedprintable.NewReader(contentReader)
+	content, err := io.ReadAll(p.decodeTransferEncoding(r, transferEncoding))
+	if err != nil {
+		return "", err
 	}
 
-	// Apply character encoding
-	if dec := p.getDecoder(charset); dec != nil {
-		contentReader = transform.NewReader(contentReader, dec.NewDecoder())
+	// Apply character encoding. Emails often declare no charset, or one we do not
+	// know, for content that is not UTF-8; guess the charset from the content then.
+	dec := p.getDecoder(charset)
+	if dec == nil {
+		dec = guessCharset(content)
+	}
+	if dec == nil {
+		return string(content), nil
 	}
 
-	content, err := io.ReadAll(contentReader)
+	decoded, err := dec.NewDecoder().Bytes(content)
 	if err != nil {
 		return "", err
 	}
 
-	return string(content), nil
+	return string(decoded), nil
 }
 
 func (p *mimeParserImpl) parseAttachment(part *multipart.Part, parsed *ParsedEmail) error {
@@ -229,7 +245,7 @@ func (p *mimeParserImpl) parseAttachment(part *multipart.Part, parsed *ParsedEma
 		return nil
 	}
 
-	data, err := io.ReadAll(part)
+	data, err := io.ReadAll(p.decodeTransferEncoding(part, part.Header.Get("Content-Transfer-Encoding")))
 	if err != nil {
 		return err
 	}
@@ -243,6 +259,20 @@ func (p *mimeParserImpl) parseAttachment(part *multipart.Part, parsed *ParsedEma
 	return nil
 }
 
+// decodeTransferEncoding wraps r with a decoder for its Content-Transfer-Encoding.
+// multipart.Reader already decodes quoted-printable parts and removes the header,
+// so the quoted-printable case only applies to single-part bodies.
+func (p *mimeParserImpl) decodeTransferEncoding(r io.Reader, transferEncoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
 func (p *mimeParserImpl) decodeHeader(header string) string {
 	decoded, err := (&mime.WordDecoder{}).DecodeHeader(header)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMIMEParser_Parse(t *testing.T) {
@@ -127,16 +128,62 @@ SlBFRyBjb250ZW50
 			for i, expectedAttachment := range tt.expectedEmail.Attachments {
 				assert.Equal(t, expectedAttachment.Filename, email.Attachments[i].Filename)
 				assert.Equal(t, expectedAttachment.ContentType, email.Attachments[i].ContentType)
-
-				// Decode base64 content
-				decodedData, err := base64.StdEncoding.DecodeString(string(email.Attachments[i].Data))
-				assert.NoError(t, err)
-				assert.Equal(t, expectedAttachment.Data, decodedData)
+				assert.Equal(t, expectedAttachment.Data, email.Attachments[i].Data)
 			}
 		})
 	}
 }
 
+func TestMIMEParser_Parse_DecodesAttachments(t *testing.T) {
+	// 1x1 transparent PNG
+	png, err := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
+	require.NoError(t, err)
+
+	// Wrap the base64 body at 20 characters, as mail clients wrap at 76
+	encoded := base64.StdEncoding.EncodeToString(png)
+	var wrapped strings.Builder
+	for len(encoded) > 20 {
+		wrapped.WriteString(encoded[:20] + "\r\n")
+		encoded = encoded[20:]
+	}
+	wrapped.WriteString(encoded)
+
+	emailContent := "From: sender@example.com\r\n" +
+		"To: recipient@example.com\r\n" +
+		"Subject: Logo\r\n" +
+		"Content-Type: multipart/mixed; boundary=boundary123\r\n" +
+		"\r\n" +
+		"--boundary123\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"See attached.\r\n" +
+		"--boundary123\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"Content-Disposition: attachment; filename=\"logo.png\"\r\n" +
+		"\r\n" +
+		wrapped.String() + "\r\n" +
+		"--boundary123\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"Content-Disposition: attachment; filename=\"agenda.csv\"\r\n" +
+		"\r\n" +
+		"item,owner=0D=0Abudget,Ph=C3=BAc\r\n" +
+		"--boundary123--\r\n"
+
+	email, err := NewMIMEParser().Parse(context.Background(), emailContent)
+	require.NoError(t, err)
+	require.Len(t, email.Attachments, 2)
+
+	attachment := email.Attachments[0]
+	assert.Equal(t, "logo.png", attachment.Filename)
+	assert.Equal(t, png, attachment.Data)
+	assert.Equal(t, []byte("\x89PNG\r\n\x1a\n"), attachment.Data[:8])
+
+	assert.Equal(t, "agenda.csv", email.Attachments[1].Filename)
+	assert.Equal(t, []byte("item,owner\r\nbudget,Phúc"), email.Attachments[1].Data)
+}
+
 func TestMIMEParser_ParseHeaders(t *testing.T) {
 	tests := []struct {
 		name         string
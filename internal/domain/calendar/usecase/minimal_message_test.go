@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailProcessorImpl_extractEmailContent_MinimalMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		plainText string
+		html      string
+	}{
+		{
+			name:      "headers without Content-Type",
+			email:     "From: alice@example.com\r\nSubject: Sync\r\n\r\nSync tomorrow at 2pm.",
+			plainText: "Sync tomorrow at 2pm.",
+		},
+		{
+			name:      "no headers at all",
+			email:     "Sync tomorrow at 2pm.\nThanks, Alice",
+			plainText: "Sync tomorrow at 2pm.\nThanks, Alice",
+		},
+		{
+			name:      "leading blank line and no headers",
+			email:     "\r\nSync tomorrow at 2pm.",
+			plainText: "Sync tomorrow at 2pm.",
+		},
+		{
+			name:      "body line that looks like a header",
+			email:     "Agenda: planning\n\nSync tomorrow at 2pm.",
+			plainText: "Agenda: planning\n\nSync tomorrow at 2pm.",
+		},
+		{
+			name:  "undeclared HTML is sniffed",
+			email: "From: alice@example.com\r\nSubject: Sync\r\n\r\n<html><body><p>Sync tomorrow at 2pm.</p></body></html>",
+			html:  "<html><body><p>Sync tomorrow at 2pm.</p></body></html>",
+		},
+		{
+			name:      "declared plain text is not sniffed",
+			email:     "From: alice@example.com\r\nContent-Type: text/plain\r\n\r\n<html><body>raw markup</body></html>",
+			plainText: "<html><body>raw markup</body></html>",
+		},
+		{
+			name: "multipart part without Content-Type",
+			email: "From: alice@example.com\r\n" +
+				"Content-Type: multipart/mixed; boundary=b1\r\n" +
+				"\r\n" +
+				"--b1\r\n" +
+				"\r\n" +
+				"Sync tomorrow at 2pm.\r\n" +
+				"--b1--\r\n",
+			plainText: "Sync tomorrow at 2pm.",
+		},
+	}
+
+	ep := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService)).(*emailProcessorImpl)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ep.parseEmail(context.Background(), tt.email)
+			require.NoError(t, err)
+
+			content, err := ep.extractEmailContent(context.Background(), msg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plainText, content.PlainText)
+			assert.Equal(t, tt.html, content.HTML)
+		})
+	}
+}
+
+func TestEmailProcessorImpl_parseEmail_Empty(t *testing.T) {
+	ep := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService)).(*emailProcessorImpl)
+
+	for _, email := range []string{"", "\r\n\r\n"} {
+		_, err := ep.parseEmail(context.Background(), email)
+		assert.Error(t, err)
+	}
+}
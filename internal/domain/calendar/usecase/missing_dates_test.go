@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailProcessorImpl_ProcessEmail_MissingDates(t *testing.T) {
+	emailContent := "From: organizer@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Let's catch up\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"We should catch up sometime soon."
+
+	tests := []struct {
+		name             string
+		opts             []EmailProcessorOption
+		expectedErr      error
+		expectedDuration time.Duration
+	}{
+		{
+			name:             "default creates the event from now",
+			expectedDuration: time.Hour,
+		},
+		{
+			name:             "use now with a configured duration",
+			opts:             []EmailProcessorOption{WithMissingDatesPolicy(MissingDatesUseNow), WithDefaultEventDuration(30 * time.Minute)},
+			expectedDuration: 30 * time.Minute,
+		},
+		{
+			name:        "skip asks for clarification",
+			opts:        []EmailProcessorOption{WithMissingDatesPolicy(MissingDatesSkip)},
+			expectedErr: ErrNoEventDates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{}, nil)
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil).Maybe()
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, tt.opts...)
+			before := time.Now()
+			event, err := processor.ProcessEmail(context.Background(), emailContent)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, event)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.WithinDuration(t, before, event.StartTime, time.Second)
+			assert.Equal(t, tt.expectedDuration, event.EndTime.Sub(event.StartTime))
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmail_DefaultEventDuration(t *testing.T) {
+	emailContent := "From: organizer@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Review\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Review tomorrow at 9am."
+
+	start := time.Now().Add(24 * time.Hour)
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, WithDefaultEventDuration(45*time.Minute))
+	event, err := processor.ProcessEmail(context.Background(), emailContent)
+	require.NoError(t, err)
+
+	assert.Equal(t, start, event.StartTime)
+	assert.Equal(t, start.Add(45*time.Minute), event.EndTime)
+}
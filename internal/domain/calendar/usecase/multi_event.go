@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// agendaItemPattern matches a bulleted or numbered list item, capturing its text
+var agendaItemPattern = regexp.MustCompile(`^\s*(?:[-*•]|\d{1,2}[.)])\s+(.+)$`)
+
+// agendaItem is one list item of an email body, a candidate meeting
+type agendaItem struct {
+	// heading is the first line of the item without its bullet
+	heading string
+	// text is the heading followed by its continuation lines
+	text string
+}
+
+func (ep *emailProcessorImpl) ProcessEmailMulti(ctx context.Context, emailContent string) ([]*EmailEvent, error) {
+	start := time.Now()
+	events, err := ep.processEmailMulti(ctx, emailContent)
+	ep.metrics.observeEmail(start, err)
+	return events, err
+}
+
+// processEmailMulti extracts the events of ProcessEmailMulti
+func (ep *emailProcessorImpl) processEmailMulti(ctx context.Context, emailContent string) ([]*EmailEvent, error) {
+	ctx, span := ep.tracer.Start(ctx, "ProcessEmailMulti")
+	defer span.End()
+
+	if err := ep.checkValidation(ctx, emailContent); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	msg, err := ep.parseEmail(ctx, emailContent)
+	if err != nil {
+		span.RecordError(err)
+		return nil, calerrors.NewParseError("failed to parse email").WithWrappedError(err)
+	}
+
+	content, err := ep.extractEmailContent(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		return nil, calerrors.NewParseError("failed to extract email content").WithWrappedError(err)
+	}
+	ctx = ep.withSenderTimezone(ctx, content.Metadata)
+
+	// Each list item with its own date is a meeting of its own
+	var events []*EmailEvent
+	items := splitAgendaItems(ep.textContent(content))
+	if len(items) >= 2 {
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			dates, err := ep.nerService.ExtractDateTime(ctx, item.text)
+			if err != nil {
+				span.RecordError(err)
+				if calerrors.IsServiceUnavailable(err) {
+					return nil, err
+				}
+				return nil, calerrors.NewServiceUnavailableError("failed to extract dates").WithWrappedError(err)
+			}
+			if len(dates) == 0 {
+				// An item without a date is a note, not a meeting
+				continue
+			}
+
+			sortDates(dates)
+			dateOnly := isDateOnly(dates)
+			if len(dates) == 1 {
+				dates = append(dates, dates[0].Add(ep.eventLength(item.text)))
+			}
+
+			event, err := ep.newEmailEvent(ctx, msg, content, item.heading, item.text, dates, dateOnly)
+			if err != nil {
+				span.RecordError(err)
+				return nil, eventInfoError(err)
+			}
+			if err := ep.validateEvent(ctx, event); err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			events = append(events, event)
+		}
+	}
+
+	// Not an agenda, the whole email describes a single meeting
+	if len(events) == 0 {
+		event, err := ep.extractEventInfo(ctx, msg, content)
+		if err != nil {
+			span.RecordError(err)
+			return nil, eventInfoError(err)
+		}
+		if err := ep.validateEvent(ctx, event); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	span.SetAttributes(
+		attribute.Int("events_count", len(events)),
+		attribute.String("event.start_time", events[0].StartTime.Format(time.RFC3339)),
+	)
+
+	return events, nil
+}
+
+// splitAgendaItems splits text into its list items. Lines following an item belong to
+// it until a blank line or the next item; text outside items is ignored.
+func splitAgendaItems(text string) []agendaItem {
+	var items []agendaItem
+	var current *agendaItem
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+
+		if match := agendaItemPattern.FindStringSubmatch(line); match != nil {
+			heading := strings.TrimSpace(match[1])
+			items = append(items, agendaItem{heading: heading, text: heading})
+			current = &items[len(items)-1]
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		if current != nil {
+			current.text += "\n" + strings.TrimSpace(line)
+		}
+	}
+	return items
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func textContaining(s string) interface{} {
+	return mock.MatchedBy(func(text string) bool { return strings.Contains(text, s) })
+}
+
+func TestEmailProcessorImpl_ProcessEmailMulti(t *testing.T) {
+	emailContent := "From: Organizer <organizer@example.com>\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Agenda for Wednesday\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Good morning, here is today's agenda:\r\n" +
+		"\r\n" +
+		"1. Standup 9:00-9:15 in Room 1\r\n" +
+		"2. Lunch break\r\n" +
+		"3. Design review 14:00-15:00 in Room 2\r\n" +
+		"   bring bob@example.com\r\n" +
+		"\r\n" +
+		"Thanks"
+
+	day := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, textContaining("Standup")).
+		Return([]time.Time{day.Add(9*time.Hour + 15*time.Minute), day.Add(9 * time.Hour)}, nil)
+	ner.On("ExtractDateTime", mock.Anything, textContaining("Lunch")).Return([]time.Time{}, nil)
+	ner.On("ExtractDateTime", mock.Anything, textContaining("Design review")).
+		Return([]time.Time{day.Add(14 * time.Hour), day.Add(15 * time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, textContaining("Standup")).Return("Room 1", nil)
+	ner.On("ExtractLocation", mock.Anything, textContaining("Design review")).Return("Room 2", nil)
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+	events, err := processor.ProcessEmailMulti(context.Background(), emailContent)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "Standup 9:00-9:15 in Room 1", events[0].Subject)
+	assert.Equal(t, day.Add(9*time.Hour), events[0].StartTime)
+	assert.Equal(t, day.Add(9*time.Hour+15*time.Minute), events[0].EndTime)
+	assert.Equal(t, "Room 1", events[0].Location)
+	assert.Equal(t, []string{"alice@example.com"}, events[0].Attendees)
+
+	assert.Equal(t, "Design review 14:00-15:00 in Room 2", events[1].Subject)
+	assert.Equal(t, "Design review 14:00-15:00 in Room 2\nbring bob@example.com", events[1].Description)
+	assert.Equal(t, day.Add(14*time.Hour), events[1].StartTime)
+	assert.Equal(t, day.Add(15*time.Hour), events[1].EndTime)
+	assert.Equal(t, "Room 2", events[1].Location)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, events[1].Attendees)
+
+	for _, event := range events {
+		assert.Equal(t, "organizer@example.com", event.Organizer)
+	}
+}
+
+func TestEmailProcessorImpl_ProcessEmailMulti_Cancelled(t *testing.T) {
+	emailContent := "From: organizer@example.com\r\n" +
+		"Subject: Agenda\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"1. Standup 9:00-9:15\r\n" +
+		"2. Design review 14:00-15:00\r\n" +
+		"3. Retro 16:00-17:00\r\n"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	day := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, textContaining("Standup")).
+		Run(func(mock.Arguments) { cancel() }).
+		Return([]time.Time{day.Add(9 * time.Hour), day.Add(9*time.Hour + 15*time.Minute)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil).Maybe()
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+	events, err := processor.ProcessEmailMulti(ctx, emailContent)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, events)
+	ner.AssertNumberOfCalls(t, "ExtractDateTime", 1)
+}
+
+func TestEmailProcessorImpl_ProcessEmailMulti_SingleMeeting(t *testing.T) {
+	emailContent := "From: organizer@example.com\r\n" +
+		"Subject: Planning\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Planning tomorrow at 2pm in Room 3."
+
+	start := time.Now().Add(24 * time.Hour)
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start, start.Add(time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("Room 3", nil)
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), ner)
+	events, err := processor.ProcessEmailMulti(context.Background(), emailContent)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	single, err := processor.ProcessEmail(context.Background(), emailContent)
+	require.NoError(t, err)
+	assert.Equal(t, single, events[0])
+}
+
+func TestSplitAgendaItems(t *testing.T) {
+	text := "Intro line\n" +
+		"- Standup at 9:00\n" +
+		"  daily sync\n" +
+		"\n" +
+		"trailing note\n" +
+		"* Review at 14:00\n" +
+		"2) Retro at 16:00\n" +
+		"9.30 is not a list item"
+
+	items := splitAgendaItems(text)
+	assert.Equal(t, []agendaItem{
+		{heading: "Standup at 9:00", text: "Standup at 9:00\ndaily sync"},
+		{heading: "Review at 14:00", text: "Review at 14:00"},
+		{heading: "Retro at 16:00", text: "Retro at 16:00\n9.30 is not a list item"},
+	}, items)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+func TestNERService_Ping(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		down        bool
+		expectError bool
+	}{
+		{name: "healthy", status: http.StatusOK},
+		{name: "unhealthy", status: http.StatusServiceUnavailable, expectError: true},
+		{name: "no health endpoint", status: http.StatusNotFound, expectError: true},
+		{name: "unreachable", down: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/health", r.URL.Path)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			if tt.down {
+				server.Close()
+			}
+
+			err := NewNERService(server.URL).Ping(context.Background())
+			if !tt.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, calerrors.IsServiceUnavailable(err), err)
+			assert.True(t, calerrors.ShouldRetry(err))
+		})
+	}
+}
+
+func TestNERService_ExtractEntities_Unavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		down        bool
+		unavailable bool
+	}{
+		{name: "server error", status: http.StatusInternalServerError, unavailable: true},
+		{name: "overloaded", status: http.StatusTooManyRequests, unavailable: true},
+		{name: "unreachable", down: true, unavailable: true},
+		{name: "bad request", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			if tt.down {
+				server.Close()
+			}
+
+			_, err := NewNERService(server.URL).ExtractEntities(context.Background(), "Meeting at 2pm", "en")
+			require.Error(t, err)
+			assert.Equal(t, tt.unavailable, calerrors.IsServiceUnavailable(err), err)
+		})
+	}
+}
+
+func TestNERService_ExtractEntities_CancelledIsNotUnavailable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ner := NewNERService("http://ner.test").(*nerServiceImpl)
+	ner.client.Transport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		cancel()
+		return nil, context.Canceled
+	})
+
+	_, err := ner.ExtractEntities(ctx, "Meeting at 2pm", "en")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, calerrors.IsServiceUnavailable(err))
+}
+
+func TestEmailProcessorImpl_ProcessEmail_NERDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	processor := NewEmailProcessorImpl(new(mockEmailValidator), NewNERService(server.URL))
+	email := "From: sender@example.com\r\n" +
+		"To: recipient@example.com\r\n" +
+		"Subject: Meeting tomorrow\r\n" +
+		"\r\n" +
+		"Let's meet tomorrow at 2pm."
+
+	_, err := processor.ProcessEmail(context.Background(), email)
+	require.Error(t, err)
+	assert.True(t, calerrors.IsServiceUnavailable(err))
+	assert.True(t, calerrors.ShouldRetry(err))
+	assert.Contains(t, err.Error(), "NER service is unavailable")
+	assert.NotContains(t, err.Error(), "failed to extract dates")
+}
@@ -5,10 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	"mail2calendar/internal/domain/calendar/service"
 )
 
 // Entity represents a named entity from NER service
@@ -30,25 +36,195 @@ type nerResponse struct {
 	ProcessingTime float64  `json:"processing_time"`
 }
 
+type nerBatchRequest struct {
+	Texts []nerRequest `json:"texts"`
+}
+
+// nerBatchResponse holds one result per text: either its entities or {"error": "..."}
+type nerBatchResponse struct {
+	Results        []json.RawMessage `json:"results"`
+	ProcessingTime float64           `json:"processing_time"`
+}
+
+// NERBatchError reports which texts of a batch failed. The entities of the other
+// texts are still returned.
+type NERBatchError struct {
+	// Errors maps the index of each failed text to its error
+	Errors map[int]error
+}
+
+func (e *NERBatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("text %d: %v", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("NER batch failed for %d of the texts: %s", len(indexes), strings.Join(msgs, "; "))
+}
+
 // NERService handles communication with the NER microservice
 type NERService interface {
 	ExtractEntities(ctx context.Context, text string, language string) ([]Entity, error)
+	// ExtractEntitiesBatch extracts the entities of several texts in one round-trip.
+	// Results are in the order of texts. When only some texts fail, the others are
+	// returned together with a *NERBatchError.
+	ExtractEntitiesBatch(ctx context.Context, texts []string, language string) ([][]Entity, error)
 	ExtractDateTime(ctx context.Context, text string) ([]time.Time, error)
 	ExtractLocation(ctx context.Context, text string) (string, error)
+	// Ping checks that the NER service is up, for readiness checks and at startup. It
+	// fails with a ServiceUnavailable calendar error when the service cannot be reached
+	// or is unhealthy.
+	Ping(ctx context.Context) error
 }
 
 type nerServiceImpl struct {
-	client  *http.Client
-	baseURL string
-	tzUtil  *TimezoneUtil
+	client       *http.Client
+	baseURL      string
+	tzUtil       *TimezoneUtil
+	orgLocations map[string]string
+	// locationLabels are the upper-cased entity labels read as a location
+	locationLabels map[string]struct{}
+	metrics        *Metrics
+	// detector guesses the language hint of ExtractDateTime and ExtractLocation
+	detector LanguageDetector
+	// language overrides the detected language hint when not empty
+	language string
 }
 
-func NewNERService(baseURL string) NERService {
-	return &nerServiceImpl{
-		client:  &http.Client{Timeout: 10 * time.Second},
-		baseURL: baseURL,
-		tzUtil:  NewTimezoneUtil("Asia/Ho_Chi_Minh"), // Default to Vietnam timezone
+// defaultLocationLabels are the entity labels of places: locations such as a street,
+// geo-political entities such as "Hanoi" and facilities such as "Building 5"
+var defaultLocationLabels = []string{"LOC", "GPE", "FAC"}
+
+// newLocationLabels returns the set of labels, upper-cased
+func newLocationLabels(labels []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		if label = strings.ToUpper(strings.TrimSpace(label)); label != "" {
+			set[label] = struct{}{}
+		}
 	}
+	return set
+}
+
+// defaultNERTimeout bounds a request to the NER service
+const defaultNERTimeout = 10 * time.Second
+
+// nerMaxIdleConnsPerHost keeps enough connections to the NER service open for
+// concurrent extractions; the default transport keeps only two per host
+const nerMaxIdleConnsPerHost = 32
+
+// newNERTransport returns the default transport tuned for a single busy host
+func newNERTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = nerMaxIdleConnsPerHost
+	return transport
+}
+
+// NERServiceOption configures optional behaviour of the NER service
+type NERServiceOption func(*nerServiceImpl)
+
+// WithOrgLocations maps ORG entities to physical locations. When no LOC entity is found,
+// an ORG whose text contains one of the keys (case-insensitive) is used as the location.
+func WithOrgLocations(locations map[string]string) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		for org, location := range locations {
+			if key := normalizeOrg(org); key != "" && location != "" {
+				s.orgLocations[key] = location
+			}
+		}
+	}
+}
+
+// WithNERHTTPClient sends requests to the NER service with client, for instance one
+// with a tuned transport. A nil client keeps the default.
+func WithNERHTTPClient(client *http.Client) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithNERTimeout sets how long a request to the NER service may take, including reading
+// the response. Non-positive values keep the current timeout, 10 seconds by default.
+func WithNERTimeout(timeout time.Duration) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		if timeout > 0 {
+			// Copy so that a client passed to WithNERHTTPClient is not modified
+			client := *s.client
+			client.Timeout = timeout
+			s.client = &client
+		}
+	}
+}
+
+// WithLocationLabels sets the entity labels ExtractLocation reads as a location,
+// compared case-insensitively, in place of LOC, GPE and FAC. Without any non-empty
+// label the default is kept.
+func WithLocationLabels(labels ...string) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		if set := newLocationLabels(labels); len(set) > 0 {
+			s.locationLabels = set
+		}
+	}
+}
+
+// WithNERMetrics records the latency of every request to the NER service into metrics
+func WithNERMetrics(metrics *Metrics) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		s.metrics = metrics
+	}
+}
+
+// WithAbbreviationRegion resolves timezone abbreviations used by several countries, such
+// as IST, to the zone of region (an ISO 3166-1 alpha-2 code) unless the user's timezone
+// is one of them
+func WithAbbreviationRegion(region string) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		WithRegion(region)(s.tzUtil)
+	}
+}
+
+// WithLanguageDetector guesses the language hint sent by ExtractDateTime and
+// ExtractLocation with detector instead of the built-in one. A nil detector keeps the
+// current one.
+func WithLanguageDetector(detector LanguageDetector) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		if detector != nil {
+			s.detector = detector
+		}
+	}
+}
+
+// WithNERLanguage sends language as the language hint of ExtractDateTime and
+// ExtractLocation instead of detecting it, for deployments whose emails share one
+// language. An empty language restores detection.
+func WithNERLanguage(language string) NERServiceOption {
+	return func(s *nerServiceImpl) {
+		s.language = language
+	}
+}
+
+func NewNERService(baseURL string, opts ...NERServiceOption) NERService {
+	s := &nerServiceImpl{
+		client:         &http.Client{Timeout: defaultNERTimeout, Transport: newNERTransport()},
+		baseURL:        baseURL,
+		tzUtil:         NewTimezoneUtil("Asia/Ho_Chi_Minh"), // Default to Vietnam timezone
+		orgLocations:   make(map[string]string),
+		locationLabels: newLocationLabels(defaultLocationLabels),
+		detector:       NewLanguageDetector(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 func (s *nerServiceImpl) ExtractEntities(ctx context.Context, text string, language string) ([]Entity, error) {
@@ -68,14 +244,16 @@ func (s *nerServiceImpl) ExtractEntities(ctx context.Context, text string, langu
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := s.client.Do(req)
+	s.metrics.observeNERRequest("extract", start)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, nerSendError(ctx, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("NER service returned status: %d", resp.StatusCode)
+		return nil, nerStatusError(resp.StatusCode)
 	}
 
 	var result nerResponse
@@ -86,17 +264,161 @@ func (s *nerServiceImpl) ExtractEntities(ctx context.Context, text string, langu
 	return result.Entities, nil
 }
 
+func (s *nerServiceImpl) ExtractEntitiesBatch(ctx context.Context, texts []string, language string) ([][]Entity, error) {
+	if len(texts) == 0 {
+		return [][]Entity{}, nil
+	}
+
+	reqBody := nerBatchRequest{Texts: make([]nerRequest, len(texts))}
+	for i, text := range texts {
+		reqBody.Texts[i] = nerRequest{Text: text, Language: language}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/api/v1/batch-extract", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	s.metrics.observeNERRequest("batch-extract", start)
+	if err != nil {
+		return nil, nerSendError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		// Older NER services have no batch endpoint
+		return s.extractEntitiesSequential(ctx, texts, language)
+	default:
+		return nil, nerStatusError(resp.StatusCode)
+	}
+
+	var result nerBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(result.Results) != len(texts) {
+		return nil, fmt.Errorf("NER service returned %d results for %d texts", len(result.Results), len(texts))
+	}
+
+	entities := make([][]Entity, len(texts))
+	failed := make(map[int]error)
+	for i, raw := range result.Results {
+		// A failed text is reported as an object instead of a list of entities
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '{' {
+			var failure struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(raw, &failure); err != nil {
+				failed[i] = fmt.Errorf("failed to decode result: %v", err)
+			} else {
+				failed[i] = fmt.Errorf("NER service error: %s", failure.Error)
+			}
+			continue
+		}
+		if err := json.Unmarshal(raw, &entities[i]); err != nil {
+			failed[i] = fmt.Errorf("failed to decode result: %v", err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return entities, &NERBatchError{Errors: failed}
+	}
+	return entities, nil
+}
+
+func (s *nerServiceImpl) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nerSendError(ctx, err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nerUnavailable(fmt.Errorf("NER service returned status: %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// nerUnavailable reports that the NER service is down, which is worth retrying later
+func nerUnavailable(err error) error {
+	return calerrors.NewServiceUnavailableError("NER service is unavailable").WithWrappedError(err)
+}
+
+// nerSendError returns the error of a request to the NER service that got no
+// response. The service is unavailable unless the request was cancelled by ctx.
+func nerSendError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return nerUnavailable(fmt.Errorf("failed to send request: %w", err))
+}
+
+// nerStatusError returns the error of an unexpected response status. Server errors
+// mean the service is unavailable, client errors that the request is wrong.
+func nerStatusError(code int) error {
+	err := fmt.Errorf("NER service returned status: %d", code)
+	if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests {
+		return nerUnavailable(err)
+	}
+	return err
+}
+
+// extractEntitiesSequential extracts each text with its own request, with the same
+// partial-failure behaviour as ExtractEntitiesBatch
+func (s *nerServiceImpl) extractEntitiesSequential(ctx context.Context, texts []string, language string) ([][]Entity, error) {
+	entities := make([][]Entity, len(texts))
+	failed := make(map[int]error)
+	for i, text := range texts {
+		// A cancelled request would fail every remaining text the same way
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := s.ExtractEntities(ctx, text, language)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		entities[i] = result
+	}
+
+	if len(failed) > 0 {
+		return entities, &NERBatchError{Errors: failed}
+	}
+	return entities, nil
+}
+
 func (s *nerServiceImpl) ExtractDateTime(ctx context.Context, text string) ([]time.Time, error) {
-	entities, err := s.ExtractEntities(ctx, text, "vi") // Default to Vietnamese
+	entities, err := s.ExtractEntities(ctx, text, s.languageOf(text))
 	if err != nil {
 		return nil, err
 	}
 
+	tzUtil := s.timezoneUtil(ctx)
 	var dates []time.Time
 	var dateEntity, timeEntity *Entity
 
 	// First pass: find DATE and TIME entities
 	for _, entity := range entities {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if strings.EqualFold(entity.Label, "DATE") {
 			dateEntity = &entity
 		} else if strings.EqualFold(entity.Label, "TIME") {
@@ -107,13 +429,13 @@ func (s *nerServiceImpl) ExtractDateTime(ctx context.Context, text string) ([]ti
 	// If we have both date and time, combine them
 	if dateEntity != nil && timeEntity != nil {
 		// Parse date first
-		dateTime, err := parseDateTime(s.tzUtil, dateEntity.Text)
+		dateTime, err := parseDateTime(ctx, tzUtil, dateEntity.Text)
 		if err != nil {
 			return nil, err
 		}
 
 		// Parse time and combine with date
-		timeOnly, err := parseDateTime(s.tzUtil, timeEntity.Text)
+		timeOnly, err := parseDateTime(ctx, tzUtil, timeEntity.Text)
 		if err != nil {
 			return nil, err
 		}
@@ -133,7 +455,10 @@ func (s *nerServiceImpl) ExtractDateTime(ctx context.Context, text string) ([]ti
 		// If we only have one entity, try to parse it
 		for _, entity := range entities {
 			if strings.EqualFold(entity.Label, "TIME") || strings.EqualFold(entity.Label, "DATE") {
-				t, err := parseDateTime(s.tzUtil, entity.Text)
+				t, err := parseDateTime(ctx, tzUtil, entity.Text)
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
 				if err == nil {
 					dates = append(dates, t)
 				}
@@ -149,47 +474,86 @@ func (s *nerServiceImpl) ExtractDateTime(ctx context.Context, text string) ([]ti
 }
 
 func (s *nerServiceImpl) ExtractLocation(ctx context.Context, text string) (string, error) {
-	entities, err := s.ExtractEntities(ctx, text, "vi")
+	entities, err := s.ExtractEntities(ctx, text, s.languageOf(text))
 	if err != nil {
 		return "", err
 	}
 
-	// Look for location entities with highest confidence
+	// Look for the location entity with highest confidence, whatever its label
 	var bestLocation string
 	var bestConfidence float64
 
 	for _, entity := range entities {
-		if entity.Label == "LOC" && entity.Confidence > bestConfidence {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if s.isLocationLabel(entity.Label) && entity.Confidence > bestConfidence {
 			bestLocation = entity.Text
 			bestConfidence = entity.Confidence
 		}
 	}
 
+	// Fall back to a configured office or meeting room for a recognized organization
+	if bestLocation == "" {
+		bestLocation = s.orgLocation(entities)
+	}
+
 	return bestLocation, nil
 }
 
-// parseDateTime attempts to parse date/time text in various formats
-func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
+// languageOf returns the language hint for text: the configured language, else the
+// detected one, else Vietnamese
+func (s *nerServiceImpl) languageOf(text string) string {
+	if s.language != "" {
+		return s.language
+	}
+	if language := s.detector.DetectLanguage(text); language != "" {
+		return language
+	}
+	return defaultNERLanguage
+}
+
+// isLocationLabel reports whether entities labelled label are locations
+func (s *nerServiceImpl) isLocationLabel(label string) bool {
+	_, ok := s.locationLabels[strings.ToUpper(label)]
+	return ok
+}
+
+// timezoneUtil returns the TimezoneUtil for the timezone of the user in ctx, or the
+// service default when the user has none or it is not a valid IANA zone
+func (s *nerServiceImpl) timezoneUtil(ctx context.Context) *TimezoneUtil {
+	if userTz := service.TimezoneFromContext(ctx); userTz != "" {
+		if tzUtil, err := NewUserTimezoneUtil(userTz, WithRegion(s.tzUtil.region)); err == nil {
+			return tzUtil
+		}
+	}
+	return s.tzUtil
+}
+
+// parseDateTime attempts to parse date/time text in various formats. It stops with
+// ctx.Err() once ctx is done.
+func parseDateTime(ctx context.Context, tzUtil *TimezoneUtil, text string) (time.Time, error) {
 	text = strings.TrimSpace(text)
 
 	// Xử lý các từ khóa thời gian tự nhiên
 	switch strings.ToLower(text) {
 	case "tomorrow":
-		now := time.Now()
-		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local), nil
+		now := time.Now().In(tzUtil.localLocation())
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()), nil
 	case "today":
-		now := time.Now()
-		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+		now := time.Now().In(tzUtil.localLocation())
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
 	}
 
-	// Check for timezone abbreviation in the text
+	// Check for timezone abbreviation in the text. Times without one are local to the
+	// user, or to the server when the user has no timezone.
 	var timezoneName string
-	for tzAbbr := range getTimezoneAbbreviations() {
-		if strings.Contains(strings.ToUpper(text), tzAbbr) {
-			timezoneName = tzUtil.GuessTimezone(tzAbbr)
-			text = strings.ReplaceAll(strings.ToUpper(text), tzAbbr, "")
-			text = strings.TrimSpace(text)
-			break
+	loc := tzUtil.localLocation()
+	if tzAbbr := findTimezoneAbbreviation(text); tzAbbr != "" {
+		timezoneName = tzUtil.GuessTimezone(tzAbbr)
+		text = removeWord(text, tzAbbr)
+		if l, err := time.LoadLocation(timezoneName); err == nil {
+			loc = l
 		}
 	}
 
@@ -198,10 +562,10 @@ func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
 	}
 
 	// Handle natural language time format (e.g., "3pm")
-	if strings.HasSuffix(strings.ToLower(text), "pm") || strings.HasSuffix(strings.ToLower(text), "am") {
+	if lower := strings.ToLower(text); strings.HasSuffix(lower, "pm") || strings.HasSuffix(lower, "am") {
 		hour := 0
-		meridiem := strings.ToLower(text[len(text)-2:])
-		hourStr := strings.TrimSuffix(strings.TrimSuffix(text, "pm"), "am")
+		meridiem := lower[len(lower)-2:]
+		hourStr := strings.TrimSpace(lower[:len(lower)-2])
 
 		if h, err := strconv.Atoi(hourStr); err == nil {
 			if meridiem == "pm" && h < 12 {
@@ -211,8 +575,8 @@ func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
 			} else {
 				hour = h
 			}
-			now := time.Now()
-			return time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.Local), nil
+			now := time.Now().In(loc)
+			return time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, loc), nil
 		}
 	}
 
@@ -223,8 +587,8 @@ func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
 			hour, errHour := strconv.Atoi(parts[0])
 			minute, errMin := strconv.Atoi(parts[1])
 			if errHour == nil && errMin == nil {
-				now := time.Now()
-				return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, time.Local), nil
+				now := time.Now().In(loc)
+				return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc), nil
 			}
 		}
 	}
@@ -249,6 +613,9 @@ func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
 
 	// Try each format
 	for _, format := range formats {
+		if err := ctx.Err(); err != nil {
+			return time.Time{}, err
+		}
 		if t, err := tzUtil.ParseTimeInTimezone(text, format, timezoneName); err == nil {
 			// If no year specified, use current year
 			if t.Year() == 0 {
@@ -262,14 +629,36 @@ func parseDateTime(tzUtil *TimezoneUtil, text string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("could not parse datetime: %s", text)
 }
 
+// findTimezoneAbbreviation returns the known timezone abbreviation appearing as a word
+// in text, or "" if there is none
+func findTimezoneAbbreviation(text string) string {
+	abbreviations := getTimezoneAbbreviations()
+	for _, word := range strings.FieldsFunc(strings.ToUpper(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if _, ok := abbreviations[word]; ok {
+			return word
+		}
+	}
+	return ""
+}
+
+// removeWord removes the case-insensitive whole word from text
+func removeWord(text, word string) string {
+	fields := strings.Fields(text)
+	kept := fields[:0]
+	for _, field := range fields {
+		if !strings.EqualFold(strings.Trim(field, "(),"), word) {
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
 func getTimezoneAbbreviations() map[string]struct{} {
-	return map[string]struct{}{
-		"EST": {}, "EDT": {},
-		"CST": {}, "CDT": {},
-		"MST": {}, "MDT": {},
-		"PST": {}, "PDT": {},
-		"GMT": {}, "UTC": {},
-		"ICT": {}, "JST": {},
-		"IST": {}, "AEST": {},
+	abbreviations := make(map[string]struct{}, len(timezoneAbbreviations))
+	for abbr := range timezoneAbbreviations {
+		abbreviations[abbr] = struct{}{}
 	}
+	return abbreviations
 }
@@ -1,14 +1,19 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNERService_ExtractEntities(t *testing.T) {
@@ -251,6 +256,118 @@ func TestNERService_ExtractDateTime(t *testing.T) {
 	}
 }
 
+// roundTripFunc lets a test intercept the requests of an http.Client
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNERService_ExtractDateTime_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entities := make([]Entity, 1000)
+	for i := range entities {
+		entities[i] = Entity{Text: "not a date", Label: "DATE"}
+	}
+	body, err := json.Marshal(nerResponse{Entities: entities})
+	require.NoError(t, err)
+
+	ner := NewNERService("http://ner.test").(*nerServiceImpl)
+	ner.client.Transport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		// The request is cancelled once the entities have arrived
+		cancel()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	dates, err := ner.ExtractDateTime(ctx, "text")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, dates)
+
+	location, err := ner.ExtractLocation(ctx, "text")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, location)
+}
+
+func TestParseDateTime_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := parseDateTime(ctx, NewTimezoneUtil("UTC"), "15/02/2024")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNERService_ExtractEntitiesBatch_SequentialCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	ner := NewNERService("http://ner.test").(*nerServiceImpl)
+	ner.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/api/v1/batch-extract" {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+		}
+		requests++
+		cancel()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"entities": []}`)),
+		}, nil
+	})
+
+	entities, err := ner.ExtractEntitiesBatch(ctx, []string{"one", "two", "three"}, "en")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, entities)
+	assert.Equal(t, 1, requests)
+}
+
+func TestNERService_HTTPClient(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_ = json.NewEncoder(w).Encode(nerResponse{})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Run("client timeout", func(t *testing.T) {
+		ner := NewNERService(server.URL, WithNERHTTPClient(&http.Client{Timeout: time.Millisecond}))
+
+		entities, err := ner.ExtractEntities(context.Background(), "Meeting at 3pm", "en")
+		assert.Nil(t, entities)
+		require.Error(t, err)
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+	})
+
+	t.Run("timeout option", func(t *testing.T) {
+		client := &http.Client{}
+		ner := NewNERService(server.URL, WithNERHTTPClient(client), WithNERTimeout(time.Millisecond))
+
+		_, err := ner.ExtractEntities(context.Background(), "Meeting at 3pm", "en")
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+
+		// The caller's client is left as it was
+		assert.Zero(t, client.Timeout)
+	})
+
+	t.Run("default client", func(t *testing.T) {
+		ner := NewNERService(server.URL).(*nerServiceImpl)
+		assert.Equal(t, defaultNERTimeout, ner.client.Timeout)
+		transport, ok := ner.client.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.Equal(t, nerMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	})
+}
+
 func TestParseDateTime(t *testing.T) {
 	tzUtil := NewTimezoneUtil("Asia/Ho_Chi_Minh")
 	now := time.Now()
@@ -294,7 +411,7 @@ func TestParseDateTime(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parsed, err := parseDateTime(tzUtil, tt.text)
+			parsed, err := parseDateTime(context.Background(), tzUtil, tt.text)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -362,6 +479,29 @@ func TestNERService_ExtractLocation(t *testing.T) {
 			expectedError:    false,
 			expectedLocation: "Starbucks",
 		},
+		{
+			name: "GPE is a location when no LOC exists",
+			text: "Meeting in Hanoi",
+			mockResponse: nerResponse{
+				Entities: []Entity{
+					{Text: "Nguyen", Label: "PERSON", Confidence: 0.99},
+					{Text: "Hanoi", Label: "GPE", Start: 11, End: 16, Confidence: 0.8},
+				},
+			},
+			expectedLocation: "Hanoi",
+		},
+		{
+			name: "highest confidence across location labels",
+			text: "Meeting in Building 5, Hanoi",
+			mockResponse: nerResponse{
+				Entities: []Entity{
+					{Text: "Building 5", Label: "FAC", Confidence: 0.92},
+					{Text: "Hanoi", Label: "GPE", Confidence: 0.85},
+					{Text: "Hoan Kiem", Label: "LOC", Confidence: 0.7},
+				},
+			},
+			expectedLocation: "Building 5",
+		},
 		{
 			name: "no location found",
 			text: "Online meeting",
@@ -407,3 +547,160 @@ func TestNERService_ExtractLocation(t *testing.T) {
 		})
 	}
 }
+
+func TestNERService_ExtractLocation_LocationLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(nerResponse{Entities: []Entity{
+			{Text: "Hanoi", Label: "GPE", Confidence: 0.9},
+			{Text: "Building 5", Label: "fac", Confidence: 0.8},
+		}})
+	}))
+	defer server.Close()
+
+	service := NewNERService(server.URL, WithLocationLabels("LOC", "FAC"))
+	location, err := service.ExtractLocation(context.Background(), "Meeting in Building 5, Hanoi")
+	require.NoError(t, err)
+	assert.Equal(t, "Building 5", location)
+
+	// Without any label the defaults are kept
+	service = NewNERService(server.URL, WithLocationLabels(" "))
+	location, err = service.ExtractLocation(context.Background(), "Meeting in Building 5, Hanoi")
+	require.NoError(t, err)
+	assert.Equal(t, "Hanoi", location)
+}
+
+func TestNERService_ExtractLocation_OrgMapping(t *testing.T) {
+	orgLocations := map[string]string{
+		"Công ty ABC":        "Tầng 5, 123 Nguyễn Huệ, Quận 1",
+		"công ty ABC Hà Nội": "Phòng họp 2, 45 Láng Hạ, Đống Đa",
+		"XYZ Corp":           "Room 301, XYZ Tower",
+	}
+
+	tests := []struct {
+		name             string
+		entities         []Entity
+		expectedLocation string
+	}{
+		{
+			name: "known ORG maps to its office",
+			entities: []Entity{
+				{Text: "văn phòng công ty ABC", Label: "ORG", Confidence: 0.9},
+			},
+			expectedLocation: "Tầng 5, 123 Nguyễn Huệ, Quận 1",
+		},
+		{
+			name: "most specific ORG wins",
+			entities: []Entity{
+				{Text: "Công ty  ABC Hà Nội", Label: "ORG", Confidence: 0.9},
+			},
+			expectedLocation: "Phòng họp 2, 45 Láng Hạ, Đống Đa",
+		},
+		{
+			name: "highest confidence ORG wins",
+			entities: []Entity{
+				{Text: "công ty ABC", Label: "ORG", Confidence: 0.7},
+				{Text: "XYZ Corp", Label: "ORG", Confidence: 0.8},
+			},
+			expectedLocation: "Room 301, XYZ Tower",
+		},
+		{
+			name: "explicit LOC takes precedence",
+			entities: []Entity{
+				{Text: "công ty ABC", Label: "ORG", Confidence: 0.99},
+				{Text: "Starbucks", Label: "LOC", Confidence: 0.6},
+			},
+			expectedLocation: "Starbucks",
+		},
+		{
+			name: "unknown ORG is ignored",
+			entities: []Entity{
+				{Text: "công ty ABCD", Label: "ORG", Confidence: 0.9},
+			},
+			expectedLocation: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewEncoder(w).Encode(nerResponse{Entities: tt.entities}); err != nil {
+					t.Errorf("failed to encode response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			service := NewNERService(server.URL, WithOrgLocations(orgLocations))
+			location, err := service.ExtractLocation(context.Background(), "Họp tại văn phòng")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLocation, location)
+		})
+	}
+}
+
+func TestNERService_ExtractEntitiesBatch(t *testing.T) {
+	texts := []string{"Họp lúc 9h", "tại Hà Nội", "với anh Nam"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/batch-extract", r.URL.Path)
+
+		var req nerBatchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		// Echo each text back as an entity so that the order can be checked
+		results := make([]interface{}, len(req.Texts))
+		for i, text := range req.Texts {
+			assert.Equal(t, "vi", text.Language)
+			if text.Text == "tại Hà Nội" {
+				results[i] = map[string]string{"error": "model crashed"}
+				continue
+			}
+			results[i] = []Entity{{Text: text.Text, Label: "MISC"}}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"results": results}))
+	}))
+	defer server.Close()
+
+	entities, err := NewNERService(server.URL).ExtractEntitiesBatch(context.Background(), texts, "vi")
+
+	var batchErr *NERBatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 1)
+	assert.ErrorContains(t, batchErr.Errors[1], "model crashed")
+
+	require.Len(t, entities, 3)
+	assert.Equal(t, []Entity{{Text: "Họp lúc 9h", Label: "MISC"}}, entities[0])
+	assert.Nil(t, entities[1])
+	assert.Equal(t, []Entity{{Text: "với anh Nam", Label: "MISC"}}, entities[2])
+}
+
+func TestNERService_ExtractEntitiesBatch_SequentialFallback(t *testing.T) {
+	texts := []string{"first", "broken", "third"}
+
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/batch-extract" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var req nerRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		calls = append(calls, req.Text)
+		if req.Text == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(nerResponse{Entities: []Entity{{Text: req.Text}}}))
+	}))
+	defer server.Close()
+
+	entities, err := NewNERService(server.URL).ExtractEntitiesBatch(context.Background(), texts, "en")
+
+	var batchErr *NERBatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Contains(t, batchErr.Errors, 1)
+
+	assert.Equal(t, texts, calls)
+	assert.Equal(t, [][]Entity{{{Text: "first"}}, nil, {{Text: "third"}}}, entities)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/microsoft"
 
 	"mail2calendar/internal/domain/calendar/logger"
 )
@@ -55,6 +56,33 @@ func NewOAuthConfig(l *logger.Logger) (*OAuthConfig, error) {
 		Endpoint: google.Endpoint,
 	}
 
+	return newOAuthConfig(l, config, "oauth_token:"), nil
+}
+
+// NewMicrosoftOAuthConfig creates OAuth configuration for Microsoft Graph. Tokens are
+// kept apart from Google tokens so a user can connect both providers.
+func NewMicrosoftOAuthConfig(l *logger.Logger) (*OAuthConfig, error) {
+	clientID := getEnvOrPanic("MICROSOFT_OAUTH_CLIENT_ID")
+	clientSecret := getEnvOrPanic("MICROSOFT_OAUTH_CLIENT_SECRET")
+	redirectURL := getEnvOrPanic("MICROSOFT_OAUTH_REDIRECT_URL")
+	tenant := getEnvOrDefault("MICROSOFT_OAUTH_TENANT", "common")
+
+	config := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes: []string{
+			"offline_access",
+			"https://graph.microsoft.com/Calendars.ReadWrite",
+		},
+		Endpoint: microsoft.AzureADEndpoint(tenant),
+	}
+
+	return newOAuthConfig(l, config, "outlook_oauth_token:"), nil
+}
+
+// newOAuthConfig wraps config with a Redis token store using the given key prefix
+func newOAuthConfig(l *logger.Logger, config *oauth2.Config, prefix string) *OAuthConfig {
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
 		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
@@ -63,7 +91,7 @@ func NewOAuthConfig(l *logger.Logger) (*OAuthConfig, error) {
 
 	tokenStore := &RedisTokenStore{
 		client: redisClient,
-		prefix: "oauth_token:",
+		prefix: prefix,
 		ttl:    24 * time.Hour,
 	}
 
@@ -73,7 +101,7 @@ func NewOAuthConfig(l *logger.Logger) (*OAuthConfig, error) {
 		logger:     l,
 		maxRetries: 3,
 		retryDelay: 1 * time.Second,
-	}, nil
+	}
 }
 
 // GetToken retrieves token for a user with retry logic
@@ -0,0 +1,60 @@
+package usecase
+
+import "strings"
+
+// orgLocation returns the configured location of the highest-confidence ORG entity
+// that matches the ORG→location table, or "" if none does
+func (s *nerServiceImpl) orgLocation(entities []Entity) string {
+	var bestLocation string
+	var bestConfidence float64
+
+	for _, entity := range entities {
+		if entity.Label != "ORG" || entity.Confidence <= bestConfidence {
+			continue
+		}
+		if location, ok := s.lookupOrg(entity.Text); ok {
+			bestLocation = location
+			bestConfidence = entity.Confidence
+		}
+	}
+
+	return bestLocation
+}
+
+// lookupOrg matches an ORG text against the table. The entity may carry extra words
+// (e.g. "văn phòng công ty ABC" for key "công ty abc"), so the longest key contained
+// in the text wins.
+func (s *nerServiceImpl) lookupOrg(text string) (string, bool) {
+	text = normalizeOrg(text)
+	if text == "" {
+		return "", false
+	}
+	if location, ok := s.orgLocations[text]; ok {
+		return location, true
+	}
+
+	var bestKey string
+	for key := range s.orgLocations {
+		if !containsWords(text, key) {
+			continue
+		}
+		if len(key) > len(bestKey) || (len(key) == len(bestKey) && key < bestKey) {
+			bestKey = key
+		}
+	}
+	if bestKey == "" {
+		return "", false
+	}
+	return s.orgLocations[bestKey], true
+}
+
+// normalizeOrg lowercases an organization name and collapses its whitespace
+func normalizeOrg(org string) string {
+	return strings.ToLower(strings.Join(strings.Fields(org), " "))
+}
+
+// containsWords reports whether phrase appears in text as whole words, so "abc"
+// does not match "công ty abcd"
+func containsWords(text, phrase string) bool {
+	return strings.Contains(" "+text+" ", " "+phrase+" ")
+}
@@ -0,0 +1,731 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+const (
+	// graphBaseURL is the Microsoft Graph v1.0 endpoint
+	graphBaseURL = "https://graph.microsoft.com/v1.0"
+	// graphDateTimeLayout is the format of dateTime in Graph dateTimeTimeZone values
+	graphDateTimeLayout = "2006-01-02T15:04:05.9999999"
+	// graphMetadataExtension is the open extension holding event metadata
+	graphMetadataExtension = "io.mail2calendar.metadata"
+)
+
+// graphWeekdays maps Graph day names to time.Weekday
+var graphWeekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// rruleGraphWeekdays maps RRULE BYDAY values to Graph day names
+var rruleGraphWeekdays = map[Weekday]string{
+	Sunday:    "sunday",
+	Monday:    "monday",
+	Tuesday:   "tuesday",
+	Wednesday: "wednesday",
+	Thursday:  "thursday",
+	Friday:    "friday",
+	Saturday:  "saturday",
+}
+
+// Microsoft Graph resources used by the Outlook calendar
+
+type graphDateTimeZone struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type graphEmailAddress struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+}
+
+type graphAttendee struct {
+	EmailAddress graphEmailAddress    `json:"emailAddress"`
+	Type         string               `json:"type,omitempty"`
+	Status       *graphResponseStatus `json:"status,omitempty"`
+}
+
+type graphResponseStatus struct {
+	Response string `json:"response"`
+}
+
+// graphResponses maps Graph attendee responses to ResponseStatus; "none" and
+// "notResponded" mean the attendee has not answered
+var graphResponses = map[string]ResponseStatus{
+	"organizer":           ResponseAccepted,
+	"accepted":            ResponseAccepted,
+	"tentativelyAccepted": ResponseTentative,
+	"declined":            ResponseDeclined,
+}
+
+type graphRecipient struct {
+	EmailAddress graphEmailAddress `json:"emailAddress"`
+}
+
+type graphLocation struct {
+	DisplayName string `json:"displayName"`
+}
+
+type graphRecurrencePattern struct {
+	Type       string   `json:"type"`
+	Interval   int      `json:"interval"`
+	DaysOfWeek []string `json:"daysOfWeek,omitempty"`
+	DayOfMonth int      `json:"dayOfMonth,omitempty"`
+	Month      int      `json:"month,omitempty"`
+}
+
+type graphRecurrenceRange struct {
+	Type                string `json:"type"`
+	StartDate           string `json:"startDate"`
+	NumberOfOccurrences int    `json:"numberOfOccurrences,omitempty"`
+}
+
+type graphRecurrence struct {
+	Pattern graphRecurrencePattern `json:"pattern"`
+	Range   graphRecurrenceRange   `json:"range"`
+}
+
+type graphExtension struct {
+	ODataType     string            `json:"@odata.type,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	ExtensionName string            `json:"extensionName"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+type graphEvent struct {
+	ID              string             `json:"id,omitempty"`
+	Subject         string             `json:"subject"`
+	Start           *graphDateTimeZone `json:"start"`
+	End             *graphDateTimeZone `json:"end"`
+	Location        *graphLocation     `json:"location,omitempty"`
+	Body            *graphItemBody     `json:"body,omitempty"`
+	Organizer       *graphRecipient    `json:"organizer,omitempty"`
+	Attendees       []graphAttendee    `json:"attendees"`
+	IsAllDay        bool               `json:"isAllDay"`
+	Recurrence      *graphRecurrence   `json:"recurrence,omitempty"`
+	SeriesMasterID  string             `json:"seriesMasterId,omitempty"`
+	CreatedDateTime string             `json:"createdDateTime,omitempty"`
+	Extensions      []graphExtension   `json:"extensions,omitempty"`
+}
+
+type graphItemBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type graphEventList struct {
+	Value    []graphEvent `json:"value"`
+	NextLink string       `json:"@odata.nextLink"`
+}
+
+type graphScheduleRequest struct {
+	Schedules                []string          `json:"schedules"`
+	StartTime                graphDateTimeZone `json:"startTime"`
+	EndTime                  graphDateTimeZone `json:"endTime"`
+	AvailabilityViewInterval int               `json:"availabilityViewInterval"`
+}
+
+type graphWorkingHours struct {
+	DaysOfWeek []string `json:"daysOfWeek"`
+	StartTime  string   `json:"startTime"`
+	EndTime    string   `json:"endTime"`
+	TimeZone   struct {
+		Name string `json:"name"`
+	} `json:"timeZone"`
+}
+
+type graphScheduleResponse struct {
+	Value []struct {
+		ScheduleID   string             `json:"scheduleId"`
+		WorkingHours *graphWorkingHours `json:"workingHours"`
+		Error        *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"value"`
+}
+
+// graphError is the error body returned by Microsoft Graph
+type graphError struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *graphError) Error() string {
+	return fmt.Sprintf("graph API error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+type outlookCalendarServiceImpl struct {
+	oauthConfig *OAuthConfig
+	tracer      trace.Tracer
+	userID      string
+	baseURL     string
+}
+
+// NewOutlookCalendarService creates a CalendarService backed by the user's Microsoft 365
+// calendar through Microsoft Graph. oauth should come from NewMicrosoftOAuthConfig.
+func NewOutlookCalendarService(oauth *OAuthConfig, tracer trace.Tracer, userID string) CalendarService {
+	return &outlookCalendarServiceImpl{
+		oauthConfig: oauth,
+		tracer:      tracer,
+		userID:      userID,
+		baseURL:     graphBaseURL,
+	}
+}
+
+func (o *outlookCalendarServiceImpl) GetEvents(ctx context.Context, timeRange TimeRange, attendees []string) ([]*CalendarEvent, error) {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.GetEvents")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("start_time", timeRange.StartTime.Format(time.RFC3339)),
+		attribute.String("end_time", timeRange.EndTime.Format(time.RFC3339)),
+		attribute.Int("attendees_count", len(attendees)),
+	)
+
+	// calendarView expands recurring series into occurrences, like SingleEvents for Google
+	var result []*CalendarEvent
+	next := o.calendarViewURL(timeRange, 0)
+	for next != "" {
+		events, nextLink, err := o.listPage(ctx, next)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		result = append(result, events...)
+		next = nextLink
+	}
+
+	return result, nil
+}
+
+func (o *outlookCalendarServiceImpl) GetEventsPage(ctx context.Context, timeRange TimeRange, pageSize int, pageToken string) ([]*CalendarEvent, string, error) {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.GetEventsPage")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("start_time", timeRange.StartTime.Format(time.RFC3339)),
+		attribute.String("end_time", timeRange.EndTime.Format(time.RFC3339)),
+		attribute.Int("page_size", pageSize),
+	)
+
+	// The page token is the nextLink Graph returned. It is a URL fetched with the
+	// user's token, so only links to the calendar view are followed.
+	endpoint := o.calendarViewURL(timeRange, pageSize)
+	if pageToken != "" {
+		if !strings.HasPrefix(pageToken, o.baseURL+"/me/calendarView?") {
+			return nil, "", ErrInvalidPageToken
+		}
+		endpoint = pageToken
+	}
+
+	events, nextLink, err := o.listPage(ctx, endpoint)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+	return events, nextLink, nil
+}
+
+func (o *outlookCalendarServiceImpl) GetEvent(ctx context.Context, eventID string) (*CalendarEvent, error) {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.GetEvent")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	query := url.Values{}
+	query.Set("$expand", fmt.Sprintf("extensions($filter=id eq '%s')", graphMetadataExtension))
+
+	var event graphEvent
+	err := o.do(ctx, http.MethodGet, o.baseURL+"/me/events/"+url.PathEscape(eventID)+"?"+query.Encode(), nil, &event)
+	if err != nil {
+		span.RecordError(err)
+		var graphErr *graphError
+		if errors.As(err, &graphErr) && graphErr.StatusCode == http.StatusNotFound {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	return fromGraphEvent(&event)
+}
+
+// calendarViewURL returns the calendar view request for timeRange, ordered by start
+// time. A positive pageSize limits the number of events per page.
+func (o *outlookCalendarServiceImpl) calendarViewURL(timeRange TimeRange, pageSize int) string {
+	query := url.Values{}
+	query.Set("startDateTime", timeRange.StartTime.UTC().Format(time.RFC3339))
+	query.Set("endDateTime", timeRange.EndTime.UTC().Format(time.RFC3339))
+	query.Set("$orderby", "start/dateTime")
+	query.Set("$expand", fmt.Sprintf("extensions($filter=id eq '%s')", graphMetadataExtension))
+	if pageSize > 0 {
+		query.Set("$top", strconv.Itoa(pageSize))
+	}
+	return o.baseURL + "/me/calendarView?" + query.Encode()
+}
+
+// listPage fetches one page of a calendar view and returns its events and nextLink
+func (o *outlookCalendarServiceImpl) listPage(ctx context.Context, endpoint string) ([]*CalendarEvent, string, error) {
+	var page graphEventList
+	if err := o.do(ctx, http.MethodGet, endpoint, nil, &page); err != nil {
+		return nil, "", fmt.Errorf("failed to list events: %w", err)
+	}
+
+	events := make([]*CalendarEvent, 0, len(page.Value))
+	for i := range page.Value {
+		event, err := fromGraphEvent(&page.Value[i])
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, event)
+	}
+	return events, page.NextLink, nil
+}
+
+func (o *outlookCalendarServiceImpl) CreateEvent(ctx context.Context, event *CalendarEvent) error {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.CreateEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", event.ID),
+		attribute.String("summary", event.Title),
+	)
+
+	body, err := toGraphEvent(event)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// Store source email headers and ingestion channel in an open extension
+	if metadata := eventMetadata(event.Headers, event.Source); metadata != nil {
+		body.Extensions = []graphExtension{{
+			ODataType:     "microsoft.graph.openTypeExtension",
+			ExtensionName: graphMetadataExtension,
+			Metadata:      metadata,
+		}}
+	}
+
+	var created graphEvent
+	if err := o.do(ctx, http.MethodPost, o.baseURL+"/me/events", body, &created); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+	event.ID = created.ID
+
+	return nil
+}
+
+func (o *outlookCalendarServiceImpl) CreateEvents(ctx context.Context, events []*CalendarEvent) []error {
+	return createConcurrently(ctx, len(events), func(ctx context.Context, i int) error {
+		return o.CreateEvent(ctx, events[i])
+	})
+}
+
+func (o *outlookCalendarServiceImpl) UpdateEvent(ctx context.Context, event *CalendarEvent) error {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.UpdateEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", event.ID),
+		attribute.String("summary", event.Title),
+	)
+
+	body, err := toGraphEvent(event)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// PATCH leaves the metadata extension written at creation untouched
+	if err := o.do(ctx, http.MethodPatch, o.baseURL+"/me/events/"+url.PathEscape(event.ID), body, nil); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update event: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outlookCalendarServiceImpl) DeleteEvent(ctx context.Context, eventID string) error {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.DeleteEvent")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if err := o.do(ctx, http.MethodDelete, o.baseURL+"/me/events/"+url.PathEscape(eventID), nil, nil); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+
+	return nil
+}
+
+// graphResponseActions are the Graph event actions answering an invitation
+var graphResponseActions = map[EventResponse]string{
+	EventAccepted:  "accept",
+	EventDeclined:  "decline",
+	EventTentative: "tentativelyAccept",
+}
+
+func (o *outlookCalendarServiceImpl) RespondToEvent(ctx context.Context, eventID string, response EventResponse) error {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.RespondToEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", eventID),
+		attribute.String("response", string(response)),
+	)
+
+	action, ok := graphResponseActions[response]
+	if !ok {
+		return fmt.Errorf("unsupported response %q", response)
+	}
+
+	body := map[string]bool{"sendResponse": true}
+	if err := o.do(ctx, http.MethodPost, o.baseURL+"/me/events/"+url.PathEscape(eventID)+"/"+action, body, nil); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to respond to event: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outlookCalendarServiceImpl) GetWorkingHours(ctx context.Context, timeRange TimeRange, attendees []string) (map[string]*WorkingHours, error) {
+	ctx, span := o.tracer.Start(ctx, "OutlookCalendar.GetWorkingHours")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("attendees_count", len(attendees)))
+
+	start, end, err := workingHoursRange(timeRange)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	req := graphScheduleRequest{
+		Schedules:                attendees,
+		StartTime:                graphDateTime(start),
+		EndTime:                  graphDateTime(end),
+		AvailabilityViewInterval: availabilityViewInterval(timeRange.Duration),
+	}
+
+	var resp graphScheduleResponse
+	if err := o.do(ctx, http.MethodPost, o.baseURL+"/me/calendar/getSchedule", req, &resp); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	// Schedules that failed or have no working hours are left out, like Google free/busy misses
+	result := make(map[string]*WorkingHours)
+	for _, schedule := range resp.Value {
+		if schedule.Error != nil || schedule.WorkingHours == nil {
+			continue
+		}
+		result[schedule.ScheduleID] = fromGraphWorkingHours(schedule.WorkingHours)
+	}
+
+	return result, nil
+}
+
+// Helper functions
+
+// do sends a Graph request with the user's token. in is encoded as JSON when set and
+// the response is decoded into out when set.
+func (o *outlookCalendarServiceImpl) do(ctx context.Context, method, endpoint string, in, out interface{}) error {
+	client, err := o.oauthConfig.GetClient(ctx, o.userID)
+	if err != nil {
+		return fmt.Errorf("failed to get graph client: %v", err)
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Ask Graph to return all times in UTC
+	req.Header.Set("Prefer", `outlook.timezone="UTC"`)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp struct {
+			Error graphError `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		errResp.Error.StatusCode = resp.StatusCode
+		return &errResp.Error
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// toGraphEvent converts a domain event to a Graph event
+func toGraphEvent(event *CalendarEvent) (*graphEvent, error) {
+	start, end := event.StartTime.UTC(), event.EndTime.UTC()
+	if event.IsAllDay {
+		// All-day events must start and end at midnight
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+	}
+
+	startTime, endTime := graphDateTime(start), graphDateTime(end)
+	gEvent := &graphEvent{
+		Subject:   event.Title,
+		Start:     &startTime,
+		End:       &endTime,
+		Location:  &graphLocation{DisplayName: event.Location},
+		Attendees: make([]graphAttendee, 0, len(event.Attendees)),
+		IsAllDay:  event.IsAllDay,
+	}
+	if event.Description != "" {
+		gEvent.Body = &graphItemBody{ContentType: "text", Content: event.Description}
+	}
+
+	// The organizer is the mailbox owner in Graph and is not invited as an attendee
+	for _, email := range event.Attendees {
+		if strings.EqualFold(email, event.Organizer) {
+			continue
+		}
+		gEvent.Attendees = append(gEvent.Attendees, graphAttendee{
+			EmailAddress: graphEmailAddress{Address: email},
+			Type:         "required",
+		})
+	}
+
+	if event.IsRecurring && event.RecurrenceRule != "" {
+		recurrence, err := toGraphRecurrence(event.RecurrenceRule, start)
+		if err != nil {
+			return nil, err
+		}
+		gEvent.Recurrence = recurrence
+	}
+
+	return gEvent, nil
+}
+
+// fromGraphEvent converts a Graph event to a domain event
+func fromGraphEvent(event *graphEvent) (*CalendarEvent, error) {
+	start, err := parseGraphDateTime(event.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of event %s: %v", event.ID, err)
+	}
+	end, err := parseGraphDateTime(event.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of event %s: %v", event.ID, err)
+	}
+
+	attendees := make([]string, 0, len(event.Attendees))
+	statuses := make([]Attendee, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		attendees = append(attendees, attendee.EmailAddress.Address)
+
+		status := ResponseNeedsAction
+		if attendee.Status != nil {
+			if response, ok := graphResponses[attendee.Status.Response]; ok {
+				status = response
+			}
+		}
+		statuses = append(statuses, Attendee{Email: attendee.EmailAddress.Address, ResponseStatus: status})
+	}
+
+	var location string
+	if event.Location != nil {
+		location = event.Location.DisplayName
+	}
+
+	var organizer string
+	if event.Organizer != nil {
+		organizer = event.Organizer.EmailAddress.Address
+	}
+
+	created, _ := time.Parse(time.RFC3339, event.CreatedDateTime)
+
+	var metadata map[string]string
+	for _, extension := range event.Extensions {
+		if extension.ExtensionName == graphMetadataExtension || strings.HasSuffix(extension.ID, graphMetadataExtension) {
+			metadata = extension.Metadata
+			break
+		}
+	}
+
+	return &CalendarEvent{
+		ID:               event.ID,
+		Title:            event.Subject,
+		StartTime:        start,
+		EndTime:          end,
+		Location:         location,
+		Organizer:        organizer,
+		Attendees:        attendees,
+		AttendeeStatuses: statuses,
+		IsAllDay:         event.IsAllDay,
+		// calendarView expands series into occurrences, which only point back to their master
+		SeriesID: event.SeriesMasterID,
+		Created:  created,
+		Headers:  headersFromMetadata(metadata),
+		Source:   service.EventSource(metadata[eventSourceMetadataKey]),
+	}, nil
+}
+
+// toGraphRecurrence converts an RRULE into a Graph recurrence starting at start
+func toGraphRecurrence(ruleStr string, start time.Time) (*graphRecurrence, error) {
+	rule, err := ParseRecurrenceRule(ruleStr)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := graphRecurrencePattern{Interval: rule.Interval}
+	switch rule.Frequency {
+	case FreqDaily:
+		pattern.Type = "daily"
+	case FreqWeekly:
+		pattern.Type = "weekly"
+		for _, day := range rule.ByDay {
+			name, ok := rruleGraphWeekdays[day]
+			if !ok {
+				return nil, fmt.Errorf("unsupported BYDAY value for Outlook: %s", day)
+			}
+			pattern.DaysOfWeek = append(pattern.DaysOfWeek, name)
+		}
+		if len(pattern.DaysOfWeek) == 0 {
+			pattern.DaysOfWeek = []string{strings.ToLower(start.Weekday().String())}
+		}
+	case FreqMonthly:
+		pattern.Type = "absoluteMonthly"
+		pattern.DayOfMonth = start.Day()
+		if len(rule.ByMonthDay) > 0 {
+			pattern.DayOfMonth = rule.ByMonthDay[0]
+		}
+	case FreqYearly:
+		pattern.Type = "absoluteYearly"
+		pattern.DayOfMonth = start.Day()
+		pattern.Month = int(start.Month())
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency for Outlook: %s", rule.Frequency)
+	}
+
+	recurrenceRange := graphRecurrenceRange{
+		Type:      "noEnd",
+		StartDate: start.Format("2006-01-02"),
+	}
+	if rule.Count != nil {
+		recurrenceRange.Type = "numbered"
+		recurrenceRange.NumberOfOccurrences = *rule.Count
+	}
+
+	return &graphRecurrence{Pattern: pattern, Range: recurrenceRange}, nil
+}
+
+// fromGraphWorkingHours converts Graph working hours to the domain model
+func fromGraphWorkingHours(hours *graphWorkingHours) *WorkingHours {
+	loc, err := time.LoadLocation(hours.TimeZone.Name)
+	if err != nil {
+		// Graph often reports Windows zone names, which time cannot load
+		loc = time.UTC
+	}
+
+	startTime, _ := time.Parse("15:04:05.9999999", hours.StartTime)
+	endTime, _ := time.Parse("15:04:05.9999999", hours.EndTime)
+
+	schedules := make([]WeeklySchedule, 0, len(hours.DaysOfWeek))
+	for _, day := range hours.DaysOfWeek {
+		weekday, ok := graphWeekdays[strings.ToLower(day)]
+		if !ok {
+			continue
+		}
+		schedules = append(schedules, WeeklySchedule{
+			DayOfWeek: weekday,
+			StartTime: time.Date(0, 0, 0, startTime.Hour(), startTime.Minute(), 0, 0, loc),
+			EndTime:   time.Date(0, 0, 0, endTime.Hour(), endTime.Minute(), 0, 0, loc),
+		})
+	}
+
+	return &WorkingHours{
+		TimeZone: hours.TimeZone.Name,
+		Schedule: schedules,
+	}
+}
+
+// availabilityViewInterval returns the getSchedule slot length in minutes for the
+// range's Duration, clamped to the 5 to 1440 minutes Graph accepts. Zero keeps one hour.
+func availabilityViewInterval(d time.Duration) int {
+	minutes := int(d / time.Minute)
+	switch {
+	case d <= 0:
+		return 60
+	case minutes < 5:
+		return 5
+	case minutes > 1440:
+		return 1440
+	default:
+		return minutes
+	}
+}
+
+// graphDateTime formats t as a UTC Graph dateTimeTimeZone
+func graphDateTime(t time.Time) graphDateTimeZone {
+	return graphDateTimeZone{
+		DateTime: t.UTC().Format(graphDateTimeLayout),
+		TimeZone: "UTC",
+	}
+}
+
+// parseGraphDateTime parses a Graph dateTimeTimeZone, falling back to UTC for zones
+// that cannot be loaded
+func parseGraphDateTime(value *graphDateTimeZone) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, fmt.Errorf("missing date time")
+	}
+
+	loc, err := time.LoadLocation(value.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return time.ParseInLocation(graphDateTimeLayout, value.DateTime, loc)
+}
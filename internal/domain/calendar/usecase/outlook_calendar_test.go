@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/oauth2"
+
+	"mail2calendar/internal/domain/calendar/logger"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// graphTransport serves Graph requests from an in-process handler
+type graphTransport struct {
+	handler http.HandlerFunc
+}
+
+func (t *graphTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler(rec, req)
+	return rec.Result(), nil
+}
+
+// newOutlookTestService returns an Outlook calendar whose Graph requests go to handler
+func newOutlookTestService(handler http.HandlerFunc) (CalendarService, context.Context) {
+	l, _ := logger.New(nil)
+	store := new(mockTokenStore)
+	store.On("GetToken", mock.Anything, "user-1").Return(&oauth2.Token{
+		AccessToken: "graph-token",
+		Expiry:      time.Now().Add(time.Hour),
+	}, nil)
+
+	oauth := &OAuthConfig{config: &oauth2.Config{}, tokenStore: store, logger: l}
+	svc := NewOutlookCalendarService(oauth, noop.NewTracerProvider().Tracer("test"), "user-1")
+
+	// The OAuth client uses the context client as its base transport
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: &graphTransport{handler: handler},
+	})
+	return svc, ctx
+}
+
+func TestOutlookCalendarService_CreateEvent(t *testing.T) {
+	var body map[string]interface{}
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1.0/me/events", r.URL.Path)
+		assert.Equal(t, "Bearer graph-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"AAMk-1"}`))
+	})
+
+	start := time.Date(2025, 3, 5, 16, 0, 0, 0, time.FixedZone("ICT", 7*60*60))
+	err := svc.CreateEvent(ctx, &CalendarEvent{
+		Title:          "Weekly sync",
+		StartTime:      start,
+		EndTime:        start.Add(30 * time.Minute),
+		Location:       "Room 2",
+		Attendees:      []string{"alice@example.com"},
+		IsRecurring:    true,
+		RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+		Headers:        map[string]string{"Message-ID": "<abc@example.com>"},
+		Source:         service.SourceQueue,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Weekly sync", body["subject"])
+	assert.Equal(t, map[string]interface{}{"dateTime": "2025-03-05T09:00:00", "timeZone": "UTC"}, body["start"])
+	assert.Equal(t, map[string]interface{}{"dateTime": "2025-03-05T09:30:00", "timeZone": "UTC"}, body["end"])
+	assert.Equal(t, map[string]interface{}{"displayName": "Room 2"}, body["location"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"emailAddress": map[string]interface{}{"address": "alice@example.com"},
+		"type":         "required",
+	}}, body["attendees"])
+	assert.Equal(t, map[string]interface{}{
+		"pattern": map[string]interface{}{"type": "weekly", "interval": float64(1), "daysOfWeek": []interface{}{"monday", "wednesday"}},
+		"range":   map[string]interface{}{"type": "numbered", "startDate": "2025-03-05", "numberOfOccurrences": float64(4)},
+	}, body["recurrence"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"@odata.type":   "microsoft.graph.openTypeExtension",
+		"extensionName": graphMetadataExtension,
+		"metadata": map[string]interface{}{
+			"email_header.Message-ID": "<abc@example.com>",
+			eventSourceMetadataKey:    string(service.SourceQueue),
+		},
+	}}, body["extensions"])
+}
+
+func TestOutlookCalendarService_GetEvents(t *testing.T) {
+	pages := map[string]string{
+		"": `{
+			"value": [{
+				"id": "AAMk-1",
+				"subject": "Standup",
+				"start": {"dateTime": "2025-03-05T09:00:00.0000000", "timeZone": "UTC"},
+				"end": {"dateTime": "2025-03-05T09:15:00.0000000", "timeZone": "UTC"},
+				"location": {"displayName": "Teams"},
+				"attendees": [
+					{"emailAddress": {"address": "bob@example.com", "name": "Bob"}, "type": "required", "status": {"response": "tentativelyAccepted"}},
+					{"emailAddress": {"address": "carol@example.com"}, "type": "optional", "status": {"response": "notResponded"}}
+				],
+				"isAllDay": false,
+				"seriesMasterId": "AAMk-series",
+				"createdDateTime": "2025-03-01T08:00:00Z",
+				"extensions": [{
+					"id": "Microsoft.OutlookServices.OpenTypeExtension.io.mail2calendar.metadata",
+					"extensionName": "io.mail2calendar.metadata",
+					"metadata": {"email_header.Subject": "Standup", "source": "queue"}
+				}]
+			}],
+			"@odata.nextLink": "https://graph.microsoft.com/v1.0/me/calendarView?page=2"
+		}`,
+		"2": `{
+			"value": [{
+				"id": "AAMk-2",
+				"subject": "Holiday",
+				"start": {"dateTime": "2025-03-06T00:00:00.0000000", "timeZone": "UTC"},
+				"end": {"dateTime": "2025-03-07T00:00:00.0000000", "timeZone": "UTC"},
+				"isAllDay": true
+			}]
+		}`,
+	}
+
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1.0/me/calendarView", r.URL.Path)
+		assert.Equal(t, `outlook.timezone="UTC"`, r.Header.Get("Prefer"))
+		if r.URL.Query().Get("page") == "" {
+			assert.Equal(t, "2025-03-05T00:00:00Z", r.URL.Query().Get("startDateTime"))
+			assert.Equal(t, "2025-03-08T00:00:00Z", r.URL.Query().Get("endDateTime"))
+		}
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("page")]))
+	})
+
+	start := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	events, err := svc.GetEvents(ctx, TimeRange{StartTime: start, EndTime: start.AddDate(0, 0, 3)}, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, &CalendarEvent{
+		ID:        "AAMk-1",
+		Title:     "Standup",
+		StartTime: time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 3, 5, 9, 15, 0, 0, time.UTC),
+		Location:  "Teams",
+		Attendees: []string{"bob@example.com", "carol@example.com"},
+		AttendeeStatuses: []Attendee{
+			{Email: "bob@example.com", ResponseStatus: ResponseTentative},
+			{Email: "carol@example.com", ResponseStatus: ResponseNeedsAction},
+		},
+		SeriesID: "AAMk-series",
+		Created:  time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		Headers:  map[string]string{"Subject": "Standup"},
+		Source:   service.SourceQueue,
+	}, events[0])
+
+	assert.Equal(t, "AAMk-2", events[1].ID)
+	assert.True(t, events[1].IsAllDay)
+	assert.False(t, events[1].IsRecurring)
+	assert.Empty(t, events[1].Attendees)
+	assert.Empty(t, events[1].Source)
+}
+
+func TestOutlookCalendarService_GetEventsPage(t *testing.T) {
+	var tops []string
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		tops = append(tops, r.URL.Query().Get("$top"))
+		if r.URL.Query().Get("$skip") == "" {
+			_, _ = w.Write([]byte(`{
+				"value": [{"id": "AAMk-1", "start": {"dateTime": "2025-03-05T09:00:00.0000000"}, "end": {"dateTime": "2025-03-05T09:15:00.0000000"}}],
+				"@odata.nextLink": "https://graph.microsoft.com/v1.0/me/calendarView?$top=1&$skip=1"
+			}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"value": [{"id": "AAMk-2", "start": {"dateTime": "2025-03-06T09:00:00.0000000"}, "end": {"dateTime": "2025-03-06T09:15:00.0000000"}}]}`))
+	})
+
+	start := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	timeRange := TimeRange{StartTime: start, EndTime: start.AddDate(0, 0, 3)}
+
+	// The page token is Graph's nextLink, followed as is
+	events, next, err := svc.GetEventsPage(ctx, timeRange, 1, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "https://graph.microsoft.com/v1.0/me/calendarView?$top=1&$skip=1", next)
+
+	events, next, err = svc.GetEventsPage(ctx, timeRange, 1, next)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "AAMk-2", events[0].ID)
+	assert.Empty(t, next)
+	assert.Equal(t, []string{"1", "1"}, tops)
+
+	// Tokens pointing anywhere else are never requested with the user's credentials
+	_, _, err = svc.GetEventsPage(ctx, timeRange, 1, "https://attacker.example.com/me/calendarView?$top=1")
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+	assert.Len(t, tops, 2)
+}
+
+func TestOutlookCalendarService_RespondToEvent(t *testing.T) {
+	var body map[string]interface{}
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1.0/me/events/AAMk-1/tentativelyAccept", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	require.NoError(t, svc.RespondToEvent(ctx, "AAMk-1", EventTentative))
+	assert.Equal(t, map[string]interface{}{"sendResponse": true}, body)
+}
+
+func TestOutlookCalendarService_GraphError(t *testing.T) {
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"ErrorItemNotFound","message":"The specified object was not found in the store."}}`))
+	})
+
+	err := svc.DeleteEvent(ctx, "missing")
+
+	var graphErr *graphError
+	require.ErrorAs(t, err, &graphErr)
+	assert.Equal(t, http.StatusNotFound, graphErr.StatusCode)
+	assert.Equal(t, "ErrorItemNotFound", graphErr.Code)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// defaultConfirmationTTL is how long a pending event waits for confirmation
+const defaultConfirmationTTL = 24 * time.Hour
+
+// ErrPendingEventNotFound is returned when a confirmation token is unknown or expired
+var ErrPendingEventNotFound = errors.New("pending event not found or expired")
+
+// PendingEvent is an event waiting for the user to confirm it before it is created
+type PendingEvent struct {
+	Event     *calendarPb.Event
+	UserID    string
+	Source    service.EventSource
+	ExpiresAt time.Time
+}
+
+// PendingEventStore keeps pending events until they are confirmed or expire
+type PendingEventStore interface {
+	// Save stores a pending event under the given confirmation token
+	Save(ctx context.Context, token string, pending *PendingEvent) error
+	// Take removes and returns the pending event for a token. It returns
+	// ErrPendingEventNotFound if the token is unknown or expired at now.
+	Take(ctx context.Context, token string, now time.Time) (*PendingEvent, error)
+	// DeleteExpired removes pending events that expired before now
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// memoryPendingEventStore is an in-memory PendingEventStore
+type memoryPendingEventStore struct {
+	mu      sync.Mutex
+	pending map[string]*PendingEvent
+}
+
+// NewMemoryPendingEventStore creates an in-memory PendingEventStore
+func NewMemoryPendingEventStore() PendingEventStore {
+	return &memoryPendingEventStore{
+		pending: make(map[string]*PendingEvent),
+	}
+}
+
+func (s *memoryPendingEventStore) Save(_ context.Context, token string, pending *PendingEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[token] = pending
+	return nil
+}
+
+func (s *memoryPendingEventStore) Take(_ context.Context, token string, now time.Time) (*PendingEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.pending[token]
+	if !ok {
+		return nil, ErrPendingEventNotFound
+	}
+	delete(s.pending, token)
+
+	if !now.Before(pending.ExpiresAt) {
+		return nil, ErrPendingEventNotFound
+	}
+	return pending, nil
+}
+
+func (s *memoryPendingEventStore) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for token, pending := range s.pending {
+		if !now.Before(pending.ExpiresAt) {
+			delete(s.pending, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// newConfirmationToken returns a random URL-safe confirmation token
+func newConfirmationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+func newConfirmationTestUseCase(store PendingEventStore, ttl time.Duration, now *time.Time) *calendarUseCase {
+	u := NewCalendarUseCase(nil, nil, WithEventConfirmation(store, ttl)).(*calendarUseCase)
+	u.now = func() time.Time { return *now }
+	return u
+}
+
+func pendingTestEvent(now time.Time) *calendarPb.Event {
+	return &calendarPb.Event{
+		Title:     "Planning",
+		StartTime: now.Add(time.Hour).Unix(),
+		EndTime:   now.Add(2 * time.Hour).Unix(),
+	}
+}
+
+func TestCalendarUseCase_ConfirmEvent(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	u := newConfirmationTestUseCase(NewMemoryPendingEventStore(), time.Hour, &now)
+
+	ctx := service.WithEventSource(context.Background(), service.SourceWebhook)
+	token, expiresAt, err := u.RequestEventConfirmation(ctx, pendingTestEvent(now), "user-1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	// Confirmation may come from a different channel; the original source is kept
+	now = now.Add(30 * time.Minute)
+	event, err := u.ConfirmEvent(service.WithEventSource(context.Background(), service.SourceAPI), token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.Id)
+	assert.Equal(t, "confirmed", event.Status)
+	assert.Equal(t, "Planning", event.Title)
+	assert.Equal(t, string(service.SourceWebhook), event.Metadata[eventSourceMetadataKey])
+
+	// Tokens are single use
+	_, err = u.ConfirmEvent(context.Background(), token)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCalendarUseCase_ConfirmEvent_Expired(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryPendingEventStore()
+	u := newConfirmationTestUseCase(store, time.Hour, &now)
+
+	expired, _, err := u.RequestEventConfirmation(context.Background(), pendingTestEvent(now), "user-1")
+	require.NoError(t, err)
+	stale, _, err := u.RequestEventConfirmation(context.Background(), pendingTestEvent(now), "user-1")
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+
+	_, err = u.ConfirmEvent(context.Background(), expired)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// A new request purges the remaining unconfirmed events that expired
+	_, _, err = u.RequestEventConfirmation(context.Background(), pendingTestEvent(now), "user-1")
+	require.NoError(t, err)
+	_, err = store.Take(context.Background(), stale, time.Time{})
+	assert.ErrorIs(t, err, ErrPendingEventNotFound)
+}
+
+func TestCalendarUseCase_RequestEventConfirmation_Invalid(t *testing.T) {
+	now := time.Now()
+	u := newConfirmationTestUseCase(nil, 0, &now)
+
+	_, _, err := u.RequestEventConfirmation(context.Background(), &calendarPb.Event{}, "user-1")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = u.ConfirmEvent(context.Background(), "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.Equal(t, defaultConfirmationTTL, u.confirmationTTL)
+}
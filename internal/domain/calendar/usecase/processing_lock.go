@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// defaultProcessingLockTTL bounds how long a worker holds the lock of an email. It
+// outlasts processing, NER and calendar requests included, and frees the email if
+// the worker dies without releasing it.
+const defaultProcessingLockTTL = 5 * time.Minute
+
+// processingLockRedisKeyPrefix namespaces the locks of queued emails in Redis
+const processingLockRedisKeyPrefix = "queue_lock:"
+
+// processingLockPollInterval is how often a worker waiting for a lock retries it
+const processingLockPollInterval = 100 * time.Millisecond
+
+// releaseLockScript deletes the lock only while it is still held with the token of
+// the caller, so that a lock which expired and was taken by another worker is kept
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// processingLock is a Redis lock letting only one worker at a time process an email
+type processingLock struct {
+	client       *redis.Client
+	ttl          time.Duration
+	pollInterval time.Duration
+}
+
+// WithProcessingLock makes consumers sharing client process a given email one at a
+// time. A worker receiving an email another worker is processing, such as a
+// redelivery, waits for it to finish first, so that the event it created is seen by
+// deduplication. Emails are identified as by WithDeliveryDedup. The lock is held for
+// at most ttl, 5 minutes when ttl is not positive. If Redis cannot be reached the
+// email is processed without the lock.
+func WithProcessingLock(client *redis.Client, ttl time.Duration) MessageQueueOption {
+	return func(s *messagingService) {
+		if ttl <= 0 {
+			ttl = defaultProcessingLockTTL
+		}
+		s.lock = &processingLock{client: client, ttl: ttl, pollInterval: processingLockPollInterval}
+	}
+}
+
+// acquire takes the lock of key, waiting while another worker holds it, and returns
+// the function releasing it. It fails with ctx.Err() if ctx is done first.
+func (l *processingLock) acquire(ctx context.Context, key string) (release func(context.Context) error, err error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		acquired, err := l.client.SetNX(ctx, key, token, l.ttl).Result()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if acquired {
+			return func(ctx context.Context) error {
+				return releaseLockScript.Run(ctx, l.client, []string{key}, token).Err()
+			}, nil
+		}
+
+		timer := time.NewTimer(l.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newLockToken returns a random value identifying the holder of a lock
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// lockEmail waits until no other worker processes the email of msg and returns the
+// function to call once it is processed. Without a lock, or if Redis cannot be
+// reached, it returns at once. It only fails when ctx is done while waiting.
+func (s *messagingService) lockEmail(ctx context.Context, msg EmailMessage) (unlock func(), err error) {
+	if s.lock == nil {
+		return func() {}, nil
+	}
+
+	release, err := s.lock.acquire(ctx, processingLockRedisKeyPrefix+emailKey(msg))
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		s.logger.WithError(err).Warn("Failed to lock queued email")
+		return func() {}, nil
+	}
+
+	return func() {
+		// Release even when processing was cancelled, otherwise the email stays
+		// locked until the lock expires
+		if err := release(context.WithoutCancel(ctx)); err != nil {
+			s.logger.WithError(err).Warn("Failed to unlock queued email")
+		}
+	}, nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestProcessingLock_Contention(t *testing.T) {
+	client, _, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	lock := &processingLock{client: client, ttl: time.Minute, pollInterval: time.Millisecond}
+
+	var holders, maxHolders, acquired int32
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := lock.acquire(context.Background(), "queue_lock:email-1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			atomic.AddInt32(&acquired, 1)
+
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				max := atomic.LoadInt32(&maxHolders)
+				if n <= max || atomic.CompareAndSwapInt32(&maxHolders, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+
+			assert.NoError(t, release(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), acquired, "both workers get the lock in turn")
+	assert.Equal(t, int32(1), maxHolders, "the lock is never held twice at once")
+}
+
+func TestProcessingLock_ReleaseKeepsLockOfOtherHolder(t *testing.T) {
+	client, mr, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	lock := &processingLock{client: client, ttl: time.Minute, pollInterval: time.Millisecond}
+	ctx := context.Background()
+
+	releaseFirst, err := lock.acquire(ctx, "queue_lock:email-1")
+	require.NoError(t, err)
+
+	// The first holder outlives its lock and another worker takes it over
+	mr.FastForward(time.Minute)
+	releaseSecond, err := lock.acquire(ctx, "queue_lock:email-1")
+	require.NoError(t, err)
+
+	require.NoError(t, releaseFirst(ctx))
+	assert.True(t, mr.Exists("queue_lock:email-1"), "a stale holder doesn't release the new lock")
+
+	require.NoError(t, releaseSecond(ctx))
+	assert.False(t, mr.Exists("queue_lock:email-1"))
+}
+
+func TestProcessingLock_WaitCancelled(t *testing.T) {
+	client, _, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	lock := &processingLock{client: client, ttl: time.Minute, pollInterval: time.Millisecond}
+
+	_, err := lock.acquire(context.Background(), "queue_lock:email-1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = lock.acquire(ctx, "queue_lock:email-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// newLockTestService creates a messaging service locking emails in client and
+// deduplicating them too when dedup is set
+func newLockTestService(calendar *mockDomainCalendarService, channel *fakeQueueChannel, client *redis.Client, dedup bool) *messagingService {
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	WithProcessingLock(client, time.Minute)(s)
+	s.lock.pollInterval = time.Millisecond
+	if dedup {
+		WithDeliveryDedup(client, time.Hour)(s)
+	}
+	return s
+}
+
+func TestMessagingService_handleDelivery_ProcessingLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		dedup     bool
+		processed int
+	}{
+		{name: "redelivery waits for the first worker", processed: 2},
+		{name: "redelivery is deduplicated once the first worker is done", dedup: true, processed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, cleanup := setupTestRedis(t)
+			defer cleanup()
+
+			var processing, overlaps int32
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+				Run(func(mock.Arguments) {
+					if atomic.AddInt32(&processing, 1) > 1 {
+						atomic.AddInt32(&overlaps, 1)
+					}
+					time.Sleep(20 * time.Millisecond)
+					atomic.AddInt32(&processing, -1)
+				}).
+				Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil)
+
+			channel := &fakeQueueChannel{}
+			s := newLockTestService(calendar, channel, client, tt.dedup)
+
+			// Two workers receive deliveries of the same email at the same time
+			var wg sync.WaitGroup
+			for tag := uint64(1); tag <= 2; tag++ {
+				wg.Add(1)
+				go func(tag uint64) {
+					defer wg.Done()
+					deliver(t, s, channel, tag, EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+				}(tag)
+			}
+			wg.Wait()
+
+			assert.Zero(t, overlaps, "the email is processed by one worker at a time")
+			calendar.AssertNumberOfCalls(t, "ProcessEmailToCalendar", tt.processed)
+			assert.ElementsMatch(t, []string{"ack 1", "ack 2"}, channel.recorded())
+		})
+	}
+}
+
+func TestMessagingService_handleDelivery_ProcessingLockCancelled(t *testing.T) {
+	client, _, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	calendar := new(mockDomainCalendarService)
+	channel := &fakeQueueChannel{}
+	s := newLockTestService(calendar, channel, client, false)
+
+	// Another worker holds the email until after the consumer stops
+	_, err := s.lock.acquire(context.Background(), processingLockRedisKeyPrefix+emailKey(EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	body, err := json.Marshal(EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+	require.NoError(t, err)
+	s.handleDelivery(ctx, amqp.Delivery{Acknowledger: channel, DeliveryTag: 1, Body: body})
+
+	calendar.AssertNotCalled(t, "ProcessEmailToCalendar", mock.Anything, mock.Anything)
+	assert.Equal(t, []string{"nack 1"}, channel.recorded())
+}
+
+func TestMessagingService_handleDelivery_ProcessingLockRedisDown(t *testing.T) {
+	client, mr, cleanup := setupTestRedis(t)
+	defer cleanup()
+	mr.Close()
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil)
+
+	channel := &fakeQueueChannel{}
+	s := newLockTestService(calendar, channel, client, false)
+
+	deliver(t, s, channel, 1, EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+
+	calendar.AssertNumberOfCalls(t, "ProcessEmailToCalendar", 1)
+	assert.Equal(t, []string{"ack 1"}, channel.recorded())
+}
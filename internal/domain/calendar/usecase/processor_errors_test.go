@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+)
+
+func TestEmailProcessorImpl_ProcessEmail_TypedErrors(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	dates := []time.Time{startTime, startTime.Add(time.Hour)}
+	noSubject := "From: organizer@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Meeting tomorrow at 2pm."
+
+	tests := []struct {
+		name      string
+		email     string
+		dates     []time.Time
+		nerErr    error
+		opts      []EmailProcessorOption
+		checker   func(error) bool
+		retryable bool
+	}{
+		{
+			name:    "empty email is a parse error",
+			email:   "",
+			checker: calerrors.IsParseError,
+		},
+		{
+			name:    "email without dates is a parse error",
+			email:   headerTestEmail,
+			dates:   []time.Time{},
+			opts:    []EmailProcessorOption{WithMissingDatesPolicy(MissingDatesSkip)},
+			checker: calerrors.IsParseError,
+		},
+		{
+			name:    "event without subject is a validation error",
+			email:   noSubject,
+			dates:   dates,
+			checker: calerrors.IsValidationError,
+		},
+		{
+			name:      "NER outage is retryable",
+			email:     headerTestEmail,
+			nerErr:    fmt.Errorf("connection refused"),
+			checker:   calerrors.IsServiceUnavailable,
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ner := new(mockNERService)
+			ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return(tt.dates, tt.nerErr).Maybe()
+			ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil).Maybe()
+
+			processor := NewEmailProcessorImpl(new(mockEmailValidator), ner, tt.opts...)
+
+			_, err := processor.ProcessEmail(context.Background(), tt.email)
+			assert.True(t, tt.checker(err), "unexpected error type: %v", err)
+			assert.Equal(t, tt.retryable, calerrors.ShouldRetry(err))
+			assert.Equal(t, tt.retryable, isRetryable(err))
+
+			_, err = processor.ProcessEmailMulti(context.Background(), tt.email)
+			assert.True(t, tt.checker(err), "unexpected error type: %v", err)
+		})
+	}
+}
+
+func TestEmailProcessorImpl_ValidateEmail_InvalidEmail(t *testing.T) {
+	validator := new(mockEmailValidator)
+	validator.On("ValidateDKIM", mock.Anything).Return(nil)
+	validator.On("ValidateSPF", mock.Anything).Return(fmt.Errorf("SPF error"))
+
+	processor := NewEmailProcessorImpl(validator, new(mockNERService))
+
+	err := processor.ValidateEmail(context.Background(), "email")
+	assert.True(t, calerrors.IsInvalidEmail(err))
+	assert.False(t, isRetryable(err))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(fmt.Errorf("calendar API timeout")))
+	assert.True(t, isRetryable(fmt.Errorf("create event: %w", calerrors.NewServiceUnavailableError("down"))))
+	assert.False(t, isRetryable(fmt.Errorf("create event: %w", calerrors.NewParseError("bad"))))
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// defaultDeliveryDedupTTL is how long a handled queued email is remembered
+const defaultDeliveryDedupTTL = 24 * time.Hour
+
+// deliveryDedupRedisKeyPrefix namespaces the keys of handled queued emails in Redis
+const deliveryDedupRedisKeyPrefix = "queue_dedup:"
+
+// WithDeliveryDedup makes the consumer skip emails it has already handled, such as
+// redeliveries after a lost ack. Emails are identified by their Message-ID, or by a
+// hash of their content when they have none, and remembered in client for ttl. A
+// non-positive ttl remembers them for 24 hours. Emails whose processing fails are
+// forgotten so that their retries are processed.
+func WithDeliveryDedup(client *redis.Client, ttl time.Duration) MessageQueueOption {
+	return func(s *messagingService) {
+		if ttl <= 0 {
+			ttl = defaultDeliveryDedupTTL
+		}
+		s.dedup = client
+		s.dedupTTL = ttl
+	}
+}
+
+// deliveryDedupKey is the Redis key of the email of msg, scoped by its user
+func deliveryDedupKey(msg EmailMessage) string {
+	return deliveryDedupRedisKeyPrefix + emailKey(msg)
+}
+
+// emailKey identifies the email of msg for its user: by its Message-ID, or by a hash
+// of its content when it has none
+func emailKey(msg EmailMessage) string {
+	key := messageIDIdempotencyKey(msg.EmailContent)
+	if key == "" {
+		sum := sha256.Sum256([]byte(msg.EmailContent))
+		key = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if msg.UserID != "" {
+		key = msg.UserID + ":" + key
+	}
+	return key
+}
+
+// claimDelivery marks the email of msg as handled and reports whether it was not
+// already. It returns the key to release if processing fails, "" when there is
+// nothing to release. If Redis cannot be reached the email is processed.
+func (s *messagingService) claimDelivery(ctx context.Context, msg EmailMessage) (key string, claimed bool) {
+	if s.dedup == nil {
+		return "", true
+	}
+
+	key = deliveryDedupKey(msg)
+	claimed, err := s.dedup.SetNX(ctx, key, time.Now().Unix(), s.dedupTTL).Result()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to check queued email for duplicates")
+		return "", true
+	}
+	if !claimed {
+		return "", false
+	}
+	return key, true
+}
+
+// releaseDelivery forgets a claimed email so that it can be processed again
+func (s *messagingService) releaseDelivery(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.dedup.Del(ctx, key).Err(); err != nil {
+		s.logger.WithError(err).Warn("Failed to release queued email")
+	}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+// newDedupTestService creates a messaging service deduplicating deliveries in client
+func newDedupTestService(calendar *mockDomainCalendarService, channel *fakeQueueChannel, client *redis.Client) *messagingService {
+	s := &messagingService{
+		channel:  channel,
+		config:   QueueConfig{EmailQueueName: "emails", DeadLetterQueue: "emails.dlq", MaxRetries: 3},
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	WithDeliveryDedup(client, time.Hour)(s)
+	return s
+}
+
+// deliver hands msg to s as the delivery with tag
+func deliver(t *testing.T, s *messagingService, channel *fakeQueueChannel, tag uint64, msg EmailMessage) {
+	t.Helper()
+
+	body, err := json.Marshal(msg)
+	require.NoError(t, err)
+	s.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: channel, DeliveryTag: tag, Body: body})
+}
+
+func TestMessagingService_handleDelivery_Dedup(t *testing.T) {
+	const noMessageID = "From: sender@example.com\r\n\r\nLet's meet tomorrow at 2pm."
+
+	tests := []struct {
+		name      string
+		first     EmailMessage
+		second    EmailMessage
+		processed int
+	}{
+		{
+			name:      "redelivered email is skipped",
+			first:     EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"},
+			second:    EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"},
+			processed: 1,
+		},
+		{
+			name:      "email without Message-ID is deduplicated by content",
+			first:     EmailMessage{EmailContent: noMessageID, UserID: "user-1"},
+			second:    EmailMessage{EmailContent: noMessageID, UserID: "user-1"},
+			processed: 1,
+		},
+		{
+			name:      "different emails without Message-ID are processed",
+			first:     EmailMessage{EmailContent: noMessageID, UserID: "user-1"},
+			second:    EmailMessage{EmailContent: noMessageID + " Room 1", UserID: "user-1"},
+			processed: 2,
+		},
+		{
+			name:      "same email of different users is processed",
+			first:     EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"},
+			second:    EmailMessage{EmailContent: headerTestEmail, UserID: "user-2"},
+			processed: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, cleanup := setupTestRedis(t)
+			defer cleanup()
+
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", mock.Anything, mock.Anything).
+				Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil)
+
+			channel := &fakeQueueChannel{}
+			s := newDedupTestService(calendar, channel, client)
+
+			deliver(t, s, channel, 1, tt.first)
+			deliver(t, s, channel, 2, tt.second)
+
+			calendar.AssertNumberOfCalls(t, "ProcessEmailToCalendar", tt.processed)
+			assert.Equal(t, []string{"ack 1", "ack 2"}, channel.recorded())
+			assert.Empty(t, channel.published["emails.dlq"])
+		})
+	}
+}
+
+func TestMessagingService_handleDelivery_DedupFailedEmail(t *testing.T) {
+	client, _, cleanup := setupTestRedis(t)
+	defer cleanup()
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+		Return(nil, calerrors.NewServiceUnavailableError("NER service down")).Once()
+	calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil).Once()
+
+	channel := &fakeQueueChannel{}
+	s := newDedupTestService(calendar, channel, client)
+
+	// The failed email is queued again and its retry is processed
+	deliver(t, s, channel, 1, EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+	require.Len(t, channel.published["emails.retry"], 1)
+	deliver(t, s, channel, 2, EmailMessage{EmailContent: headerTestEmail, UserID: "user-1", RetryCount: 1})
+
+	calendar.AssertExpectations(t)
+	assert.Equal(t, []string{"ack 1", "ack 2"}, channel.recorded())
+}
+
+func TestMessagingService_handleDelivery_DedupRedisDown(t *testing.T) {
+	client, mr, cleanup := setupTestRedis(t)
+	defer cleanup()
+	mr.Close()
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, headerTestEmail).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "event-1"}, nil)
+
+	channel := &fakeQueueChannel{}
+	s := newDedupTestService(calendar, channel, client)
+
+	deliver(t, s, channel, 1, EmailMessage{EmailContent: headerTestEmail, UserID: "user-1"})
+
+	calendar.AssertNumberOfCalls(t, "ProcessEmailToCalendar", 1)
+	assert.Equal(t, []string{"ack 1"}, channel.recorded())
+}
@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/sirupsen/logrus"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// rawEmailObjectPrefix is the folder of the raw emails in the MinIO bucket
+const rawEmailObjectPrefix = "raw-emails/"
+
+// ErrEmailNotArchived is returned when reprocessing an email that was never stored
+var ErrEmailNotArchived = errors.New("email is not archived")
+
+// ArchivedEmail is the raw MIME of an email ingested for a user
+type ArchivedEmail struct {
+	UserID  string
+	Content string
+}
+
+// EmailArchive keeps the raw MIME of ingested emails so that they can be processed
+// again once extraction improves. Emails are identified by their Message-ID, with or
+// without angle brackets.
+type EmailArchive interface {
+	// Save stores the email of userID, replacing a previous copy of the same email
+	Save(ctx context.Context, messageID, userID, content string) error
+	// Load returns every stored copy of the email, one per user that received it, or
+	// ErrEmailNotArchived when there is none
+	Load(ctx context.Context, messageID string) ([]ArchivedEmail, error)
+}
+
+// normalizeMessageID strips the spaces and angle brackets around a Message-ID
+func normalizeMessageID(messageID string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(messageID), "<"), ">")
+}
+
+// emailMessageID returns the normalized Message-ID of emailContent, "" if it has none
+func emailMessageID(emailContent string) string {
+	msg, err := mail.ReadMessage(strings.NewReader(emailContent))
+	if err != nil {
+		return ""
+	}
+	return normalizeMessageID(msg.Header.Get("Message-ID"))
+}
+
+// memoryEmailArchive is an in-memory EmailArchive
+type memoryEmailArchive struct {
+	mu sync.Mutex
+	// emails maps a normalized Message-ID to the content stored for each user
+	emails map[string]map[string]string
+}
+
+// NewMemoryEmailArchive creates an in-memory EmailArchive
+func NewMemoryEmailArchive() EmailArchive {
+	return &memoryEmailArchive{emails: make(map[string]map[string]string)}
+}
+
+func (a *memoryEmailArchive) Save(_ context.Context, messageID, userID, content string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	messageID = normalizeMessageID(messageID)
+	if a.emails[messageID] == nil {
+		a.emails[messageID] = make(map[string]string)
+	}
+	a.emails[messageID][userID] = content
+	return nil
+}
+
+func (a *memoryEmailArchive) Load(_ context.Context, messageID string) ([]ArchivedEmail, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	copies := a.emails[normalizeMessageID(messageID)]
+	if len(copies) == 0 {
+		return nil, ErrEmailNotArchived
+	}
+	emails := make([]ArchivedEmail, 0, len(copies))
+	for userID, content := range copies {
+		emails = append(emails, ArchivedEmail{UserID: userID, Content: content})
+	}
+	sort.Slice(emails, func(i, j int) bool { return emails[i].UserID < emails[j].UserID })
+	return emails, nil
+}
+
+// minioEmailArchive stores raw emails as objects of a MinIO bucket
+type minioEmailArchive struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewMinIOEmailArchive creates an EmailArchive storing raw emails in bucket, as
+// raw-emails/<sha256 of the Message-ID>/<user ID>.eml
+func NewMinIOEmailArchive(client *minio.Client, bucket string) EmailArchive {
+	return &minioEmailArchive{client: client, bucket: bucket}
+}
+
+// rawEmailFolder is the folder holding the copies of the email with messageID. The
+// Message-ID is hashed as it may contain characters not allowed in object names.
+func rawEmailFolder(messageID string) string {
+	sum := sha256.Sum256([]byte(normalizeMessageID(messageID)))
+	return rawEmailObjectPrefix + hex.EncodeToString(sum[:]) + "/"
+}
+
+func (a *minioEmailArchive) Save(ctx context.Context, messageID, userID, content string) error {
+	name := rawEmailFolder(messageID) + url.PathEscape(userID) + ".eml"
+	_, err := a.client.PutObject(ctx, a.bucket, name, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		ContentType: "message/rfc822",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store raw email: %w", err)
+	}
+	return nil
+}
+
+func (a *minioEmailArchive) Load(ctx context.Context, messageID string) ([]ArchivedEmail, error) {
+	var emails []ArchivedEmail
+	for object := range a.client.ListObjects(ctx, a.bucket, minio.ListObjectsOptions{Prefix: rawEmailFolder(messageID)}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list raw emails: %w", object.Err)
+		}
+
+		userID, err := url.PathUnescape(strings.TrimSuffix(path.Base(object.Key), ".eml"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid raw email name %s: %w", object.Key, err)
+		}
+		content, err := a.get(ctx, object.Key)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, ArchivedEmail{UserID: userID, Content: content})
+	}
+
+	if len(emails) == 0 {
+		return nil, ErrEmailNotArchived
+	}
+	return emails, nil
+}
+
+// get reads the object name
+func (a *minioEmailArchive) get(ctx context.Context, name string) (string, error) {
+	obj, err := a.client.GetObject(ctx, a.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get raw email: %w", err)
+	}
+	defer obj.Close()
+
+	content, err := io.ReadAll(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to read raw email: %w", err)
+	}
+	return string(content), nil
+}
+
+// archivingCalendarService stores the raw emails a service.CalendarService processes
+type archivingCalendarService struct {
+	service.CalendarService
+	archive EmailArchive
+	logger  *logrus.Logger
+}
+
+// NewArchivingCalendarService wraps svc so that every email it processes is stored in
+// archive first, for EmailReprocessor. Only emails with a Message-ID, processed for the
+// user set with service.WithUserID, are stored. Failing to store an email is logged
+// and does not fail its processing.
+func NewArchivingCalendarService(svc service.CalendarService, archive EmailArchive) service.CalendarService {
+	return &archivingCalendarService{
+		CalendarService: svc,
+		archive:         archive,
+		logger:          logrus.New(),
+	}
+}
+
+func (s *archivingCalendarService) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*calendarPb.CreateEventResponseV2, error) {
+	// A reprocessed email is already stored
+	if service.EventSourceFromContext(ctx) != service.SourceReprocess {
+		if err := s.save(ctx, emailContent); err != nil {
+			s.logger.WithError(err).Warn("Failed to store raw email")
+		}
+	}
+	return s.CalendarService.ProcessEmailToCalendar(ctx, emailContent)
+}
+
+// save stores emailContent for the user in ctx
+func (s *archivingCalendarService) save(ctx context.Context, emailContent string) error {
+	userID, ok := service.UserIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	messageID := emailMessageID(emailContent)
+	if messageID == "" {
+		return nil
+	}
+	return s.archive.Save(ctx, messageID, userID, emailContent)
+}
+
+// ReprocessResult is the outcome of processing again the copy of an email of a user
+type ReprocessResult struct {
+	UserID string
+	// EventID is the event created or updated, "" if Err is set
+	EventID string
+	Err     error
+}
+
+// UserCalendarFunc returns the calendar of the user with userID
+type UserCalendarFunc func(userID string) CalendarService
+
+// EmailReprocessor processes stored raw emails again, e.g. after extraction improved
+type EmailReprocessor struct {
+	archive   EmailArchive
+	calendar  service.CalendarService
+	records   EventRepository
+	processor EmailProcessor
+	calendars UserCalendarFunc
+	now       func() time.Time
+}
+
+// NewEmailReprocessor creates an EmailReprocessor reading emails from archive. Events
+// recorded in records for an email are updated on the calendar of their user, with
+// the event processor extracts again. Emails without a recorded event are processed
+// with calendar, as when they were received.
+func NewEmailReprocessor(archive EmailArchive, calendar service.CalendarService, records EventRepository, processor EmailProcessor, calendars UserCalendarFunc) *EmailReprocessor {
+	return &EmailReprocessor{
+		archive:   archive,
+		calendar:  calendar,
+		records:   records,
+		processor: processor,
+		calendars: calendars,
+		now:       time.Now,
+	}
+}
+
+// Reprocess processes every stored copy of the email with messageID again, for the
+// user it was received for. The event recorded for the email is updated in place, so
+// a changed title or time doesn't create a second event. A copy without a recorded
+// event bypasses the Message-ID idempotency that skips emails already processed. It
+// fails with ErrEmailNotArchived when the email was not stored; the failure of a
+// single copy is reported in its result.
+func (r *EmailReprocessor) Reprocess(ctx context.Context, messageID string) ([]ReprocessResult, error) {
+	messageID = normalizeMessageID(messageID)
+	if messageID == "" {
+		return nil, ErrEmailNotArchived
+	}
+
+	emails, err := r.archive.Load(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "reprocess:" + messageID + ":" + strconv.FormatInt(r.now().UnixNano(), 10)
+	results := make([]ReprocessResult, 0, len(emails))
+	for _, email := range emails {
+		result := ReprocessResult{UserID: email.UserID}
+		result.EventID, result.Err = r.reprocess(ctx, email, key)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// reprocess processes email again for its user and returns the ID of its event
+func (r *EmailReprocessor) reprocess(ctx context.Context, email ArchivedEmail, key string) (string, error) {
+	ctx = service.WithUserID(ctx, email.UserID)
+	ctx = service.WithEventSource(ctx, service.SourceReprocess)
+
+	extracted, err := r.processor.ProcessEmail(ctx, email.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract event: %w", err)
+	}
+
+	record, err := r.records.FindByMessageID(ctx, email.UserID, extracted.Metadata.MessageID)
+	if errors.Is(err, ErrEventRecordNotFound) {
+		// No event was created the first time, e.g. because extraction failed
+		resp, err := r.calendar.ProcessEmailToCalendar(service.WithIdempotencyKey(ctx, key), email.Content)
+		if err != nil {
+			return "", err
+		}
+		return resp.EventID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	calendar := r.calendars(email.UserID)
+	event, err := calendar.GetEvent(ctx, record.GoogleEventID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get event %s: %w", record.GoogleEventID, err)
+	}
+
+	// Attendees stay as invited, so a reprocess never invites or uninvites anyone
+	event.Title = extracted.Subject
+	event.Description = extracted.Description
+	event.StartTime = extracted.StartTime
+	event.EndTime = extracted.EndTime
+	event.IsAllDay = extracted.IsAllDay
+	if extracted.TimeZone != "" {
+		event.TimeZone = extracted.TimeZone
+	}
+	if extracted.Location != "" {
+		event.Location = extracted.Location
+	}
+	if err := calendar.UpdateEvent(ctx, event); err != nil {
+		return "", fmt.Errorf("failed to update event %s: %w", event.ID, err)
+	}
+	return event.ID, nil
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+// countingCalendar processes every email into a new event
+type countingCalendar struct {
+	service.CalendarService
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (c *countingCalendar) ProcessEmailToCalendar(ctx context.Context, emailContent string) (*calendarPb.CreateEventResponseV2, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &calendarPb.CreateEventResponseV2{EventID: fmt.Sprintf("evt-%d", c.calls)}, nil
+}
+
+// memoryEventRepository keeps event records in memory
+type memoryEventRepository struct {
+	mu      sync.Mutex
+	records []*EventRecord
+}
+
+func (r *memoryEventRepository) Create(_ context.Context, record *EventRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.ID = uint64(len(r.records) + 1)
+	record.CreatedAt = time.Now()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *memoryEventRepository) ListByUser(_ context.Context, userID string) ([]*EventRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]*EventRecord, 0)
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].UserID == userID {
+			records = append(records, r.records[i])
+		}
+	}
+	return records, nil
+}
+
+func (r *memoryEventRepository) FindByMessageID(ctx context.Context, userID, messageID string) (*EventRecord, error) {
+	records, _ := r.ListByUser(ctx, userID)
+	for _, record := range records {
+		if record.MessageID == messageID {
+			return record, nil
+		}
+	}
+	return nil, ErrEventRecordNotFound
+}
+
+const archivedTestEmail = "From: alice@example.com\r\n" +
+	"Message-ID: <kickoff-1@example.com>\r\n" +
+	"Subject: project kickoff\r\n" +
+	"\r\n" +
+	"Kickoff tomorrow at 10:00."
+
+// newReprocessTestProcessor returns an EmailProcessor finding an hour-long event
+// starting at start in every email
+func newReprocessTestProcessor(start time.Time) EmailProcessor {
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return([]time.Time{start, start.Add(time.Hour)}, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil)
+
+	validator := new(mockEmailValidator)
+	validator.On("ValidateDKIM", mock.Anything).Return(nil)
+	return NewEmailProcessorImpl(validator, ner)
+}
+
+func TestEmailReprocessor_UpdatesRecordedEvent(t *testing.T) {
+	// The event created when the email arrived, with the title and time the old
+	// extraction found
+	var updates []map[string]interface{}
+	google, googleCtx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/calendar/v3/calendars/primary/events/google-1", r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id":"google-1","etag":"\"1\"","summary":"kickoff","location":"Room 1",
+				"start":{"dateTime":"2025-03-06T09:00:00Z"},"end":{"dateTime":"2025-03-06T10:00:00Z"},
+				"attendees":[{"email":"bob@example.com","responseStatus":"accepted"}]}`))
+		case http.MethodPut:
+			assert.Equal(t, `"1"`, r.Header.Get("If-Match"))
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			updates = append(updates, body)
+			_, _ = w.Write([]byte(`{"id":"google-1"}`))
+		default:
+			t.Errorf("unexpected %s request", r.Method)
+		}
+	})
+	calendars := func(userID string) CalendarService {
+		assert.Equal(t, "42", userID)
+		return NewCalendarService(google)
+	}
+
+	ingested := new(mockDomainCalendarService)
+	ingested.On("ProcessEmailToCalendar", mock.Anything, archivedTestEmail).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "google-1"}, nil).Once()
+	oldStart := parseTime("2025-03-06T09:00:00Z")
+	ingested.On("GetEvent", mock.Anything, &calendarPb.GetEventRequestV2{EventID: "google-1"}).
+		Return(&calendarPb.GetEventResponseV2{Event: &calendarPb.Event{
+			Id:        "google-1",
+			Title:     "kickoff",
+			Location:  "Room 1",
+			StartTime: oldStart.Unix(),
+			EndTime:   oldStart.Add(time.Hour).Unix(),
+		}}, nil).Once()
+
+	archive := NewMemoryEmailArchive()
+	records := &memoryEventRepository{}
+	svc := NewArchivingCalendarService(NewAuditedCalendarService(ingested, records), archive)
+
+	_, err := svc.ProcessEmailToCalendar(service.WithUserID(context.Background(), "42"), archivedTestEmail)
+	require.NoError(t, err)
+	require.Len(t, records.records, 1)
+
+	// Extraction improves: the title no longer matches the event and the time moves
+	newStart := parseTime("2025-03-06T10:00:00Z")
+	reprocessor := NewEmailReprocessor(archive, svc, records, newReprocessTestProcessor(newStart), calendars)
+	results, err := reprocessor.Reprocess(googleCtx, "<kickoff-1@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, []ReprocessResult{{UserID: "42", EventID: "google-1"}}, results)
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, "project kickoff", updates[0]["summary"])
+	assert.Equal(t, "Kickoff tomorrow at 10:00.", updates[0]["description"])
+	assert.Equal(t, "Room 1", updates[0]["location"])
+	assert.Equal(t, "2025-03-06T10:00:00Z", updates[0]["start"].(map[string]interface{})["dateTime"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"email": "bob@example.com"}}, updates[0]["attendees"])
+
+	// The recorded event was updated rather than a second one created
+	ingested.AssertExpectations(t)
+	assert.Len(t, records.records, 1)
+}
+
+func TestEmailReprocessor_Reprocess(t *testing.T) {
+	processor := newReprocessTestProcessor(parseTime("2025-03-06T09:00:00Z"))
+	noCalendar := func(string) CalendarService {
+		t.Error("unexpected calendar lookup")
+		return nil
+	}
+
+	t.Run("email not archived", func(t *testing.T) {
+		reprocessor := NewEmailReprocessor(NewMemoryEmailArchive(), &countingCalendar{}, &memoryEventRepository{}, processor, noCalendar)
+		_, err := reprocessor.Reprocess(context.Background(), "<missing@example.com>")
+		assert.ErrorIs(t, err, ErrEmailNotArchived)
+
+		_, err = reprocessor.Reprocess(context.Background(), " <> ")
+		assert.ErrorIs(t, err, ErrEmailNotArchived)
+	})
+
+	t.Run("email without recorded event is processed again", func(t *testing.T) {
+		calendar := &countingCalendar{}
+		archive := NewMemoryEmailArchive()
+		svc := NewArchivingCalendarService(NewIdempotentCalendarService(calendar, NewMemoryIdempotencyStore(), 0), archive)
+
+		ctx := service.WithUserID(context.Background(), "42")
+		_, err := svc.ProcessEmailToCalendar(ctx, archivedTestEmail)
+		require.NoError(t, err)
+		// A redelivery is skipped by idempotency
+		_, err = svc.ProcessEmailToCalendar(ctx, archivedTestEmail)
+		require.NoError(t, err)
+		assert.Equal(t, 1, calendar.calls)
+
+		reprocessor := NewEmailReprocessor(archive, svc, &memoryEventRepository{}, processor, noCalendar)
+		results, err := reprocessor.Reprocess(context.Background(), "kickoff-1@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, []ReprocessResult{{UserID: "42", EventID: "evt-2"}}, results)
+
+		// Reprocessing again runs again rather than replaying the first reprocess
+		_, err = reprocessor.Reprocess(context.Background(), "kickoff-1@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, 3, calendar.calls)
+	})
+
+	t.Run("every user's copy is reprocessed with its failure", func(t *testing.T) {
+		archive := NewMemoryEmailArchive()
+		require.NoError(t, archive.Save(context.Background(), "<kickoff-1@example.com>", "42", archivedTestEmail))
+		require.NoError(t, archive.Save(context.Background(), "kickoff-1@example.com", "7", archivedTestEmail))
+
+		calendar := &countingCalendar{err: errors.New("calendar unavailable")}
+		results, err := NewEmailReprocessor(archive, calendar, &memoryEventRepository{}, processor, noCalendar).
+			Reprocess(context.Background(), "kickoff-1@example.com")
+		require.NoError(t, err)
+
+		require.Len(t, results, 2)
+		assert.Equal(t, "42", results[0].UserID)
+		assert.Equal(t, "7", results[1].UserID)
+		for _, result := range results {
+			assert.EqualError(t, result.Err, "calendar unavailable")
+			assert.Empty(t, result.EventID)
+		}
+	})
+
+	t.Run("deleted event is reported", func(t *testing.T) {
+		archive := NewMemoryEmailArchive()
+		require.NoError(t, archive.Save(context.Background(), "kickoff-1@example.com", "42", archivedTestEmail))
+		records := &memoryEventRepository{}
+		require.NoError(t, records.Create(context.Background(), &EventRecord{
+			UserID: "42", MessageID: "<kickoff-1@example.com>", GoogleEventID: "google-1",
+		}))
+
+		calendarService := new(mockCalendarService)
+		calendarService.On("GetEvent", mock.Anything, "google-1").Return(nil, ErrEventNotFound)
+		calendar := &countingCalendar{}
+		results, err := NewEmailReprocessor(archive, calendar, records, processor, func(string) CalendarService { return calendarService }).
+			Reprocess(context.Background(), "kickoff-1@example.com")
+		require.NoError(t, err)
+
+		require.Len(t, results, 1)
+		assert.ErrorIs(t, results[0].Err, ErrEventNotFound)
+		// A deleted event is not created again
+		assert.Zero(t, calendar.calls)
+		calendarService.AssertNotCalled(t, "UpdateEvent", mock.Anything, mock.Anything)
+	})
+}
+
+func TestArchivingCalendarService_ProcessEmailToCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		email    string
+		archived bool
+	}{
+		{
+			name:     "ingested email is stored",
+			ctx:      service.WithUserID(context.Background(), "42"),
+			email:    archivedTestEmail,
+			archived: true,
+		},
+		{
+			name:  "email without user is not stored",
+			ctx:   context.Background(),
+			email: archivedTestEmail,
+		},
+		{
+			name:  "email without Message-ID is not stored",
+			ctx:   service.WithUserID(context.Background(), "42"),
+			email: "From: alice@example.com\r\nSubject: project kickoff\r\n\r\nKickoff tomorrow.",
+		},
+		{
+			name:  "reprocessed email is not stored again",
+			ctx:   service.WithEventSource(service.WithUserID(context.Background(), "42"), service.SourceReprocess),
+			email: archivedTestEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := NewMemoryEmailArchive()
+			calendar := &countingCalendar{}
+
+			_, err := NewArchivingCalendarService(calendar, archive).ProcessEmailToCalendar(tt.ctx, tt.email)
+			require.NoError(t, err)
+			assert.Equal(t, 1, calendar.calls)
+
+			_, err = archive.Load(context.Background(), "kickoff-1@example.com")
+			if tt.archived {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrEmailNotArchived)
+			}
+		})
+	}
+}
+
+func TestRawEmailFolder(t *testing.T) {
+	assert.Equal(t, rawEmailFolder("<kickoff-1@example.com>"), rawEmailFolder(" kickoff-1@example.com "))
+	assert.NotEqual(t, rawEmailFolder("kickoff-1@example.com"), rawEmailFolder("kickoff-2@example.com"))
+	assert.True(t, strings.HasPrefix(rawEmailFolder("kickoff-1@example.com"), "raw-emails/"))
+}
@@ -105,6 +105,30 @@ func ParseRecurrenceRule(ruleStr string) (*RecurrenceRule, error) {
 	return rule, nil
 }
 
+// ValidateRecurrenceRule checks that ruleStr is an RRULE with a known frequency and
+// a positive interval and count
+func ValidateRecurrenceRule(ruleStr string) error {
+	rule, err := ParseRecurrenceRule(ruleStr)
+	if err != nil {
+		return err
+	}
+
+	switch rule.Frequency {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly, FreqHourly:
+	case "":
+		return fmt.Errorf("invalid recurrence rule: FREQ is required")
+	default:
+		return fmt.Errorf("invalid recurrence rule: unknown FREQ %q", rule.Frequency)
+	}
+	if rule.Interval <= 0 {
+		return fmt.Errorf("invalid recurrence rule: INTERVAL must be positive")
+	}
+	if rule.Count != nil && *rule.Count <= 0 {
+		return fmt.Errorf("invalid recurrence rule: COUNT must be positive")
+	}
+	return nil
+}
+
 // GetRecurrences returns all recurrence times within the given range
 func (r *RecurrenceRule) GetRecurrences(start time.Time, end time.Time, duration time.Duration) []TimeSlot {
 	var slots []TimeSlot
@@ -108,6 +108,34 @@ func TestParseRecurrenceRule(t *testing.T) {
 	}
 }
 
+func TestValidateRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          string
+		expectedError string
+	}{
+		{name: "weekly on mondays", rule: "RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		{name: "monthly ten times", rule: "RRULE:FREQ=MONTHLY;COUNT=10;INTERVAL=2"},
+		{name: "missing prefix", rule: "FREQ=WEEKLY", expectedError: "missing RRULE prefix"},
+		{name: "missing frequency", rule: "RRULE:BYDAY=MO", expectedError: "FREQ is required"},
+		{name: "unknown frequency", rule: "RRULE:FREQ=FORTNIGHTLY", expectedError: `unknown FREQ "FORTNIGHTLY"`},
+		{name: "zero interval", rule: "RRULE:FREQ=DAILY;INTERVAL=0", expectedError: "INTERVAL must be positive"},
+		{name: "negative count", rule: "RRULE:FREQ=DAILY;COUNT=-1", expectedError: "COUNT must be positive"},
+		{name: "invalid count", rule: "RRULE:FREQ=DAILY;COUNT=x", expectedError: "invalid COUNT value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRecurrenceRule(tt.rule)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
 func TestGetRecurrences(t *testing.T) {
 	baseTime := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
 	oneHour := time.Hour
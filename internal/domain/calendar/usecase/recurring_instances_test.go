@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleCalendarService_ListEvents_ExpandedSeries(t *testing.T) {
+	svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/calendar/v3/calendars/primary/events", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("singleEvents"))
+		_, _ = w.Write([]byte(`{"items":[
+			{"id":"weekly_20250303T090000Z","summary":"Weekly sync","recurringEventId":"weekly",
+			 "start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T09:30:00Z"}},
+			{"id":"weekly_20250310T090000Z","summary":"Weekly sync","recurringEventId":"weekly",
+			 "start":{"dateTime":"2025-03-10T09:00:00Z"},"end":{"dateTime":"2025-03-10T09:30:00Z"}},
+			{"id":"weekly_20250317T090000Z","summary":"Weekly sync","recurringEventId":"weekly",
+			 "start":{"dateTime":"2025-03-17T09:00:00Z"},"end":{"dateTime":"2025-03-17T09:30:00Z"}},
+			{"id":"one-off","summary":"Review",
+			 "start":{"dateTime":"2025-03-12T14:00:00Z"},"end":{"dateTime":"2025-03-12T15:00:00Z"}}
+		]}`))
+	})
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	events, err := NewCalendarService(svc).GetEvents(ctx, TimeRange{StartTime: from, EndTime: from.AddDate(0, 0, 21)}, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	for i, week := range []int{3, 10, 17} {
+		assert.False(t, events[i].IsRecurring)
+		assert.Empty(t, events[i].RecurrenceRule)
+		assert.Equal(t, "weekly", events[i].SeriesID)
+		assert.Equal(t, time.Date(2025, 3, week, 9, 0, 0, 0, time.UTC), events[i].StartTime.UTC())
+	}
+	assert.Empty(t, events[3].SeriesID)
+
+	// The conflict checker sees every instance as its own busy period
+	calendar := new(mockCalendarService)
+	calendar.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return(events, nil)
+	checker := NewConflictChecker(calendar)
+	busy, err := checker.GetBusyPeriods(ctx, TimeRange{StartTime: from, EndTime: from.AddDate(0, 0, 21)}, nil)
+	require.NoError(t, err)
+	assert.Len(t, busy, 4)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calerrors "mail2calendar/internal/domain/calendar/errors"
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestEmailProcessorImpl_RequireValidation(t *testing.T) {
+	startTime := parseTime("2025-02-06T14:00:00Z")
+	newNER := func() *mockNERService {
+		ner := new(mockNERService)
+		ner.On("ExtractDateTime", mock.Anything, mock.Anything).
+			Return([]time.Time{startTime, startTime.Add(time.Hour)}, nil).Maybe()
+		ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("", nil).Maybe()
+		return ner
+	}
+
+	t.Run("spoofed email is rejected", func(t *testing.T) {
+		validator := new(mockEmailValidator)
+		validator.On("ValidateDKIM", headerTestEmail).Return(fmt.Errorf("signature invalid"))
+		ner := newNER()
+
+		processor := NewEmailProcessorImpl(validator, ner, WithRequireValidation(true))
+
+		event, err := processor.ProcessEmail(context.Background(), headerTestEmail)
+		assert.Nil(t, event)
+		assert.True(t, calerrors.IsInvalidEmail(err))
+		assert.ErrorContains(t, err, "signature invalid")
+
+		events, err := processor.ProcessEmailMulti(context.Background(), headerTestEmail)
+		assert.Nil(t, events)
+		assert.True(t, calerrors.IsInvalidEmail(err))
+
+		ner.AssertNotCalled(t, "ExtractDateTime", mock.Anything, mock.Anything)
+	})
+
+	t.Run("valid email is processed", func(t *testing.T) {
+		validator := new(mockEmailValidator)
+		validator.On("ValidateDKIM", headerTestEmail).Return(nil)
+		validator.On("ValidateSPF", headerTestEmail).Return(nil)
+		validator.On("ValidateSender", headerTestEmail).Return(nil)
+
+		processor := NewEmailProcessorImpl(validator, newNER(), WithRequireValidation(true))
+
+		event, err := processor.ProcessEmail(context.Background(), headerTestEmail)
+		require.NoError(t, err)
+		assert.Equal(t, startTime, event.StartTime)
+		validator.AssertExpectations(t)
+	})
+
+	t.Run("validation off passes through", func(t *testing.T) {
+		validator := new(mockEmailValidator)
+
+		processor := NewEmailProcessorImpl(validator, newNER())
+
+		event, err := processor.ProcessEmail(context.Background(), headerTestEmail)
+		require.NoError(t, err)
+		assert.Equal(t, startTime, event.StartTime)
+		validator.AssertNotCalled(t, "ValidateDKIM", mock.Anything)
+	})
+}
+
+func TestMessagingService_handleDelivery_Validation(t *testing.T) {
+	validator := new(mockEmailValidator)
+	validator.On("ValidateDKIM", "email").Return(nil)
+	validator.On("ValidateSPF", "email").Return(nil)
+	validator.On("ValidateSender", "email").Return(nil)
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	s := &messagingService{
+		calendar: calendar,
+		tracer:   otel.Tracer("test"),
+		logger:   logrus.New(),
+	}
+	WithEmailValidation(NewEmailProcessorImpl(validator, new(mockNERService)))(s)
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+
+	s.handleDelivery(context.Background(), amqp.Delivery{Body: body})
+	validator.AssertExpectations(t)
+	calendar.AssertExpectations(t)
+}
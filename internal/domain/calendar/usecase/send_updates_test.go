@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleCalendarService_SendUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []GoogleCalendarOption
+		expected string
+	}{
+		{name: "default notifies no one", expected: "none"},
+		{name: "all", opts: []GoogleCalendarOption{WithSendUpdates(SendUpdatesAll)}, expected: "all"},
+		{name: "external only", opts: []GoogleCalendarOption{WithSendUpdates(SendUpdatesExternalOnly)}, expected: "externalOnly"},
+		{name: "invalid value keeps the default", opts: []GoogleCalendarOption{WithSendUpdates("everyone")}, expected: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := map[string]string{}
+			svc, ctx := newGoogleTestService(func(w http.ResponseWriter, r *http.Request) {
+				sent[r.Method] = r.URL.Query().Get("sendUpdates")
+				_, _ = w.Write([]byte(`{"id":"review"}`))
+			}, tt.opts...)
+
+			start := time.Date(2025, 3, 12, 14, 0, 0, 0, time.UTC)
+			event := &GoogleCalendarEvent{
+				ID:        "review",
+				Summary:   "Review",
+				Start:     start,
+				End:       start.Add(time.Hour),
+				Attendees: []string{"alice@example.com"},
+			}
+			require.NoError(t, svc.CreateEvent(ctx, event))
+			require.NoError(t, svc.UpdateEvent(ctx, event))
+
+			assert.Equal(t, map[string]string{
+				http.MethodPost: tt.expected,
+				http.MethodPut:  tt.expected,
+			}, sent)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mail2calendar/internal/domain/calendar/logger"
+)
+
+// ExtractionFieldDiff is one event field on which the primary and shadow extraction disagree
+type ExtractionFieldDiff struct {
+	Field   string
+	Primary string
+	Shadow  string
+}
+
+// ExtractionDiff describes how a shadow extraction differed from the primary one
+type ExtractionDiff struct {
+	Fields []ExtractionFieldDiff
+}
+
+// ShadowRecorder receives the differences found in shadow mode
+type ShadowRecorder interface {
+	RecordDiff(ctx context.Context, diff ExtractionDiff)
+}
+
+// ShadowRecorderFunc adapts a function to a ShadowRecorder
+type ShadowRecorderFunc func(ctx context.Context, diff ExtractionDiff)
+
+// RecordDiff calls f(ctx, diff)
+func (f ShadowRecorderFunc) RecordDiff(ctx context.Context, diff ExtractionDiff) {
+	f(ctx, diff)
+}
+
+// NewLoggingShadowRecorder returns a ShadowRecorder that logs every difference as a warning
+func NewLoggingShadowRecorder(l *logger.Logger) ShadowRecorder {
+	return ShadowRecorderFunc(func(ctx context.Context, diff ExtractionDiff) {
+		fields := make(logger.Fields, len(diff.Fields))
+		for _, d := range diff.Fields {
+			fields[d.Field] = map[string]string{"primary": d.Primary, "shadow": d.Shadow}
+		}
+		l.WithContext(ctx).Warn("shadow extraction differs from primary", fields)
+	})
+}
+
+// shadowEmailProcessor runs a second extraction path next to the primary one and
+// records where they disagree. Only the primary result is ever returned.
+type shadowEmailProcessor struct {
+	primary  EmailProcessor
+	shadow   EmailProcessor
+	recorder ShadowRecorder
+}
+
+// NewShadowEmailProcessor wraps primary so that, when enabled, every email is also
+// extracted by shadow and differences are passed to recorder. It is meant to compare
+// a new extraction logic against the current one on real traffic. When disabled, or
+// when shadow or recorder is nil, primary is returned unchanged.
+func NewShadowEmailProcessor(primary, shadow EmailProcessor, recorder ShadowRecorder, enabled bool) EmailProcessor {
+	if !enabled || shadow == nil || recorder == nil {
+		return primary
+	}
+	return &shadowEmailProcessor{
+		primary:  primary,
+		shadow:   shadow,
+		recorder: recorder,
+	}
+}
+
+func (p *shadowEmailProcessor) ProcessEmail(ctx context.Context, emailContent string) (*EmailEvent, error) {
+	event, err := p.primary.ProcessEmail(ctx, emailContent)
+	p.compare(ctx, emailContent, event, err)
+	return event, err
+}
+
+// ProcessEmailMulti is not shadowed; it returns the primary result directly
+func (p *shadowEmailProcessor) ProcessEmailMulti(ctx context.Context, emailContent string) ([]*EmailEvent, error) {
+	return p.primary.ProcessEmailMulti(ctx, emailContent)
+}
+
+// ProcessEmailPreview is not shadowed; it returns the primary result directly
+func (p *shadowEmailProcessor) ProcessEmailPreview(ctx context.Context, emailContent string) (*EmailEvent, error) {
+	return p.primary.ProcessEmailPreview(ctx, emailContent)
+}
+
+func (p *shadowEmailProcessor) ValidateEmail(ctx context.Context, emailContent string) error {
+	return p.primary.ValidateEmail(ctx, emailContent)
+}
+
+// compare runs the shadow extraction and records any difference. A failing or
+// panicking shadow path never affects the primary result.
+func (p *shadowEmailProcessor) compare(ctx context.Context, emailContent string, primary *EmailEvent, primaryErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.recorder.RecordDiff(ctx, ExtractionDiff{Fields: []ExtractionFieldDiff{
+				{Field: "panic", Shadow: fmt.Sprint(r)},
+			}})
+		}
+	}()
+
+	shadow, shadowErr := p.shadow.ProcessEmail(ctx, emailContent)
+	if diff := diffExtraction(primary, primaryErr, shadow, shadowErr); len(diff.Fields) > 0 {
+		p.recorder.RecordDiff(ctx, diff)
+	}
+}
+
+// diffExtraction compares the extracted fields of two results. Errors are compared
+// by presence only, since their messages usually differ between implementations.
+func diffExtraction(primary *EmailEvent, primaryErr error, shadow *EmailEvent, shadowErr error) ExtractionDiff {
+	var diff ExtractionDiff
+	add := func(field, a, b string) {
+		if a != b {
+			diff.Fields = append(diff.Fields, ExtractionFieldDiff{Field: field, Primary: a, Shadow: b})
+		}
+	}
+
+	if primaryErr != nil || shadowErr != nil {
+		if (primaryErr == nil) != (shadowErr == nil) {
+			add("error", errorString(primaryErr), errorString(shadowErr))
+		}
+		return diff
+	}
+	if primary == nil || shadow == nil {
+		add("event", fmt.Sprint(primary != nil), fmt.Sprint(shadow != nil))
+		return diff
+	}
+
+	add("subject", primary.Subject, shadow.Subject)
+	add("description", primary.Description, shadow.Description)
+	add("start_time", formatExtractionTime(primary.StartTime), formatExtractionTime(shadow.StartTime))
+	add("end_time", formatExtractionTime(primary.EndTime), formatExtractionTime(shadow.EndTime))
+	add("all_day", fmt.Sprint(primary.IsAllDay), fmt.Sprint(shadow.IsAllDay))
+	add("time_zone", primary.TimeZone, shadow.TimeZone)
+	add("location", primary.Location, shadow.Location)
+	add("organizer", primary.Organizer, shadow.Organizer)
+	add("attendees", strings.Join(primary.Attendees, ","), strings.Join(shadow.Attendees, ","))
+	add("source", string(primary.Source), string(shadow.Source))
+	return diff
+}
+
+func formatExtractionTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const shadowTestEmail = "From: organizer@example.com\r\n" +
+	"To: alice@example.com\r\n" +
+	"Subject: Planning\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Planning tomorrow at 2pm in the main hall."
+
+func newShadowTestNER(dates []time.Time) *mockNERService {
+	ner := new(mockNERService)
+	ner.On("ExtractDateTime", mock.Anything, mock.Anything).Return(dates, nil)
+	ner.On("ExtractLocation", mock.Anything, mock.Anything).Return("main  hall.", nil).Maybe()
+	return ner
+}
+
+func TestShadowEmailProcessor(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	dates := []time.Time{start, start.Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		dates      []time.Time
+		shadowOpts []EmailProcessorOption
+		expected   []ExtractionFieldDiff
+	}{
+		{
+			name:  "paths agree",
+			dates: dates,
+		},
+		{
+			name:       "paths disagree on location",
+			dates:      dates,
+			shadowOpts: []EmailProcessorOption{WithLocationNormalization(true)},
+			expected: []ExtractionFieldDiff{
+				{Field: "location", Primary: "main  hall.", Shadow: "Main Hall"},
+			},
+		},
+		{
+			name:       "only the shadow path fails",
+			dates:      []time.Time{},
+			shadowOpts: []EmailProcessorOption{WithMissingDatesPolicy(MissingDatesSkip)},
+			expected: []ExtractionFieldDiff{
+				{Field: "error", Shadow: "PARSE_ERROR: failed to extract event info (caused by: no event dates found in email)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diffs []ExtractionDiff
+			recorder := ShadowRecorderFunc(func(_ context.Context, diff ExtractionDiff) {
+				diffs = append(diffs, diff)
+			})
+
+			primary := NewEmailProcessorImpl(new(mockEmailValidator), newShadowTestNER(tt.dates))
+			shadow := NewEmailProcessorImpl(new(mockEmailValidator), newShadowTestNER(tt.dates), tt.shadowOpts...)
+			processor := NewShadowEmailProcessor(primary, shadow, recorder, true)
+
+			event, err := processor.ProcessEmail(context.Background(), shadowTestEmail)
+			require.NoError(t, err)
+			// The primary result is returned whatever the shadow path does
+			assert.Equal(t, "main  hall.", event.Location)
+
+			if tt.expected == nil {
+				assert.Empty(t, diffs)
+				return
+			}
+			require.Len(t, diffs, 1)
+			assert.Equal(t, tt.expected, diffs[0].Fields)
+		})
+	}
+}
+
+func TestNewShadowEmailProcessor_Disabled(t *testing.T) {
+	primary := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService))
+	shadow := NewEmailProcessorImpl(new(mockEmailValidator), new(mockNERService))
+	recorder := ShadowRecorderFunc(func(context.Context, ExtractionDiff) {})
+
+	assert.Same(t, primary, NewShadowEmailProcessor(primary, shadow, recorder, false))
+	assert.Same(t, primary, NewShadowEmailProcessor(primary, nil, recorder, true))
+}
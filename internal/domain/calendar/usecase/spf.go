@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// SPFResult is the outcome of an SPF check as defined by RFC 7208
+type SPFResult string
+
+const (
+	SPFPass      SPFResult = "pass"
+	SPFFail      SPFResult = "fail"
+	SPFSoftFail  SPFResult = "softfail"
+	SPFNeutral   SPFResult = "neutral"
+	SPFNone      SPFResult = "none"
+	SPFTempError SPFResult = "temperror"
+	SPFPermError SPFResult = "permerror"
+)
+
+// maxSPFLookups caps the DNS-querying terms of one SPF evaluation (RFC 7208 section 4.6.4)
+const maxSPFLookups = 10
+
+// errSPFLookupLimit is returned when a policy needs more than maxSPFLookups DNS queries
+var errSPFLookupLimit = errors.New("too many SPF DNS lookups")
+
+// DNSResolver is the subset of *net.Resolver used for DKIM and SPF lookups
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// SPFChecker checks whether an IP is allowed to send mail for a domain. It is meant for
+// callers that know the sending IP, such as the SMTP session receiving the email.
+type SPFChecker interface {
+	CheckSPF(ctx context.Context, ip net.IP, domain string) (SPFResult, error)
+}
+
+// spfEvaluation tracks the DNS lookups spent while evaluating one check_host
+type spfEvaluation struct {
+	resolver DNSResolver
+	ip       net.IP
+	lookups  int
+}
+
+// CheckSPF evaluates the SPF policy of domain for ip
+func (v *emailValidatorImpl) CheckSPF(ctx context.Context, ip net.IP, domain string) (SPFResult, error) {
+	if ip == nil {
+		return SPFNone, fmt.Errorf("no sending IP to check")
+	}
+	eval := &spfEvaluation{resolver: v.resolver, ip: ip}
+	return eval.checkHost(ctx, strings.ToLower(strings.TrimSuffix(domain, ".")))
+}
+
+func (e *spfEvaluation) checkHost(ctx context.Context, domain string) (SPFResult, error) {
+	record, result, err := e.lookupRecord(ctx, domain)
+	if record == "" {
+		return result, err
+	}
+
+	var redirect string
+	for _, term := range strings.Fields(record)[1:] {
+		// Modifiers are name=value; only redirect affects the result
+		if name, value, found := strings.Cut(term, "="); found && !strings.ContainsAny(name, ":/") {
+			if strings.EqualFold(name, "redirect") {
+				redirect = value
+			}
+			continue
+		}
+
+		qualifier := SPFPass
+		switch term[0] {
+		case '+':
+			term = term[1:]
+		case '-':
+			qualifier, term = SPFFail, term[1:]
+		case '~':
+			qualifier, term = SPFSoftFail, term[1:]
+		case '?':
+			qualifier, term = SPFNeutral, term[1:]
+		}
+
+		matched, err := e.matchMechanism(ctx, domain, term)
+		if err != nil {
+			if errors.Is(err, errSPFLookupLimit) {
+				return SPFPermError, err
+			}
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+				return SPFTempError, err
+			}
+			return SPFPermError, err
+		}
+		if matched {
+			return qualifier, nil
+		}
+	}
+
+	if redirect != "" {
+		if err := e.countLookup(); err != nil {
+			return SPFPermError, err
+		}
+		result, err := e.checkHost(ctx, strings.ToLower(redirect))
+		if result == SPFNone {
+			return SPFPermError, fmt.Errorf("SPF redirect to %s has no policy", redirect)
+		}
+		return result, err
+	}
+	return SPFNeutral, nil
+}
+
+// lookupRecord returns the single SPF record of domain. When there is none, the record
+// is empty and the result tells why.
+func (e *spfEvaluation) lookupRecord(ctx context.Context, domain string) (string, SPFResult, error) {
+	records, err := e.resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", SPFNone, nil
+		}
+		return "", SPFTempError, fmt.Errorf("SPF lookup for %s failed: %v", domain, err)
+	}
+
+	var spf []string
+	for _, record := range records {
+		lower := strings.ToLower(record)
+		if lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ") {
+			spf = append(spf, record)
+		}
+	}
+
+	switch len(spf) {
+	case 0:
+		return "", SPFNone, nil
+	case 1:
+		return spf[0], "", nil
+	default:
+		return "", SPFPermError, fmt.Errorf("%s publishes %d SPF records", domain, len(spf))
+	}
+}
+
+// matchMechanism reports whether the sending IP matches one SPF mechanism
+func (e *spfEvaluation) matchMechanism(ctx context.Context, domain, term string) (bool, error) {
+	name, arg, _ := strings.Cut(term, ":")
+	// a and mx may omit the domain but keep a CIDR, as in "a/24"
+	if !strings.Contains(term, ":") {
+		if i := strings.Index(term, "/"); i >= 0 {
+			name, arg = term[:i], term[i:]
+		}
+	}
+
+	switch strings.ToLower(name) {
+	case "all":
+		return true, nil
+
+	case "ip4", "ip6":
+		if !strings.Contains(arg, "/") {
+			if strings.EqualFold(name, "ip4") {
+				arg += "/32"
+			} else {
+				arg += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(arg)
+		if err != nil {
+			return false, fmt.Errorf("invalid SPF mechanism %s: %v", term, err)
+		}
+		return network.Contains(e.ip), nil
+
+	case "a":
+		if err := e.countLookup(); err != nil {
+			return false, err
+		}
+		target, cidr4, cidr6, err := splitSPFDomainCIDR(arg, domain)
+		if err != nil {
+			return false, err
+		}
+		return e.matchHost(ctx, target, cidr4, cidr6)
+
+	case "mx":
+		if err := e.countLookup(); err != nil {
+			return false, err
+		}
+		target, cidr4, cidr6, err := splitSPFDomainCIDR(arg, domain)
+		if err != nil {
+			return false, err
+		}
+		mxs, err := e.resolver.LookupMX(ctx, target)
+		if err != nil {
+			return false, ignoreNotFound(err)
+		}
+		for i, mx := range mxs {
+			if i == maxSPFLookups {
+				return false, errSPFLookupLimit
+			}
+			matched, err := e.matchHost(ctx, strings.TrimSuffix(mx.Host, "."), cidr4, cidr6)
+			if matched || err != nil {
+				return matched, err
+			}
+		}
+		return false, nil
+
+	case "include":
+		if err := e.countLookup(); err != nil {
+			return false, err
+		}
+		result, err := e.checkHost(ctx, strings.ToLower(arg))
+		switch result {
+		case SPFPass:
+			return true, nil
+		case SPFTempError, SPFPermError:
+			return false, err
+		case SPFNone:
+			return false, fmt.Errorf("SPF include of %s has no policy", arg)
+		default:
+			return false, nil
+		}
+
+	case "exists":
+		if err := e.countLookup(); err != nil {
+			return false, err
+		}
+		addrs, err := e.resolver.LookupIPAddr(ctx, arg)
+		if err != nil {
+			return false, ignoreNotFound(err)
+		}
+		return len(addrs) > 0, nil
+
+	case "ptr":
+		// ptr is deprecated and never matches here
+		return false, e.countLookup()
+
+	default:
+		return false, fmt.Errorf("unknown SPF mechanism %s", term)
+	}
+}
+
+// matchHost reports whether the sending IP is within cidr of one address of host
+func (e *spfEvaluation) matchHost(ctx context.Context, host string, cidr4, cidr6 int) (bool, error) {
+	addrs, err := e.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return false, ignoreNotFound(err)
+	}
+
+	for _, addr := range addrs {
+		bits, ones := 128, cidr6
+		if addr.IP.To4() != nil {
+			bits, ones = 32, cidr4
+		}
+		if (e.ip.To4() != nil) != (bits == 32) {
+			continue
+		}
+		network := net.IPNet{IP: addr.IP.Mask(net.CIDRMask(ones, bits)), Mask: net.CIDRMask(ones, bits)}
+		if network.Contains(e.ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e *spfEvaluation) countLookup() error {
+	e.lookups++
+	if e.lookups > maxSPFLookups {
+		return errSPFLookupLimit
+	}
+	return nil
+}
+
+// splitSPFDomainCIDR splits the "domain/cidr4//cidr6" argument of a and mx
+func splitSPFDomainCIDR(arg, domain string) (string, int, int, error) {
+	cidr4, cidr6 := 32, 128
+
+	target, cidrs, _ := strings.Cut(arg, "/")
+	if target == "" {
+		target = domain
+	}
+	if cidrs == "" {
+		return target, cidr4, cidr6, nil
+	}
+
+	v4, v6, dual := strings.Cut(cidrs, "//")
+	if strings.HasPrefix(cidrs, "/") {
+		v4, v6, dual = "", cidrs[1:], true
+	}
+	var err error
+	if v4 != "" {
+		if cidr4, err = strconv.Atoi(v4); err != nil || cidr4 < 0 || cidr4 > 32 {
+			return "", 0, 0, fmt.Errorf("invalid SPF ip4 CIDR length %q", v4)
+		}
+	}
+	if dual && v6 != "" {
+		if cidr6, err = strconv.Atoi(v6); err != nil || cidr6 < 0 || cidr6 > 128 {
+			return "", 0, 0, fmt.Errorf("invalid SPF ip6 CIDR length %q", v6)
+		}
+	}
+	return target, cidr4, cidr6, nil
+}
+
+// ignoreNotFound treats a missing DNS name as an empty answer
+func ignoreNotFound(err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailValidator_CheckSPF(t *testing.T) {
+	resolver := &fakeResolver{
+		txt: map[string][]string{
+			"example.com":       {"google-site-verification=abc", "v=spf1 ip4:192.0.2.0/24 a:relay.example.com mx include:_spf.partner.net -all"},
+			"_spf.partner.net":  {"v=spf1 ip6:2001:db8::/32 ~all"},
+			"soft.example.com":  {"v=spf1 ~all"},
+			"redir.example.com": {"v=spf1 redirect=example.com"},
+			"double.example":    {"v=spf1 -all", "v=spf1 +all"},
+			"loop.example":      {"v=spf1 include:loop.example -all"},
+		},
+		ips: map[string][]string{
+			"relay.example.com": {"198.51.100.7"},
+			"mx1.example.com":   {"203.0.113.5", "2001:db8:ffff::5"},
+		},
+		mx: map[string][]string{
+			"example.com": {"mx1.example.com."},
+		},
+	}
+	v := NewEmailValidator(nil, WithDNSResolver(resolver)).(*emailValidatorImpl)
+
+	tests := []struct {
+		ip       string
+		domain   string
+		expected SPFResult
+	}{
+		{ip: "192.0.2.44", domain: "example.com", expected: SPFPass},
+		{ip: "198.51.100.7", domain: "example.com", expected: SPFPass},
+		{ip: "203.0.113.5", domain: "example.com", expected: SPFPass},
+		{ip: "2001:db8::1", domain: "example.com", expected: SPFPass},
+		{ip: "203.0.113.6", domain: "example.com", expected: SPFFail},
+		{ip: "203.0.113.6", domain: "soft.example.com", expected: SPFSoftFail},
+		{ip: "192.0.2.44", domain: "redir.example.com", expected: SPFPass},
+		{ip: "192.0.2.44", domain: "unknown.example", expected: SPFNone},
+		{ip: "192.0.2.44", domain: "double.example", expected: SPFPermError},
+		{ip: "192.0.2.44", domain: "loop.example", expected: SPFPermError},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s from %s", tt.domain, tt.ip), func(t *testing.T) {
+			result, _ := v.CheckSPF(context.Background(), net.ParseIP(tt.ip), tt.domain)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEmailValidator_ValidateSPF(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"example.com": {"v=spf1 ip4:192.0.2.10 -all"},
+	}}
+	v := NewEmailValidator(nil, WithDNSResolver(resolver))
+
+	email := "Received: from mail.example.com (mail.example.com [%s])\r\n" +
+		"\tby mx.mail2calendar.io ([10.0.0.1]) with ESMTPS\r\n" +
+		"Return-Path: <bounces@example.com>\r\n" +
+		"From: alice@example.com\r\n" +
+		"Subject: Planning\r\n" +
+		"\r\n" +
+		"Planning at 2pm."
+
+	assert.NoError(t, v.ValidateSPF(fmt.Sprintf(email, "192.0.2.10")))
+	assert.ErrorContains(t, v.ValidateSPF(fmt.Sprintf(email, "192.0.2.11")), "fail")
+	assert.ErrorContains(t, v.ValidateSPF("From: alice@example.com\r\n\r\nbody"), "sending IP")
+}
+
+func TestEmailValidator_ValidateSender(t *testing.T) {
+	email := func(from string) string {
+		return "From: " + from + "\r\nSubject: Planning\r\n\r\nbody"
+	}
+
+	v := NewEmailValidator([]string{"Example.com", "partner.net"}, WithBlockedSenders("spammer@example.com", "bad.example"))
+	assert.NoError(t, v.ValidateSender(email("Alice <alice@example.com>")))
+	assert.NoError(t, v.ValidateSender(email("bob@PARTNER.net")))
+	assert.ErrorContains(t, v.ValidateSender(email("spammer@example.com")), "blocked")
+	assert.ErrorContains(t, v.ValidateSender(email("carol@other.org")), "not trusted")
+
+	// Without trusted domains every sender that is not blocked is accepted
+	v = NewEmailValidator(nil, WithBlockedSenders("bad.example"))
+	assert.NoError(t, v.ValidateSender(email("carol@other.org")))
+	assert.ErrorContains(t, v.ValidateSender(email("eve@bad.example")), "blocked")
+	assert.Error(t, v.ValidateSender(email("not an address")))
+}
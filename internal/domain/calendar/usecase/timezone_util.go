@@ -9,16 +9,62 @@ import (
 // TimezoneUtil handles timezone conversions and standardization
 type TimezoneUtil struct {
 	defaultTimezone string
+	// userLocation is the zone of times that name no timezone; nil means the server's
+	// local time
+	userLocation *time.Location
+	// region is the ISO 3166-1 alpha-2 code used to resolve overloaded abbreviations
+	region string
+}
+
+// TimezoneUtilOption configures a TimezoneUtil
+type TimezoneUtilOption func(*TimezoneUtil)
+
+// WithRegion resolves timezone abbreviations used by several countries, such as IST
+// (India, Israel, Ireland) or CST (US, China), to the zone of region, an ISO 3166-1
+// alpha-2 country code
+func WithRegion(region string) TimezoneUtilOption {
+	return func(tu *TimezoneUtil) {
+		tu.region = strings.ToUpper(region)
+	}
 }
 
 // NewTimezoneUtil creates a new TimezoneUtil with default timezone
-func NewTimezoneUtil(defaultTz string) *TimezoneUtil {
+func NewTimezoneUtil(defaultTz string, opts ...TimezoneUtilOption) *TimezoneUtil {
 	if defaultTz == "" {
 		defaultTz = "UTC"
 	}
-	return &TimezoneUtil{
+	tu := &TimezoneUtil{
 		defaultTimezone: defaultTz,
 	}
+	for _, opt := range opts {
+		opt(tu)
+	}
+	return tu
+}
+
+// NewUserTimezoneUtil creates a TimezoneUtil for a user's preferred IANA timezone.
+// Times that name no timezone are read in that zone rather than the server's local time.
+func NewUserTimezoneUtil(userTz string, opts ...TimezoneUtilOption) (*TimezoneUtil, error) {
+	loc, err := time.LoadLocation(userTz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %v", userTz, err)
+	}
+	tu := &TimezoneUtil{
+		defaultTimezone: userTz,
+		userLocation:    loc,
+	}
+	for _, opt := range opts {
+		opt(tu)
+	}
+	return tu, nil
+}
+
+// localLocation returns the zone of times that name no timezone
+func (tu *TimezoneUtil) localLocation() *time.Location {
+	if tu.userLocation != nil {
+		return tu.userLocation
+	}
+	return time.Local
 }
 
 // ConvertTime converts time between timezones
@@ -68,32 +114,133 @@ func (tu *TimezoneUtil) ParseTimeInTimezone(timeStr, layout, timezone string) (t
 	return time.ParseInLocation(layout, timeStr, loc)
 }
 
+// abbreviationZone is a timezone that an abbreviation may refer to
+type abbreviationZone struct {
+	timezone string
+	// region is the ISO 3166-1 alpha-2 code of the country using the abbreviation
+	region string
+}
+
+// timezoneAbbreviations maps timezone abbreviations to the zones they refer to. The
+// standard and daylight abbreviations of a zone map to the same IANA zone, whose rules
+// give the offset in effect at the parsed date. Overloaded abbreviations list their
+// most common reading first.
+var timezoneAbbreviations = map[string][]abbreviationZone{
+	"UTC": {{timezone: "UTC"}},
+	"GMT": {{timezone: "UTC"}},
+
+	// North America
+	"EST":  {{timezone: "America/New_York", region: "US"}},
+	"EDT":  {{timezone: "America/New_York", region: "US"}},
+	"CST":  {{timezone: "America/Chicago", region: "US"}, {timezone: "Asia/Shanghai", region: "CN"}, {timezone: "America/Havana", region: "CU"}},
+	"CDT":  {{timezone: "America/Chicago", region: "US"}, {timezone: "America/Havana", region: "CU"}},
+	"MST":  {{timezone: "America/Denver", region: "US"}},
+	"MDT":  {{timezone: "America/Denver", region: "US"}},
+	"PST":  {{timezone: "America/Los_Angeles", region: "US"}, {timezone: "Asia/Manila", region: "PH"}},
+	"PDT":  {{timezone: "America/Los_Angeles", region: "US"}},
+	"AKST": {{timezone: "America/Anchorage", region: "US"}},
+	"AKDT": {{timezone: "America/Anchorage", region: "US"}},
+	"HST":  {{timezone: "Pacific/Honolulu", region: "US"}},
+	"AST":  {{timezone: "America/Halifax", region: "CA"}, {timezone: "Asia/Riyadh", region: "SA"}},
+	"ADT":  {{timezone: "America/Halifax", region: "CA"}},
+	"NST":  {{timezone: "America/St_Johns", region: "CA"}},
+	"NDT":  {{timezone: "America/St_Johns", region: "CA"}},
+
+	// Europe
+	"WET":  {{timezone: "Europe/Lisbon", region: "PT"}},
+	"WEST": {{timezone: "Europe/Lisbon", region: "PT"}},
+	"BST":  {{timezone: "Europe/London", region: "GB"}, {timezone: "Asia/Dhaka", region: "BD"}},
+	"CET":  {{timezone: "Europe/Paris", region: "FR"}},
+	"CEST": {{timezone: "Europe/Paris", region: "FR"}},
+	"EET":  {{timezone: "Europe/Athens", region: "GR"}},
+	"EEST": {{timezone: "Europe/Athens", region: "GR"}},
+	"MSK":  {{timezone: "Europe/Moscow", region: "RU"}},
+
+	// Asia
+	"IST": {{timezone: "Asia/Kolkata", region: "IN"}, {timezone: "Asia/Jerusalem", region: "IL"}, {timezone: "Europe/Dublin", region: "IE"}},
+	"IDT": {{timezone: "Asia/Jerusalem", region: "IL"}},
+	"PKT": {{timezone: "Asia/Karachi", region: "PK"}},
+	"ICT": {{timezone: "Asia/Bangkok", region: "TH"}, {timezone: "Asia/Ho_Chi_Minh", region: "VN"}},
+	"WIB": {{timezone: "Asia/Jakarta", region: "ID"}},
+	"SGT": {{timezone: "Asia/Singapore", region: "SG"}},
+	"HKT": {{timezone: "Asia/Hong_Kong", region: "HK"}},
+	"PHT": {{timezone: "Asia/Manila", region: "PH"}},
+	"KST": {{timezone: "Asia/Seoul", region: "KR"}},
+	"JST": {{timezone: "Asia/Tokyo", region: "JP"}},
+
+	// Oceania
+	"AWST": {{timezone: "Australia/Perth", region: "AU"}},
+	"ACST": {{timezone: "Australia/Adelaide", region: "AU"}},
+	"ACDT": {{timezone: "Australia/Adelaide", region: "AU"}},
+	"AEST": {{timezone: "Australia/Sydney", region: "AU"}},
+	"AEDT": {{timezone: "Australia/Sydney", region: "AU"}},
+	"NZST": {{timezone: "Pacific/Auckland", region: "NZ"}},
+	"NZDT": {{timezone: "Pacific/Auckland", region: "NZ"}},
+}
+
 // GuessTimezone attempts to guess timezone from timezone abbreviation
 func (tu *TimezoneUtil) GuessTimezone(abbr string) string {
-	abbr = strings.ToUpper(abbr)
-	timezoneMap := map[string]string{
-		"EST":  "America/New_York",
-		"EDT":  "America/New_York",
-		"CST":  "America/Chicago",
-		"CDT":  "America/Chicago",
-		"MST":  "America/Denver",
-		"MDT":  "America/Denver",
-		"PST":  "America/Los_Angeles",
-		"PDT":  "America/Los_Angeles",
-		"GMT":  "UTC",
-		"UTC":  "UTC",
-		"ICT":  "Asia/Bangkok",
-		"JST":  "Asia/Tokyo",
-		"IST":  "Asia/Kolkata",
-		"AEST": "Australia/Sydney",
-	}
-
-	if tz, ok := timezoneMap[abbr]; ok {
-		return tz
+	if zone, ok := tu.abbreviationZone(abbr); ok {
+		return zone.timezone
 	}
 	return tu.defaultTimezone
 }
 
+// abbreviationZone resolves abbr to a zone. Overloaded abbreviations prefer the
+// default timezone, then a zone in the configured region, then the most common reading.
+func (tu *TimezoneUtil) abbreviationZone(abbr string) (abbreviationZone, bool) {
+	candidates := timezoneAbbreviations[strings.ToUpper(abbr)]
+	if len(candidates) == 0 {
+		return abbreviationZone{}, false
+	}
+
+	for _, zone := range candidates {
+		if zone.timezone == tu.defaultTimezone {
+			return zone, true
+		}
+	}
+	if tu.region != "" {
+		for _, zone := range candidates {
+			if zone.region == tu.region {
+				return zone, true
+			}
+		}
+	}
+	return candidates[0], true
+}
+
+// fractionalOffsetTimezones maps UTC offsets that are not whole hours, which have no
+// Etc/GMT zone, to a representative timezone
+var fractionalOffsetTimezones = map[int]string{
+	-(3*3600 + 1800): "America/St_Johns",
+	3*3600 + 1800:    "Asia/Tehran",
+	4*3600 + 1800:    "Asia/Kabul",
+	5*3600 + 1800:    "Asia/Kolkata",
+	5*3600 + 2700:    "Asia/Kathmandu",
+	6*3600 + 1800:    "Asia/Yangon",
+	9*3600 + 1800:    "Australia/Darwin",
+}
+
+// TimezoneForOffset returns an IANA timezone that has the given UTC offset, in seconds,
+// at t. The default timezone is preferred when it matches; otherwise whole hours map to
+// the fixed Etc/GMT zones. It returns "" for offsets it cannot map.
+func (tu *TimezoneUtil) TimezoneForOffset(offset int, t time.Time) string {
+	if loc, err := time.LoadLocation(tu.defaultTimezone); err == nil {
+		if _, defaultOffset := t.In(loc).Zone(); defaultOffset == offset {
+			return tu.defaultTimezone
+		}
+	}
+
+	if offset == 0 {
+		return "UTC"
+	}
+	if offset%3600 == 0 && offset >= -12*3600 && offset <= 14*3600 {
+		// Etc/GMT zones use POSIX signs: UTC+7 is Etc/GMT-7
+		return fmt.Sprintf("Etc/GMT%+d", -offset/3600)
+	}
+	return fractionalOffsetTimezones[offset]
+}
+
 // AdjustTimeToWorkingHours adjusts time to fall within working hours
 func (tu *TimezoneUtil) AdjustTimeToWorkingHours(t time.Time, workingHours *GoogleWorkingHours) time.Time {
 	if workingHours == nil {
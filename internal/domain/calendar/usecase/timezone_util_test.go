@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+func TestTimezoneUtil_GuessTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		tzUtil   *TimezoneUtil
+		abbr     string
+		expected string
+	}{
+		{name: "central european summer time", tzUtil: NewTimezoneUtil("UTC"), abbr: "CEST", expected: "Europe/Paris"},
+		{name: "lower case", tzUtil: NewTimezoneUtil("UTC"), abbr: "aedt", expected: "Australia/Sydney"},
+		{name: "new zealand", tzUtil: NewTimezoneUtil("UTC"), abbr: "NZST", expected: "Pacific/Auckland"},
+		{name: "unknown abbreviation uses the default", tzUtil: NewTimezoneUtil("Asia/Ho_Chi_Minh"), abbr: "XYZ", expected: "Asia/Ho_Chi_Minh"},
+		{name: "ambiguous IST defaults to India", tzUtil: NewTimezoneUtil("UTC"), abbr: "IST", expected: "Asia/Kolkata"},
+		{name: "ambiguous IST resolved by region", tzUtil: NewTimezoneUtil("UTC", WithRegion("il")), abbr: "IST", expected: "Asia/Jerusalem"},
+		{name: "ambiguous IST resolved by default timezone", tzUtil: NewTimezoneUtil("Europe/Dublin", WithRegion("IL")), abbr: "IST", expected: "Europe/Dublin"},
+		{name: "region without a matching zone", tzUtil: NewTimezoneUtil("UTC", WithRegion("FR")), abbr: "CST", expected: "America/Chicago"},
+		{name: "ICT in Vietnam", tzUtil: NewTimezoneUtil("Asia/Ho_Chi_Minh"), abbr: "ICT", expected: "Asia/Ho_Chi_Minh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.tzUtil.GuessTimezone(tt.abbr))
+		})
+	}
+}
+
+func TestTimezoneUtil_AbbreviationsLoad(t *testing.T) {
+	for abbr, zones := range timezoneAbbreviations {
+		for _, zone := range zones {
+			_, err := time.LoadLocation(zone.timezone)
+			assert.NoError(t, err, "%s: %s", abbr, zone.timezone)
+		}
+	}
+}
+
+func TestParseDateTime_AbbreviationRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		tzUtil         *TimezoneUtil
+		text           string
+		expectedZone   string
+		expectedOffset int
+	}{
+		{
+			name:           "CEST in summer",
+			tzUtil:         NewTimezoneUtil("UTC"),
+			text:           "15/07/2025 15:00 CEST",
+			expectedZone:   "Europe/Paris",
+			expectedOffset: 2 * 3600,
+		},
+		{
+			name:           "CET in winter",
+			tzUtil:         NewTimezoneUtil("UTC"),
+			text:           "15/01/2025 15:00 CET",
+			expectedZone:   "Europe/Paris",
+			expectedOffset: 3600,
+		},
+		{
+			name:           "IST in India",
+			tzUtil:         NewTimezoneUtil("UTC"),
+			text:           "15/01/2025 15:00 IST",
+			expectedZone:   "Asia/Kolkata",
+			expectedOffset: 5*3600 + 1800,
+		},
+		{
+			name:           "IST in Israel",
+			tzUtil:         NewTimezoneUtil("UTC", WithRegion("IL")),
+			text:           "15/01/2025 15:00 IST",
+			expectedZone:   "Asia/Jerusalem",
+			expectedOffset: 2 * 3600,
+		},
+		{
+			name:           "IST in Ireland",
+			tzUtil:         NewTimezoneUtil("Europe/Dublin"),
+			text:           "15/07/2025 15:00 IST",
+			expectedZone:   "Europe/Dublin",
+			expectedOffset: 3600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseDateTime(context.Background(), tt.tzUtil, tt.text)
+			require.NoError(t, err)
+
+			assert.Equal(t, 15, parsed.Hour())
+			assert.Equal(t, tt.expectedZone, parsed.Location().String())
+			_, offset := parsed.Zone()
+			assert.Equal(t, tt.expectedOffset, offset)
+		})
+	}
+}
+
+func TestNERService_ExtractDateTime_AbbreviationRegion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(nerResponse{
+			Entities: []Entity{{Text: "15/01/2025 15:00 IST", Label: "DATE", Confidence: 0.9}},
+		})
+	}))
+	defer server.Close()
+
+	ner := NewNERService(server.URL, WithAbbreviationRegion("IL"))
+
+	dates, err := ner.ExtractDateTime(context.Background(), "Call on 15/01/2025 15:00 IST")
+	require.NoError(t, err)
+	require.NotEmpty(t, dates)
+	assert.Equal(t, "Asia/Jerusalem", dates[0].Location().String())
+
+	// The user's own timezone wins over the region
+	ctx := service.WithTimezone(context.Background(), "Asia/Kolkata")
+	dates, err = ner.ExtractDateTime(ctx, "Call on 15/01/2025 15:00 IST")
+	require.NoError(t, err)
+	require.NotEmpty(t, dates)
+	assert.Equal(t, "Asia/Kolkata", dates[0].Location().String())
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// amqpHeaderCarrier adapts AMQP message headers to a propagation.TextMapCarrier
+type amqpHeaderCarrier amqp.Table
+
+var _ propagation.TextMapCarrier = amqpHeaderCarrier(nil)
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	switch v := c[key].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c amqpHeaderCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// textMapPropagator returns the propagator used for message headers, falling
+// back to the global one
+func (s *messagingService) textMapPropagator() propagation.TextMapPropagator {
+	if s.propagator != nil {
+		return s.propagator
+	}
+	return otel.GetTextMapPropagator()
+}
+
+// injectTraceContext writes the trace context of ctx into a new header table
+func (s *messagingService) injectTraceContext(ctx context.Context) amqp.Table {
+	headers := amqp.Table{}
+	s.textMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+	return headers
+}
+
+// extractTraceContext returns ctx with the trace context carried by the headers
+func (s *messagingService) extractTraceContext(ctx context.Context, headers amqp.Table) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return s.textMapPropagator().Extract(ctx, amqpHeaderCarrier(headers))
+}
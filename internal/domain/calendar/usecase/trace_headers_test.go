@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+)
+
+func TestMessagingService_TracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	var processedSpan trace.SpanContext
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Run(func(args mock.Arguments) {
+			processedSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+		}).
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	s := &messagingService{
+		calendar:   calendar,
+		tracer:     provider.Tracer("test"),
+		logger:     logrus.New(),
+		propagator: propagation.TraceContext{},
+	}
+
+	// Publisher side: the headers written on publish carry the request's trace
+	publishCtx, publishSpan := s.tracer.Start(context.Background(), "PublishEmailEvent")
+	headers := s.injectTraceContext(publishCtx)
+	publishSpan.End()
+	require.NotEmpty(t, headers["traceparent"])
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+
+	// Consumer side starts from a fresh context, as the consumer goroutine does
+	s.handleDelivery(context.Background(), amqp.Delivery{Headers: headers, Body: body})
+	calendar.AssertExpectations(t)
+
+	var consumed sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "ProcessMessage" {
+			consumed = span
+		}
+	}
+	require.NotNil(t, consumed)
+
+	publisherTrace := publishSpan.SpanContext().TraceID()
+	assert.Equal(t, publisherTrace, consumed.SpanContext().TraceID())
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), consumed.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindConsumer, consumed.SpanKind())
+	assert.Equal(t, publisherTrace, processedSpan.TraceID())
+}
+
+func TestMessagingService_handleDelivery_WithoutTraceHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	s := &messagingService{
+		calendar:   calendar,
+		tracer:     provider.Tracer("test"),
+		logger:     logrus.New(),
+		propagator: propagation.TraceContext{},
+	}
+
+	body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+	require.NoError(t, err)
+	s.handleDelivery(context.Background(), amqp.Delivery{Body: body})
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	assert.True(t, ended[0].SpanContext().IsValid())
+	assert.False(t, ended[0].Parent().IsValid())
+}
+
+func TestMessagingService_PublishEmailEvent_TraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	calendar := new(mockDomainCalendarService)
+	calendar.On("ProcessEmailToCalendar", mock.Anything, "email").
+		Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+	channel := &fakeQueueChannel{}
+	s := &messagingService{
+		channel:    channel,
+		config:     QueueConfig{EmailQueueName: "emails"},
+		calendar:   calendar,
+		tracer:     provider.Tracer("test"),
+		logger:     logrus.New(),
+		propagator: propagation.TraceContext{},
+	}
+
+	require.NoError(t, s.PublishEmailEvent(context.Background(), "email", "user-1"))
+	require.Len(t, channel.published["emails"], 1)
+	published := channel.published["emails"][0]
+
+	s.handleDelivery(context.Background(), amqp.Delivery{Headers: published.Headers, Body: published.Body})
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "PublishEmailEvent")
+	require.Contains(t, spans, "ProcessMessage")
+
+	publish, consume := spans["PublishEmailEvent"], spans["ProcessMessage"]
+	assert.Equal(t, trace.SpanKindProducer, publish.SpanKind())
+	assert.Equal(t, publish.SpanContext().TraceID(), consume.SpanContext().TraceID())
+	assert.Equal(t, publish.SpanContext().SpanID(), consume.Parent().SpanID())
+}
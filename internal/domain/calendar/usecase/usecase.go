@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	calendarPb "mail2calendar/internal/domain/calendar/proto"
 )
@@ -9,8 +10,25 @@ import (
 // CalendarUseCase defines the interface for calendar operations
 type CalendarUseCase interface {
 	CreateEvent(ctx context.Context, event *calendarPb.Event, userID string) (*calendarPb.Event, error)
+	// CreateEvents creates up to MaxBatchCreateEvents events. Each event has its own
+	// result, in request order, and failures don't fail the batch.
+	CreateEvents(ctx context.Context, events []*calendarPb.Event, userID string) ([]EventCreateResult, error)
 	UpdateEvent(ctx context.Context, event *calendarPb.Event, userID string) (*calendarPb.Event, error)
 	DeleteEvent(ctx context.Context, eventID string, userID string) error
+	// GetEvent returns an event from the calendar of the user, failing with
+	// codes.NotFound if the calendar has no such event
 	GetEvent(ctx context.Context, eventID string, userID string) (*calendarPb.Event, error)
-	ListEvents(ctx context.Context, userID string, startTime int64, endTime int64, calendarID string, pageSize int32, pageToken string) ([]*calendarPb.Event, string, error)
+	// RespondToEvent accepts, declines or tentatively accepts the invitation of the
+	// user to an event
+	RespondToEvent(ctx context.Context, eventID string, response EventResponse, userID string) error
+	ListEvents(ctx context.Context, userID string, startTime int64, endTime int64, calendarID string, pageSize int32, pageToken string, sortBy EventSort) ([]*calendarPb.Event, string, error)
+	// SearchEvents returns the events within timeRange whose title, description or
+	// location contains every word of query, ignoring case. A zero start or end time
+	// defaults to the ListEvents window, from now to 30 days later.
+	SearchEvents(ctx context.Context, query string, timeRange TimeRange, userID string) ([]*calendarPb.Event, error)
+	// RequestEventConfirmation stores the event as pending and returns a confirmation
+	// token instead of creating it
+	RequestEventConfirmation(ctx context.Context, event *calendarPb.Event, userID string) (string, time.Time, error)
+	// ConfirmEvent creates the pending event stored under the confirmation token
+	ConfirmEvent(ctx context.Context, token string) (*calendarPb.Event, error)
 }
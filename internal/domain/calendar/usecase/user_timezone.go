@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// UserTimezoneStore returns the preferred IANA timezone of users
+type UserTimezoneStore interface {
+	// UserTimezone returns the user's timezone, or "" when the user has not set one
+	UserTimezone(ctx context.Context, userID string) (string, error)
+}
+
+// sqlUserTimezoneStore reads the timezone column of the users table
+type sqlUserTimezoneStore struct {
+	db *sql.DB
+}
+
+// NewSQLUserTimezoneStore creates a UserTimezoneStore backed by the users table
+func NewSQLUserTimezoneStore(db *sql.DB) UserTimezoneStore {
+	return &sqlUserTimezoneStore{db: db}
+}
+
+func (s *sqlUserTimezoneStore) UserTimezone(ctx context.Context, userID string) (string, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	var timezone sql.NullString
+	err = s.db.QueryRowContext(ctx, `SELECT timezone FROM users WHERE id = $1`, id).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load user timezone: %w", err)
+	}
+
+	return timezone.String, nil
+}
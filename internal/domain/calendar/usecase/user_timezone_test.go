@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	calendarPb "mail2calendar/internal/domain/calendar/proto"
+	"mail2calendar/internal/domain/calendar/service"
+)
+
+type userTimezoneStoreFunc func(ctx context.Context, userID string) (string, error)
+
+func (f userTimezoneStoreFunc) UserTimezone(ctx context.Context, userID string) (string, error) {
+	return f(ctx, userID)
+}
+
+func TestParseDateTime_UserTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tzUtil, err := NewUserTimezoneUtil("America/New_York")
+	require.NoError(t, err)
+
+	parsed, err := parseDateTime(context.Background(), tzUtil, "3pm")
+	require.NoError(t, err)
+
+	now := time.Now().In(newYork)
+	expected := time.Date(now.Year(), now.Month(), now.Day(), 15, 0, 0, 0, newYork)
+	assert.True(t, expected.Equal(parsed), "expected %v, got %v", expected, parsed)
+	_, offset := parsed.Zone()
+	_, expectedOffset := expected.Zone()
+	assert.Equal(t, expectedOffset, offset)
+
+	_, err = NewUserTimezoneUtil("Mars/Olympus_Mons")
+	assert.Error(t, err)
+}
+
+func TestNERService_ExtractDateTime_UserTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(nerResponse{
+			Entities: []Entity{{Text: "3pm", Label: "TIME", Confidence: 0.9}},
+		})
+	}))
+	defer server.Close()
+
+	ner := NewNERService(server.URL)
+
+	tests := []struct {
+		name     string
+		timezone string
+		location *time.Location
+	}{
+		{name: "user timezone", timezone: "America/New_York", location: newYork},
+		{name: "no timezone uses the server zone", location: time.Local},
+		{name: "invalid timezone uses the server zone", timezone: "Not/A_Zone", location: time.Local},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timezone != "" {
+				ctx = service.WithTimezone(ctx, tt.timezone)
+			}
+
+			dates, err := ner.ExtractDateTime(ctx, "Call at 3pm")
+			require.NoError(t, err)
+			require.NotEmpty(t, dates)
+
+			now := time.Now().In(tt.location)
+			expected := time.Date(now.Year(), now.Month(), now.Day(), 15, 0, 0, 0, tt.location)
+			assert.True(t, expected.Equal(dates[0]), "expected %v, got %v", expected, dates[0])
+		})
+	}
+}
+
+func TestSQLUserTimezoneStore_UserTimezone(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    string
+		mockSetup func(mock sqlmock.Sqlmock)
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:   "user with timezone",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT timezone FROM users WHERE id = \$1`).
+					WithArgs(uint64(42)).
+					WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow("America/New_York"))
+			},
+			expected: "America/New_York",
+		},
+		{
+			name:   "user without timezone",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT timezone FROM users`).
+					WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow(nil))
+			},
+		},
+		{
+			name:   "unknown user",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT timezone FROM users`).WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name:   "database error",
+			userID: "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT timezone FROM users`).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "non-numeric user ID",
+			userID:    "user-1",
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			timezone, err := NewSQLUserTimezoneStore(db).UserTimezone(context.Background(), tt.userID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, timezone)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMessagingService_handleDelivery_UserTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    UserTimezoneStore
+		expected string
+	}{
+		{
+			name: "user timezone is added to the context",
+			store: userTimezoneStoreFunc(func(_ context.Context, userID string) (string, error) {
+				assert.Equal(t, "user-1", userID)
+				return "America/New_York", nil
+			}),
+			expected: "America/New_York",
+		},
+		{
+			name: "user without timezone",
+			store: userTimezoneStoreFunc(func(context.Context, string) (string, error) {
+				return "", nil
+			}),
+		},
+		{
+			name: "store error does not stop processing",
+			store: userTimezoneStoreFunc(func(context.Context, string) (string, error) {
+				return "", errors.New("connection refused")
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := new(mockDomainCalendarService)
+			calendar.On("ProcessEmailToCalendar", mock.MatchedBy(func(ctx context.Context) bool {
+				return service.TimezoneFromContext(ctx) == tt.expected
+			}), "email").Return(&calendarPb.CreateEventResponseV2{EventID: "evt-1"}, nil)
+
+			s := &messagingService{
+				calendar: calendar,
+				tracer:   otel.Tracer("test"),
+				logger:   logrus.New(),
+			}
+			WithUserTimezones(tt.store)(s)
+
+			body, err := json.Marshal(EmailMessage{EmailContent: "email", UserID: "user-1"})
+			require.NoError(t, err)
+
+			s.handleDelivery(context.Background(), amqp.Delivery{Body: body})
+			calendar.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/oauth2"
+
+	"mail2calendar/internal/domain/calendar/logger"
+)
+
+// newGoogleTestService returns a Google calendar whose API requests go to handler
+func newGoogleTestService(handler http.HandlerFunc, opts ...GoogleCalendarOption) (GoogleCalendarService, context.Context) {
+	l, _ := logger.New(nil)
+	store := new(mockTokenStore)
+	store.On("GetToken", mock.Anything, "user-1").Return(&oauth2.Token{
+		AccessToken: "google-token",
+		Expiry:      time.Now().Add(time.Hour),
+	}, nil)
+
+	oauth := &OAuthConfig{config: &oauth2.Config{}, tokenStore: store, logger: l}
+	svc := NewGoogleCalendarService(oauth, noop.NewTracerProvider().Tracer("test"), "user-1", opts...)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: &graphTransport{handler: handler},
+	})
+	return svc, ctx
+}
+
+func TestGoogleCalendarService_GetWorkingHours_TimeRange(t *testing.T) {
+	var query map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/calendar/v3/users/me/settings":
+			_, _ = w.Write([]byte(`{"items":[{"id":"timezone","value":"Asia/Ho_Chi_Minh"}]}`))
+		case "/calendar/v3/freeBusy":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+			_, _ = w.Write([]byte(`{"calendars":{"alice@example.com":{"busy":[]}}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	svc, ctx := newGoogleTestService(handler)
+
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	hours, err := svc.GetWorkingHours(ctx, TimeRange{StartTime: start, EndTime: start.AddDate(0, 0, 5)}, []string{"alice@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-03-10T00:00:00Z", query["timeMin"])
+	assert.Equal(t, "2025-03-15T00:00:00Z", query["timeMax"])
+	require.Contains(t, hours, "alice@example.com")
+	assert.Equal(t, "Asia/Ho_Chi_Minh", hours["alice@example.com"].TimeZone)
+}
+
+func TestGoogleCalendarService_GetWorkingHours_Baseline(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/calendar/v3/users/me/settings":
+			_, _ = w.Write([]byte(`{"items":[{"id":"timezone","value":"Asia/Dubai"}]}`))
+		case "/calendar/v3/freeBusy":
+			_, _ = w.Write([]byte(`{"calendars":{"alice@example.com":{"busy":[]}}}`))
+		}
+	}
+	clock := func(hour int) time.Time {
+		return time.Date(0, 0, 0, hour, 0, 0, 0, time.Local)
+	}
+	schedule := func(start, end int, days ...time.Weekday) []GoogleWeeklySchedule {
+		schedules := make([]GoogleWeeklySchedule, len(days))
+		for i, day := range days {
+			schedules[i] = GoogleWeeklySchedule{DayOfWeek: day, StartTime: clock(start), EndTime: clock(end)}
+		}
+		return schedules
+	}
+
+	tests := []struct {
+		name     string
+		opts     []GoogleCalendarOption
+		expected []GoogleWeeklySchedule
+	}{
+		{
+			name:     "default Monday to Friday, 9 to 5",
+			expected: schedule(9, 17, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+		},
+		{
+			name: "Sunday to Thursday, 10 to 6",
+			opts: []GoogleCalendarOption{
+				WithWorkingDays(time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Sunday),
+				WithWorkingHours(10*time.Hour, 18*time.Hour),
+			},
+			expected: schedule(10, 18, time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday),
+		},
+		{
+			name: "invalid configuration keeps the defaults",
+			opts: []GoogleCalendarOption{
+				WithWorkingDays(),
+				WithWorkingHours(18*time.Hour, 10*time.Hour),
+			},
+			expected: schedule(9, 17, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, ctx := newGoogleTestService(handler, tt.opts...)
+
+			start := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+			hours, err := svc.GetWorkingHours(ctx, TimeRange{StartTime: start, EndTime: start.AddDate(0, 0, 7)}, []string{"alice@example.com"})
+			require.NoError(t, err)
+			require.Contains(t, hours, "alice@example.com")
+			assert.Equal(t, tt.expected, hours["alice@example.com"].Schedule)
+		})
+	}
+}
+
+func TestOutlookCalendarService_GetWorkingHours_TimeRange(t *testing.T) {
+	var req graphScheduleRequest
+	svc, ctx := newOutlookTestService(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1.0/me/calendar/getSchedule", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_, _ = w.Write([]byte(`{"value":[]}`))
+	})
+
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.FixedZone("ICT", 7*60*60))
+	_, err := svc.GetWorkingHours(ctx, TimeRange{
+		StartTime: start,
+		EndTime:   start.AddDate(0, 0, 5),
+		Duration:  15 * time.Minute,
+	}, []string{"alice@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, graphDateTimeZone{DateTime: "2025-03-10T02:00:00", TimeZone: "UTC"}, req.StartTime)
+	assert.Equal(t, graphDateTimeZone{DateTime: "2025-03-15T02:00:00", TimeZone: "UTC"}, req.EndTime)
+	assert.Equal(t, 15, req.AvailabilityViewInterval)
+}
+
+func TestWorkingHoursRange(t *testing.T) {
+	before := time.Now()
+	start, end, err := workingHoursRange(TimeRange{})
+	require.NoError(t, err)
+	assert.WithinDuration(t, before, start, time.Second)
+	assert.Equal(t, 7*24*time.Hour, end.Sub(start))
+
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	start, end, err = workingHoursRange(TimeRange{StartTime: from})
+	require.NoError(t, err)
+	assert.Equal(t, from, start)
+	assert.Equal(t, from.AddDate(0, 0, 7), end)
+
+	_, _, err = workingHoursRange(TimeRange{StartTime: from, EndTime: from.Add(-time.Hour)})
+	assert.Error(t, err)
+}
+
+func TestAvailabilityViewInterval(t *testing.T) {
+	assert.Equal(t, 60, availabilityViewInterval(0))
+	assert.Equal(t, 5, availabilityViewInterval(time.Minute))
+	assert.Equal(t, 30, availabilityViewInterval(30*time.Minute))
+	assert.Equal(t, 1440, availabilityViewInterval(48*time.Hour))
+}
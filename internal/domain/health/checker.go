@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Checker verifies that a dependency is reachable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts an ordinary function to a Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// RedisChecker pings the Redis server
+func RedisChecker(client *redis.Client) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// RabbitMQChecker opens and closes a channel on the broker connection
+func RabbitMQChecker(conn *amqp.Connection) Checker {
+	return CheckerFunc(func(context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection closed")
+		}
+		ch, err := conn.Channel()
+		if err != nil {
+			return err
+		}
+		return ch.Close()
+	})
+}
+
+// MinIOChecker verifies that the attachment bucket is reachable
+func MinIOChecker(client *minio.Client, bucket string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		exists, err := client.BucketExists(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("bucket %s does not exist", bucket)
+		}
+		return nil
+	})
+}
+
+// GRPCHealthChecker asks the standard gRPC health service on conn, such as the one of
+// the NER service, whether service is SERVING
+func GRPCHealthChecker(conn grpc.ClientConnInterface, service string) Checker {
+	client := healthpb.NewHealthClient(conn)
+	return CheckerFunc(func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// HTTPChecker sends a HEAD request to url, such as the Google Calendar API, and fails
+// if it cannot be reached or answers with a server error. Client errors such as 401
+// still prove the service is reachable.
+func HTTPChecker(client *http.Client, url string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	})
+}
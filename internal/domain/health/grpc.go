@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultGRPCCheckInterval is how often the gRPC health statuses are refreshed
+const defaultGRPCCheckInterval = 10 * time.Second
+
+// GRPCServer is the standard gRPC health service backed by Readiness. Each gRPC
+// service is SERVING only while the dependencies it needs are up, and the server as a
+// whole, the service "", only while every dependency is up.
+//
+// The server only serves HTTP for now. Whatever builds the gRPC server registers
+// this on it with RegisterGRPCService and keeps it current with Run.
+type GRPCServer struct {
+	*grpchealth.Server
+	useCase UseCase
+	// services maps each gRPC service name, e.g. "calendar.CalendarService", to the
+	// names of the checkers it depends on
+	services map[string][]string
+}
+
+// NewGRPCServer creates a gRPC health service reporting services, which map a gRPC
+// service name to the checkers it depends on. Everything is NOT_SERVING until the
+// first Update.
+func NewGRPCServer(useCase UseCase, services map[string][]string) *GRPCServer {
+	s := &GRPCServer{
+		Server:   grpchealth.NewServer(),
+		useCase:  useCase,
+		services: services,
+	}
+
+	s.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for service := range services {
+		s.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return s
+}
+
+// Update runs the readiness checks once and sets the status of every service
+func (s *GRPCServer) Update(ctx context.Context) {
+	report := s.useCase.Readiness(ctx)
+
+	s.SetServingStatus("", servingStatus(report.Healthy()))
+	for service, dependencies := range s.services {
+		up := true
+		for _, dependency := range dependencies {
+			if report.Dependencies[dependency].Status != StatusUp {
+				up = false
+				break
+			}
+		}
+		s.SetServingStatus(service, servingStatus(up))
+	}
+}
+
+// Run updates the statuses every interval, or every 10 seconds if interval is not
+// positive, until ctx is done. Watchers are then told the server is shutting down.
+func (s *GRPCServer) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultGRPCCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Update(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RegisterGRPCService registers the health service on server
+func RegisterGRPCService(server grpc.ServiceRegistrar, s *GRPCServer) {
+	healthpb.RegisterHealthServer(server, s)
+}
+
+func servingStatus(up bool) healthpb.HealthCheckResponse_ServingStatus {
+	if up {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
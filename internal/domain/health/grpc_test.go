@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newHealthClient serves s over an in-memory connection and returns a client for it
+func newHealthClient(t *testing.T, s *GRPCServer) healthpb.HealthClient {
+	return healthpb.NewHealthClient(newHealthConn(t, s))
+}
+
+// newHealthConn serves s over an in-memory connection and returns the connection to it
+func newHealthConn(t *testing.T, s *GRPCServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	RegisterGRPCService(srv, s)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestGRPCServer_Update(t *testing.T) {
+	tests := []struct {
+		name     string
+		redisErr error
+		nerErr   error
+		expected map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name: "all dependencies up",
+			expected: map[string]healthpb.HealthCheckResponse_ServingStatus{
+				"":                         healthpb.HealthCheckResponse_SERVING,
+				"calendar.CalendarService": healthpb.HealthCheckResponse_SERVING,
+				"ner.NERService":           healthpb.HealthCheckResponse_SERVING,
+			},
+		},
+		{
+			name:     "redis down",
+			redisErr: errors.New("connection refused"),
+			expected: map[string]healthpb.HealthCheckResponse_ServingStatus{
+				"":                         healthpb.HealthCheckResponse_NOT_SERVING,
+				"calendar.CalendarService": healthpb.HealthCheckResponse_NOT_SERVING,
+				"ner.NERService":           healthpb.HealthCheckResponse_SERVING,
+			},
+		},
+		{
+			name:   "ner down",
+			nerErr: errors.New("model not loaded"),
+			expected: map[string]healthpb.HealthCheckResponse_ServingStatus{
+				"":                         healthpb.HealthCheckResponse_NOT_SERVING,
+				"calendar.CalendarService": healthpb.HealthCheckResponse_SERVING,
+				"ner.NERService":           healthpb.HealthCheckResponse_NOT_SERVING,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("Readiness").Return(nil)
+
+			useCase := New(mockRepo,
+				WithChecker("redis", CheckerFunc(func(context.Context) error { return tt.redisErr })),
+				WithChecker("ner", CheckerFunc(func(context.Context) error { return tt.nerErr })),
+			)
+			s := NewGRPCServer(useCase, map[string][]string{
+				"calendar.CalendarService": {"database", "redis"},
+				"ner.NERService":           {"ner"},
+			})
+			client := newHealthClient(t, s)
+
+			s.Update(context.Background())
+
+			for service, expected := range tt.expected {
+				resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+				require.NoError(t, err)
+				assert.Equal(t, expected, resp.Status, "service %q", service)
+			}
+		})
+	}
+}
+
+func TestGRPCServer_NotServingBeforeUpdate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	s := NewGRPCServer(New(mockRepo), map[string][]string{"calendar.CalendarService": {"database"}})
+	client := newHealthClient(t, s)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "calendar.CalendarService"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	mockRepo.AssertNotCalled(t, "Readiness")
+}
+
+func TestGRPCHealthChecker(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("Readiness").Return(errors.New("database connection error"))
+	s := NewGRPCServer(New(mockRepo), map[string][]string{"ner.NERService": nil})
+	s.Update(context.Background())
+
+	conn := newHealthConn(t, s)
+
+	assert.NoError(t, GRPCHealthChecker(conn, "ner.NERService").Check(context.Background()))
+	assert.Error(t, GRPCHealthChecker(conn, "").Check(context.Background()))
+}
@@ -26,17 +26,34 @@ func (h *Handler) Health(w http.ResponseWriter, _ *http.Request) {
 	respond.Json(w, http.StatusOK, map[string]int{"status": 200})
 }
 
-// Readiness checks if database is alive
-// @Summary Checks if both API and Database are up
-// @Description Hits this API to see if both API and Database are running in the server
+// Liveness checks if the process is up without touching any dependency, so a
+// briefly unavailable dependency does not get the pod restarted
+// @Summary Checks if API process is alive
+// @Description Hits this API to see if the API process responds. Dependencies are not checked, use readiness for that.
 // @Success 200
-// @Failure 500
+// @router /api/health/liveness [get]
+func (h *Handler) Liveness(w http.ResponseWriter, _ *http.Request) {
+	respond.Json(w, http.StatusOK, map[string]int{"status": 200})
+}
+
+// readinessResponse lists the status of each dependency
+type readinessResponse struct {
+	Status       int                         `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Readiness checks if the database and all other dependencies are reachable
+// @Summary Checks if API and all of its dependencies are up
+// @Description Hits this API to see if the API, Database, and other registered dependencies such as Redis, RabbitMQ, and MinIO are running. Returns 503 if any of them is down.
+// @Success 200
+// @Failure 503
 // @router /api/health/readiness [get]
-func (h *Handler) Readiness(w http.ResponseWriter, _ *http.Request) {
-	err := h.useCase.Readiness()
-	if err != nil {
-		respond.Error(w, http.StatusInternalServerError, err)
-		return
+func (h *Handler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.useCase.Readiness(r.Context())
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
 	}
-	respond.Json(w, http.StatusOK, map[string]int{"status": 200})
+	respond.Json(w, status, readinessResponse{Status: status, Dependencies: report.Dependencies})
 }
@@ -1,12 +1,14 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,9 +18,9 @@ type MockUseCase struct {
 	mock.Mock
 }
 
-func (m *MockUseCase) Readiness() error {
-	args := m.Called()
-	return args.Error(0)
+func (m *MockUseCase) Readiness(ctx context.Context) Report {
+	args := m.Called(ctx)
+	return args.Get(0).(Report)
 }
 
 func TestNewHandler(t *testing.T) {
@@ -45,31 +47,54 @@ func TestHandler_Health(t *testing.T) {
 	assert.Equal(t, 200, response["status"])
 }
 
+func TestHandler_Liveness(t *testing.T) {
+	// The use case must not be called, a liveness probe never checks dependencies
+	mockUseCase := new(MockUseCase)
+	handler := NewHandler(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health/liveness", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Liveness(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response map[string]int
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response["status"])
+
+	mockUseCase.AssertNotCalled(t, "Readiness", mock.Anything)
+}
+
 func TestHandler_Readiness(t *testing.T) {
 	tests := []struct {
 		name           string
-		mockError      error
+		report         Report
 		expectedStatus int
-		expectedBody   map[string]int
 	}{
 		{
-			name:           "successful readiness check",
-			mockError:      nil,
+			name: "successful readiness check",
+			report: Report{Dependencies: map[string]DependencyStatus{
+				"database": {Status: StatusUp},
+				"redis":    {Status: StatusUp},
+			}},
 			expectedStatus: http.StatusOK,
-			expectedBody:   map[string]int{"status": 200},
 		},
 		{
-			name:           "database error",
-			mockError:      errors.New("database connection error"),
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   nil,
+			name: "database error",
+			report: Report{Dependencies: map[string]DependencyStatus{
+				"database": {Status: StatusDown, Error: "database connection error"},
+				"redis":    {Status: StatusUp},
+			}},
+			expectedStatus: http.StatusServiceUnavailable,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUseCase := new(MockUseCase)
-			mockUseCase.On("Readiness").Return(tt.mockError)
+			mockUseCase.On("Readiness", mock.Anything).Return(tt.report)
 
 			handler := NewHandler(mockUseCase)
 
@@ -80,14 +105,40 @@ func TestHandler_Readiness(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, rec.Code)
 
-			if tt.expectedBody != nil {
-				var response map[string]int
-				err := json.NewDecoder(rec.Body).Decode(&response)
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedBody, response)
-			}
+			var response readinessResponse
+			err := json.NewDecoder(rec.Body).Decode(&response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Equal(t, tt.report.Dependencies, response.Dependencies)
 
 			mockUseCase.AssertExpectations(t)
 		})
 	}
 }
+
+func TestHandler_Readiness_RedisDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	mr.Close()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("Readiness").Return(nil)
+
+	handler := NewHandler(New(mockRepo, WithChecker("redis", RedisChecker(client))))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health/readiness", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Readiness(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var response readinessResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, http.StatusServiceUnavailable, response.Status)
+	assert.Equal(t, DependencyStatus{Status: StatusUp}, response.Dependencies["database"])
+	assert.Equal(t, StatusDown, response.Dependencies["redis"].Status)
+	assert.NotEmpty(t, response.Dependencies["redis"].Error)
+}
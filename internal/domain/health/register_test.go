@@ -2,10 +2,12 @@ package health
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestRegisterHTTPEndPoints(t *testing.T) {
@@ -21,6 +23,7 @@ func TestRegisterHTTPEndPoints(t *testing.T) {
 		path   string
 	}{
 		{http.MethodGet, "/api/health"},
+		{http.MethodGet, "/api/health/liveness"},
 		{http.MethodGet, "/api/health/readiness"},
 	}
 
@@ -37,3 +40,26 @@ func TestRegisterHTTPEndPoints(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestRegisterHTTPEndPoints_Probes(t *testing.T) {
+	mockUseCase := new(MockUseCase)
+	mockUseCase.On("Readiness", mock.Anything).Return(Report{Dependencies: map[string]DependencyStatus{
+		"database": {Status: StatusUp},
+		"redis":    {Status: StatusDown, Error: "connection refused"},
+	}})
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(router, mockUseCase)
+
+	// Redis being down makes the pod unready but must not fail liveness
+	for path, expected := range map[string]int{
+		"/api/health/liveness":  http.StatusOK,
+		"/api/health/readiness": http.StatusServiceUnavailable,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, expected, rec.Code, path)
+	}
+
+	mockUseCase.AssertNumberOfCalls(t, "Readiness", 1)
+}
@@ -1,19 +1,124 @@
 package health
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// StatusUp means the dependency answered its check
+	StatusUp = "up"
+	// StatusDown means the dependency check failed or timed out
+	StatusDown = "down"
+
+	databaseChecker     = "database"
+	defaultCheckTimeout = 2 * time.Second
+)
+
+var errCheckTimeout = errors.New("check timed out")
+
 type UseCase interface {
-	Readiness() error
+	Readiness(ctx context.Context) Report
+}
+
+// DependencyStatus is the outcome of a single dependency check
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report holds the status of every registered dependency
+type Report struct {
+	Dependencies map[string]DependencyStatus
+}
+
+// Healthy reports whether all dependencies are up
+func (r Report) Healthy() bool {
+	for _, dependency := range r.Dependencies {
+		if dependency.Status != StatusUp {
+			return false
+		}
+	}
+	return true
 }
 
 type Health struct {
 	healthRepo Repository
+	checkers   map[string]Checker
+	timeout    time.Duration
+}
+
+// Option configures the health use case
+type Option func(*Health)
+
+// WithChecker registers a dependency checked by Readiness under the given name
+func WithChecker(name string, checker Checker) Option {
+	return func(u *Health) {
+		u.checkers[name] = checker
+	}
 }
 
-func New(health Repository) *Health {
-	return &Health{
+// WithCheckTimeout bounds how long Readiness waits for all checkers
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(u *Health) {
+		if timeout > 0 {
+			u.timeout = timeout
+		}
+	}
+}
+
+func New(health Repository, opts ...Option) *Health {
+	u := &Health{
 		healthRepo: health,
+		checkers: map[string]Checker{
+			databaseChecker: CheckerFunc(func(context.Context) error {
+				return health.Readiness()
+			}),
+		},
+		timeout: defaultCheckTimeout,
 	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
 }
 
-func (u *Health) Readiness() error {
-	return u.healthRepo.Readiness()
+// Readiness runs all checkers concurrently. Checkers that have not answered when the
+// timeout expires are reported as down.
+func (u *Health) Readiness(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	results := make(chan result, len(u.checkers))
+	report := Report{Dependencies: make(map[string]DependencyStatus, len(u.checkers))}
+	for name, checker := range u.checkers {
+		report.Dependencies[name] = DependencyStatus{Status: StatusDown, Error: errCheckTimeout.Error()}
+
+		go func(name string, checker Checker) {
+			results <- result{name: name, err: checker.Check(ctx)}
+		}(name, checker)
+	}
+
+	for range u.checkers {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				report.Dependencies[res.name] = DependencyStatus{Status: StatusDown, Error: res.err.Error()}
+			} else {
+				report.Dependencies[res.name] = DependencyStatus{Status: StatusUp}
+			}
+		case <-ctx.Done():
+			return report
+		}
+	}
+
+	return report
 }
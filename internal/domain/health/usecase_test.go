@@ -1,8 +1,10 @@
 package health
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,19 +29,22 @@ func TestNew(t *testing.T) {
 
 func TestHealth_Readiness(t *testing.T) {
 	tests := []struct {
-		name          string
-		mockError     error
-		expectedError error
+		name             string
+		mockError        error
+		expectedHealthy  bool
+		expectedDatabase DependencyStatus
 	}{
 		{
-			name:          "successful readiness check",
-			mockError:     nil,
-			expectedError: nil,
+			name:             "successful readiness check",
+			mockError:        nil,
+			expectedHealthy:  true,
+			expectedDatabase: DependencyStatus{Status: StatusUp},
 		},
 		{
-			name:          "database error",
-			mockError:     errors.New("database connection error"),
-			expectedError: errors.New("database connection error"),
+			name:             "database error",
+			mockError:        errors.New("database connection error"),
+			expectedHealthy:  false,
+			expectedDatabase: DependencyStatus{Status: StatusDown, Error: "database connection error"},
 		},
 	}
 
@@ -49,16 +54,47 @@ func TestHealth_Readiness(t *testing.T) {
 			mockRepo.On("Readiness").Return(tt.mockError)
 
 			useCase := New(mockRepo)
-			err := useCase.Readiness()
+			report := useCase.Readiness(context.Background())
 
-			if tt.expectedError != nil {
-				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError.Error(), err.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			assert.Equal(t, tt.expectedHealthy, report.Healthy())
+			assert.Equal(t, map[string]DependencyStatus{"database": tt.expectedDatabase}, report.Dependencies)
 
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestHealth_Readiness_Checkers(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("Readiness").Return(nil)
+
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	useCase := New(mockRepo,
+		WithCheckTimeout(50*time.Millisecond),
+		WithChecker("redis", CheckerFunc(func(context.Context) error {
+			return nil
+		})),
+		WithChecker("rabbitmq", CheckerFunc(func(context.Context) error {
+			return errors.New("connection refused")
+		})),
+		// Ignores its context, so only the use case timeout can stop waiting for it
+		WithChecker("minio", CheckerFunc(func(context.Context) error {
+			<-blocked
+			return nil
+		})),
+	)
+
+	start := time.Now()
+	report := useCase.Readiness(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.False(t, report.Healthy())
+	assert.Equal(t, map[string]DependencyStatus{
+		"database": {Status: StatusUp},
+		"redis":    {Status: StatusUp},
+		"rabbitmq": {Status: StatusDown, Error: "connection refused"},
+		"minio":    {Status: StatusDown, Error: errCheckTimeout.Error()},
+	}, report.Dependencies)
+}
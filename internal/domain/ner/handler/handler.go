@@ -12,7 +12,7 @@ import (
 )
 
 type NERUseCase interface {
-	ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error)
+	ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error)
 }
 
 type Handler struct {
@@ -32,6 +32,8 @@ func RegisterRoutes(r chi.Router, uc NERUseCase, rateLimiter *middleware.RedisRa
 
 type extractRequest struct {
 	Text string `json:"text"`
+	// Language is optional; the NER service auto-detects it when empty
+	Language string `json:"language,omitempty"`
 }
 
 func (h *Handler) ExtractEntities(w http.ResponseWriter, r *http.Request) {
@@ -47,7 +49,7 @@ func (h *Handler) ExtractEntities(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entities, err := h.useCase.ExtractEntities(r.Context(), req.Text)
+	entities, err := h.useCase.ExtractEntities(r.Context(), req.Text, req.Language)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
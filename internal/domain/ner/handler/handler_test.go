@@ -25,8 +25,8 @@ type MockNERUseCase struct {
 	mock.Mock
 }
 
-func (m *MockNERUseCase) ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error) {
-	args := m.Called(ctx, text)
+func (m *MockNERUseCase) ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error) {
+	args := m.Called(ctx, text, language)
 	return args.Get(0).(*ner.ExtractResponse), args.Error(1)
 }
 
@@ -44,7 +44,7 @@ func TestHandler_ExtractEntities(t *testing.T) {
 				Text: "test",
 			},
 			setupMock: func(m *MockNERUseCase) {
-				m.On("ExtractEntities", mock.Anything, "test").Return(
+				m.On("ExtractEntities", mock.Anything, "test", "").Return(
 					&ner.ExtractResponse{
 						Entities: []*ner.Entity{
 							{
@@ -61,6 +61,18 @@ func TestHandler_ExtractEntities(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"Entities":[{"Text":"test","Label":"TEST","Start":0,"End":4}]}` + "\n",
 		},
+		{
+			name: "language is passed through",
+			requestBody: extractRequest{
+				Text:     "họp lúc 2 giờ",
+				Language: "vi",
+			},
+			setupMock: func(m *MockNERUseCase) {
+				m.On("ExtractEntities", mock.Anything, "họp lúc 2 giờ", "vi").Return(&ner.ExtractResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"Entities":null}` + "\n",
+		},
 		{
 			name:           "invalid request body",
 			requestBody:    "invalid json",
@@ -17,7 +17,7 @@ type Entity struct {
 }
 
 type NER interface {
-	ExtractEntities(ctx context.Context, text string) ([]*Entity, error)
+	ExtractEntities(ctx context.Context, text string, language string) ([]*Entity, error)
 }
 
 type NERUseCase struct {
@@ -31,14 +31,15 @@ func New(client client.NER) *NERUseCase {
 	}
 }
 
-// ExtractEntities extracts named entities from the given text
-func (uc *NERUseCase) ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error) {
-	return uc.client.ExtractEntities(ctx, text)
+// ExtractEntities extracts named entities from the given text. An empty language
+// lets the NER service auto-detect it.
+func (uc *NERUseCase) ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error) {
+	return uc.client.ExtractEntities(ctx, text, language)
 }
 
 // ExtractEntitiesFromText extracts named entities from the given text and converts to internal format
-func (uc *NERUseCase) ExtractEntitiesFromText(ctx context.Context, text string) ([]*ner.Entity, error) {
-	response, err := uc.client.ExtractEntities(ctx, text)
+func (uc *NERUseCase) ExtractEntitiesFromText(ctx context.Context, text string, language string) ([]*ner.Entity, error) {
+	response, err := uc.client.ExtractEntities(ctx, text, language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract entities: %v", err)
 	}
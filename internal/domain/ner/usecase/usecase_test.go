@@ -16,8 +16,8 @@ type MockNERClient struct {
 	mock.Mock
 }
 
-func (m *MockNERClient) ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error) {
-	args := m.Called(ctx, text)
+func (m *MockNERClient) ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error) {
+	args := m.Called(ctx, text, language)
 	if resp, ok := args.Get(0).(*ner.ExtractResponse); ok {
 		return resp, args.Error(1)
 	}
@@ -79,10 +79,10 @@ func TestNERUseCase_ExtractEntities(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := new(MockNERClient)
-			mockClient.On("ExtractEntities", mock.Anything, tt.text).Return(tt.mockResponse, tt.mockError)
+			mockClient.On("ExtractEntities", mock.Anything, tt.text, "vi").Return(tt.mockResponse, tt.mockError)
 
 			useCase := New(mockClient)
-			response, err := useCase.ExtractEntities(context.Background(), tt.text)
+			response, err := useCase.ExtractEntities(context.Background(), tt.text, "vi")
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -153,10 +153,10 @@ func TestNERUseCase_ExtractEntitiesFromText(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := new(MockNERClient)
-			mockClient.On("ExtractEntities", mock.Anything, tt.text).Return(tt.mockResponse, tt.mockError)
+			mockClient.On("ExtractEntities", mock.Anything, tt.text, "vi").Return(tt.mockResponse, tt.mockError)
 
 			useCase := New(mockClient)
-			entities, err := useCase.ExtractEntitiesFromText(context.Background(), tt.text)
+			entities, err := useCase.ExtractEntitiesFromText(context.Background(), tt.text, "vi")
 
 			if tt.expectedError {
 				assert.Error(t, err)
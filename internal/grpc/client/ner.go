@@ -14,7 +14,7 @@ import (
 
 // NER defines the interface for NER client operations
 type NER interface {
-	ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error)
+	ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error)
 }
 
 type NERClient struct {
@@ -43,10 +43,12 @@ func NewNERClient(cfg *config.Config) (*NERClient, error) {
 	}, nil
 }
 
-// ExtractEntities extracts named entities from text
-func (c *NERClient) ExtractEntities(ctx context.Context, text string) (*ner.ExtractResponse, error) {
+// ExtractEntities extracts named entities from text. Language is an ISO 639-1 code
+// such as "vi" or "en"; when empty the NER service auto-detects it.
+func (c *NERClient) ExtractEntities(ctx context.Context, text string, language string) (*ner.ExtractResponse, error) {
 	req := &pb.ExtractEntitiesRequest{
-		Text: text,
+		Text:     text,
+		Language: language,
 	}
 	resp, err := c.client.ExtractEntities(ctx, req)
 	if err != nil {
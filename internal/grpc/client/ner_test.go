@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "mail2calendar/ner-service/protos/ner"
+)
+
+// recordingNERServer records the requests it receives
+type recordingNERServer struct {
+	pb.UnimplementedNERServiceServer
+	requests []*pb.ExtractEntitiesRequest
+}
+
+func (s *recordingNERServer) ExtractEntities(_ context.Context, req *pb.ExtractEntitiesRequest) (*pb.ExtractEntitiesResponse, error) {
+	s.requests = append(s.requests, req)
+	return &pb.ExtractEntitiesResponse{
+		Entities: []*pb.Entity{{Text: "Hà Nội", Type: "LOC", StartPos: 10, EndPos: 16}},
+	}, nil
+}
+
+func newTestNERClient(t *testing.T, srv pb.NERServiceServer) *NERClient {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterNERServiceServer(s, srv)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	c := &NERClient{client: pb.NewNERServiceClient(conn), connection: conn}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestNERClient_ExtractEntities_Language(t *testing.T) {
+	srv := &recordingNERServer{}
+	c := newTestNERClient(t, srv)
+
+	resp, err := c.ExtractEntities(context.Background(), "Họp tại Hà Nội", "vi")
+	require.NoError(t, err)
+	require.Len(t, resp.Entities, 1)
+	assert.Equal(t, "LOC", resp.Entities[0].Label)
+
+	// An empty language is sent as is so the service auto-detects it
+	_, err = c.ExtractEntities(context.Background(), "Meeting in Hanoi", "")
+	require.NoError(t, err)
+
+	require.Len(t, srv.requests, 2)
+	assert.Equal(t, "Họp tại Hà Nội", srv.requests[0].Text)
+	assert.Equal(t, "vi", srv.requests[0].Language)
+	assert.Empty(t, srv.requests[1].Language)
+}
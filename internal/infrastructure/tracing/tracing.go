@@ -0,0 +1,94 @@
+// Package tracing sets up the OpenTelemetry tracer provider behind otel.Tracer
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Config holds the tracing configuration
+type Config struct {
+	// Enable turns tracing on; when false spans are not recorded
+	Enable bool
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector
+	OTLPEndpoint   string
+	ServiceName    string
+	ServiceVersion string
+	// SamplerRatio is the fraction of new traces that are sampled
+	SamplerRatio float64
+}
+
+// ShutdownFunc flushes the spans not yet exported and stops the tracer provider
+type ShutdownFunc func(ctx context.Context) error
+
+type options struct {
+	exporter sdktrace.SpanExporter
+}
+
+// Option configures optional behaviour of Init
+type Option func(*options)
+
+// WithExporter exports spans to exporter instead of the OTLP collector
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(o *options) {
+		o.exporter = exporter
+	}
+}
+
+// Init installs a tracer provider exporting to the OTLP collector of cfg as the global
+// one, so that spans of otel.Tracer are exported. Call the returned ShutdownFunc before
+// exiting, or spans still batched are lost. When tracing is disabled, Init installs
+// nothing and the ShutdownFunc does nothing.
+func Init(ctx context.Context, cfg Config, opts ...Option) (ShutdownFunc, error) {
+	if !cfg.Enable {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	exporter := o.exporter
+	if exporter == nil {
+		var err error
+		exporter, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplerRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down tracer provider: %w", err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// stubExporter keeps the spans exported to it, including after shutdown
+type stubExporter struct {
+	mu       sync.Mutex
+	spans    []sdktrace.ReadOnlySpan
+	shutdown bool
+}
+
+func (e *stubExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *stubExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+func TestInit_ExportsSpans(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	exporter := &stubExporter{}
+	shutdown, err := Init(context.Background(), Config{
+		Enable:         true,
+		ServiceName:    "mail2calendar",
+		ServiceVersion: "1.0.0",
+		SamplerRatio:   1,
+	}, WithExporter(exporter))
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "ProcessMessage")
+	span.End()
+
+	// Spans are batched until the provider is shut down
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.True(t, exporter.shutdown)
+	require.Len(t, exporter.spans, 1)
+	assert.Equal(t, "ProcessMessage", exporter.spans[0].Name())
+	assert.Contains(t, exporter.spans[0].Resource().Attributes(), semconv.ServiceName("mail2calendar"))
+}
+
+func TestInit_Disabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), Config{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestInit_OTLPExporter(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	// The exporter connects lazily, so no collector is needed to start and stop
+	shutdown, err := Init(context.Background(), Config{
+		Enable:       true,
+		OTLPEndpoint: "localhost:4317",
+		ServiceName:  "mail2calendar",
+		SamplerRatio: 0.1,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
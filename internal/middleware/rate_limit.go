@@ -2,30 +2,93 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/gmhafiz/scs/v2"
 	"github.com/go-redis/redis/v8"
 )
 
+// slidingWindowScript ghi nhận request vào sorted set của key theo thời điểm (micro giây).
+// Các request đã ra khỏi cửa sổ bị xoá trước, request mới chỉ được thêm khi còn hạn mức nên
+// request bị từ chối không kéo dài thời gian chặn. Trả về {allowed, count, oldest}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, math.ceil(window / 1000))
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	return {allowed, count, tonumber(oldest[2])}
+end
+return {allowed, count, now}
+`)
+
+// RedisRateLimiter giới hạn số request theo cửa sổ trượt: tại mọi thời điểm, số request được
+// chấp nhận trong khoảng window gần nhất không vượt quá limit, kể cả ở ranh giới giữa hai cửa sổ.
 type RedisRateLimiter struct {
 	redisClient *redis.Client
 	limit       int
 	window      time.Duration
+	session     *scs.SessionManager
+	now         func() time.Time
+	seq         atomic.Uint64
+}
+
+// RateLimiterOption cấu hình thêm cho RedisRateLimiter
+type RateLimiterOption func(*RedisRateLimiter)
+
+// WithSessionUser giới hạn theo user ID trong session thay vì IP khi người dùng đã đăng nhập.
+// Request ẩn danh vẫn bị giới hạn theo IP. Session phải được nạp trước bởi LoadAndSave.
+func WithSessionUser(session *scs.SessionManager) RateLimiterOption {
+	return func(r *RedisRateLimiter) {
+		r.session = session
+	}
 }
 
-func NewRedisRateLimiter(redisClient *redis.Client, limit int, window time.Duration) *RedisRateLimiter {
-	return &RedisRateLimiter{
+func NewRedisRateLimiter(redisClient *redis.Client, limit int, window time.Duration, opts ...RateLimiterOption) *RedisRateLimiter {
+	r := &RedisRateLimiter{
 		redisClient: redisClient,
 		limit:       limit,
 		window:      window,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	return r
+}
+
+// key trả về bucket của request: theo user ID nếu đã đăng nhập, ngược lại theo IP
+func (r *RedisRateLimiter) key(req *http.Request) string {
+	if r.session != nil {
+		if userID, ok := r.session.Get(req.Context(), string(KeyID)).(uint64); ok {
+			return fmt.Sprintf("rate_limit:user:%d:%s", userID, req.URL.Path)
+		}
+	}
+	return fmt.Sprintf("rate_limit:%s:%s", req.RemoteAddr, req.URL.Path)
 }
 
 func (r *RedisRateLimiter) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		key := fmt.Sprintf("rate_limit:%s:%s", req.RemoteAddr, req.URL.Path)
+		key := r.key(req)
 		ctx := req.Context()
 
 		// Kiểm tra kết nối Redis
@@ -35,20 +98,33 @@ func (r *RedisRateLimiter) Limit(next http.Handler) http.Handler {
 			return
 		}
 
-		// Tăng counter và set expire
-		pipe := r.redisClient.Pipeline()
-		incr := pipe.Incr(ctx, key)
-		pipe.Expire(ctx, key, r.window)
-
-		_, err = pipe.Exec(ctx)
-		if err != nil {
+		now := r.now()
+		member := fmt.Sprintf("%d-%d", now.UnixNano(), r.seq.Add(1))
+		res, err := slidingWindowScript.Run(ctx, r.redisClient, []string{key},
+			now.UnixMicro(), r.window.Microseconds(), r.limit, member).Int64Slice()
+		if err != nil || len(res) != 3 {
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
+		allowed, count := res[0] == 1, res[1]
+
+		// Slot tiếp theo được giải phóng khi request cũ nhất trong cửa sổ hết hạn
+		reset := time.UnixMicro(res[2]).Add(r.window)
+
+		// Báo cho client hạn mức còn lại để tự điều tiết
+		remaining := int64(r.limit) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(r.limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
 
-		count := incr.Val()
-		if count > int64(r.limit) {
-			retryAfter := int(r.window.Seconds())
+		if !allowed {
+			retryAfter := int(math.Ceil(reset.Sub(now).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
 			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
 			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
 			return
@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/gmhafiz/scs/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,6 +67,158 @@ func TestRedisRateLimiter(t *testing.T) {
 	})
 }
 
+func TestRedisRateLimiterSessionUser(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	})
+	defer redisClient.Close()
+
+	session := scs.New()
+	limiter := NewRedisRateLimiter(redisClient, 2, time.Minute, WithSessionUser(session))
+
+	handler := LoadAndSave(session)(limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	// loginAs tạo sẵn một session đã đăng nhập và trả về token của session đó
+	loginAs := func(userID uint64) string {
+		ctx, err := session.Load(context.Background(), "")
+		assert.NoError(t, err)
+		session.Put(ctx, string(KeyID), userID)
+		token, _, err := session.Commit(ctx)
+		assert.NoError(t, err)
+		return token
+	}
+
+	request := func(token string) int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "203.0.113.7:4000" // Cùng một IP, ví dụ sau NAT
+		if token != "" {
+			req.AddCookie(&http.Cookie{Name: session.Cookie.Name, Value: token})
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	alice := loginAs(1)
+	bob := loginAs(2)
+
+	assert.Equal(t, http.StatusOK, request(alice))
+	assert.Equal(t, http.StatusOK, request(alice))
+	assert.Equal(t, http.StatusTooManyRequests, request(alice))
+
+	// Bob dùng chung IP nhưng có bucket riêng
+	assert.Equal(t, http.StatusOK, request(bob))
+	assert.Equal(t, http.StatusOK, request(bob))
+	assert.Equal(t, http.StatusTooManyRequests, request(bob))
+
+	// Request ẩn danh vẫn bị giới hạn theo IP
+	assert.Equal(t, http.StatusOK, request(""))
+	assert.Equal(t, http.StatusOK, request(""))
+	assert.Equal(t, http.StatusTooManyRequests, request(""))
+}
+
+func TestRedisRateLimiterHeaders(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	})
+	defer redisClient.Close()
+
+	limiter := NewRedisRateLimiter(redisClient, 3, time.Minute)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i, want := range []struct {
+		code      int
+		remaining string
+	}{
+		{http.StatusOK, "2"},
+		{http.StatusOK, "1"},
+		{http.StatusOK, "0"},
+		{http.StatusTooManyRequests, "0"},
+	} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/test", nil))
+
+		assert.Equal(t, want.code, rr.Code, "request %d", i+1)
+		assert.Equal(t, "3", rr.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, want.remaining, rr.Header().Get("X-RateLimit-Remaining"), "request %d", i+1)
+
+		reset, err := strconv.ParseInt(rr.Header().Get("X-RateLimit-Reset"), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute).Unix(), reset)
+	}
+}
+
+func TestRedisRateLimiterSlidingWindow(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	})
+	defer redisClient.Close()
+
+	limiter := NewRedisRateLimiter(redisClient, 3, time.Minute)
+
+	// Bắt đầu ngay trước ranh giới phút để request rơi vào hai cửa sổ cố định khác nhau
+	now := time.Date(2025, 3, 1, 9, 0, 59, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/test", nil))
+		return rr
+	}
+
+	// Dùng hết hạn mức ở cuối cửa sổ thứ nhất
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request().Code, "request %d", i+1)
+	}
+
+	// Sang cửa sổ cố định tiếp theo, burst vẫn bị chặn vì 3 request trên còn trong window
+	now = now.Add(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		rr := request()
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code, "request %d", i+1)
+		assert.Equal(t, "58", rr.Header().Get("Retry-After"))
+	}
+
+	// Khi các request cũ trượt ra khỏi window thì hạn mức được trả lại
+	now = now.Add(58 * time.Second)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request().Code, "request %d", i+1)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request().Code)
+}
+
 func TestRedisRateLimiterErrors(t *testing.T) {
 	// Setup Redis client with wrong address to simulate errors
 	redisClient := redis.NewClient(&redis.Options{
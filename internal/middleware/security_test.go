@@ -69,6 +69,27 @@ func TestSecurityHeaders(t *testing.T) {
 				"X-Custom-Header":           "test-value",
 			},
 		},
+		{
+			name: "Additional Directives",
+			config: &security.SecurityConfig{
+				HSTSMaxAge: 600,
+				CSPDirectives: map[string][]string{
+					"img-src":     {"'self'", "data:"},
+					"default-src": {"'none'"},
+					"connect-src": {"'self'"},
+				},
+				FrameOptions:        "DENY",
+				XContentTypeOptions: "nosniff",
+				ReferrerPolicy:      "no-referrer",
+			},
+			expectedHeaders: map[string]string{
+				"Content-Security-Policy":   "default-src 'none'; connect-src 'self'; img-src 'self' data:",
+				"Strict-Transport-Security": "max-age=600",
+				"X-Frame-Options":           "DENY",
+				"X-Content-Type-Options":    "nosniff",
+				"Referrer-Policy":           "no-referrer",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,6 +2,8 @@ package security
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -72,17 +74,17 @@ func BuildCSP(directives map[string][]string) string {
 		}
 	}
 
-	// Then add any remaining directives
-	for directive, sources := range directives {
-		// Skip if already processed
-		isOrdered := false
-		for _, orderedDirective := range orderedDirectives {
-			if directive == orderedDirective {
-				isOrdered = true
-				break
-			}
+	// Then add any remaining directives, sorted so the header is stable across requests
+	remaining := make([]string, 0, len(directives))
+	for directive := range directives {
+		if !slices.Contains(orderedDirectives, directive) {
+			remaining = append(remaining, directive)
 		}
-		if !isOrdered && len(sources) > 0 {
+	}
+	sort.Strings(remaining)
+
+	for _, directive := range remaining {
+		if sources := directives[directive]; len(sources) > 0 {
 			policies = append(policies, fmt.Sprintf("%s %s", directive, strings.Join(sources, " ")))
 		}
 	}
@@ -96,9 +98,15 @@ func BuildFeaturePolicy(policies map[string]string) string {
 		return ""
 	}
 
-	var featurePolicies []string
-	for feature, value := range policies {
-		featurePolicies = append(featurePolicies, fmt.Sprintf("%s=%s", feature, value))
+	features := make([]string, 0, len(policies))
+	for feature := range policies {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	featurePolicies := make([]string, 0, len(features))
+	for _, feature := range features {
+		featurePolicies = append(featurePolicies, fmt.Sprintf("%s=%s", feature, policies[feature]))
 	}
 	return strings.Join(featurePolicies, "; ")
 }
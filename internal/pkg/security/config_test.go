@@ -122,6 +122,33 @@ func TestBuildCSP(t *testing.T) {
 	}
 }
 
+func TestBuildCSP_StableOrder(t *testing.T) {
+	directives := map[string][]string{
+		"img-src":     {"'self'", "data:"},
+		"default-src": {"'self'"},
+		"connect-src": {"'self'", "https://api.example.com"},
+		"style-src":   {"'self'"},
+		"frame-src":   {"'none'"},
+	}
+	expected := "default-src 'self'; style-src 'self'; connect-src 'self' https://api.example.com; frame-src 'none'; img-src 'self' data:"
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, BuildCSP(directives))
+	}
+}
+
+func TestBuildFeaturePolicy_StableOrder(t *testing.T) {
+	policies := map[string]string{
+		"microphone":  "self",
+		"camera":      "none",
+		"geolocation": "*",
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "camera=none; geolocation=*; microphone=self", BuildFeaturePolicy(policies))
+	}
+}
+
 func TestBuildFeaturePolicy(t *testing.T) {
 	tests := []struct {
 		name     string
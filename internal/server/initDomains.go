@@ -1,22 +1,31 @@
 package server
 
 import (
+	"context"
 	"embed"
 	"io/fs"
+	"log"
+	"net"
 	"net/http"
+	"net/smtp"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"mail2calendar/internal/domain/authentication"
+	calendarUseCase "mail2calendar/internal/domain/calendar/usecase"
 	"mail2calendar/internal/domain/health"
 	"mail2calendar/internal/middleware"
 	"mail2calendar/internal/utility/respond"
+	"mail2calendar/third_party/redis"
 )
 
 func (s *Server) InitDomains() {
 	s.initVersion()
 	s.initSwagger()
 	s.initAuthentication()
+	s.initMetrics()
 	s.initHealth()
 }
 
@@ -32,10 +41,35 @@ func (s *Server) initVersion() {
 
 func (s *Server) initHealth() {
 	newHealthRepo := health.NewRepo(s.sqlx)
-	newHealthUseCase := health.New(newHealthRepo)
+
+	var opts []health.Option
+	if s.cfg.Cache.Enable {
+		opts = append(opts, health.WithChecker("redis", health.RedisChecker(redis.New(s.cfg.Cache))))
+	}
+	if s.cfg.NER.ServiceURL != "" {
+		ner := calendarUseCase.NewNERService(s.cfg.NER.ServiceURL,
+			calendarUseCase.WithNERTimeout(s.cfg.NER.RequestTimeout),
+			calendarUseCase.WithNERMetrics(s.metrics),
+		)
+		// Startup goes on without it; the warning only explains why emails would fail
+		if err := ner.Ping(context.Background()); err != nil {
+			log.Printf("NER service at %s is not ready: %v\n", s.cfg.NER.ServiceURL, err)
+		}
+		opts = append(opts, health.WithChecker("ner", health.CheckerFunc(ner.Ping)))
+	}
+
+	newHealthUseCase := health.New(newHealthRepo, opts...)
 	health.RegisterHTTPEndPoints(s.router, newHealthUseCase)
 }
 
+// initMetrics registers the calendar usecase metrics with the default Prometheus
+// registry and exposes it on /metrics. Services built afterwards record into
+// s.metrics through their WithXxxMetrics option.
+func (s *Server) initMetrics() {
+	s.metrics = calendarUseCase.NewMetrics(prometheus.DefaultRegisterer)
+	s.router.Handle("/metrics", promhttp.Handler())
+}
+
 //go:embed docs/*
 var swaggerDocsAssetPath embed.FS
 
@@ -58,5 +92,32 @@ func (s *Server) initSwagger() {
 
 func (s *Server) initAuthentication() {
 	repo := authentication.NewRepo(s.db, s.session)
-	authentication.RegisterHTTPEndPoints(s.router, s.session, repo)
+
+	var opts []authentication.HandlerOption
+	if s.cfg.Cache.Enable {
+		limiter := authentication.NewRedisLoginLimiter(redis.New(s.cfg.Cache))
+		opts = append(opts, authentication.WithLoginLimiter(limiter))
+	}
+
+	authentication.RegisterHTTPEndPoints(s.router, s.session, repo, s.tokenSender(), opts...)
+}
+
+// tokenSender gửi email xác thực và đặt lại mật khẩu qua SMTP. Nếu chưa cấu hình SMTP thì
+// server không khởi động, trừ khi bật MAIL_DEV_LOG_TOKENS khi phát triển.
+func (s *Server) tokenSender() authentication.TokenSender {
+	cfg := s.cfg.Mail
+	if cfg.SMTPHost == "" {
+		if !cfg.DevLogTokens {
+			log.Fatal("MAIL_SMTP_HOST is required to send verification emails; set MAIL_DEV_LOG_TOKENS=true for local development")
+		}
+		log.Println("MAIL_DEV_LOG_TOKENS is enabled: verification and password reset tokens are logged instead of emailed")
+		return authentication.NewDevLogTokenSender()
+	}
+
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPHost)
+	}
+
+	return authentication.NewSMTPTokenSender(net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort), auth, cfg.From, cfg.BaseURL)
 }
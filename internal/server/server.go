@@ -24,6 +24,7 @@ import (
 
 	"mail2calendar/config"
 	"mail2calendar/ent/gen"
+	calendarUseCase "mail2calendar/internal/domain/calendar/usecase"
 	"mail2calendar/internal/middleware"
 	db "mail2calendar/third_party/database"
 	"mail2calendar/third_party/postgresstore"
@@ -45,6 +46,8 @@ type Server struct {
 	cors      *cors.Cors
 	router    *chi.Mux
 
+	metrics *calendarUseCase.Metrics
+
 	httpServer *http.Server
 }
 
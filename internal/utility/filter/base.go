@@ -9,6 +9,7 @@ import (
 const (
 	paginationDefaultPage = 1
 	paginationDefaultSize = 30
+	paginationMaxSize     = 100
 
 	queryParamPage          = "page"
 	queryParamLimit         = "limit"
@@ -27,16 +28,56 @@ type Filter struct {
 	Search bool
 }
 
+// Pagination holds the page size bounds applied to list requests
+type Pagination struct {
+	DefaultSize int
+	MaxSize     int
+}
+
+// DefaultPagination returns the page size bounds used when none are configured
+func DefaultPagination() Pagination {
+	return Pagination{
+		DefaultSize: paginationDefaultSize,
+		MaxSize:     paginationMaxSize,
+	}
+}
+
+// Clamp returns the default size for a non-positive limit and caps oversized limits at MaxSize
+func (p Pagination) Clamp(limit int) int {
+	defaultSize := p.DefaultSize
+	if defaultSize <= 0 {
+		defaultSize = paginationDefaultSize
+	}
+	maxSize := p.MaxSize
+	if maxSize <= 0 {
+		maxSize = paginationMaxSize
+	}
+	if defaultSize > maxSize {
+		defaultSize = maxSize
+	}
+
+	if limit <= 0 {
+		return defaultSize
+	}
+	if limit > maxSize {
+		return maxSize
+	}
+	return limit
+}
+
 func New(queries url.Values) *Filter {
+	return NewWithPagination(queries, DefaultPagination())
+}
+
+// NewWithPagination parses list query parameters, clamping the limit to the given bounds
+func NewWithPagination(queries url.Values, pagination Pagination) *Filter {
 	var page, limit, offset int
 	page, err := strconv.Atoi(queries.Get(queryParamPage))
-	if err != nil {
+	if err != nil || page < 1 {
 		page = paginationDefaultPage
 	}
-	limit, err = strconv.Atoi(queries.Get(queryParamLimit))
-	if err != nil {
-		limit = paginationDefaultSize
-	}
+	limit, _ = strconv.Atoi(queries.Get(queryParamLimit))
+	limit = pagination.Clamp(limit)
 
 	offset, err = strconv.Atoi(queries.Get(queryParamOffset))
 	if err != nil {
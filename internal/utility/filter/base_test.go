@@ -0,0 +1,45 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Limit(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected int
+	}{
+		{name: "absent limit uses default", query: url.Values{}, expected: paginationDefaultSize},
+		{name: "zero limit uses default", query: url.Values{"limit": {"0"}}, expected: paginationDefaultSize},
+		{name: "negative limit uses default", query: url.Values{"limit": {"-5"}}, expected: paginationDefaultSize},
+		{name: "limit within bounds is kept", query: url.Values{"limit": {"50"}}, expected: 50},
+		{name: "limit over max is clamped", query: url.Values{"limit": {"1000"}}, expected: paginationMaxSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, New(tt.query).Limit)
+		})
+	}
+}
+
+func TestNewWithPagination(t *testing.T) {
+	pagination := Pagination{DefaultSize: 10, MaxSize: 20}
+
+	f := NewWithPagination(url.Values{"page": {"3"}}, pagination)
+	assert.Equal(t, 10, f.Limit)
+	assert.Equal(t, 20, f.Offset)
+
+	f = NewWithPagination(url.Values{"limit": {"500"}}, pagination)
+	assert.Equal(t, 20, f.Limit)
+}
+
+func TestPagination_Clamp(t *testing.T) {
+	assert.Equal(t, paginationDefaultSize, Pagination{}.Clamp(0))
+	assert.Equal(t, paginationMaxSize, Pagination{}.Clamp(1000))
+	assert.Equal(t, 5, Pagination{DefaultSize: 50, MaxSize: 5}.Clamp(0))
+}
@@ -20,8 +20,8 @@ type Meta struct {
 	Total int `json:"total"`
 }
 
-// JSON gửi phản hồi dạng JSON
-func JSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+// Json gửi phản hồi dạng JSON
+func Json(w http.ResponseWriter, statusCode int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -37,8 +37,12 @@ class NERService(ner_pb2_grpc.NERServiceServicer):
                     grpc.StatusCode.INVALID_ARGUMENT, "Text cannot be empty"
                 )
 
+            # An empty language means auto-detect; results are cached per language
+            language = request.language
+            cache_key = f"{language}:{text}" if language else text
+
             # Try to get from cache first
-            cached_result = await self.cache.get(text)
+            cached_result = await self.cache.get(cache_key)
             if cached_result:
                 return self._create_response(cached_result)
 
@@ -54,7 +58,7 @@ class NERService(ner_pb2_grpc.NERServiceServicer):
                 processed_entities.append(entity)
 
             # Cache the result
-            await self.cache.set(text, processed_entities)
+            await self.cache.set(cache_key, processed_entities)
 
             return self._create_response(processed_entities)
 
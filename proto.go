@@ -3,4 +3,4 @@
 package main
 
 //go:generate protoc --proto_path=. --go_out=. --go_opt=module=mail2calendar --go-grpc_out=. --go-grpc_opt=module=mail2calendar ner-service/protos/ner.proto
-//go:generate protoc --proto_path=. --go_out=. --go_opt=module=mail2calendar --go-grpc_out=. --go-grpc_opt=module=mail2calendar internal/domain/calendar/proto/calendar.proto
+//go:generate protoc --proto_path=. --proto_path=third_party/googleapis --go_out=. --go_opt=module=mail2calendar --go-grpc_out=. --go-grpc_opt=module=mail2calendar --grpc-gateway_out=. --grpc-gateway_opt=module=mail2calendar internal/domain/calendar/proto/calendar.proto
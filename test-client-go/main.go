@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"mail2calendar/backoff"
 	pb "test-client-go/proto"
 
 	"google.golang.org/grpc"
@@ -18,7 +19,9 @@ const (
 	address = "localhost:50051"
 )
 
-func waitForService(maxRetries int, retryDelay time.Duration) error {
+// waitForService waits up to timeout for the service to report SERVING, retrying with
+// exponential backoff
+func waitForService(ctx context.Context, timeout time.Duration) error {
 	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return fmt.Errorf("did not connect: %v", err)
@@ -26,26 +29,33 @@ func waitForService(maxRetries int, retryDelay time.Duration) error {
 	defer conn.Close()
 
 	healthClient := grpc_health_v1.NewHealthClient(conn)
-	for i := 0; i < maxRetries; i++ {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		resp, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
-		cancel()
+	b := backoff.New(backoff.WithMaxElapsed(timeout))
+	err = b.Retry(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
 
-		if err == nil && resp.Status == grpc_health_v1.HealthCheckResponse_SERVING {
-			fmt.Println("Service is ready!")
-			return nil
+		resp, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			fmt.Printf("Service not ready: %v\n", err)
+			return err
 		}
-
-		fmt.Printf("Service not ready, retrying in %v seconds...\n", retryDelay.Seconds())
-		time.Sleep(retryDelay)
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			fmt.Printf("Service not ready: %v\n", resp.Status)
+			return fmt.Errorf("service status %v", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("service did not become ready: %w", err)
 	}
 
-	return fmt.Errorf("service did not become ready after %d retries", maxRetries)
+	fmt.Println("Service is ready!")
+	return nil
 }
 
 func main() {
 	// Wait for service to be ready
-	if err := waitForService(5, 5*time.Second); err != nil {
+	if err := waitForService(context.Background(), 2*time.Minute); err != nil {
 		log.Fatalf("Failed to wait for service: %v", err)
 	}
 
@@ -59,40 +69,44 @@ func main() {
 	// Create NER client
 	client := pb.NewNERServiceClient(conn)
 
-	// Test cases for multiple languages
-	testCases := []string{
+	// Test cases for multiple languages. Language is left empty where the
+	// service should auto-detect it.
+	testCases := []struct {
+		text     string
+		language string
+	}{
 		// Vietnamese
-		"Tôi có cuộc họp với anh Nam và chị Hương vào lúc 2 giờ chiều ngày mai tại văn phòng công ty ABC ở Hà Nội",
-		"Bộ trưởng Nguyễn Văn A đã có chuyến thăm chính thức tới Microsoft tại Singapore vào tháng trước",
-		"Trường Đại học Bách Khoa Hà Nội tổ chức hội thảo về AI tại Việt Nam",
+		{"Tôi có cuộc họp với anh Nam và chị Hương vào lúc 2 giờ chiều ngày mai tại văn phòng công ty ABC ở Hà Nội", "vi"},
+		{"Bộ trưởng Nguyễn Văn A đã có chuyến thăm chính thức tới Microsoft tại Singapore vào tháng trước", "vi"},
+		{"Trường Đại học Bách Khoa Hà Nội tổ chức hội thảo về AI tại Việt Nam", "vi"},
 
 		// English
-		"John Smith and Mary Johnson will meet with Google's CEO at their New York office tomorrow",
-		"Apple announced their new iPhone at their headquarters in Cupertino, California",
-		"The United Nations conference in Geneva discussed climate change with representatives from China and Russia",
+		{"John Smith and Mary Johnson will meet with Google's CEO at their New York office tomorrow", "en"},
+		{"Apple announced their new iPhone at their headquarters in Cupertino, California", "en"},
+		{"The United Nations conference in Geneva discussed climate change with representatives from China and Russia", "en"},
 
 		// Chinese
-		"李明和王芳将在明天下午在北京微软公司与张总监会面讨论新项目",
-		"中国科学院的研究人员在上海举办了一场关于人工智能的研讨会",
-		"阿里巴巴集团在杭州总部宣布与腾讯合作新计划",
+		{"李明和王芳将在明天下午在北京微软公司与张总监会面讨论新项目", ""},
+		{"中国科学院的研究人员在上海举办了一场关于人工智能的研讨会", ""},
+		{"阿里巴巴集团在杭州总部宣布与腾讯合作新计划", ""},
 
 		// Japanese
-		"田中さんは明日東京のソニー本社で佐藤部長と山本社長と会議があります",
-		"トヨタ自動車は名古屋工場で新型電気自動車の発表会を開催する",
-		"日立製作所の鈴木部長は大阪支社の山田課長と打ち合わせを行う",
+		{"田中さんは明日東京のソニー本社で佐藤部長と山本社長と会議があります", ""},
+		{"トヨタ自動車は名古屋工場で新型電気自動車の発表会を開催する", ""},
+		{"日立製作所の鈴木部長は大阪支社の山田課長と打ち合わせを行う", ""},
 
 		// Korean
-		"김영희는 내일 삼성전자 서울사무소에서 이부장과 박차장을 만날 예정입니다",
-		"현대자동차는 울산공장에서 신형 전기차를 공개했습니다",
-		"LG전자의 정회장은 부산지사의 최부장과 회의를 가졌습니다",
+		{"김영희는 내일 삼성전자 서울사무소에서 이부장과 박차장을 만날 예정입니다", ""},
+		{"현대자동차는 울산공장에서 신형 전기차를 공개했습니다", ""},
+		{"LG전자의 정회장은 부산지사의 최부장과 회의를 가졌습니다", ""},
 	}
 
 	// Process each test case
-	for _, text := range testCases {
-		fmt.Printf("\nInput text: %s\n", text)
+	for _, tc := range testCases {
+		fmt.Printf("\nInput text: %s\n", tc.text)
 
 		// Create the request
-		req := &pb.ExtractEntitiesRequest{Text: text}
+		req := &pb.ExtractEntitiesRequest{Text: tc.text, Language: tc.language}
 
 		// Call the service
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)